@@ -0,0 +1,122 @@
+package encrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// 数据密钥长度, 使用AES-256
+const dataKeySize = 32
+
+// 信封加密结果
+// 明文由随机生成的数据密钥加密, 数据密钥再由主密钥加密, 轮换主密钥时只需重新加密数据密钥
+type Envelope struct {
+	// 被主密钥加密的数据密钥, base64编码
+	EncryptedDataKey string
+	// 被数据密钥加密的密文, base64编码
+	Ciphertext string
+}
+
+// 信封加密, 主密钥长度需为16/24/32字节
+func EnvelopeEncrypt(masterKey, plaintext []byte) (*Envelope, error) {
+	dataKey := make([]byte, dataKeySize)
+	_, err := io.ReadFull(rand.Reader, dataKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "生成数据密钥错误")
+	}
+
+	encryptedDataKey, err := gcmSeal(masterKey, dataKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "加密数据密钥错误")
+	}
+
+	ciphertext, err := gcmSeal(dataKey, plaintext)
+	if err != nil {
+		return nil, errors.Wrap(err, "加密错误")
+	}
+
+	return &Envelope{
+		EncryptedDataKey: base64.StdEncoding.EncodeToString(encryptedDataKey),
+		Ciphertext:       base64.StdEncoding.EncodeToString(ciphertext),
+	}, nil
+}
+
+// 信封解密
+func EnvelopeDecrypt(masterKey []byte, envelope *Envelope) ([]byte, error) {
+	encryptedDataKey, err := base64.StdEncoding.DecodeString(envelope.EncryptedDataKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "解码数据密钥错误")
+	}
+
+	ciphertext, err := base64.StdEncoding.DecodeString(envelope.Ciphertext)
+	if err != nil {
+		return nil, errors.Wrap(err, "解码密文错误")
+	}
+
+	dataKey, err := gcmOpen(masterKey, encryptedDataKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "解密数据密钥错误")
+	}
+
+	plaintext, err := gcmOpen(dataKey, ciphertext)
+	if err != nil {
+		return nil, errors.Wrap(err, "解密错误")
+	}
+
+	return plaintext, nil
+}
+
+// AES-GCM加密, 随机nonce拼接在密文之前
+func gcmSeal(key, plaintext []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	_, err = io.ReadFull(rand.Reader, nonce)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// AES-GCM解密
+func gcmOpen(key, data []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < gcm.NonceSize() {
+		return nil, errors.New("密文长度错误")
+	}
+
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return gcm, nil
+}
@@ -0,0 +1,34 @@
+package encrypt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvelopeEncrypt(t *testing.T) {
+	masterKey := []byte("0123456789abcdef0123456789abcdef")
+
+	t.Run("normal", func(t *testing.T) {
+		envelope, err := EnvelopeEncrypt(masterKey, []byte("secret"))
+		assert.Nil(t, err)
+		assert.NotContains(t, envelope.Ciphertext, "secret")
+
+		plaintext, err := EnvelopeDecrypt(masterKey, envelope)
+		assert.Nil(t, err)
+		assert.Equal(t, "secret", string(plaintext))
+	})
+
+	t.Run("wrong master key", func(t *testing.T) {
+		envelope, err := EnvelopeEncrypt(masterKey, []byte("secret"))
+		assert.Nil(t, err)
+
+		_, err = EnvelopeDecrypt([]byte("fedcba9876543210fedcba9876543210"), envelope)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("invalid master key", func(t *testing.T) {
+		_, err := EnvelopeEncrypt([]byte("short"), []byte("secret"))
+		assert.NotNil(t, err)
+	})
+}
@@ -9,6 +9,13 @@ type Bucket struct {
 
 	// 次数
 	count int64
+
+	// 采样值总和
+	sum int64
+	// 采样次数
+	samples int64
+	// 最小采样值
+	min int64
 }
 
 // 重置
@@ -16,6 +23,9 @@ func (b *Bucket) Reset() {
 	b.failure = 0
 	b.success = 0
 	b.count = 0
+	b.sum = 0
+	b.samples = 0
+	b.min = 0
 }
 
 // 失败
@@ -37,3 +47,12 @@ func (b *Bucket) Increase() {
 func (b *Bucket) Decrease() {
 	b.count--
 }
+
+// 添加采样值
+func (b *Bucket) Add(value int64) {
+	if b.samples == 0 || value < b.min {
+		b.min = value
+	}
+	b.sum += value
+	b.samples++
+}
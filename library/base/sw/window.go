@@ -65,6 +65,14 @@ func (w *SlidingWindow) Decrease() {
 	w.bucketRWMutex.Unlock()
 }
 
+// 添加采样值，如请求耗时
+func (w *SlidingWindow) Add(value int64) {
+	w.bucketRWMutex.Lock()
+	b := w.getCurrentBucket()
+	b.Add(value)
+	w.bucketRWMutex.Unlock()
+}
+
 // 重置
 func (w *SlidingWindow) Reset() {
 	w.bucketRWMutex.Lock()
@@ -174,3 +182,57 @@ func (w *SlidingWindow) Count() int64 {
 
 	return count
 }
+
+// 采样次数
+func (w *SlidingWindow) Samples() int64 {
+	w.bucketRWMutex.RLock()
+
+	var samples int64
+	w.bucketRing.Do(func(x interface{}) {
+		b := x.(*Bucket)
+		samples += b.samples
+	})
+
+	w.bucketRWMutex.RUnlock()
+
+	return samples
+}
+
+// 采样平均值
+func (w *SlidingWindow) Average() float64 {
+	w.bucketRWMutex.RLock()
+
+	var sum, samples int64
+	w.bucketRing.Do(func(x interface{}) {
+		b := x.(*Bucket)
+		sum += b.sum
+		samples += b.samples
+	})
+
+	w.bucketRWMutex.RUnlock()
+
+	if samples == 0 {
+		return 0.0
+	}
+
+	return float64(sum) / float64(samples)
+}
+
+// 最小采样值，无采样时为0
+func (w *SlidingWindow) Min() int64 {
+	w.bucketRWMutex.RLock()
+
+	var min int64
+	found := false
+	w.bucketRing.Do(func(x interface{}) {
+		b := x.(*Bucket)
+		if b.samples > 0 && (!found || b.min < min) {
+			min = b.min
+			found = true
+		}
+	})
+
+	w.bucketRWMutex.RUnlock()
+
+	return min
+}
@@ -30,6 +30,31 @@ func TestSlidingWindowCounts(t *testing.T) {
 	})
 }
 
+func TestSlidingWindowSamples(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		w := NewSlidingWindow(time.Second*10, 2)
+		assert.Equal(t, 0.0, w.Average())
+		assert.Equal(t, int64(0), w.Min())
+
+		w.Add(30)
+		w.Add(10)
+		w.Add(20)
+
+		assert.Equal(t, int64(3), w.Samples())
+		assert.Equal(t, 20.0, w.Average())
+		assert.Equal(t, int64(10), w.Min())
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		w := NewSlidingWindow(time.Second*10, 2)
+		w.Add(10)
+		w.Reset()
+
+		assert.Equal(t, int64(0), w.Samples())
+		assert.Equal(t, int64(0), w.Min())
+	})
+}
+
 func TestSlidingWindowSlide(t *testing.T) {
 	t.Run("normal", func(t *testing.T) {
 		w := NewSlidingWindow(time.Second, 5)
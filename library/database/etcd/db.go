@@ -128,6 +128,7 @@ func (db *DB) loadPrefixData(parentCtx context.Context, prefix string, valueFilt
 
 						dataMap.Store(key, value)
 						db.after(ctx, prefix, string(e.Kv.Key), string(e.Kv.Value), "watch")
+						storeData.notify(key, value)
 					}
 				}
 			}
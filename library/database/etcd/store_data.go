@@ -16,6 +16,11 @@ type StoreData struct {
 	cancel context.CancelFunc
 	// 是否开启监听
 	enableWatch bool
+
+	// 变更回调
+	listeners []func(key, value string)
+	// 变更回调读写锁
+	listenerRWMutex sync.RWMutex
 }
 
 // 获取指定键的值
@@ -27,3 +32,32 @@ func (s *StoreData) Get(key string) (string, error) {
 
 	return value.(string), nil
 }
+
+// 遍历所有键值，f返回false时停止遍历
+func (s *StoreData) Range(f func(key, value string) bool) {
+	s.data.Range(func(key, value interface{}) bool {
+		return f(key.(string), value.(string))
+	})
+}
+
+// 添加变更回调，开启监听时在数据变更后调用，回调不应阻塞
+func (s *StoreData) OnChange(f func(key, value string)) {
+	if f == nil {
+		return
+	}
+
+	s.listenerRWMutex.Lock()
+	s.listeners = append(s.listeners, f)
+	s.listenerRWMutex.Unlock()
+}
+
+// 通知变更
+func (s *StoreData) notify(key, value string) {
+	s.listenerRWMutex.RLock()
+	listeners := s.listeners
+	s.listenerRWMutex.RUnlock()
+
+	for _, f := range listeners {
+		f(key, value)
+	}
+}
@@ -6,6 +6,7 @@ require (
 	github.com/BurntSushi/toml v0.3.1
 	github.com/Shopify/sarama v1.24.1
 	github.com/Shopify/toxiproxy v2.1.4+incompatible
+	github.com/alicebob/miniredis/v2 v2.17.0
 	github.com/cenkalti/backoff v2.2.1+incompatible
 	github.com/codahale/hdrhistogram v0.9.0 // indirect
 	github.com/coreos/etcd v3.3.24+incompatible
@@ -2,23 +2,44 @@ package circuitbreaker
 
 import (
 	"context"
+	"sync"
 	"sync/atomic"
 	"time"
 
 	"gitlab.shanhai.int/sre/library/base/sw"
 	"gitlab.shanhai.int/sre/library/net/errcode"
+	"gitlab.shanhai.int/sre/library/net/metric"
 )
 
 // 断路器状态
-type state = int64
+type State int64
 
 const (
 	// 关
-	closed state = 0
+	StateClosed State = 0
 	// 开
-	open state = 1
+	StateOpen State = 1
+	// 半开，只放行有限的探测请求
+	StateHalfOpen State = 2
 )
 
+// 状态名
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// 状态变化回调函数
+type StateChangeFunc func(cb *Breaker, from, to State)
+
 // 断路器
 type Breaker struct {
 	// 触发断路函数
@@ -28,11 +49,23 @@ type Breaker struct {
 	// 断路重试接口
 	ShouldRetry ShouldRetry
 
+	// 名称，用于统计指标及快照，可能在使用中由断路器组设置，原子读写
+	name atomic.Value
+	// 半开状态下最大并发探测请求数
+	halfOpenMaxProbes int64
+	// 当前探测请求数
+	halfOpenProbes int64
+
 	// 连续失败次数
 	consecutiveFailuresCount int64
 	// 连续成功次数
 	consecutiveSuccessCount int64
 
+	// 断路次数
+	tripCount int64
+	// 拒绝请求次数
+	rejectCount int64
+
 	// 上次请求时间
 	lastRequestTime int64
 	// 上次请求时间
@@ -42,11 +75,18 @@ type Breaker struct {
 
 	// 计数桶滑动窗口
 	counts *sw.SlidingWindow
-	// 断路器状态，分别 open\closed
+	// 断路器状态，分别 open\closed\half-open
 	breakState int64
 	// 是否终止
 	broken int32
 
+	// 状态变化回调
+	listeners     []StateChangeFunc
+	listenerMutex sync.RWMutex
+
+	// 判断函数读写锁，用于动态更新判断函数
+	funcRWMutex sync.RWMutex
+
 	// 修复599问题
 	// https://github.com/golang/go/issues/599
 	_ [4]byte
@@ -54,17 +94,17 @@ type Breaker struct {
 
 // 断路
 func (cb *Breaker) Trip() {
-	atomic.StoreInt64(&cb.breakState, open)
+	cb.setState(StateOpen)
 }
 
 // 放行(关闭断路)
 func (cb *Breaker) Resume() {
-	atomic.StoreInt64(&cb.breakState, closed)
+	cb.setState(StateClosed)
 }
 
 // 重置
 func (cb *Breaker) Reset() {
-	atomic.StoreInt64(&cb.breakState, closed)
+	cb.setState(StateClosed)
 	atomic.StoreInt32(&cb.broken, 0)
 	atomic.StoreInt64(&cb.lastRequestFailTime, 0)
 	atomic.StoreInt64(&cb.lastRequestSuccessTime, 0)
@@ -96,12 +136,16 @@ func (cb *Breaker) Fail() {
 	atomic.StoreInt64(&cb.consecutiveSuccessCount, 0)
 	cb.counts.Fail()
 
-	currentState := cb.currentState()
-	if currentState == closed {
-		if cb.ShouldTrip != nil && cb.ShouldTrip(cb) {
+	switch cb.State() {
+	case StateClosed:
+		if shouldTrip := cb.tripFunc(); shouldTrip != nil && shouldTrip(cb) {
 			cb.Trip()
 		}
-	} else {
+	case StateHalfOpen:
+		// 探测失败，重新断路
+		cb.Trip()
+		defer cb.ShouldRetry.Reset(cb, false)
+	default:
 		defer cb.ShouldRetry.Reset(cb, false)
 	}
 
@@ -120,9 +164,9 @@ func (cb *Breaker) Success() {
 	atomic.StoreInt64(&cb.consecutiveFailuresCount, 0)
 	cb.counts.Success()
 
-	currentState := cb.currentState()
-	if currentState != closed {
-		if cb.ShouldResume != nil && cb.ShouldResume(cb) {
+	currentState := cb.State()
+	if currentState != StateClosed {
+		if shouldResume := cb.resumeFunc(); shouldResume != nil && shouldResume(cb) {
 			cb.Resume()
 		}
 		defer cb.ShouldRetry.Reset(cb, true)
@@ -134,29 +178,74 @@ func (cb *Breaker) Success() {
 }
 
 // 是否准备好，即是否允许真实调用
+// 断路状态下允许重试时进入半开状态，不占用探测请求名额
 func (cb *Breaker) Ready() bool {
 	if atomic.LoadInt32(&cb.broken) == 1 {
 		return true
 	}
 
-	currentState := cb.currentState()
-	if currentState == closed {
+	currentState := cb.State()
+	if currentState == StateClosed {
 		return true
 	} else if cb.ShouldRetry.Retry(cb) {
+		cb.halfOpen()
 		return true
 	}
 
 	return false
 }
 
+// 是否允许调用，返回是否为半开状态下的探测请求
+func (cb *Breaker) allow() (allowed, probe bool) {
+	if atomic.LoadInt32(&cb.broken) == 1 || cb.State() == StateClosed {
+		return true, false
+	}
+
+	// 先占用探测名额，避免重试接口已放行后被拒绝
+	if !cb.acquireProbe() {
+		return false, false
+	}
+	if !cb.ShouldRetry.Retry(cb) {
+		cb.releaseProbe()
+		return false, false
+	}
+
+	cb.halfOpen()
+	return true, true
+}
+
+// 占用探测名额
+func (cb *Breaker) acquireProbe() bool {
+	for {
+		cur := atomic.LoadInt64(&cb.halfOpenProbes)
+		if cur >= cb.halfOpenMaxProbes {
+			return false
+		}
+		if atomic.CompareAndSwapInt64(&cb.halfOpenProbes, cur, cur+1) {
+			return true
+		}
+	}
+}
+
+// 释放探测名额
+func (cb *Breaker) releaseProbe() {
+	atomic.AddInt64(&cb.halfOpenProbes, -1)
+}
+
 // 调用函数
 // 当timeout为0时，表示无超时
+// 半开状态下最多同时放行 HalfOpenMaxProbes 个探测请求
 func (cb *Breaker) Call(ctx context.Context, f func() error, timeout time.Duration) error {
 	var err error
 
-	if !cb.Ready() {
+	allowed, probe := cb.allow()
+	if !allowed {
+		cb.reject()
 		return errcode.BreakerOpenError
 	}
+	if probe {
+		defer cb.releaseProbe()
+	}
 
 	if timeout == 0 {
 		err = f()
@@ -184,14 +273,122 @@ func (cb *Breaker) Call(ctx context.Context, f func() error, timeout time.Durati
 	return nil
 }
 
-// 获取状态
-func (cb *Breaker) currentState() state {
-	return atomic.LoadInt64(&cb.breakState)
+// 拒绝请求
+func (cb *Breaker) reject() {
+	atomic.AddInt64(&cb.rejectCount, 1)
+	if name := cb.Name(); name != "" {
+		metric.BreakerRejectTotal.WithLabelValues(name, cb.State().String()).Inc()
+	}
+}
+
+// 断路状态进入半开状态
+func (cb *Breaker) halfOpen() {
+	if atomic.CompareAndSwapInt64(&cb.breakState, int64(StateOpen), int64(StateHalfOpen)) {
+		cb.stateChanged(StateOpen, StateHalfOpen)
+	}
+}
+
+// 设置状态
+func (cb *Breaker) setState(to State) {
+	from := State(atomic.SwapInt64(&cb.breakState, int64(to)))
+	if from != to {
+		cb.stateChanged(from, to)
+	}
+}
+
+// 状态变化后更新统计指标并通知回调
+func (cb *Breaker) stateChanged(from, to State) {
+	if to == StateOpen {
+		atomic.AddInt64(&cb.tripCount, 1)
+	}
+
+	if name := cb.Name(); name != "" {
+		metric.BreakerState.WithLabelValues(name).Set(float64(to))
+		if to == StateOpen {
+			metric.BreakerTripTotal.WithLabelValues(name).Inc()
+		}
+	}
+
+	cb.listenerMutex.RLock()
+	listeners := cb.listeners
+	cb.listenerMutex.RUnlock()
+
+	for _, f := range listeners {
+		f(cb, from, to)
+	}
 }
 
-// 是否断路状态
+// 更新断路及恢复判断函数，为nil时不更新
+// 已统计的计数及当前状态保持不变
+func (cb *Breaker) SetJudgeFuncs(shouldTrip TripFunc, shouldResume ShouldResume) {
+	cb.funcRWMutex.Lock()
+	if shouldTrip != nil {
+		cb.ShouldTrip = shouldTrip
+	}
+	if shouldResume != nil {
+		cb.ShouldResume = shouldResume
+	}
+	cb.funcRWMutex.Unlock()
+}
+
+// 断路判断函数
+func (cb *Breaker) tripFunc() TripFunc {
+	cb.funcRWMutex.RLock()
+	defer cb.funcRWMutex.RUnlock()
+
+	return cb.ShouldTrip
+}
+
+// 恢复判断函数
+func (cb *Breaker) resumeFunc() ShouldResume {
+	cb.funcRWMutex.RLock()
+	defer cb.funcRWMutex.RUnlock()
+
+	return cb.ShouldResume
+}
+
+// 添加状态变化回调，回调在状态变化的调用中同步执行，不应阻塞
+func (cb *Breaker) OnStateChange(f StateChangeFunc) {
+	if f == nil {
+		return
+	}
+
+	cb.listenerMutex.Lock()
+	listeners := make([]StateChangeFunc, 0, len(cb.listeners)+1)
+	listeners = append(listeners, cb.listeners...)
+	cb.listeners = append(listeners, f)
+	cb.listenerMutex.Unlock()
+}
+
+// 名称
+func (cb *Breaker) Name() string {
+	name, _ := cb.name.Load().(string)
+	return name
+}
+
+// 当前状态
+func (cb *Breaker) State() State {
+	return State(atomic.LoadInt64(&cb.breakState))
+}
+
+// 是否断路状态(包括半开状态)
 func (cb *Breaker) IsTripped() bool {
-	return cb.currentState() == open
+	return cb.State() != StateClosed
+}
+
+// 当前探测请求数
+func (cb *Breaker) HalfOpenProbes() int64 {
+	return atomic.LoadInt64(&cb.halfOpenProbes)
+}
+
+// 断路次数
+func (cb *Breaker) TripCount() int64 {
+	return atomic.LoadInt64(&cb.tripCount)
+}
+
+// 拒绝请求次数
+func (cb *Breaker) RejectCount() int64 {
+	return atomic.LoadInt64(&cb.rejectCount)
 }
 
 // 失败次数
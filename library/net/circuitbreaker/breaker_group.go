@@ -1,7 +1,10 @@
 package circuitbreaker
 
 import (
+	"sort"
 	"sync"
+
+	"github.com/pkg/errors"
 )
 
 // 断路器组
@@ -13,8 +16,12 @@ type BreakerGroup struct {
 }
 
 // 添加断路器
+// 断路器未命名时以组内名称命名
 func (p *BreakerGroup) Add(name string, cb *Breaker) {
 	p.groupRWMutex.Lock()
+	if cb.Name() == "" {
+		cb.setName(name)
+	}
 	p.BreakerMap[name] = cb
 	p.groupRWMutex.Unlock()
 }
@@ -32,6 +39,59 @@ func (p *BreakerGroup) Get(name string) *Breaker {
 	return cb
 }
 
+// 按规则更新断路器，规则错误时返回错误且不更新任何断路器
+// 已存在的断路器只更新判断函数，保留统计及状态，不存在时新建
+func (p *BreakerGroup) UpdateRules(rules []*Rule) error {
+	names := make(map[string]bool, len(rules))
+	for _, r := range rules {
+		if err := r.IsValid(); err != nil {
+			return err
+		}
+		if names[r.Name] {
+			return errors.Errorf("breaker %s is duplicated", r.Name)
+		}
+		names[r.Name] = true
+	}
+
+	p.groupRWMutex.Lock()
+	defer p.groupRWMutex.Unlock()
+
+	for _, r := range rules {
+		if cb, ok := p.BreakerMap[r.Name]; ok {
+			cb.SetJudgeFuncs(r.TripFunc(), r.ResumeFunc())
+			continue
+		}
+
+		p.BreakerMap[r.Name] = NewBreakerWithOptions(&Options{
+			Name:         r.Name,
+			ShouldTrip:   r.TripFunc(),
+			ShouldResume: r.ResumeFunc(),
+		})
+	}
+
+	return nil
+}
+
+// 获取组内所有断路器的快照，按名称排序
+func (p *BreakerGroup) Snapshot() []*Snapshot {
+	p.groupRWMutex.RLock()
+	names := make([]string, 0, len(p.BreakerMap))
+	for name := range p.BreakerMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	res := make([]*Snapshot, 0, len(names))
+	for _, name := range names {
+		snapshot := p.BreakerMap[name].Snapshot()
+		snapshot.Name = name
+		res = append(res, snapshot)
+	}
+	p.groupRWMutex.RUnlock()
+
+	return res
+}
+
 // 新建断路器组
 func NewBreakerGroup() *BreakerGroup {
 	return &BreakerGroup{
@@ -1,6 +1,7 @@
 package circuitbreaker
 
 import (
+	"context"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
@@ -18,3 +19,86 @@ func TestBreakerGroup_Get(t *testing.T) {
 		assert.Nil(t, b)
 	})
 }
+
+func TestBreakerGroup_Snapshot(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		bg := NewBreakerGroup()
+		bg.Add("b", NewBreaker())
+		bg.Add("a", NewThresholdBreaker(1))
+
+		bg.Get("a").Fail()
+
+		snapshots := bg.Snapshot()
+		assert.Len(t, snapshots, 2)
+		assert.Equal(t, "a", snapshots[0].Name)
+		assert.Equal(t, StateOpen.String(), snapshots[0].State)
+		assert.Equal(t, int64(1), snapshots[0].FailureCount)
+		assert.Equal(t, int64(1), snapshots[0].TripCount)
+		assert.Equal(t, "b", snapshots[1].Name)
+		assert.Equal(t, StateClosed.String(), snapshots[1].State)
+
+		// 未命名的断路器以组内名称命名
+		assert.Equal(t, "a", bg.Get("a").Name())
+	})
+}
+
+func TestBreakerGroup_UpdateRules(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		bg := NewBreakerGroup()
+		bg.Add("a", NewThresholdBreaker(3))
+		bg.Get("a").Fail()
+
+		err := bg.UpdateRules([]*Rule{
+			{Name: "a", TripType: TripTypeThreshold, Threshold: 2},
+			{Name: "b", TripType: TripTypeConsecutive, Threshold: 1},
+		})
+		assert.Nil(t, err)
+
+		// 保留已有的统计，新阀值下再失败一次即断路
+		a := bg.Get("a")
+		a.Fail()
+		assert.True(t, a.IsTripped())
+
+		b := bg.Get("b")
+		assert.NotNil(t, b)
+		assert.Equal(t, "b", b.Name())
+		b.Fail()
+		assert.True(t, b.IsTripped())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		bg := NewBreakerGroup()
+		err := bg.UpdateRules([]*Rule{
+			{Name: "a", TripType: TripTypeThreshold, Threshold: 2},
+			{Name: "b", TripType: TripTypeRate, Rate: 2},
+		})
+		assert.NotNil(t, err)
+		assert.Nil(t, bg.Get("a"))
+
+		err = bg.UpdateRules([]*Rule{
+			{Name: "a", TripType: TripTypeThreshold, Threshold: 2},
+			{Name: "a", TripType: TripTypeConsecutive, Threshold: 2},
+		})
+		assert.NotNil(t, err)
+	})
+}
+
+func TestBreakerGroup_AddConcurrently(t *testing.T) {
+	cb := NewThresholdBreaker(1)
+	bg := NewBreakerGroup()
+
+	// 断路器使用中加入组，命名与拒绝请求并发执行
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			cb.Fail()
+			_ = cb.Call(context.Background(), func() error { return nil }, 0)
+		}
+	}()
+	bg.Add("a", cb)
+	<-done
+
+	assert.Equal(t, "a", cb.Name())
+	assert.Equal(t, "a", cb.Snapshot().Name)
+}
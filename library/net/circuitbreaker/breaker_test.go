@@ -2,6 +2,7 @@ package circuitbreaker
 
 import (
 	"context"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -180,6 +181,104 @@ func TestBreaker_Call(t *testing.T) {
 	})
 }
 
+func TestBreaker_HalfOpen(t *testing.T) {
+	newBreaker := func() *Breaker {
+		return NewBreakerWithOptions(&Options{
+			ShouldTrip:        ConsecutiveTripFunc(1),
+			ShouldResume:      ConsecutiveResumeFunc(2),
+			ShouldRetry:       NewFailBackoffRequestRetry(NewExponentialBackOff(time.Millisecond*100, time.Millisecond*100)),
+			HalfOpenMaxProbes: 2,
+		})
+	}
+
+	t.Run("probe limit", func(t *testing.T) {
+		cb := newBreaker()
+		ctx := context.Background()
+
+		cb.Fail()
+		assert.Equal(t, StateOpen, cb.State())
+
+		// 等待静默期后，进入半开状态
+		time.Sleep(time.Millisecond * 200)
+
+		var started sync.WaitGroup
+		release, finished := make(chan struct{}), make(chan struct{})
+		for i := 0; i < 2; i++ {
+			started.Add(1)
+			go func() {
+				_ = cb.Call(ctx, func() error {
+					started.Done()
+					<-release
+					return nil
+				}, 0)
+				finished <- struct{}{}
+			}()
+		}
+		started.Wait()
+		assert.Equal(t, StateHalfOpen, cb.State())
+		assert.Equal(t, int64(2), cb.HalfOpenProbes())
+
+		// 超过探测名额的请求被拒绝
+		err := cb.Call(ctx, func() error { return nil }, 0)
+		assert.Equal(t, errcode.BreakerOpenError, err)
+		assert.Equal(t, int64(1), cb.RejectCount())
+
+		// 探测请求依次成功，关闭断路器
+		for i := 0; i < 2; i++ {
+			release <- struct{}{}
+			<-finished
+		}
+		assert.Equal(t, StateClosed, cb.State())
+		assert.Equal(t, int64(0), cb.HalfOpenProbes())
+	})
+
+	t.Run("probe fail", func(t *testing.T) {
+		cb := newBreaker()
+		ctx := context.Background()
+
+		cb.Fail()
+		time.Sleep(time.Millisecond * 200)
+
+		err := cb.Call(ctx, func() error { return errors.New("this is a test") }, 0)
+		assert.NotNil(t, err)
+		assert.NotEqual(t, errcode.BreakerOpenError, err)
+
+		// 探测失败，重新断路
+		assert.Equal(t, StateOpen, cb.State())
+		assert.Equal(t, int64(2), cb.TripCount())
+		assert.Equal(t, int64(0), cb.HalfOpenProbes())
+	})
+}
+
+func TestBreaker_OnStateChange(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		var changes []string
+		cb := NewBreakerWithOptions(&Options{
+			ShouldTrip:   ConsecutiveTripFunc(1),
+			ShouldResume: ConsecutiveResumeFunc(1),
+			ShouldRetry:  NewSingleRequestRetry(NewExponentialBackOff(time.Millisecond*100, time.Millisecond*100)),
+			OnStateChange: func(cb *Breaker, from, to State) {
+				changes = append(changes, from.String()+"->"+to.String())
+			},
+		})
+
+		var resumed int
+		cb.OnStateChange(func(cb *Breaker, from, to State) {
+			if to == StateClosed {
+				resumed++
+			}
+		})
+
+		cb.Fail()
+		time.Sleep(time.Millisecond * 200)
+		err := cb.Call(context.Background(), func() error { return nil }, 0)
+		assert.Nil(t, err)
+
+		assert.Equal(t, []string{"closed->open", "open->half-open", "half-open->closed"}, changes)
+		assert.Equal(t, 1, resumed)
+	})
+}
+
 func TestBreaker_LastRequestTime(t *testing.T) {
 	t.Run("normal", func(t *testing.T) {
 		cb := NewBreaker()
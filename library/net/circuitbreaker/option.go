@@ -6,8 +6,13 @@ import (
 	"gitlab.shanhai.int/sre/library/base/sw"
 )
 
+// 半开状态下默认最大并发探测请求数
+const DefaultHalfOpenMaxProbes = 5
+
 // 配置
 type Options struct {
+	// 名称，用于统计指标及快照，为空时不上报统计指标
+	Name string
 	// 触发断路函数
 	ShouldTrip TripFunc
 	// 关闭断路函数
@@ -18,6 +23,10 @@ type Options struct {
 	WindowSlideInterval time.Duration
 	// 滑动窗口覆盖的桶数量
 	WindowBucketCount int
+	// 半开状态下 Call 最大并发探测请求数
+	HalfOpenMaxProbes int64
+	// 状态变化回调
+	OnStateChange StateChangeFunc
 }
 
 // 通过配置新建断路器
@@ -49,10 +58,15 @@ func NewBreakerWithOptions(options *Options) *Breaker {
 		options.WindowBucketCount = 10
 	}
 
-	return &Breaker{
+	if options.HalfOpenMaxProbes <= 0 {
+		options.HalfOpenMaxProbes = DefaultHalfOpenMaxProbes
+	}
+
+	cb := &Breaker{
 		ShouldTrip:               options.ShouldTrip,
 		ShouldResume:             options.ShouldResume,
 		ShouldRetry:              options.ShouldRetry,
+		halfOpenMaxProbes:        options.HalfOpenMaxProbes,
 		consecutiveFailuresCount: 0,
 		consecutiveSuccessCount:  0,
 		lastRequestTime:          0,
@@ -60,6 +74,10 @@ func NewBreakerWithOptions(options *Options) *Breaker {
 		lastRequestFailTime:      0,
 		counts:                   sw.NewSlidingWindow(options.WindowSlideInterval, options.WindowBucketCount),
 	}
+	cb.OnStateChange(options.OnStateChange)
+	cb.setName(options.Name)
+
+	return cb
 }
 
 // 新建断路器
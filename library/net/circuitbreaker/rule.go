@@ -0,0 +1,86 @@
+package circuitbreaker
+
+import (
+	"github.com/pkg/errors"
+)
+
+// 断路类型
+type TripType string
+
+const (
+	// 失败比例
+	TripTypeRate TripType = "rate"
+	// 时间间隔内的失败次数
+	TripTypeThreshold TripType = "threshold"
+	// 连续失败次数
+	TripTypeConsecutive TripType = "consecutive"
+)
+
+// 断路器规则，用于动态更新断路器组
+type Rule struct {
+	// 断路器名称，与断路器组中的名称对应，如httpclient中为域名
+	Name string `json:"name"`
+	// 断路类型
+	TripType TripType `json:"trip_type"`
+	// 断路错误比例 [0,1]
+	// 要求TripType为rate
+	Rate float64 `json:"rate"`
+	// 最小采样数
+	// 要求TripType为rate
+	MinSamples int64 `json:"min_samples"`
+	// 失败次数阀值
+	// 要求TripType为threshold或consecutive
+	Threshold int64 `json:"threshold"`
+	// 恢复时的成功比例 [0,1]，为0表示不更新恢复判断函数
+	ResumeRate float64 `json:"resume_rate"`
+	// 恢复时的最小采样数
+	ResumeMinSamples int64 `json:"resume_min_samples"`
+}
+
+// 是否合法
+func (r *Rule) IsValid() error {
+	if r.Name == "" {
+		return errors.New("breaker name is empty")
+	}
+
+	switch r.TripType {
+	case TripTypeRate:
+		if r.Rate <= 0 || r.Rate > 1 {
+			return errors.Errorf("rate of breaker %s should be in (0,1]", r.Name)
+		}
+		if r.MinSamples < 0 {
+			return errors.Errorf("min samples of breaker %s can't be negative", r.Name)
+		}
+	case TripTypeThreshold, TripTypeConsecutive:
+		if r.Threshold <= 0 {
+			return errors.Errorf("threshold of breaker %s should be positive", r.Name)
+		}
+	default:
+		return errors.Errorf("trip type %s of breaker %s is not supported", r.TripType, r.Name)
+	}
+
+	if r.ResumeRate < 0 || r.ResumeRate > 1 || r.ResumeMinSamples < 0 {
+		return errors.Errorf("resume rate or resume min samples of breaker %s is invalid", r.Name)
+	}
+	return nil
+}
+
+// 断路判断函数
+func (r *Rule) TripFunc() TripFunc {
+	switch r.TripType {
+	case TripTypeThreshold:
+		return ThresholdTripFunc(r.Threshold)
+	case TripTypeConsecutive:
+		return ConsecutiveTripFunc(r.Threshold)
+	default:
+		return RateTripFunc(r.Rate, r.MinSamples)
+	}
+}
+
+// 恢复判断函数，未设置恢复比例时为nil
+func (r *Rule) ResumeFunc() ShouldResume {
+	if r.ResumeRate == 0 {
+		return nil
+	}
+	return RateResumeFunc(r.ResumeRate, r.ResumeMinSamples)
+}
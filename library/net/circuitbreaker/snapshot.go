@@ -0,0 +1,67 @@
+package circuitbreaker
+
+import (
+	"sync/atomic"
+	"time"
+
+	"gitlab.shanhai.int/sre/library/net/metric"
+)
+
+// 断路器快照，用于调试接口输出
+type Snapshot struct {
+	// 名称
+	Name string `json:"name"`
+	// 状态
+	State string `json:"state"`
+	// 是否终止
+	Broken bool `json:"broken"`
+	// 滑动窗口内失败次数
+	FailureCount int64 `json:"failure_count"`
+	// 滑动窗口内成功次数
+	SuccessCount int64 `json:"success_count"`
+	// 滑动窗口内错误比例
+	ErrorRate float64 `json:"error_rate"`
+	// 连续失败次数
+	ConsecutiveFailureCount int64 `json:"consecutive_failure_count"`
+	// 连续成功次数
+	ConsecutiveSuccessCount int64 `json:"consecutive_success_count"`
+	// 当前探测请求数
+	HalfOpenProbes int64 `json:"half_open_probes"`
+	// 断路次数
+	TripCount int64 `json:"trip_count"`
+	// 拒绝请求次数
+	RejectCount int64 `json:"reject_count"`
+	// 上一次请求时间
+	LastRequestTime time.Time `json:"last_request_time"`
+	// 上一次请求失败时间
+	LastRequestFailTime time.Time `json:"last_request_fail_time"`
+}
+
+// 获取断路器快照
+func (cb *Breaker) Snapshot() *Snapshot {
+	return &Snapshot{
+		Name:                    cb.Name(),
+		State:                   cb.State().String(),
+		Broken:                  atomic.LoadInt32(&cb.broken) == 1,
+		FailureCount:            cb.FailureCount(),
+		SuccessCount:            cb.SuccessCount(),
+		ErrorRate:               cb.ErrorRate(),
+		ConsecutiveFailureCount: cb.ConsecutiveFailureCount(),
+		ConsecutiveSuccessCount: cb.ConsecutiveSuccessCount(),
+		HalfOpenProbes:          cb.HalfOpenProbes(),
+		TripCount:               cb.TripCount(),
+		RejectCount:             cb.RejectCount(),
+		LastRequestTime:         cb.LastRequestTime(),
+		LastRequestFailTime:     cb.LastRequestFailTime(),
+	}
+}
+
+// 设置名称
+func (cb *Breaker) setName(name string) {
+	if name == "" {
+		return
+	}
+
+	cb.name.Store(name)
+	metric.BreakerState.WithLabelValues(name).Set(float64(cb.State()))
+}
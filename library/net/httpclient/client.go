@@ -4,12 +4,14 @@ import (
 	"context"
 	"net"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
 	"gitlab.shanhai.int/sre/library/base/ctime"
 	"gitlab.shanhai.int/sre/library/base/hook"
 	"gitlab.shanhai.int/sre/library/net/circuitbreaker"
+	"gitlab.shanhai.int/sre/library/net/trafficshaping"
 )
 
 // 客户端
@@ -20,6 +22,10 @@ type Client struct {
 	manager *hook.Manager
 	// 断路器组
 	breakerGroup *circuitbreaker.BreakerGroup
+	// 自适应并发限制器
+	limiters map[string]*trafficshaping.AdaptiveLimiter
+	// 自适应并发限制器读写锁
+	limiterRWMutex sync.RWMutex
 	// 负载均衡器
 	loadBalancer *LoadBalancer
 	// 全局上下文
@@ -55,6 +61,7 @@ func Open(c *Config) (*Client, error) {
 	client.conf = c
 	client.manager = NewHookManager().RegisterLogHook(c.Config, patternMap)
 	client.breakerGroup = circuitbreaker.NewBreakerGroup()
+	client.limiters = make(map[string]*trafficshaping.AdaptiveLimiter)
 	client.globalContext, client.globalCancelFunc = context.WithCancel(context.Background())
 	client.client = &http.Client{
 		Timeout: time.Duration(c.RequestTimeout),
@@ -109,6 +116,45 @@ func (c *Client) Breaker(host string, breaker *circuitbreaker.Breaker) *Client {
 	return c
 }
 
+// 获取所有域名断路器的快照
+func (c *Client) BreakerSnapshot() []*circuitbreaker.Snapshot {
+	return c.breakerGroup.Snapshot()
+}
+
+// 获取域名断路器组，可注册到rulesource.Manager动态更新断路器规则
+func (c *Client) BreakerGroup() *circuitbreaker.BreakerGroup {
+	return c.breakerGroup
+}
+
+// 设置不同域名的自适应并发限制器
+func (c *Client) ConcurrencyLimiter(host string, limiter *trafficshaping.AdaptiveLimiter) *Client {
+	c.limiterRWMutex.Lock()
+	c.limiters[host] = limiter
+	c.limiterRWMutex.Unlock()
+	return c
+}
+
+// 获取域名的自适应并发限制器，未开启自适应并发限制时只返回手动设置的限制器
+func (c *Client) getConcurrencyLimiter(host string, autoCreate bool) *trafficshaping.AdaptiveLimiter {
+	c.limiterRWMutex.RLock()
+	limiter := c.limiters[host]
+	c.limiterRWMutex.RUnlock()
+	if limiter != nil || !autoCreate {
+		return limiter
+	}
+
+	c.limiterRWMutex.Lock()
+	defer c.limiterRWMutex.Unlock()
+
+	if limiter = c.limiters[host]; limiter == nil {
+		limiter = trafficshaping.NewAdaptiveLimiter(&trafficshaping.Rule{
+			Type: trafficshaping.AdaptiveConcurrency,
+		})
+		c.limiters[host] = limiter
+	}
+	return limiter
+}
+
 // 创建构建器
 func (c *Client) Builder() *Span {
 	return NewSpan(c)
@@ -28,6 +28,10 @@ type Config struct {
 	// 断路器断路最小采样数
 	BreakerMinSample int `yaml:"breakerMinSample"`
 
+	// 是否开启自适应并发限制，开启后为每个域名创建默认的自适应并发限制器
+	// 通过Client.ConcurrencyLimiter设置的限制器不受该配置影响
+	EnableConcurrencyLimiter bool `yaml:"enableConcurrencyLimiter"`
+
 	// 是否关闭链路跟踪
 	DisableTracing bool `yaml:"disableTracing"`
 	// 是否关闭sentry
@@ -2,8 +2,10 @@ package httpclient
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"strconv"
 	"time"
@@ -94,6 +96,53 @@ func GetAccessStatusCodeHandler() HandlerFunc {
 	}
 }
 
+// 自适应并发限制处理方法
+// 超过并发限制时直接返回错误，不发出请求
+func GetConcurrencyLimiterHandler() HandlerFunc {
+	return func(b *Span) {
+		limiter, err := b.GetUrlConcurrencyLimiter(b.url)
+		if err != nil {
+			b.SetError(err)
+			return
+		} else if limiter == nil {
+			b.Next()
+			return
+		}
+
+		if !limiter.Acquire() {
+			b.SetError(errors.Wrapf(errcode.ConcurrencyLimitError, "limit: %.0f", limiter.Limit()))
+			return
+		}
+
+		startTime := time.Now()
+		b.Next()
+
+		err = b.GetError()
+		// 断路器拒绝时请求未实际发出，不上报耗时
+		if errcode.EqualError(errcode.BreakerOpenError, err) || errcode.EqualError(errcode.BreakerDegradedError, err) {
+			limiter.Cancel()
+			return
+		}
+		limiter.Release(time.Since(startTime), isOverloaded(b.Response, err))
+	}
+}
+
+// 是否为过载导致的失败，如超时或服务端返回429/503
+func isOverloaded(resp *http.Response, err error) bool {
+	if resp != nil && (resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable) {
+		return true
+	}
+	if err == nil {
+		return false
+	}
+
+	if errcode.EqualError(errcode.BreakerTimeoutError, err) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
 // 断路器处理方法
 func GetBreakerHandler() HandlerFunc {
 	return func(b *Span) {
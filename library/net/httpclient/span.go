@@ -16,6 +16,7 @@ import (
 	"gitlab.shanhai.int/sre/library/base/hook"
 	"gitlab.shanhai.int/sre/library/base/runtime"
 	"gitlab.shanhai.int/sre/library/net/circuitbreaker"
+	"gitlab.shanhai.int/sre/library/net/trafficshaping"
 )
 
 type Span struct {
@@ -85,7 +86,7 @@ func NewSpan(client *Client) *Span {
 		handlerIndex:     -1,
 		// tracing、metrics、sentry、log通过hook来实现
 		handlerChain: []HandlerFunc{
-			GetBreakerHandler(), GetFilterHandler(), GetMetricsHandler(),
+			GetConcurrencyLimiterHandler(), GetBreakerHandler(), GetFilterHandler(), GetMetricsHandler(),
 			GetTracingHandler(), GetSentryHandler(), GetK8sLoadBalancerHandler(),
 		},
 		client:       client,
@@ -149,6 +150,16 @@ func (b *Span) GetUrlBreaker(rawUrl string) (*circuitbreaker.Breaker, error) {
 	}
 }
 
+// 获取当前url的自适应并发限制器，没有限制器时返回nil
+func (b *Span) GetUrlConcurrencyLimiter(rawUrl string) (*trafficshaping.AdaptiveLimiter, error) {
+	parsedUrl, err := url.Parse(rawUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.client.getConcurrencyLimiter(parsedUrl.Host, b.conf.EnableConcurrencyLimiter), nil
+}
+
 // 获取配置
 func (b *Span) GetConfig() Config {
 	return b.conf
@@ -184,6 +195,12 @@ func (b *Span) DisableBreaker(disableBreaker bool) *Span {
 	return b
 }
 
+// 设置是否开启自适应并发限制
+func (b *Span) EnableConcurrencyLimiter(enable bool) *Span {
+	b.conf.EnableConcurrencyLimiter = enable
+	return b
+}
+
 // 设置断路器断路最小错误比例 0~1
 func (b *Span) BreakerRate(rate float64) *Span {
 	if rate > 1.0 || rate < 0 {
@@ -4,6 +4,8 @@ import (
 	"context"
 	"math/rand"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"sync"
 	"testing"
 	"time"
@@ -11,6 +13,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"gitlab.shanhai.int/sre/library/net/errcode"
+	"gitlab.shanhai.int/sre/library/net/trafficshaping"
 )
 
 func TestClient_Span(t *testing.T) {
@@ -198,6 +201,36 @@ func TestGetBreakerHandler(t *testing.T) {
 	})
 }
 
+func TestGetConcurrencyLimiterHandler(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	serverUrl, err := url.Parse(server.URL)
+	assert.Nil(t, err)
+
+	limiter := trafficshaping.NewAdaptiveLimiter(&trafficshaping.Rule{
+		Type:  trafficshaping.AdaptiveConcurrency,
+		Limit: 1,
+	})
+	c.ConcurrencyLimiter(serverUrl.Host, limiter)
+
+	t.Run("reject", func(t *testing.T) {
+		assert.True(t, limiter.Acquire())
+		defer limiter.Cancel()
+
+		resp := c.Builder().URL(server.URL).Fetch(context.Background())
+		assert.True(t, errcode.EqualError(errcode.ConcurrencyLimitError, resp.Error()))
+	})
+
+	t.Run("normal", func(t *testing.T) {
+		resp := c.Builder().URL(server.URL).Fetch(context.Background())
+		assert.Nil(t, resp.Error())
+		assert.Equal(t, int64(0), limiter.Inflight())
+	})
+}
+
 func TestSpan_AccessStatusCode(t *testing.T) {
 	t.Run("normal", func(t *testing.T) {
 		resp := c.Builder().
@@ -0,0 +1,27 @@
+package metric
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// 断路器状态，0为关闭，1为断路，2为半开
+var BreakerState = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "circuit_breaker_state",
+	},
+	[]string{"name"},
+)
+
+// 断路器断路次数
+var BreakerTripTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "circuit_breaker_trip_total",
+	},
+	[]string{"name"},
+)
+
+// 断路器拒绝请求数量
+var BreakerRejectTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "circuit_breaker_reject_total",
+	},
+	[]string{"name", "state"},
+)
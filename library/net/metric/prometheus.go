@@ -30,12 +30,18 @@ var OtherCollector = []prometheus.Collector{
 	GoroutineRequestTotal, GoroutineRequestDurationSummary, GoroutineResponseTotal,
 }
 
+// 断路器收集器
+var BreakerCollector = []prometheus.Collector{
+	BreakerState, BreakerTripTotal, BreakerRejectTotal,
+}
+
 // 初始化
 func Init() {
 	collector := make([]prometheus.Collector, 0)
 	collector = append(collector, WebCollector...)
 	collector = append(collector, DBCollector...)
 	collector = append(collector, OtherCollector...)
+	collector = append(collector, BreakerCollector...)
 
 	CustomInit(collector...)
 }
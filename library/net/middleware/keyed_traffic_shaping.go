@@ -0,0 +1,143 @@
+package middleware
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	_context "gitlab.shanhai.int/sre/library/base/context"
+	"gitlab.shanhai.int/sre/library/net/errcode"
+	"gitlab.shanhai.int/sre/library/net/response"
+	"gitlab.shanhai.int/sre/library/net/trafficshaping"
+)
+
+const (
+	// 限流响应头
+	HeaderRateLimitLimit     = "RateLimit-Limit"
+	HeaderRateLimitRemaining = "RateLimit-Remaining"
+	HeaderRateLimitReset     = "RateLimit-Reset"
+	HeaderRetryAfter         = "Retry-After"
+
+	// qps滑动窗口重置时间，秒
+	rateLimitResetSeconds = 1
+)
+
+// 限流key提取函数，返回空字符串表示不限流
+type KeyFunc func(c *gin.Context) string
+
+// 按请求头限流
+func HeaderKey(name string) KeyFunc {
+	return func(c *gin.Context) string {
+		return c.GetHeader(name)
+	}
+}
+
+// 按客户端ip限流
+func ClientIPKey() KeyFunc {
+	return func(c *gin.Context) string {
+		return c.ClientIP()
+	}
+}
+
+// 按路由模版限流，未匹配路由时不限流
+func RouteKey() KeyFunc {
+	return func(c *gin.Context) string {
+		path := c.FullPath()
+		if path == "" {
+			return ""
+		}
+		return c.Request.Method + " " + path
+	}
+}
+
+// 按jwt的sub字段限流
+// 只读取认证中间件校验签名后以string写入gin上下文subjectKey的sub，不解析未校验的Authorization请求头
+// 需在认证中间件之后注册，上下文中没有sub时不限流
+func JWTSubjectKey(subjectKey string) KeyFunc {
+	return func(c *gin.Context) string {
+		return c.GetString(subjectKey)
+	}
+}
+
+// 按key流量控制配置
+type KeyedTrafficShapingConfig struct {
+	trafficshaping.KeyedPipelineConfig
+	// key提取函数
+	KeyFunc KeyFunc
+}
+
+// 按key流量控制中间件
+// 每个key使用独立的管道，避免单个客户端耗尽所有请求的限制
+// 当配置错误时，会panic
+func KeyedTrafficShapingMiddleware(cfg *KeyedTrafficShapingConfig) gin.HandlerFunc {
+	kp, err := trafficshaping.NewKeyedPipeline(&cfg.KeyedPipelineConfig)
+	if err != nil {
+		panic(err)
+	}
+
+	return KeyedPipelineTrafficShapingMiddleware(kp, cfg.KeyFunc)
+}
+
+// 使用调用方创建的按key管道的流量控制中间件
+// 调用方持有管道，可注册到rulesource.Manager等动态更新规则
+// 当参数错误时，会panic
+func KeyedPipelineTrafficShapingMiddleware(kp *trafficshaping.KeyedPipeline, keyFunc KeyFunc) gin.HandlerFunc {
+	if kp == nil {
+		panic("traffic shaping keyed pipeline is nil")
+	}
+	if keyFunc == nil {
+		panic("traffic shaping key func is nil")
+	}
+
+	return func(c *gin.Context) {
+		key := keyFunc(c)
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		p, err := kp.Get(key)
+		if err != nil {
+			response.StandardJSON(c, nil, err)
+			c.Abort()
+			return
+		}
+
+		doTrafficShaping(c, p)
+	}
+}
+
+// 执行流量控制，并设置限流响应头
+func doTrafficShaping(c *gin.Context, p *trafficshaping.Pipeline) {
+	limit := p.QPSLimit()
+	if limit > 0 {
+		remaining := int64(limit) - p.QPS() - 1
+		if remaining < 0 {
+			remaining = 0
+		}
+		c.Header(HeaderRateLimitLimit, strconv.FormatInt(int64(math.Ceil(limit)), 10))
+		c.Header(HeaderRateLimitRemaining, strconv.FormatInt(remaining, 10))
+		c.Header(HeaderRateLimitReset, strconv.Itoa(rateLimitResetSeconds))
+	}
+
+	err := p.Do(func() {
+		c.Next()
+	})
+	if err != nil {
+		abortTooManyRequests(c, time.Second*rateLimitResetSeconds)
+	}
+}
+
+// 拒绝请求
+func abortTooManyRequests(c *gin.Context, retryAfter time.Duration) {
+	c.Header(HeaderRetryAfter, strconv.FormatInt(int64(math.Ceil(retryAfter.Seconds())), 10))
+	if c.Writer.Header().Get(HeaderRateLimitLimit) != "" {
+		c.Header(HeaderRateLimitRemaining, "0")
+	}
+
+	// 被拒绝的请求不记录错误日志，避免流量突增时产生大量日志
+	c.Set(_context.ContextErrCode, errcode.TooManyRequests.Code())
+	c.AbortWithStatusJSON(errcode.TooManyRequests.StatusCode(),
+		(&response.V2Response{}).WithErrCode(errcode.TooManyRequests))
+}
@@ -0,0 +1,203 @@
+package middleware
+
+import (
+	"encoding/base64"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	httpUtil "gitlab.shanhai.int/sre/library/base/net"
+	"gitlab.shanhai.int/sre/library/net/errcode"
+	"gitlab.shanhai.int/sre/library/net/trafficshaping"
+)
+
+func TestKeyedTrafficShapingMiddleware(t *testing.T) {
+	newRouter := func() *gin.Engine {
+		router := gin.New()
+		router.Use(KeyedTrafficShapingMiddleware(&KeyedTrafficShapingConfig{
+			KeyedPipelineConfig: trafficshaping.KeyedPipelineConfig{
+				Rules: []*trafficshaping.Rule{
+					{
+						Type:            trafficshaping.QPS,
+						ControlBehavior: trafficshaping.Reject,
+						Limit:           2,
+					},
+				},
+				Overrides: map[string][]*trafficshaping.Rule{
+					"vip": {
+						{
+							Type:            trafficshaping.QPS,
+							ControlBehavior: trafficshaping.Reject,
+							Limit:           5,
+						},
+					},
+				},
+			},
+			KeyFunc: HeaderKey("QT-User-ID"),
+		}))
+		router.GET("/", func(c *gin.Context) {
+			c.JSON(http.StatusOK, nil)
+		})
+		return router
+	}
+
+	request := func(router *gin.Engine, user string) (int, http.Header) {
+		header := make(http.Header)
+		if user != "" {
+			header.Add("QT-User-ID", user)
+		}
+		r, err := httpUtil.TestGinJsonRequest(router, "GET", "/", header, nil, nil)
+		assert.Nil(t, err)
+		return r.Code, r.Header()
+	}
+
+	t.Run("normal", func(t *testing.T) {
+		router := newRouter()
+
+		code, header := request(router, "a")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "2", header.Get(HeaderRateLimitLimit))
+		assert.Equal(t, "1", header.Get(HeaderRateLimitRemaining))
+		assert.Equal(t, "1", header.Get(HeaderRateLimitReset))
+
+		code, _ = request(router, "a")
+		assert.Equal(t, http.StatusOK, code)
+
+		code, header = request(router, "a")
+		assert.Equal(t, http.StatusTooManyRequests, code)
+		assert.Equal(t, "0", header.Get(HeaderRateLimitRemaining))
+		assert.Equal(t, "1", header.Get(HeaderRetryAfter))
+
+		// 其他key不受影响
+		code, _ = request(router, "b")
+		assert.Equal(t, http.StatusOK, code)
+	})
+
+	t.Run("override", func(t *testing.T) {
+		router := newRouter()
+
+		for i := 0; i < 5; i++ {
+			code, header := request(router, "vip")
+			assert.Equal(t, http.StatusOK, code)
+			assert.Equal(t, "5", header.Get(HeaderRateLimitLimit))
+		}
+		code, _ := request(router, "vip")
+		assert.Equal(t, http.StatusTooManyRequests, code)
+	})
+
+	t.Run("empty key", func(t *testing.T) {
+		router := newRouter()
+
+		for i := 0; i < 5; i++ {
+			code, header := request(router, "")
+			assert.Equal(t, http.StatusOK, code)
+			assert.Empty(t, header.Get(HeaderRateLimitLimit))
+		}
+	})
+
+	t.Run("reject body", func(t *testing.T) {
+		router := newRouter()
+
+		request(router, "a")
+		request(router, "a")
+
+		header := make(http.Header)
+		header.Add("QT-User-ID", "a")
+		r, err := httpUtil.TestGinJsonRequest(router, "GET", "/", header, nil, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusTooManyRequests, r.Code)
+		assert.Contains(t, r.Body.String(), `"errcode":1040429`)
+	})
+
+	t.Run("caller owned pipeline", func(t *testing.T) {
+		rules := []*trafficshaping.Rule{
+			{
+				Type:            trafficshaping.QPS,
+				ControlBehavior: trafficshaping.Reject,
+				Limit:           2,
+			},
+		}
+		kp, err := trafficshaping.NewKeyedPipeline(&trafficshaping.KeyedPipelineConfig{
+			Rules: rules,
+		})
+		assert.Nil(t, err)
+
+		router := gin.New()
+		router.Use(KeyedPipelineTrafficShapingMiddleware(kp, HeaderKey("QT-User-ID")))
+		router.GET("/", func(c *gin.Context) {
+			c.JSON(http.StatusOK, nil)
+		})
+
+		_, header := request(router, "vip")
+		assert.Equal(t, "2", header.Get(HeaderRateLimitLimit))
+
+		// 更新规则后中间件使用新规则
+		err = kp.UpdateRules(rules, map[string][]*trafficshaping.Rule{
+			"vip": {
+				{
+					Type:            trafficshaping.QPS,
+					ControlBehavior: trafficshaping.Reject,
+					Limit:           5,
+				},
+			},
+		})
+		assert.Nil(t, err)
+		_, header = request(router, "vip")
+		assert.Equal(t, "5", header.Get(HeaderRateLimitLimit))
+	})
+}
+
+func TestKeyFunc(t *testing.T) {
+	t.Run("route", func(t *testing.T) {
+		router := gin.New()
+		router.GET("/users/:id", func(c *gin.Context) {
+			assert.Equal(t, "GET /users/:id", RouteKey()(c))
+		})
+
+		r, err := httpUtil.TestGinJsonRequest(router, "GET", "/users/1", nil, nil, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, r.Code)
+	})
+
+	t.Run("jwt subject", func(t *testing.T) {
+		router := gin.New()
+		// 模拟认证中间件，校验通过时写入sub
+		router.Use(func(c *gin.Context) {
+			if c.GetHeader("Authorization") == "Bearer valid" {
+				c.Set("jwt_subject", "user-1")
+			}
+		})
+		router.GET("/", func(c *gin.Context) {
+			c.String(http.StatusOK, JWTSubjectKey("jwt_subject")(c))
+		})
+
+		header := make(http.Header)
+		header.Add("Authorization", "Bearer valid")
+		r, err := httpUtil.TestGinJsonRequest(router, "GET", "/", header, nil, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, "user-1", r.Body.String())
+
+		// 未校验的jwt不作为key
+		payload := base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"user-2"}`))
+		header.Set("Authorization", "Bearer header."+payload+".signature")
+		r, err = httpUtil.TestGinJsonRequest(router, "GET", "/", header, nil, nil)
+		assert.Nil(t, err)
+		assert.Empty(t, r.Body.String())
+	})
+}
+
+func TestAbortTooManyRequests(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		router := gin.New()
+		router.GET("/", func(c *gin.Context) {
+			abortTooManyRequests(c, 0)
+		})
+
+		r, err := httpUtil.TestGinJsonRequest(router, "GET", "/", nil, nil, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, errcode.TooManyRequests.StatusCode(), r.Code)
+		assert.Equal(t, "0", r.Header().Get(HeaderRetryAfter))
+		assert.Empty(t, r.Header().Get(HeaderRateLimitRemaining))
+	})
+}
@@ -1,26 +1,28 @@
 package middleware
 
 import (
-	"net/http"
-
 	"github.com/gin-gonic/gin"
 	"gitlab.shanhai.int/sre/library/net/trafficshaping"
 )
 
 // 流量控制中间件
+// 集群规则需要传入trafficshaping.ClusterController，如redisctl.Controller
 // 当规则错误时，会panic
-func TrafficShapingMiddleware(rules []*trafficshaping.Rule) gin.HandlerFunc {
-	p, err := trafficshaping.NewPipeline(rules)
+func TrafficShapingMiddleware(rules []*trafficshaping.Rule, extraCtls ...trafficshaping.Controller) gin.HandlerFunc {
+	p, err := trafficshaping.NewPipeline(rules, extraCtls...)
 	if err != nil {
 		panic(err)
 	}
+	return PipelineTrafficShapingMiddleware(p)
+}
+
+// 使用调用方创建的管道的流量控制中间件
+// 调用方持有管道，可注册到rulesource.Manager等动态更新规则
+func PipelineTrafficShapingMiddleware(p *trafficshaping.Pipeline) gin.HandlerFunc {
+	if p == nil {
+		panic("traffic shaping pipeline is nil")
+	}
 	return func(c *gin.Context) {
-		err := p.Do(func() {
-			c.Next()
-		})
-		if err != nil {
-			c.Writer.WriteHeader(http.StatusTooManyRequests)
-			c.Abort()
-		}
+		doTrafficShaping(c, p)
 	}
 }
@@ -0,0 +1,48 @@
+package rulesource
+
+import (
+	"context"
+
+	render "gitlab.shanhai.int/sre/library/base/logrender"
+	"gitlab.shanhai.int/sre/library/net/agollo"
+	"gitlab.shanhai.int/sre/library/net/circuitbreaker"
+	"gitlab.shanhai.int/sre/library/net/trafficshaping"
+)
+
+// Apollo的rules namespace中配置
+// trafficshaping.api: [{"type":"qps","limit":100},{"type":"adaptive_concurrency","max_limit":200}]
+// circuitbreaker.http: [{"name":"example.com","trip_type":"rate","rate":0.5,"min_samples":20}]
+func ExampleWatchApollo() {
+	p, err := trafficshaping.NewPipeline([]*trafficshaping.Rule{
+		{
+			Type:            trafficshaping.QPS,
+			ControlBehavior: trafficshaping.Reject,
+			Limit:           10,
+		},
+	})
+	if err != nil {
+		panic(err)
+	}
+	bg := circuitbreaker.NewBreakerGroup()
+
+	m := NewManager()
+	_ = m.RegisterPipeline("api", p)
+	_ = m.RegisterBreakerGroup("http", bg)
+
+	client := agollo.NewClient(&agollo.Config{
+		AppID:             "your_appID",
+		Cluster:           "dev",
+		ServerHost:        "http://localhost:8080",
+		NotDaemon:         true,
+		PreloadNamespaces: []string{"rules"},
+		Config: &render.Config{
+			Stdout: true,
+		},
+	})
+	defer client.Close()
+
+	err = WatchApollo(context.Background(), client, "rules", m)
+	if err != nil {
+		panic(err)
+	}
+}
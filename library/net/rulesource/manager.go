@@ -0,0 +1,231 @@
+package rulesource
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"gitlab.shanhai.int/sre/library/log"
+	"gitlab.shanhai.int/sre/library/net/circuitbreaker"
+	"gitlab.shanhai.int/sre/library/net/trafficshaping"
+)
+
+const (
+	// 流量控制规则的配置键前缀，配置键为 trafficshaping.<管道名称>
+	// 管道的值为TrafficRule数组的json，按key区分的管道的值为KeyedTrafficRules的json
+	TrafficShapingKeyPrefix = "trafficshaping."
+	// 断路器规则的配置键前缀，配置键为 circuitbreaker.<断路器组名称>，值为circuitbreaker.Rule数组的json
+	CircuitBreakerKeyPrefix = "circuitbreaker."
+)
+
+// 可动态更新流量控制规则的管道，如trafficshaping.Pipeline
+type TrafficRuleUpdater interface {
+	// 更新规则，规则错误时返回错误且不影响当前规则
+	UpdateRules(rules []*trafficshaping.Rule) error
+}
+
+// 可动态更新默认规则及指定key规则的管道，如trafficshaping.KeyedPipeline
+type KeyedTrafficRuleUpdater interface {
+	// 更新默认规则及指定key的规则，规则错误时返回错误且不影响当前规则
+	UpdateRules(rules []*trafficshaping.Rule, overrides map[string][]*trafficshaping.Rule) error
+}
+
+// 规则管理器
+// 将配置中心的规则应用到已注册的管道及断路器组，规则错误时记录日志并保留当前规则
+type Manager struct {
+	// 互斥锁
+	mutex sync.Mutex
+	// 管道，key为名称
+	pipelines map[string]TrafficRuleUpdater
+	// 按key区分的管道，key为名称
+	keyedPipelines map[string]KeyedTrafficRuleUpdater
+	// 断路器组，key为名称
+	breakerGroups map[string]*circuitbreaker.BreakerGroup
+	// 最近收到的规则，key为配置键
+	values map[string]string
+	// 已成功应用的规则，key为配置键
+	applied map[string]string
+}
+
+// 新建规则管理器
+func NewManager() *Manager {
+	return &Manager{
+		pipelines:      make(map[string]TrafficRuleUpdater),
+		keyedPipelines: make(map[string]KeyedTrafficRuleUpdater),
+		breakerGroups:  make(map[string]*circuitbreaker.BreakerGroup),
+		values:         make(map[string]string),
+		applied:        make(map[string]string),
+	}
+}
+
+// 注册管道，已收到该管道的规则时立即应用
+func (m *Manager) RegisterPipeline(name string, p TrafficRuleUpdater) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	key := TrafficShapingKeyPrefix + name
+	m.pipelines[name] = p
+	delete(m.keyedPipelines, name)
+	delete(m.applied, key)
+
+	if value, ok := m.values[key]; ok {
+		return m.apply(key, value)
+	}
+	return nil
+}
+
+// 注册按key区分的管道，已收到该管道的规则时立即应用
+func (m *Manager) RegisterKeyedPipeline(name string, p KeyedTrafficRuleUpdater) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	key := TrafficShapingKeyPrefix + name
+	m.keyedPipelines[name] = p
+	delete(m.pipelines, name)
+	delete(m.applied, key)
+
+	if value, ok := m.values[key]; ok {
+		return m.apply(key, value)
+	}
+	return nil
+}
+
+// 注册断路器组，已收到该断路器组的规则时立即应用
+func (m *Manager) RegisterBreakerGroup(name string, g *circuitbreaker.BreakerGroup) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	key := CircuitBreakerKeyPrefix + name
+	m.breakerGroups[name] = g
+	delete(m.applied, key)
+
+	if value, ok := m.values[key]; ok {
+		return m.apply(key, value)
+	}
+	return nil
+}
+
+// 应用配置中的规则，忽略非规则的配置键及未变化的规则
+// 错误的规则记录日志后跳过，不影响其他规则，返回第一个错误
+func (m *Manager) Apply(data map[string]string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	// 按配置键排序，保证应用顺序稳定
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		if strings.HasPrefix(key, TrafficShapingKeyPrefix) || strings.HasPrefix(key, CircuitBreakerKeyPrefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var firstErr error
+	for _, key := range keys {
+		value := data[key]
+		m.values[key] = value
+		if applied, ok := m.applied[key]; ok && applied == value {
+			continue
+		}
+
+		if err := m.apply(key, value); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+// 应用单个配置键的规则，目标未注册时跳过
+func (m *Manager) apply(key, value string) error {
+	var err error
+	if name := strings.TrimPrefix(key, TrafficShapingKeyPrefix); name != key {
+		if p, ok := m.pipelines[name]; ok {
+			err = applyTrafficRules(p, value)
+		} else if kp, ok := m.keyedPipelines[name]; ok {
+			err = applyKeyedTrafficRules(kp, value)
+		} else {
+			return nil
+		}
+	} else if name := strings.TrimPrefix(key, CircuitBreakerKeyPrefix); name != key {
+		g, ok := m.breakerGroups[name]
+		if !ok {
+			return nil
+		}
+		err = applyBreakerRules(g, value)
+	}
+
+	if err != nil {
+		err = errors.Wrapf(err, "apply rules of %s error", key)
+		log.Error("%s, keep current rules, value: %s", err, value)
+		return err
+	}
+
+	m.applied[key] = value
+	log.Info("apply rules of %s success, value: %s", key, value)
+	return nil
+}
+
+// 应用流量控制规则
+func applyTrafficRules(p TrafficRuleUpdater, value string) error {
+	configs := make([]*TrafficRule, 0)
+	if err := json.Unmarshal([]byte(value), &configs); err != nil {
+		return err
+	}
+
+	rules, err := toRules(configs)
+	if err != nil {
+		return err
+	}
+
+	return p.UpdateRules(rules)
+}
+
+// 应用按key区分的流量控制规则，未配置的key使用默认规则
+func applyKeyedTrafficRules(p KeyedTrafficRuleUpdater, value string) error {
+	config := new(KeyedTrafficRules)
+	if err := json.Unmarshal([]byte(value), config); err != nil {
+		return err
+	}
+
+	rules, err := toRules(config.Rules)
+	if err != nil {
+		return err
+	}
+
+	overrides := make(map[string][]*trafficshaping.Rule, len(config.Overrides))
+	for key, configs := range config.Overrides {
+		keyRules, err := toRules(configs)
+		if err != nil {
+			return errors.Wrapf(err, "rules of key %s", key)
+		}
+		overrides[key] = keyRules
+	}
+
+	return p.UpdateRules(rules, overrides)
+}
+
+// 转换为流量控制规则
+func toRules(configs []*TrafficRule) ([]*trafficshaping.Rule, error) {
+	rules := make([]*trafficshaping.Rule, 0, len(configs))
+	for _, c := range configs {
+		rule, err := c.ToRule()
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// 应用断路器规则
+func applyBreakerRules(g *circuitbreaker.BreakerGroup, value string) error {
+	rules := make([]*circuitbreaker.Rule, 0)
+	if err := json.Unmarshal([]byte(value), &rules); err != nil {
+		return err
+	}
+
+	return g.UpdateRules(rules)
+}
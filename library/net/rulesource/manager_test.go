@@ -0,0 +1,117 @@
+package rulesource
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gitlab.shanhai.int/sre/library/net/circuitbreaker"
+	"gitlab.shanhai.int/sre/library/net/trafficshaping"
+)
+
+func TestManager_Apply(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		p, err := trafficshaping.NewPipeline([]*trafficshaping.Rule{
+			{Type: trafficshaping.QPS, Limit: 10},
+		})
+		assert.Nil(t, err)
+		bg := circuitbreaker.NewBreakerGroup()
+
+		m := NewManager()
+		assert.Nil(t, m.RegisterPipeline("api", p))
+		assert.Nil(t, m.RegisterBreakerGroup("http", bg))
+
+		err = m.Apply(map[string]string{
+			"trafficshaping.api": `[{"type":"qps","limit":100},{"type":"waiting","limit":1}]`,
+			"other":              "ignored",
+		})
+		assert.NotNil(t, err)
+		assert.Equal(t, float64(10), p.QPSLimit())
+
+		err = m.Apply(map[string]string{
+			"trafficshaping.api":  `[{"type":"qps","limit":100},{"type":"concurrency","limit":5}]`,
+			"circuitbreaker.http": `[{"name":"example.com","trip_type":"consecutive","threshold":1}]`,
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, float64(100), p.QPSLimit())
+		assert.Len(t, p.Rules(), 2)
+
+		cb := bg.Get("example.com")
+		assert.NotNil(t, cb)
+		cb.Fail()
+		assert.True(t, cb.IsTripped())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		p, err := trafficshaping.NewPipeline([]*trafficshaping.Rule{
+			{Type: trafficshaping.QPS, Limit: 10},
+		})
+		assert.Nil(t, err)
+
+		m := NewManager()
+		assert.Nil(t, m.RegisterPipeline("api", p))
+
+		// json错误、规则校验失败时保留当前规则
+		for _, value := range []string{
+			`{`,
+			`[{"type":"qps","limit":100,"max_waiting_time":"1s"}]`,
+			`[{"type":"qps","scope":"cluster","resource":"api","limit":100}]`,
+			`[{"type":"qps","limit":-1}]`,
+			`[{"type":"qps","control_behavior":"waiting","limit":0}]`,
+			`[{"type":"qps","control_behavior":"waiting","limit":10,"max_waiting_time":"-1s"}]`,
+		} {
+			err = m.Apply(map[string]string{"trafficshaping.api": value})
+			assert.NotNil(t, err)
+			assert.Equal(t, float64(10), p.QPSLimit())
+		}
+	})
+
+	t.Run("register later", func(t *testing.T) {
+		m := NewManager()
+		err := m.Apply(map[string]string{
+			"trafficshaping.api": `[{"type":"qps","limit":100}]`,
+		})
+		assert.Nil(t, err)
+
+		p, err := trafficshaping.NewPipeline([]*trafficshaping.Rule{
+			{Type: trafficshaping.QPS, Limit: 10},
+		})
+		assert.Nil(t, err)
+
+		assert.Nil(t, m.RegisterPipeline("api", p))
+		assert.Equal(t, float64(100), p.QPSLimit())
+	})
+
+	t.Run("keyed", func(t *testing.T) {
+		kp, err := trafficshaping.NewKeyedPipeline(&trafficshaping.KeyedPipelineConfig{
+			Rules: []*trafficshaping.Rule{
+				{Type: trafficshaping.QPS, Limit: 10},
+			},
+			Overrides: map[string][]*trafficshaping.Rule{
+				"vip": {{Type: trafficshaping.QPS, Limit: 20}},
+			},
+		})
+		assert.Nil(t, err)
+		a, err := kp.Get("a")
+		assert.Nil(t, err)
+		vip, err := kp.Get("vip")
+		assert.Nil(t, err)
+
+		m := NewManager()
+		assert.Nil(t, m.RegisterKeyedPipeline("user", kp))
+
+		// 指定key的规则错误时保留当前规则
+		err = m.Apply(map[string]string{
+			"trafficshaping.user": `{"rules":[{"type":"qps","limit":100}],"overrides":{"b":[{"type":"waiting"}]}}`,
+		})
+		assert.NotNil(t, err)
+		assert.Equal(t, float64(10), a.QPSLimit())
+
+		// 替换默认规则及全部指定key的规则
+		err = m.Apply(map[string]string{
+			"trafficshaping.user": `{"rules":[{"type":"qps","limit":100}],"overrides":{"a":[{"type":"qps","limit":50}]}}`,
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, float64(50), a.QPSLimit())
+		assert.Equal(t, float64(100), vip.QPSLimit())
+	})
+}
@@ -0,0 +1,122 @@
+package rulesource
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"gitlab.shanhai.int/sre/library/base/ctime"
+	"gitlab.shanhai.int/sre/library/net/trafficshaping"
+)
+
+var (
+	// 规则类型
+	ruleTypes = map[string]trafficshaping.RuleType{
+		"qps":                  trafficshaping.QPS,
+		"concurrency":          trafficshaping.Concurrency,
+		"adaptive_concurrency": trafficshaping.AdaptiveConcurrency,
+	}
+	// 控制行为，为空表示直接拒绝
+	controlBehaviors = map[string]trafficshaping.ControlBehavior{
+		"":        trafficshaping.Reject,
+		"reject":  trafficshaping.Reject,
+		"waiting": trafficshaping.Waiting,
+	}
+	// 作用范围，为空表示单进程
+	scopes = map[string]trafficshaping.Scope{
+		"":        trafficshaping.Local,
+		"local":   trafficshaping.Local,
+		"cluster": trafficshaping.Cluster,
+	}
+	// 集群限流算法，为空表示滑动窗口
+	algorithms = map[string]trafficshaping.Algorithm{
+		"":               trafficshaping.SlidingWindow,
+		"sliding_window": trafficshaping.SlidingWindow,
+		"token_bucket":   trafficshaping.TokenBucket,
+	}
+	// 自适应并发算法，为空表示Gradient
+	adaptiveAlgorithms = map[string]trafficshaping.AdaptiveAlgorithm{
+		"":         trafficshaping.Gradient,
+		"gradient": trafficshaping.Gradient,
+		"vegas":    trafficshaping.Vegas,
+	}
+)
+
+// 流量控制规则配置，字段含义见trafficshaping.Rule
+type TrafficRule struct {
+	// 规则类型：qps、concurrency、adaptive_concurrency
+	Type string `json:"type"`
+	// 控制行为：reject、waiting
+	ControlBehavior string `json:"control_behavior"`
+	// 最大等待时间，如 500ms
+	MaxWaitingTime ctime.Duration `json:"max_waiting_time"`
+	// 限制个数
+	Limit float64 `json:"limit"`
+	// 作用范围：local、cluster
+	Scope string `json:"scope"`
+	// 集群限流的资源名
+	Resource string `json:"resource"`
+	// 集群限流算法：sliding_window、token_bucket
+	Algorithm string `json:"algorithm"`
+	// 令牌桶容量
+	Burst float64 `json:"burst"`
+	// redis不可用时的单进程qps限制
+	FallbackLimit float64 `json:"fallback_limit"`
+	// 自适应并发算法：gradient、vegas
+	AdaptiveAlgorithm string `json:"adaptive_algorithm"`
+	// 自适应并发的最小限制
+	MinLimit float64 `json:"min_limit"`
+	// 自适应并发的最大限制
+	MaxLimit float64 `json:"max_limit"`
+}
+
+// 按key区分的流量控制规则配置
+type KeyedTrafficRules struct {
+	// 默认规则
+	Rules []*TrafficRule `json:"rules"`
+	// 指定key的规则，存在时替换默认规则，未配置的key使用默认规则
+	Overrides map[string][]*TrafficRule `json:"overrides"`
+}
+
+// 转换为流量控制规则并校验
+func (r *TrafficRule) ToRule() (*trafficshaping.Rule, error) {
+	ruleType, ok := ruleTypes[r.Type]
+	if !ok {
+		return nil, errors.Errorf("rule type %s is not supported", r.Type)
+	}
+	behavior, ok := controlBehaviors[r.ControlBehavior]
+	if !ok {
+		return nil, errors.Errorf("control behavior %s is not supported", r.ControlBehavior)
+	}
+	scope, ok := scopes[r.Scope]
+	if !ok {
+		return nil, errors.Errorf("scope %s is not supported", r.Scope)
+	}
+	algorithm, ok := algorithms[r.Algorithm]
+	if !ok {
+		return nil, errors.Errorf("algorithm %s is not supported", r.Algorithm)
+	}
+	adaptiveAlgorithm, ok := adaptiveAlgorithms[r.AdaptiveAlgorithm]
+	if !ok {
+		return nil, errors.Errorf("adaptive algorithm %s is not supported", r.AdaptiveAlgorithm)
+	}
+
+	rule := &trafficshaping.Rule{
+		Type:              ruleType,
+		ControlBehavior:   behavior,
+		MaxWaitingTime:    time.Duration(r.MaxWaitingTime),
+		Limit:             r.Limit,
+		Scope:             scope,
+		Resource:          r.Resource,
+		Algorithm:         algorithm,
+		Burst:             r.Burst,
+		FallbackLimit:     r.FallbackLimit,
+		AdaptiveAlgorithm: adaptiveAlgorithm,
+		MinLimit:          r.MinLimit,
+		MaxLimit:          r.MaxLimit,
+	}
+	if err := rule.IsValid(); err != nil {
+		return nil, err
+	}
+
+	return rule, nil
+}
@@ -0,0 +1,89 @@
+package rulesource
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"gitlab.shanhai.int/sre/library/database/etcd"
+	"gitlab.shanhai.int/sre/library/net/agollo"
+)
+
+// 从Apollo的namespace加载规则并监听变化
+// client需设置NotDaemon为true，且namespace在PreloadNamespaces中
+// 配置键被删除时保留当前规则
+func WatchApollo(ctx context.Context, client *agollo.Client, namespace string, m *Manager) error {
+	events, err := client.Watch([]string{namespace})
+	if err != nil {
+		return err
+	}
+
+	// 错误已记录日志，不影响后续监听
+	_ = m.Apply(apolloValues(client.Get(namespace)))
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case e, ok := <-events:
+				if !ok {
+					return
+				}
+				if e.Type == agollo.EventUpdate && e.Namespace == namespace {
+					_ = m.Apply(apolloValues(e.NewValue))
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// 转换Apollo配置的值
+func apolloValues(data map[string]interface{}) map[string]string {
+	values := make(map[string]string, len(data))
+	for key, value := range data {
+		if s, ok := value.(string); ok {
+			values[key] = s
+		} else {
+			values[key] = fmt.Sprint(value)
+		}
+	}
+	return values
+}
+
+// 从etcd的前缀加载规则并监听变化，配置键为去掉前缀后的键
+// 配置键被删除时保留当前规则
+func WatchEtcd(ctx context.Context, db *etcd.DB, prefix string, m *Manager) error {
+	data, err := db.LoadPrefixData(ctx, prefix, nil, true)
+	if err != nil {
+		return err
+	}
+
+	// 先添加回调再加载全部数据，避免遗漏加载期间的变化
+	// 加载完成前回调等待，回调重新读取当前值，避免旧值覆盖新值
+	var mutex sync.Mutex
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	data.OnChange(func(key, _ string) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		value, err := data.Get(key)
+		if err != nil {
+			return
+		}
+		_ = m.Apply(map[string]string{key: value})
+	})
+
+	values := make(map[string]string)
+	data.Range(func(key, value string) bool {
+		values[key] = value
+		return true
+	})
+	_ = m.Apply(values)
+
+	return nil
+}
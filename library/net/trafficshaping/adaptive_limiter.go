@@ -0,0 +1,256 @@
+package trafficshaping
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gitlab.shanhai.int/sre/library/base/sw"
+)
+
+const (
+	// 默认自适应初始并发数量
+	DefaultAdaptiveInitialLimit = 20
+	// 默认自适应最小并发数量
+	DefaultAdaptiveMinLimit = 1
+	// 默认自适应最大并发数量
+	DefaultAdaptiveMaxLimit = 1000
+
+	// 限制调整间隔
+	adaptiveUpdateInterval = time.Millisecond * 100
+	// 耗时滑动窗口桶数量，窗口大小为1秒
+	adaptiveWindowBuckets = 10
+	// 限制平滑系数
+	adaptiveLimitSmoothing = 0.2
+	// 请求过载失败时限制收缩比例
+	adaptiveDropBackoffRatio = 0.9
+
+	// Gradient长期平均耗时平滑系数
+	gradientLongRTTSmoothing = 0.01
+	// Gradient耗时容忍比例，短期平均耗时超过长期平均耗时的该倍数时才收缩限制
+	gradientRTTTolerance = 1.5
+
+	// Vegas无负载耗时重新探测间隔
+	vegasProbeInterval = time.Second * 30
+)
+
+// 自适应并发限制器
+// 根据滑动窗口内的请求耗时及当前并发数定期调整并发限制
+type AdaptiveLimiter struct {
+	// 算法
+	algorithm AdaptiveAlgorithm
+	// 最小限制
+	minLimit float64
+	// 最大限制
+	maxLimit float64
+
+	// 当前并发数量
+	inflight int64
+	// 耗时滑动窗口，单位纳秒
+	rtts *sw.SlidingWindow
+
+	// 互斥锁
+	mutex sync.Mutex
+	// 当前限制
+	limit float64
+	// 调整间隔内是否有过载失败的请求
+	dropped bool
+	// 长期平均耗时，用于Gradient
+	longRTT float64
+	// 无负载耗时，用于Vegas
+	noLoadRTT int64
+	// 上次探测无负载耗时的时间
+	lastProbeTime time.Time
+	// 上次调整时间
+	lastUpdateTime time.Time
+}
+
+// 新建自适应并发限制器
+// 规则类型需为AdaptiveConcurrency
+func NewAdaptiveLimiter(r *Rule) *AdaptiveLimiter {
+	l := &AdaptiveLimiter{
+		algorithm: r.AdaptiveAlgorithm,
+		minLimit:  r.MinLimit,
+		maxLimit:  r.MaxLimit,
+		limit:     r.Limit,
+		rtts:      sw.NewSlidingWindow(time.Second/adaptiveWindowBuckets, adaptiveWindowBuckets),
+	}
+
+	if l.minLimit == 0 {
+		l.minLimit = DefaultAdaptiveMinLimit
+	}
+	if l.maxLimit == 0 {
+		l.maxLimit = math.Max(DefaultAdaptiveMaxLimit, l.minLimit)
+	}
+	if l.limit == 0 {
+		l.limit = DefaultAdaptiveInitialLimit
+	}
+	l.limit = l.clamp(l.limit)
+
+	return l
+}
+
+// 尝试占用并发，成功后需要调用Release
+func (l *AdaptiveLimiter) Acquire() bool {
+	limit := l.Limit()
+	for {
+		cur := atomic.LoadInt64(&l.inflight)
+		if float64(cur+1) > limit {
+			return false
+		}
+		if atomic.CompareAndSwapInt64(&l.inflight, cur, cur+1) {
+			return true
+		}
+	}
+}
+
+// 释放并发并上报请求耗时
+// dropped表示请求因超时等过载原因失败，将直接收缩限制
+func (l *AdaptiveLimiter) Release(rtt time.Duration, dropped bool) {
+	inflight := atomic.AddInt64(&l.inflight, -1) + 1
+	l.Observe(rtt, inflight, dropped)
+}
+
+// 释放并发但不上报耗时，用于请求未实际发出的情况
+func (l *AdaptiveLimiter) Cancel() {
+	atomic.AddInt64(&l.inflight, -1)
+}
+
+// 上报请求耗时及请求时的并发数量，达到调整间隔时调整限制
+func (l *AdaptiveLimiter) Observe(rtt time.Duration, inflight int64, dropped bool) {
+	l.rtts.Add(int64(rtt))
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if dropped {
+		l.dropped = true
+	}
+
+	now := time.Now()
+	if now.Sub(l.lastUpdateTime) < adaptiveUpdateInterval {
+		return
+	}
+	l.lastUpdateTime = now
+
+	l.rtts.Slide()
+	avgRTT := l.rtts.Average()
+	if avgRTT <= 0 {
+		return
+	}
+
+	var newLimit float64
+	switch l.algorithm {
+	case Vegas:
+		newLimit = l.vegas(now, avgRTT, l.rtts.Min(), inflight)
+	default:
+		newLimit = l.gradient(avgRTT, inflight)
+	}
+
+	l.dropped = false
+	l.limit = l.clamp(newLimit)
+}
+
+// Gradient2算法
+// 长期平均耗时与短期平均耗时的比例作为梯度，梯度小于1时说明出现排队，按比例收缩限制
+func (l *AdaptiveLimiter) gradient(shortRTT float64, inflight int64) float64 {
+	if l.longRTT == 0 {
+		l.longRTT = shortRTT
+	} else {
+		l.longRTT = l.longRTT*(1-gradientLongRTTSmoothing) + shortRTT*gradientLongRTTSmoothing
+	}
+
+	// 负载恢复后长期平均耗时远高于短期平均耗时，快速衰减以便及时收缩限制
+	if l.longRTT/shortRTT > 2 {
+		l.longRTT *= 0.95
+	}
+
+	if l.dropped {
+		return l.limit * adaptiveDropBackoffRatio
+	}
+	// 并发未达到限制的一半时不调整，避免空闲时限制无限增长
+	if float64(inflight) < l.limit/2 {
+		return l.limit
+	}
+
+	gradient := math.Max(0.5, math.Min(1.0, gradientRTTTolerance*l.longRTT/shortRTT))
+	newLimit := l.limit*gradient + math.Sqrt(l.limit)
+	return l.limit*(1-adaptiveLimitSmoothing) + newLimit*adaptiveLimitSmoothing
+}
+
+// Vegas算法
+// 根据平均耗时与无负载耗时估算排队数量，排队较少时增加限制，排队较多时减少限制
+func (l *AdaptiveLimiter) vegas(now time.Time, avgRTT float64, minRTT int64, inflight int64) float64 {
+	if l.noLoadRTT == 0 || minRTT < l.noLoadRTT || now.Sub(l.lastProbeTime) > vegasProbeInterval {
+		l.noLoadRTT = minRTT
+		l.lastProbeTime = now
+	}
+
+	if l.dropped {
+		return l.limit * adaptiveDropBackoffRatio
+	}
+	// 并发未达到限制的一半时不调整，避免空闲时限制无限增长
+	if float64(inflight) < l.limit/2 {
+		return l.limit
+	}
+
+	queue := math.Ceil(l.limit * (1 - float64(l.noLoadRTT)/avgRTT))
+	threshold := math.Max(1, math.Log10(l.limit))
+	alpha, beta := 3*threshold, 6*threshold
+
+	switch {
+	case queue <= threshold:
+		return l.limit + beta
+	case queue < alpha:
+		return l.limit + threshold
+	case queue > beta:
+		return l.limit - threshold
+	default:
+		return l.limit
+	}
+}
+
+// 限制在最小及最大限制之间
+func (l *AdaptiveLimiter) clamp(limit float64) float64 {
+	return math.Max(l.minLimit, math.Min(l.maxLimit, limit))
+}
+
+// 当前限制
+func (l *AdaptiveLimiter) Limit() float64 {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.limit
+}
+
+// 当前并发数量，只统计通过Acquire占用的并发
+func (l *AdaptiveLimiter) Inflight() int64 {
+	return atomic.LoadInt64(&l.inflight)
+}
+
+// 自适应并发控制器
+type AdaptiveController struct {
+}
+
+func (c *AdaptiveController) Check(p *Pipeline, r *Rule) *Result {
+	if r.Type != AdaptiveConcurrency {
+		return DefaultResult()
+	} else if p == nil {
+		return DefaultResult()
+	}
+
+	l := p.limiter(r)
+	if l == nil {
+		return DefaultResult()
+	}
+
+	if float64(p.ConcurrentCount()+1) > l.Limit() {
+		return RejectResult()
+	}
+	return DefaultResult()
+}
+
+func NewAdaptiveController() *AdaptiveController {
+	return &AdaptiveController{}
+}
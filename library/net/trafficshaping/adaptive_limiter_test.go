@@ -0,0 +1,103 @@
+package trafficshaping
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdaptiveLimiter(t *testing.T) {
+	t.Run("acquire", func(t *testing.T) {
+		l := NewAdaptiveLimiter(&Rule{Type: AdaptiveConcurrency, Limit: 2})
+		assert.True(t, l.Acquire())
+		assert.True(t, l.Acquire())
+		assert.False(t, l.Acquire())
+		assert.Equal(t, int64(2), l.Inflight())
+
+		l.Cancel()
+		assert.Equal(t, int64(1), l.Inflight())
+		assert.True(t, l.Acquire())
+	})
+
+	t.Run("default", func(t *testing.T) {
+		l := NewAdaptiveLimiter(&Rule{Type: AdaptiveConcurrency})
+		assert.Equal(t, float64(DefaultAdaptiveInitialLimit), l.Limit())
+
+		l = NewAdaptiveLimiter(&Rule{Type: AdaptiveConcurrency, Limit: 100, MaxLimit: 50})
+		assert.Equal(t, float64(50), l.Limit())
+	})
+
+	t.Run("gradient", func(t *testing.T) {
+		l := NewAdaptiveLimiter(&Rule{Type: AdaptiveConcurrency, Limit: 20})
+		l.Observe(time.Millisecond*10, 20, false)
+		assert.Greater(t, l.Limit(), float64(20))
+
+		// 耗时大幅增加时收缩限制
+		limit := l.Limit()
+		time.Sleep(adaptiveUpdateInterval)
+		for i := 0; i < 100; i++ {
+			l.Observe(time.Millisecond*100, 20, false)
+		}
+		time.Sleep(adaptiveUpdateInterval)
+		l.Observe(time.Millisecond*100, 20, false)
+		assert.Less(t, l.Limit(), limit)
+	})
+
+	t.Run("vegas", func(t *testing.T) {
+		l := NewAdaptiveLimiter(&Rule{Type: AdaptiveConcurrency, AdaptiveAlgorithm: Vegas, Limit: 20})
+		l.Observe(time.Millisecond*10, 20, false)
+		assert.Greater(t, l.Limit(), float64(20))
+	})
+
+	t.Run("app limited", func(t *testing.T) {
+		l := NewAdaptiveLimiter(&Rule{Type: AdaptiveConcurrency, Limit: 20})
+		l.Observe(time.Millisecond*10, 1, false)
+		assert.Equal(t, float64(20), l.Limit())
+	})
+
+	t.Run("dropped", func(t *testing.T) {
+		l := NewAdaptiveLimiter(&Rule{Type: AdaptiveConcurrency, Limit: 20, MinLimit: 19})
+		l.Observe(time.Millisecond*10, 20, true)
+		assert.Equal(t, float64(19), l.Limit())
+	})
+}
+
+func TestAdaptivePipeline(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		p, err := NewPipeline([]*Rule{
+			{
+				Type:  AdaptiveConcurrency,
+				Limit: 10,
+			},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, float64(10), p.AdaptiveLimit())
+
+		passed := false
+		err = p.Do(func() {
+			passed = true
+		})
+		assert.Nil(t, err)
+		assert.True(t, passed)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := NewPipeline([]*Rule{
+			{
+				Type:            AdaptiveConcurrency,
+				ControlBehavior: Waiting,
+			},
+		})
+		assert.NotNil(t, err)
+
+		_, err = NewPipeline([]*Rule{
+			{
+				Type:     Concurrency,
+				Limit:    10,
+				MaxLimit: 10,
+			},
+		})
+		assert.NotNil(t, err)
+	})
+}
@@ -12,12 +12,19 @@ type Controller interface {
 	Check(p *Pipeline, r *Rule) *Result
 }
 
+// 集群控制器接口，处理Scope为Cluster的规则，如redisctl.Controller
+type ClusterController interface {
+	Controller
+	// 集群共享限制是否可用，不可用时降级为单进程限制
+	Available() bool
+}
+
 // 拒绝控制器
 type RejectController struct {
 }
 
 func (c *RejectController) Check(p *Pipeline, r *Rule) *Result {
-	if r.ControlBehavior != Reject {
+	if r.ControlBehavior != Reject || r.Scope != Local || r.Type == AdaptiveConcurrency {
 		return DefaultResult()
 	} else if p == nil {
 		return DefaultResult()
@@ -46,7 +53,7 @@ type WaitingController struct {
 }
 
 func (c *WaitingController) Check(p *Pipeline, r *Rule) *Result {
-	if r.ControlBehavior != Waiting {
+	if r.ControlBehavior != Waiting || r.Scope != Local {
 		return DefaultResult()
 	} else if p == nil {
 		return DefaultResult()
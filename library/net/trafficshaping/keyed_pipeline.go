@@ -0,0 +1,202 @@
+package trafficshaping
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+const (
+	// 默认最多保留的key数量
+	DefaultMaxKeys = 10000
+)
+
+// 按key区分的流量管道配置
+type KeyedPipelineConfig struct {
+	// 默认规则
+	Rules []*Rule
+	// 指定key的规则，存在时替换默认规则
+	Overrides map[string][]*Rule
+	// 最多保留的key数量，超出时淘汰最久未使用的key，为0表示使用DefaultMaxKeys
+	MaxKeys int
+	// key闲置超过该时间后淘汰，为0表示不按闲置时间淘汰
+	IdleTimeout time.Duration
+	// 额外的控制器，所有key共用
+	Controllers []Controller
+}
+
+// 按key区分的流量管道
+// 每个key使用独立的管道，集群规则的Resource会追加key以区分不同key的共享限制
+type KeyedPipeline struct {
+	// 配置
+	cfg *KeyedPipelineConfig
+	// 最多保留的key数量
+	maxKeys int
+
+	// 互斥锁
+	mutex sync.Mutex
+	// 按最近使用排序的管道链表
+	ll *list.List
+	// key对应的链表元素
+	items map[string]*list.Element
+}
+
+// 链表元素
+type keyedEntry struct {
+	// key
+	key string
+	// 管道
+	pipeline *Pipeline
+	// 上次使用时间
+	lastUsedTime time.Time
+}
+
+// 新建按key区分的流量管道
+func NewKeyedPipeline(cfg *KeyedPipelineConfig) (*KeyedPipeline, error) {
+	// 提前校验规则，避免请求时才发现规则错误
+	if _, err := newKeyPipeline(cfg.Rules, "", cfg.Controllers); err != nil {
+		return nil, err
+	}
+	for key, rules := range cfg.Overrides {
+		if _, err := newKeyPipeline(rules, key, cfg.Controllers); err != nil {
+			return nil, err
+		}
+	}
+
+	maxKeys := cfg.MaxKeys
+	if maxKeys <= 0 {
+		maxKeys = DefaultMaxKeys
+	}
+
+	// 复制配置，避免更新规则时修改调用方的配置
+	c := *cfg
+
+	return &KeyedPipeline{
+		cfg:     &c,
+		maxKeys: maxKeys,
+		ll:      list.New(),
+		items:   make(map[string]*list.Element),
+	}, nil
+}
+
+// 新建单个key的管道
+func newKeyPipeline(rules []*Rule, key string, ctls []Controller) (*Pipeline, error) {
+	return NewPipeline(newKeyRules(rules, key), ctls...)
+}
+
+// 单个key的规则
+func newKeyRules(rules []*Rule, key string) []*Rule {
+	keyRules := make([]*Rule, 0, len(rules))
+	for _, rule := range rules {
+		r := *rule
+		if r.Scope == Cluster && key != "" {
+			r.Resource = r.Resource + ":" + key
+		}
+		keyRules = append(keyRules, &r)
+	}
+	return keyRules
+}
+
+// 更新默认规则及指定key的规则，overrides替换全部指定key的规则，已存在的key的管道同时更新
+// 规则错误时返回错误且不影响当前规则
+func (kp *KeyedPipeline) UpdateRules(rules []*Rule, overrides map[string][]*Rule) error {
+	if err := validateRules(rules, kp.cfg.Controllers); err != nil {
+		return err
+	}
+	for _, keyRules := range overrides {
+		if err := validateRules(keyRules, kp.cfg.Controllers); err != nil {
+			return err
+		}
+	}
+
+	kp.mutex.Lock()
+	defer kp.mutex.Unlock()
+
+	kp.cfg.Rules, kp.cfg.Overrides = rules, overrides
+	for key, e := range kp.items {
+		if err := e.Value.(*keyedEntry).pipeline.UpdateRules(newKeyRules(kp.keyRules(key), key)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// key使用的规则，指定key的规则存在时替换默认规则
+func (kp *KeyedPipeline) keyRules(key string) []*Rule {
+	if rules, ok := kp.cfg.Overrides[key]; ok {
+		return rules
+	}
+	return kp.cfg.Rules
+}
+
+// 获取key对应的管道，不存在时新建
+func (kp *KeyedPipeline) Get(key string) (*Pipeline, error) {
+	now := time.Now()
+
+	kp.mutex.Lock()
+	defer kp.mutex.Unlock()
+
+	kp.evictIdle(now)
+
+	if e, ok := kp.items[key]; ok {
+		entry := e.Value.(*keyedEntry)
+		entry.lastUsedTime = now
+		kp.ll.MoveToFront(e)
+		return entry.pipeline, nil
+	}
+
+	p, err := newKeyPipeline(kp.keyRules(key), key, kp.cfg.Controllers)
+	if err != nil {
+		return nil, err
+	}
+
+	kp.items[key] = kp.ll.PushFront(&keyedEntry{
+		key:          key,
+		pipeline:     p,
+		lastUsedTime: now,
+	})
+	for kp.ll.Len() > kp.maxKeys {
+		kp.removeElement(kp.ll.Back())
+	}
+
+	return p, nil
+}
+
+// 执行函数
+func (kp *KeyedPipeline) Do(key string, f func()) error {
+	p, err := kp.Get(key)
+	if err != nil {
+		return err
+	}
+	return p.Do(f)
+}
+
+// 当前保留的key数量
+func (kp *KeyedPipeline) Len() int {
+	kp.mutex.Lock()
+	defer kp.mutex.Unlock()
+
+	return kp.ll.Len()
+}
+
+// 淘汰闲置的key
+func (kp *KeyedPipeline) evictIdle(now time.Time) {
+	if kp.cfg.IdleTimeout <= 0 {
+		return
+	}
+
+	for e := kp.ll.Back(); e != nil; e = kp.ll.Back() {
+		entry := e.Value.(*keyedEntry)
+		if now.Sub(entry.lastUsedTime) <= kp.cfg.IdleTimeout {
+			return
+		}
+		kp.removeElement(e)
+	}
+}
+
+// 移除链表元素
+func (kp *KeyedPipeline) removeElement(e *list.Element) {
+	kp.ll.Remove(e)
+	delete(kp.items, e.Value.(*keyedEntry).key)
+}
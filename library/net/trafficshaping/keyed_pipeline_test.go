@@ -0,0 +1,144 @@
+package trafficshaping
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 测试用集群控制器
+type fakeClusterController struct {
+}
+
+func (c *fakeClusterController) Check(p *Pipeline, r *Rule) *Result {
+	return DefaultResult()
+}
+
+func (c *fakeClusterController) Available() bool {
+	return true
+}
+
+func TestKeyedPipeline(t *testing.T) {
+	rules := []*Rule{
+		{
+			Type:            QPS,
+			ControlBehavior: Reject,
+			Limit:           10,
+		},
+	}
+
+	t.Run("lru", func(t *testing.T) {
+		kp, err := NewKeyedPipeline(&KeyedPipelineConfig{
+			Rules:   rules,
+			MaxKeys: 2,
+		})
+		assert.Nil(t, err)
+
+		a, err := kp.Get("a")
+		assert.Nil(t, err)
+		_, err = kp.Get("b")
+		assert.Nil(t, err)
+
+		// a最近使用，淘汰b
+		a2, err := kp.Get("a")
+		assert.Nil(t, err)
+		assert.Same(t, a, a2)
+		_, err = kp.Get("c")
+		assert.Nil(t, err)
+		assert.Equal(t, 2, kp.Len())
+
+		a3, err := kp.Get("a")
+		assert.Nil(t, err)
+		assert.Same(t, a, a3)
+	})
+
+	t.Run("idle", func(t *testing.T) {
+		kp, err := NewKeyedPipeline(&KeyedPipelineConfig{
+			Rules:       rules,
+			IdleTimeout: time.Millisecond * 50,
+		})
+		assert.Nil(t, err)
+
+		a, err := kp.Get("a")
+		assert.Nil(t, err)
+		time.Sleep(time.Millisecond * 100)
+
+		_, err = kp.Get("b")
+		assert.Nil(t, err)
+		assert.Equal(t, 1, kp.Len())
+
+		a2, err := kp.Get("a")
+		assert.Nil(t, err)
+		assert.NotSame(t, a, a2)
+	})
+
+	t.Run("cluster resource", func(t *testing.T) {
+		kp, err := NewKeyedPipeline(&KeyedPipelineConfig{
+			Rules: []*Rule{
+				{
+					Type:            QPS,
+					ControlBehavior: Reject,
+					Limit:           10,
+					Scope:           Cluster,
+					Resource:        "api",
+				},
+			},
+			Controllers: []Controller{&fakeClusterController{}},
+		})
+		assert.Nil(t, err)
+
+		p, err := kp.Get("a")
+		assert.Nil(t, err)
+		assert.Equal(t, "api:a", p.rules[0].Resource)
+	})
+
+	t.Run("update rules", func(t *testing.T) {
+		kp, err := NewKeyedPipeline(&KeyedPipelineConfig{
+			Rules: rules,
+			Overrides: map[string][]*Rule{
+				"vip": {{Type: QPS, ControlBehavior: Reject, Limit: 20}},
+			},
+		})
+		assert.Nil(t, err)
+
+		a, err := kp.Get("a")
+		assert.Nil(t, err)
+		vip, err := kp.Get("vip")
+		assert.Nil(t, err)
+
+		// 规则错误时不影响当前规则
+		err = kp.UpdateRules(rules, map[string][]*Rule{
+			"a": {{Type: QPS, ControlBehavior: Reject, Limit: 10, Scope: Cluster, Resource: "api"}},
+		})
+		assert.NotNil(t, err)
+		assert.Equal(t, float64(20), vip.QPSLimit())
+
+		// 移除vip的规则，新增a的规则
+		err = kp.UpdateRules([]*Rule{{Type: QPS, ControlBehavior: Reject, Limit: 5}}, map[string][]*Rule{
+			"a": {{Type: QPS, ControlBehavior: Reject, Limit: 30}},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, float64(30), a.QPSLimit())
+		assert.Equal(t, float64(5), vip.QPSLimit())
+
+		b, err := kp.Get("b")
+		assert.Nil(t, err)
+		assert.Equal(t, float64(5), b.QPSLimit())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := NewKeyedPipeline(&KeyedPipelineConfig{
+			Rules: []*Rule{
+				{
+					Type:            QPS,
+					ControlBehavior: Reject,
+					Limit:           10,
+					Scope:           Cluster,
+					Resource:        "api",
+				},
+			},
+		})
+		assert.NotNil(t, err)
+	})
+}
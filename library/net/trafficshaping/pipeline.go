@@ -5,6 +5,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/pkg/errors"
 	"gitlab.shanhai.int/sre/library/base/sw"
 )
 
@@ -20,24 +21,136 @@ type Pipeline struct {
 	concurrentCount int64
 	// 控制器数组
 	ctls []Controller
+	// 自适应并发规则的限制器
+	limiters map[*Rule]*AdaptiveLimiter
 }
 
 // 新建管道
-func NewPipeline(rules []*Rule) (*Pipeline, error) {
+// 额外的控制器在默认控制器之后执行，集群规则需要传入ClusterController
+func NewPipeline(rules []*Rule, extraCtls ...Controller) (*Pipeline, error) {
+	if err := validateRules(rules, extraCtls); err != nil {
+		return nil, err
+	}
+
+	ctls := append([]Controller{NewRejectController(), NewWaitingController(), NewAdaptiveController()},
+		extraCtls...)
+
+	return &Pipeline{
+		rules:    rules,
+		rwMutex:  new(sync.RWMutex),
+		window:   sw.NewSlidingWindow(time.Millisecond*100, 10),
+		ctls:     ctls,
+		limiters: newLimiters(rules, nil),
+	}, nil
+}
+
+// 校验规则
+func validateRules(rules []*Rule, ctls []Controller) error {
+	hasClusterCtl := false
+	for _, ctl := range ctls {
+		if _, ok := ctl.(ClusterController); ok {
+			hasClusterCtl = true
+		}
+	}
+
 	for _, rule := range rules {
 		if err := rule.IsValid(); err != nil {
-			return nil, err
+			return err
+		}
+		if rule.Scope == Cluster && !hasClusterCtl {
+			return errors.Errorf("scope of rule is Cluster, but ClusterController is missing")
 		}
 	}
+	return nil
+}
 
-	ctls := []Controller{NewRejectController(), NewWaitingController()}
+// 新建自适应并发规则的限制器，与旧规则相同的规则沿用旧的限制器
+func newLimiters(rules []*Rule, oldLimiters map[*Rule]*AdaptiveLimiter) map[*Rule]*AdaptiveLimiter {
+	limiters := make(map[*Rule]*AdaptiveLimiter)
+	used := make(map[*Rule]bool)
+	for _, rule := range rules {
+		if rule.Type != AdaptiveConcurrency {
+			continue
+		}
 
-	return &Pipeline{
-		rules:   rules,
-		rwMutex: new(sync.RWMutex),
-		window:  sw.NewSlidingWindow(time.Millisecond*100, 10),
-		ctls:    ctls,
-	}, nil
+		for oldRule, l := range oldLimiters {
+			if !used[oldRule] && *oldRule == *rule {
+				limiters[rule] = l
+				used[oldRule] = true
+				break
+			}
+		}
+		if limiters[rule] == nil {
+			limiters[rule] = NewAdaptiveLimiter(rule)
+		}
+	}
+	return limiters
+}
+
+// 更新规则，规则错误时返回错误且不影响当前规则
+// 正在执行的请求仍使用旧规则，自适应并发规则未变化时保留已调整的限制
+func (p *Pipeline) UpdateRules(rules []*Rule) error {
+	if err := validateRules(rules, p.ctls); err != nil {
+		return err
+	}
+
+	p.rwMutex.Lock()
+	p.limiters = newLimiters(rules, p.limiters)
+	p.rules = rules
+	p.rwMutex.Unlock()
+
+	return nil
+}
+
+// 当前规则
+func (p *Pipeline) Rules() []*Rule {
+	p.rwMutex.RLock()
+	defer p.rwMutex.RUnlock()
+
+	return p.rules
+}
+
+// 获取规则及限制器
+func (p *Pipeline) snapshot() ([]*Rule, map[*Rule]*AdaptiveLimiter) {
+	p.rwMutex.RLock()
+	defer p.rwMutex.RUnlock()
+
+	return p.rules, p.limiters
+}
+
+// 获取自适应并发规则的限制器
+func (p *Pipeline) limiter(r *Rule) *AdaptiveLimiter {
+	p.rwMutex.RLock()
+	defer p.rwMutex.RUnlock()
+
+	return p.limiters[r]
+}
+
+// 单进程qps规则中最小的限制，为0表示没有qps限制
+func (p *Pipeline) QPSLimit() float64 {
+	var limit float64
+	for _, rule := range p.Rules() {
+		if rule.Type != QPS || rule.Scope != Local || rule.Limit <= 0 {
+			continue
+		}
+		if limit == 0 || rule.Limit < limit {
+			limit = rule.Limit
+		}
+	}
+	return limit
+}
+
+// 自适应并发规则中最小的当前限制，为0表示没有自适应并发规则
+func (p *Pipeline) AdaptiveLimit() float64 {
+	_, limiters := p.snapshot()
+
+	var limit float64
+	for _, l := range limiters {
+		if cur := l.Limit(); limit == 0 || cur < limit {
+			limit = cur
+		}
+	}
+	return limit
 }
 
 func (p *Pipeline) QPS() int64 {
@@ -54,8 +167,13 @@ func (p *Pipeline) beforeDo() {
 	p.window.Increase()
 }
 
-func (p *Pipeline) afterDo() {
-	atomic.AddInt64(&p.concurrentCount, -1)
+func (p *Pipeline) afterDo(startTime time.Time, limiters map[*Rule]*AdaptiveLimiter) {
+	inflight := atomic.AddInt64(&p.concurrentCount, -1) + 1
+
+	rtt := time.Since(startTime)
+	for _, l := range limiters {
+		l.Observe(rtt, inflight, false)
+	}
 }
 
 func (p *Pipeline) check(ctl Controller, rules []*Rule) error {
@@ -74,8 +192,9 @@ func (p *Pipeline) check(ctl Controller, rules []*Rule) error {
 }
 
 func (p *Pipeline) Do(f func()) error {
+	rules, limiters := p.snapshot()
 	for _, ctl := range p.ctls {
-		err := p.check(ctl, p.rules)
+		err := p.check(ctl, rules)
 		if err != nil {
 			return err
 		}
@@ -83,7 +202,7 @@ func (p *Pipeline) Do(f func()) error {
 
 	// todo:存在并发缺陷，无法严格控制
 	p.beforeDo()
-	defer p.afterDo()
+	defer p.afterDo(time.Now(), limiters)
 	f()
 	return nil
 }
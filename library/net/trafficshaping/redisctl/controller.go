@@ -0,0 +1,251 @@
+package redisctl
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"gitlab.shanhai.int/sre/library/database/redis"
+	"gitlab.shanhai.int/sre/library/net/trafficshaping"
+)
+
+const (
+	// 默认redis key前缀
+	DefaultKeyPrefix = "trafficshaping"
+	// 默认redis不可用后的重试间隔
+	DefaultRetryInterval = time.Second
+
+	// 滑动窗口大小，毫秒
+	slidingWindowMilliseconds = 1000
+)
+
+// 滑动窗口脚本
+// 有序集合中保存窗口内每个请求的时间戳，移除过期请求后判断数量是否达到限制
+// 时间取redis服务器时间，避免各实例时钟偏差影响共享限制
+var slidingWindowScript = newLuaScript(`
+redis.replicate_commands()
+local key = KEYS[1]
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+local window = tonumber(ARGV[1])
+local limit = tonumber(ARGV[2])
+local member = ARGV[3]
+
+redis.call('ZREMRANGEBYSCORE', key, '-inf', now - window)
+if redis.call('ZCARD', key) + 1 > limit then
+	return 0
+end
+
+redis.call('ZADD', key, now, member)
+redis.call('PEXPIRE', key, window)
+return 1
+`)
+
+// 令牌桶脚本
+// 哈希中保存剩余令牌数及上次填充时间，按速率填充后尝试取出一个令牌
+// 时间取redis服务器时间，避免各实例时钟偏差影响填充
+var tokenBucketScript = newLuaScript(`
+redis.replicate_commands()
+local key = KEYS[1]
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+
+local bucket = redis.call('HMGET', key, 'tokens', 'ts')
+local tokens = tonumber(bucket[1])
+local ts = tonumber(bucket[2])
+if tokens == nil or ts == nil then
+	tokens = burst
+	ts = now
+end
+
+if now > ts then
+	tokens = math.min(burst, tokens + (now - ts) * rate / 1000)
+	ts = now
+end
+
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call('HMSET', key, 'tokens', tostring(tokens), 'ts', tostring(ts))
+redis.call('PEXPIRE', key, math.ceil(burst / rate * 1000) + 1000)
+return allowed
+`)
+
+// lua脚本
+type luaScript struct {
+	// 脚本内容
+	src string
+	// 脚本sha1
+	hash string
+}
+
+func newLuaScript(src string) *luaScript {
+	h := sha1.Sum([]byte(src))
+	return &luaScript{
+		src:  src,
+		hash: hex.EncodeToString(h[:]),
+	}
+}
+
+// 执行脚本，优先使用EVALSHA，脚本未加载时使用EVAL
+func (s *luaScript) do(ctx context.Context, con *redis.Conn, key string, args ...interface{}) (interface{}, error) {
+	params := append([]interface{}{s.hash, 1, key}, args...)
+	reply, err := con.Do(ctx, "EVALSHA", params...)
+	if err != nil && strings.HasPrefix(err.Error(), "NOSCRIPT") {
+		params[0] = s.src
+		reply, err = con.Do(ctx, "EVAL", params...)
+	}
+	return reply, err
+}
+
+// redis控制器配置
+type Config struct {
+	// key前缀，为空表示使用DefaultKeyPrefix
+	KeyPrefix string
+	// redis不可用后的重试间隔，期间直接使用单进程限制，为0表示使用DefaultRetryInterval
+	RetryInterval time.Duration
+}
+
+// redis控制器
+// 基于redis在所有实例间共享集群规则的qps限制，redis不可用时降级为单进程限制
+type Controller struct {
+	// 连接池
+	pool *redis.Pool
+	// key前缀
+	keyPrefix string
+	// 重试间隔
+	retryInterval time.Duration
+
+	// 实例标识，用于生成滑动窗口中的请求标识
+	instanceID string
+	// 请求序号
+	sequence int64
+	// redis不可用截止时间
+	unavailableUntil int64
+	// 降级次数
+	fallbackCount int64
+}
+
+func (c *Controller) Check(p *trafficshaping.Pipeline, r *trafficshaping.Rule) *trafficshaping.Result {
+	if r.Scope != trafficshaping.Cluster {
+		return trafficshaping.DefaultResult()
+	} else if r.Limit <= 0 {
+		return trafficshaping.DefaultResult()
+	}
+
+	now := time.Now()
+	if now.UnixNano() >= atomic.LoadInt64(&c.unavailableUntil) {
+		allowed, err := c.allow(r)
+		if err == nil {
+			if allowed {
+				return trafficshaping.DefaultResult()
+			}
+			return trafficshaping.RejectResult()
+		}
+		atomic.StoreInt64(&c.unavailableUntil, now.Add(c.retryInterval).UnixNano())
+	}
+
+	return c.fallback(p, r)
+}
+
+// 通过redis判断是否允许通过
+func (c *Controller) allow(r *trafficshaping.Rule) (bool, error) {
+	var reply interface{}
+	err := c.pool.WrapDo(func(con *redis.Conn) error {
+		var err error
+		switch r.Algorithm {
+		case trafficshaping.TokenBucket:
+			burst := r.Burst
+			if burst == 0 {
+				burst = r.Limit
+			}
+			reply, err = tokenBucketScript.do(context.Background(), con, c.key(r, "tb"),
+				r.Limit, burst)
+		default:
+			member := fmt.Sprintf("%s-%d", c.instanceID, atomic.AddInt64(&c.sequence, 1))
+			reply, err = slidingWindowScript.do(context.Background(), con, c.key(r, "sw"),
+				slidingWindowMilliseconds, r.Limit, member)
+		}
+		return err
+	})
+	if err != nil {
+		return false, err
+	}
+
+	allowed, ok := reply.(int64)
+	if !ok {
+		return false, fmt.Errorf("unexpected reply type %T of traffic shaping script", reply)
+	}
+	return allowed == 1, nil
+}
+
+// 降级为单进程限制
+func (c *Controller) fallback(p *trafficshaping.Pipeline, r *trafficshaping.Rule) *trafficshaping.Result {
+	atomic.AddInt64(&c.fallbackCount, 1)
+	if p == nil {
+		return trafficshaping.DefaultResult()
+	}
+
+	limit := r.FallbackLimit
+	if limit == 0 {
+		limit = r.Limit
+	}
+
+	if float64(p.QPS()+1) > limit {
+		return trafficshaping.RejectResult()
+	}
+	return trafficshaping.DefaultResult()
+}
+
+// redis key
+func (c *Controller) key(r *trafficshaping.Rule, algorithm string) string {
+	return fmt.Sprintf("%s:%s:%s", c.keyPrefix, r.Resource, algorithm)
+}
+
+// redis是否可用
+func (c *Controller) Available() bool {
+	return time.Now().UnixNano() >= atomic.LoadInt64(&c.unavailableUntil)
+}
+
+// 降级次数
+func (c *Controller) FallbackCount() int64 {
+	return atomic.LoadInt64(&c.fallbackCount)
+}
+
+// 生成实例标识，避免不同实例的请求标识重复
+func newInstanceID() string {
+	hostname, _ := os.Hostname()
+	return fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), strconv.FormatInt(time.Now().UnixNano(), 36))
+}
+
+// 新建redis控制器
+func NewController(pool *redis.Pool, cfg *Config) *Controller {
+	c := &Controller{
+		pool:          pool,
+		keyPrefix:     DefaultKeyPrefix,
+		retryInterval: DefaultRetryInterval,
+		instanceID:    newInstanceID(),
+	}
+
+	if cfg != nil {
+		if cfg.KeyPrefix != "" {
+			c.keyPrefix = cfg.KeyPrefix
+		}
+		if cfg.RetryInterval > 0 {
+			c.retryInterval = cfg.RetryInterval
+		}
+	}
+
+	return c
+}
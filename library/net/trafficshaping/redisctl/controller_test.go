@@ -0,0 +1,183 @@
+package redisctl
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"gitlab.shanhai.int/sre/library/base/ctime"
+	"gitlab.shanhai.int/sre/library/database/redis"
+	"gitlab.shanhai.int/sre/library/net/trafficshaping"
+)
+
+// 新建连接到miniredis的连接池
+func newTestPool(t *testing.T, s *miniredis.Miniredis) *redis.Pool {
+	return redis.NewPool(&redis.Config{
+		PoolConfig: &redis.PoolConfig{
+			Active:         10,
+			Idle:           10,
+			ReadTimeout:    ctime.Duration(time.Millisecond * 100),
+			WriteTimeout:   ctime.Duration(time.Millisecond * 100),
+			ConnectTimeout: ctime.Duration(time.Millisecond * 100),
+		},
+		Proto: "tcp",
+		Endpoint: &redis.EndpointConfig{
+			Address: s.Host(),
+			Port:    mustPort(t, s),
+		},
+	})
+}
+
+func mustPort(t *testing.T, s *miniredis.Miniredis) int {
+	port, err := strconv.Atoi(s.Port())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return port
+}
+
+// 新建集群规则的管道
+func newTestPipeline(t *testing.T, c *Controller, rule *trafficshaping.Rule) *trafficshaping.Pipeline {
+	p, err := trafficshaping.NewPipeline([]*trafficshaping.Rule{rule}, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+// 执行n次请求，返回通过的次数
+func doN(p *trafficshaping.Pipeline, n int) int {
+	passed := 0
+	for i := 0; i < n; i++ {
+		if err := p.Do(func() {}); err == nil {
+			passed++
+		}
+	}
+	return passed
+}
+
+func TestController_SlidingWindow(t *testing.T) {
+	s, err := miniredis.Run()
+	assert.Nil(t, err)
+	defer s.Close()
+
+	pool := newTestPool(t, s)
+	rule := &trafficshaping.Rule{
+		Type:            trafficshaping.QPS,
+		ControlBehavior: trafficshaping.Reject,
+		Limit:           3,
+		Scope:           trafficshaping.Cluster,
+		Resource:        "api",
+		Algorithm:       trafficshaping.SlidingWindow,
+	}
+
+	// 两个实例共享限制
+	c1 := NewController(pool, nil)
+	c2 := NewController(pool, nil)
+	p1 := newTestPipeline(t, c1, rule)
+	p2 := newTestPipeline(t, c2, rule)
+
+	assert.Equal(t, 2, doN(p1, 2))
+	assert.Equal(t, 1, doN(p2, 3))
+	assert.Equal(t, 0, doN(p1, 1))
+
+	assert.True(t, c1.Available())
+	assert.Equal(t, int64(0), c1.FallbackCount())
+	assert.True(t, s.Exists("trafficshaping:api:sw"))
+
+	// 窗口过期后重新允许通过
+	members, err := s.ZMembers("trafficshaping:api:sw")
+	assert.Nil(t, err)
+	assert.Len(t, members, 3)
+	for _, member := range members {
+		_, err := s.ZAdd("trafficshaping:api:sw", 0, member)
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, 3, doN(p2, 4))
+}
+
+func TestController_TokenBucket(t *testing.T) {
+	s, err := miniredis.Run()
+	assert.Nil(t, err)
+	defer s.Close()
+
+	c := NewController(newTestPool(t, s), &Config{KeyPrefix: "order"})
+	p := newTestPipeline(t, c, &trafficshaping.Rule{
+		Type:            trafficshaping.QPS,
+		ControlBehavior: trafficshaping.Reject,
+		Limit:           1,
+		Scope:           trafficshaping.Cluster,
+		Resource:        "api",
+		Algorithm:       trafficshaping.TokenBucket,
+		Burst:           3,
+	})
+
+	// 初始令牌数为Burst，速率为1qps，测试期间不会填充新令牌
+	assert.Equal(t, 3, doN(p, 5))
+	tokens, err := strconv.ParseFloat(s.HGet("order:api:tb", "tokens"), 64)
+	assert.Nil(t, err)
+	assert.Less(t, tokens, float64(1))
+
+	// 上次填充时间前移后按速率填充令牌，且不超过Burst
+	ts, err := strconv.ParseInt(s.HGet("order:api:tb", "ts"), 10, 64)
+	assert.Nil(t, err)
+	s.HSet("order:api:tb", "ts", strconv.FormatInt(ts-10000, 10))
+	assert.Equal(t, 3, doN(p, 5))
+}
+
+func TestController_Fallback(t *testing.T) {
+	s, err := miniredis.Run()
+	assert.Nil(t, err)
+	defer s.Close()
+
+	c := NewController(newTestPool(t, s), &Config{RetryInterval: time.Millisecond * 200})
+	p := newTestPipeline(t, c, &trafficshaping.Rule{
+		Type:            trafficshaping.QPS,
+		ControlBehavior: trafficshaping.Reject,
+		Limit:           100,
+		Scope:           trafficshaping.Cluster,
+		Resource:        "api",
+		FallbackLimit:   2,
+	})
+
+	// redis不可用时降级为单进程限制
+	s.Close()
+	assert.Equal(t, 2, doN(p, 5))
+	assert.False(t, c.Available())
+	assert.Equal(t, int64(5), c.FallbackCount())
+
+	// 重试间隔后恢复使用redis
+	assert.Nil(t, s.Restart())
+	time.Sleep(time.Millisecond * 300)
+	assert.True(t, c.Available())
+	assert.Equal(t, 5, doN(p, 5))
+	assert.Equal(t, int64(5), c.FallbackCount())
+}
+
+func TestController_ScriptNotLoaded(t *testing.T) {
+	s, err := miniredis.Run()
+	assert.Nil(t, err)
+	defer s.Close()
+
+	c := NewController(newTestPool(t, s), nil)
+	p := newTestPipeline(t, c, &trafficshaping.Rule{
+		Type:            trafficshaping.QPS,
+		ControlBehavior: trafficshaping.Reject,
+		Limit:           1,
+		Scope:           trafficshaping.Cluster,
+		Resource:        "api",
+	})
+
+	// 脚本缓存被清空后通过EVAL重新加载
+	assert.Equal(t, 1, doN(p, 1))
+	s.FlushAll()
+	_ = c.pool.WrapDo(func(con *redis.Conn) error {
+		_, err := con.Do(context.Background(), "SCRIPT", "FLUSH")
+		return err
+	})
+	assert.Equal(t, 1, doN(p, 2))
+	assert.True(t, c.Available())
+}
@@ -13,6 +13,8 @@ const (
 	QPS RuleType = iota
 	// 并发限制
 	Concurrency
+	// 自适应并发限制，根据请求耗时及并发数自动调整限制
+	AdaptiveConcurrency
 )
 
 // 控制行为
@@ -26,6 +28,36 @@ const (
 	Waiting
 )
 
+// 规则作用范围
+type Scope int
+
+const (
+	// 单进程
+	Local Scope = iota
+	// 集群，所有实例共享限制，需要配合ClusterController使用，如redisctl.Controller
+	Cluster
+)
+
+// 集群限流算法
+type Algorithm int
+
+const (
+	// 滑动窗口，严格限制任意1秒内的请求数
+	SlidingWindow Algorithm = iota
+	// 令牌桶，允许不超过桶容量的突发流量
+	TokenBucket
+)
+
+// 自适应并发算法
+type AdaptiveAlgorithm int
+
+const (
+	// Gradient2，根据短期与长期平均耗时的比例调整限制
+	Gradient AdaptiveAlgorithm = iota
+	// Vegas，根据平均耗时与无负载耗时估算排队数调整限制
+	Vegas
+)
+
 // 规则
 type Rule struct {
 	// 规则类型
@@ -38,13 +70,78 @@ type Rule struct {
 	// 限制个数，为0表示不限制
 	// 当类型为QPS模式时，该参数为qps限制个数
 	// 当类型为并发模式时，该参数为最大并发数量
+	// 当类型为自适应并发模式时，该参数为初始并发数量，为0表示使用DefaultAdaptiveInitialLimit
 	Limit float64
+
+	// 作用范围，默认单进程
+	Scope Scope
+	// 资源名，作为redis key的一部分，相同资源名的实例共享限制
+	// 要求Scope为Cluster
+	Resource string
+	// 集群限流算法
+	// 要求Scope为Cluster
+	Algorithm Algorithm
+	// 令牌桶容量，为0表示与Limit相同
+	// 要求Algorithm为TokenBucket
+	Burst float64
+	// redis不可用时降级使用的单进程qps限制，为0表示使用Limit
+	// 要求Scope为Cluster
+	FallbackLimit float64
+
+	// 自适应并发算法
+	// 要求Type为AdaptiveConcurrency
+	AdaptiveAlgorithm AdaptiveAlgorithm
+	// 自适应并发的最小限制，为0表示使用DefaultAdaptiveMinLimit
+	// 要求Type为AdaptiveConcurrency
+	MinLimit float64
+	// 自适应并发的最大限制，为0表示使用DefaultAdaptiveMaxLimit
+	// 要求Type为AdaptiveConcurrency
+	MaxLimit float64
 }
 
 // 是否合法
 func (r *Rule) IsValid() error {
+	if r.Limit < 0 || r.MaxWaitingTime < 0 {
+		return errors.Errorf("Limit and MaxWaitingTime can't be negative")
+	}
 	if r.MaxWaitingTime != 0 && r.ControlBehavior != Waiting {
 		return errors.Errorf("MaxWaitingTime isn't empty, but type isn't Waiting")
 	}
+	// 匀速排队按Limit计算请求间隔
+	if r.ControlBehavior == Waiting && r.Limit == 0 {
+		return errors.Errorf("control behavior is Waiting, but Limit is empty")
+	}
+
+	if r.Scope == Cluster {
+		if r.Type != QPS {
+			return errors.Errorf("scope is Cluster, but type isn't QPS")
+		}
+		if r.ControlBehavior != Reject {
+			return errors.Errorf("scope is Cluster, but control behavior isn't Reject")
+		}
+		if r.Resource == "" {
+			return errors.Errorf("scope is Cluster, but Resource is empty")
+		}
+	} else if r.Resource != "" || r.FallbackLimit != 0 || r.Algorithm != SlidingWindow {
+		return errors.Errorf("Resource, Algorithm or FallbackLimit is set, but scope isn't Cluster")
+	}
+
+	if r.Type == AdaptiveConcurrency {
+		if r.ControlBehavior != Reject || r.Scope != Local {
+			return errors.Errorf("type is AdaptiveConcurrency, but control behavior isn't Reject or scope isn't Local")
+		}
+		if r.MinLimit < 0 || r.MaxLimit < 0 || (r.MaxLimit != 0 && r.MinLimit > r.MaxLimit) {
+			return errors.Errorf("MinLimit and MaxLimit of AdaptiveConcurrency are invalid")
+		}
+	} else if r.AdaptiveAlgorithm != Gradient || r.MinLimit != 0 || r.MaxLimit != 0 {
+		return errors.Errorf("AdaptiveAlgorithm, MinLimit or MaxLimit is set, but type isn't AdaptiveConcurrency")
+	}
+
+	if r.Burst < 0 || r.FallbackLimit < 0 {
+		return errors.Errorf("Burst and FallbackLimit can't be negative")
+	}
+	if r.Burst != 0 && r.Algorithm != TokenBucket {
+		return errors.Errorf("Burst isn't empty, but algorithm isn't TokenBucket")
+	}
 	return nil
 }
@@ -67,6 +67,9 @@ type OtherConfig struct {
 	IngressChangeWaitDuration      ctime.Duration `yaml:"ingressChangeWaitDuration"`
 
 	HWConsoleURL string `yaml:"hwConsoleUrl"`
+
+	// SRE 用户id, 可创建全局及生产环境的封网窗口
+	SREUserIDs []string `yaml:"sreUserIDs"`
 }
 
 // QTConfigCenterConfig 蜻蜓配置中心配置
@@ -207,6 +210,115 @@ type ImageRegistryConfig struct {
 	Namespace string `yaml:"namespace"`
 }
 
+// SecretConfig 密钥变量加密配置
+type SecretConfig struct {
+	// 信封加密主密钥, base64编码的32字节密钥
+	MasterKey string `yaml:"masterKey"`
+}
+
+// StatusWorkerConfig 状态处理任务多副本分片配置
+type StatusWorkerConfig struct {
+	// 分片数, 上线后不宜修改
+	ShardCount int `yaml:"shardCount"`
+	// 分片租约及副本心跳的过期时间
+	LeaseExpiry ctime.Duration `yaml:"leaseExpiry"`
+	// 一致性哈希环每个副本的虚拟节点数
+	VirtualNodes int `yaml:"virtualNodes"`
+}
+
+// NotificationConfig 通知渠道配置
+type NotificationConfig struct {
+	// 单次通知最大发送次数, 含首次发送
+	MaxAttempts int `yaml:"maxAttempts"`
+	// 重试间隔, 按重试次数线性递增
+	RetryInterval ctime.Duration `yaml:"retryInterval"`
+	SMTP          *SMTPConfig    `yaml:"smtp"`
+}
+
+// SMTPConfig 邮件通知发件配置
+type SMTPConfig struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	From     string `yaml:"from"`
+}
+
+// RightSizingConfig 资源规格推荐配置
+type RightSizingConfig struct {
+	// 统计资源使用量的时间, 如 7d
+	CountTime string `yaml:"countTime"`
+	// 回溯部署任务的时间范围
+	Lookback ctime.Duration `yaml:"lookback"`
+	// 推荐值相对使用量的冗余比例, 如 0.2
+	Headroom float64 `yaml:"headroom"`
+	// 各环境资源单价, key 为环境名
+	Prices map[string]*ResourcePriceConfig `yaml:"prices"`
+}
+
+// ResourcePriceConfig 资源每月单价
+type ResourcePriceConfig struct {
+	// 每核每月单价
+	CPUCoreMonthly float64 `yaml:"cpuCoreMonthly"`
+	// 每 GiB 每月单价
+	MemGiBMonthly float64 `yaml:"memGiBMonthly"`
+}
+
+// DriftDetectionConfig 配置漂移检测配置
+type DriftDetectionConfig struct {
+	// 回溯部署任务的时间范围
+	Lookback ctime.Duration `yaml:"lookback"`
+	// 是否自动重新应用期望状态
+	AutoHeal bool `yaml:"autoHeal"`
+	// 允许自动修复的环境, 为空表示所有环境
+	AutoHealEnvNames []string `yaml:"autoHealEnvNames"`
+}
+
+// IsAutoHealEnabled 环境是否开启自动修复
+func (c *DriftDetectionConfig) IsAutoHealEnabled(envName string) bool {
+	if c == nil || !c.AutoHeal {
+		return false
+	}
+	if len(c.AutoHealEnvNames) == 0 {
+		return true
+	}
+
+	for _, name := range c.AutoHealEnvNames {
+		if name == envName {
+			return true
+		}
+	}
+	return false
+}
+
+// AvailabilityConfig 高可用配置, 按环境创建 PodDisruptionBudget 及渲染拓扑分布约束
+type AvailabilityConfig struct {
+	// 各环境配置, key 为环境名, 未配置的环境使用默认配置
+	Envs map[string]*AvailabilityEnvConfig `yaml:"envs"`
+}
+
+// AvailabilityEnvConfig 环境高可用配置
+type AvailabilityEnvConfig struct {
+	// 是否为 Service, Worker 应用创建 PodDisruptionBudget
+	EnablePDB bool `yaml:"enablePDB"`
+	// 最大不可用 pod 数量或比例, 如 1, 25%, 为空时为 1
+	PDBMaxUnavailable string `yaml:"pdbMaxUnavailable"`
+	// 创建 PodDisruptionBudget 的最小实例数, 单实例应用设置后节点将无法驱逐
+	PDBMinReplicas int `yaml:"pdbMinReplicas"`
+	// 拓扑分布约束
+	TopologySpread []*TopologySpreadConfig `yaml:"topologySpread"`
+}
+
+// TopologySpreadConfig 拓扑分布约束配置
+type TopologySpreadConfig struct {
+	// 拓扑键, 如 topology.kubernetes.io/zone, kubernetes.io/hostname
+	TopologyKey string `yaml:"topologyKey"`
+	// 最大偏差
+	MaxSkew int32 `yaml:"maxSkew"`
+	// 无法满足时的策略, DoNotSchedule 或 ScheduleAnyway
+	WhenUnsatisfiable string `yaml:"whenUnsatisfiable"`
+}
+
 type Feishu struct {
 	Host             string `yaml:"host"`
 	DeployNotiChatID string `yaml:"deployNotiChatID"`
@@ -246,6 +358,12 @@ type Config struct {
 	OneTimeJobMaxCount int                             `yaml:"oneTimeJobMaxCount"`
 	IstioOnEnv         []string                        `yaml:"istioOnEnv"` // 用于控制哪些环境中已经可以 istio 部署
 	Feishu             *Feishu                         `yaml:"feishu"`
+	StatusWorker       *StatusWorkerConfig             `yaml:"statusWorker"`
+	Secret             *SecretConfig                   `yaml:"secret"`
+	Notification       *NotificationConfig             `yaml:"notification"`
+	RightSizing        *RightSizingConfig              `yaml:"rightSizing"`
+	DriftDetection     *DriftDetectionConfig           `yaml:"driftDetection"`
+	Availability       *AvailabilityConfig             `yaml:"availability"`
 }
 
 // Read 读取并加载配置文件
@@ -0,0 +1,141 @@
+package dao
+
+import (
+	"rulai/models/entity"
+	_errcode "rulai/utils/errcode"
+
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"gitlab.shanhai.int/sre/library/net/errcode"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func (d *Dao) CreateSingleFreezeWindow(ctx context.Context, window *entity.FreezeWindow) error {
+	_, err := d.Mongo.Collection(new(entity.FreezeWindow).TableName()).
+		InsertOne(ctx, window)
+	if err != nil {
+		return errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return nil
+}
+
+func (d *Dao) FindFreezeWindows(ctx context.Context, filter bson.M, opts ...*options.FindOptions) (
+	[]*entity.FreezeWindow, error) {
+	windows := make([]*entity.FreezeWindow, 0)
+	filter["delete_time"] = bson.M{
+		"$eq": primitive.Null{},
+	}
+
+	err := d.Mongo.ReadOnlyCollection(new(entity.FreezeWindow).TableName()).
+		Find(ctx, filter, opts...).
+		Decode(&windows)
+	if err != nil {
+		return nil, errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return windows, nil
+}
+
+func (d *Dao) CountFreezeWindows(ctx context.Context, filter bson.M, opts ...*options.CountOptions) (int, error) {
+	filter["delete_time"] = bson.M{
+		"$eq": primitive.Null{},
+	}
+
+	count, err := d.Mongo.ReadOnlyCollection(new(entity.FreezeWindow).TableName()).
+		CountDocuments(ctx, filter, opts...)
+	if err != nil {
+		return 0, errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return int(count), nil
+}
+
+func (d *Dao) FindSingleFreezeWindow(ctx context.Context, filter bson.M) (*entity.FreezeWindow, error) {
+	filter["delete_time"] = bson.M{"$eq": primitive.Null{}}
+
+	window := new(entity.FreezeWindow)
+
+	err := d.Mongo.ReadOnlyCollection(window.TableName()).
+		FindOne(ctx, filter).
+		Decode(window)
+	if err == mongo.ErrNoDocuments {
+		return nil, errors.Wrapf(errcode.NoRowsFoundError, "%s", err)
+	} else if err != nil {
+		return nil, errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return window, nil
+}
+
+func (d *Dao) UpdateSingleFreezeWindow(ctx context.Context, id string, change bson.M) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.Wrap(_errcode.InvalidHexStringError, err.Error())
+	}
+
+	_, err = d.Mongo.Collection(new(entity.FreezeWindow).TableName()).
+		UpdateOne(ctx, bson.M{
+			"_id": objectID,
+			"delete_time": bson.M{
+				"$eq": primitive.Null{},
+			},
+		}, change)
+	if err != nil {
+		return errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return nil
+}
+
+func (d *Dao) DeleteSingleFreezeWindowByID(ctx context.Context, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.Wrap(_errcode.InvalidHexStringError, err.Error())
+	}
+
+	_, err = d.Mongo.Collection(new(entity.FreezeWindow).TableName()).
+		UpdateOne(ctx, bson.M{
+			"_id": objectID,
+		}, bson.M{
+			"$set": bson.M{
+				"delete_time": time.Now(),
+			},
+		})
+	if err != nil {
+		return errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return nil
+}
+
+// CreateFreezeOverrideRecord creates freeze override record.
+func (d *Dao) CreateFreezeOverrideRecord(ctx context.Context,
+	record *entity.FreezeOverrideRecord) (primitive.ObjectID, error) {
+	res, err := d.Mongo.Collection(new(entity.FreezeOverrideRecord).TableName()).
+		InsertOne(ctx, record)
+	if err != nil {
+		return primitive.NilObjectID, errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return res.InsertedID.(primitive.ObjectID), nil
+}
+
+// ListFreezeOverrideRecords lists freeze override records.
+func (d *Dao) ListFreezeOverrideRecords(ctx context.Context, filter bson.M,
+	opts ...*options.FindOptions) ([]*entity.FreezeOverrideRecord, error) {
+	records := make([]*entity.FreezeOverrideRecord, 0)
+	err := d.Mongo.ReadOnlyCollection(new(entity.FreezeOverrideRecord).TableName()).
+		Find(ctx, filter, opts...).
+		Decode(&records)
+	if err != nil {
+		return nil, errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return records, nil
+}
@@ -0,0 +1,72 @@
+package dao
+
+import (
+	"rulai/models/entity"
+
+	"context"
+
+	"github.com/pkg/errors"
+	"gitlab.shanhai.int/sre/library/net/errcode"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func (d *Dao) CreateSingleK8sDrift(ctx context.Context, drift *entity.K8sDrift) error {
+	_, err := d.Mongo.Collection(drift.TableName()).
+		InsertOne(ctx, drift)
+	if err != nil {
+		return errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return nil
+}
+
+func (d *Dao) FindK8sDrifts(ctx context.Context, filter bson.M, opts ...*options.FindOptions) ([]*entity.K8sDrift, error) {
+	drifts := make([]*entity.K8sDrift, 0)
+
+	err := d.Mongo.ReadOnlyCollection(new(entity.K8sDrift).TableName()).
+		Find(ctx, filter, opts...).
+		Decode(&drifts)
+	if err != nil {
+		return nil, errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return drifts, nil
+}
+
+func (d *Dao) CountK8sDrifts(ctx context.Context, filter bson.M, opts ...*options.CountOptions) (int, error) {
+	count, err := d.Mongo.ReadOnlyCollection(new(entity.K8sDrift).TableName()).
+		CountDocuments(ctx, filter, opts...)
+	if err != nil {
+		return 0, errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return int(count), nil
+}
+
+func (d *Dao) FindSingleK8sDrift(ctx context.Context, filter bson.M) (*entity.K8sDrift, error) {
+	drift := new(entity.K8sDrift)
+
+	err := d.Mongo.Collection(drift.TableName()).
+		FindOne(ctx, filter).
+		Decode(drift)
+	if err == mongo.ErrNoDocuments {
+		return nil, errors.Wrapf(errcode.NoRowsFoundError, "%s", err)
+	} else if err != nil {
+		return nil, errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return drift, nil
+}
+
+func (d *Dao) UpdateSingleK8sDrift(ctx context.Context, id primitive.ObjectID, change bson.M) error {
+	_, err := d.Mongo.Collection(new(entity.K8sDrift).TableName()).
+		UpdateOne(ctx, bson.M{"_id": id}, change)
+	if err != nil {
+		return errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,162 @@
+package dao
+
+import (
+	"rulai/models/entity"
+	_errcode "rulai/utils/errcode"
+
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"gitlab.shanhai.int/sre/library/net/errcode"
+	"gitlab.shanhai.int/sre/library/net/redlock"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	MaintenanceScheduleWorkerLockKey = "maintenance_schedule_worker:"
+)
+
+// GetMaintenanceScheduleWorkerLock 获取定时运维计划的分布式锁
+func (d *Dao) GetMaintenanceScheduleWorkerLock(ctx context.Context, id string) *redlock.Mutex {
+	return d.Redlock.NewMutex(fmt.Sprintf("%s%s", MaintenanceScheduleWorkerLockKey, id))
+}
+
+func (d *Dao) CreateSingleMaintenanceSchedule(ctx context.Context, schedule *entity.MaintenanceSchedule) error {
+	_, err := d.Mongo.Collection(new(entity.MaintenanceSchedule).TableName()).
+		InsertOne(ctx, schedule)
+	if err != nil {
+		return errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return nil
+}
+
+func (d *Dao) FindMaintenanceSchedules(ctx context.Context, filter bson.M, opts ...*options.FindOptions) (
+	[]*entity.MaintenanceSchedule, error) {
+	schedules := make([]*entity.MaintenanceSchedule, 0)
+	filter["delete_time"] = bson.M{
+		"$eq": primitive.Null{},
+	}
+
+	err := d.Mongo.ReadOnlyCollection(new(entity.MaintenanceSchedule).TableName()).
+		Find(ctx, filter, opts...).
+		Decode(&schedules)
+	if err != nil {
+		return nil, errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return schedules, nil
+}
+
+func (d *Dao) CountMaintenanceSchedules(ctx context.Context, filter bson.M, opts ...*options.CountOptions) (int, error) {
+	filter["delete_time"] = bson.M{
+		"$eq": primitive.Null{},
+	}
+
+	count, err := d.Mongo.ReadOnlyCollection(new(entity.MaintenanceSchedule).TableName()).
+		CountDocuments(ctx, filter, opts...)
+	if err != nil {
+		return 0, errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return int(count), nil
+}
+
+func (d *Dao) FindSingleMaintenanceSchedule(ctx context.Context, filter bson.M) (*entity.MaintenanceSchedule, error) {
+	filter["delete_time"] = bson.M{"$eq": primitive.Null{}}
+
+	schedule := new(entity.MaintenanceSchedule)
+
+	// 执行计划依赖最新的下次执行时间, 从主库读取
+	err := d.Mongo.Collection(schedule.TableName()).
+		FindOne(ctx, filter).
+		Decode(schedule)
+	if err == mongo.ErrNoDocuments {
+		return nil, errors.Wrapf(errcode.NoRowsFoundError, "%s", err)
+	} else if err != nil {
+		return nil, errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return schedule, nil
+}
+
+func (d *Dao) UpdateSingleMaintenanceSchedule(ctx context.Context, id string, change bson.M) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.Wrap(_errcode.InvalidHexStringError, err.Error())
+	}
+
+	_, err = d.Mongo.Collection(new(entity.MaintenanceSchedule).TableName()).
+		UpdateOne(ctx, bson.M{
+			"_id": objectID,
+			"delete_time": bson.M{
+				"$eq": primitive.Null{},
+			},
+		}, change)
+	if err != nil {
+		return errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return nil
+}
+
+func (d *Dao) DeleteSingleMaintenanceScheduleByID(ctx context.Context, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.Wrap(_errcode.InvalidHexStringError, err.Error())
+	}
+
+	_, err = d.Mongo.Collection(new(entity.MaintenanceSchedule).TableName()).
+		UpdateOne(ctx, bson.M{
+			"_id": objectID,
+		}, bson.M{
+			"$set": bson.M{
+				"delete_time": time.Now(),
+			},
+		})
+	if err != nil {
+		return errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return nil
+}
+
+func (d *Dao) CreateSingleMaintenanceScheduleExecution(ctx context.Context,
+	execution *entity.MaintenanceScheduleExecution) error {
+	_, err := d.Mongo.Collection(new(entity.MaintenanceScheduleExecution).TableName()).
+		InsertOne(ctx, execution)
+	if err != nil {
+		return errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return nil
+}
+
+func (d *Dao) FindMaintenanceScheduleExecutions(ctx context.Context, filter bson.M, opts ...*options.FindOptions) (
+	[]*entity.MaintenanceScheduleExecution, error) {
+	executions := make([]*entity.MaintenanceScheduleExecution, 0)
+	err := d.Mongo.ReadOnlyCollection(new(entity.MaintenanceScheduleExecution).TableName()).
+		Find(ctx, filter, opts...).
+		Decode(&executions)
+	if err != nil {
+		return nil, errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return executions, nil
+}
+
+func (d *Dao) CountMaintenanceScheduleExecutions(ctx context.Context, filter bson.M,
+	opts ...*options.CountOptions) (int, error) {
+	count, err := d.Mongo.ReadOnlyCollection(new(entity.MaintenanceScheduleExecution).TableName()).
+		CountDocuments(ctx, filter, opts...)
+	if err != nil {
+		return 0, errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return int(count), nil
+}
@@ -0,0 +1,84 @@
+package dao
+
+import (
+	"rulai/models/entity"
+
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"gitlab.shanhai.int/sre/library/net/errcode"
+	"gitlab.shanhai.int/sre/library/net/redlock"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	MultiClusterDeployWorkerLockKey = "multi_cluster_deploy_worker:"
+)
+
+// GetMultiClusterDeployWorkerLock 获取多集群部署的分布式锁
+func (d *Dao) GetMultiClusterDeployWorkerLock(ctx context.Context, id string) *redlock.Mutex {
+	return d.Redlock.NewMutex(fmt.Sprintf("%s%s", MultiClusterDeployWorkerLockKey, id))
+}
+
+func (d *Dao) CreateSingleMultiClusterDeploy(ctx context.Context, deploy *entity.MultiClusterDeploy) error {
+	_, err := d.Mongo.Collection(new(entity.MultiClusterDeploy).TableName()).
+		InsertOne(ctx, deploy)
+	if err != nil {
+		return errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return nil
+}
+
+func (d *Dao) FindMultiClusterDeploys(ctx context.Context, filter bson.M,
+	opts ...*options.FindOptions) ([]*entity.MultiClusterDeploy, error) {
+	deploys := make([]*entity.MultiClusterDeploy, 0)
+	err := d.Mongo.ReadOnlyCollection(new(entity.MultiClusterDeploy).TableName()).
+		Find(ctx, filter, opts...).
+		Decode(&deploys)
+	if err != nil {
+		return nil, errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return deploys, nil
+}
+
+func (d *Dao) CountMultiClusterDeploys(ctx context.Context, filter bson.M, opts ...*options.CountOptions) (int, error) {
+	count, err := d.Mongo.ReadOnlyCollection(new(entity.MultiClusterDeploy).TableName()).
+		CountDocuments(ctx, filter, opts...)
+	if err != nil {
+		return 0, errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return int(count), nil
+}
+
+func (d *Dao) FindSingleMultiClusterDeploy(ctx context.Context, filter bson.M) (*entity.MultiClusterDeploy, error) {
+	deploy := new(entity.MultiClusterDeploy)
+
+	// 批次推进依赖最新的状态, 从主库读取
+	err := d.Mongo.Collection(deploy.TableName()).
+		FindOne(ctx, filter).
+		Decode(deploy)
+	if err == mongo.ErrNoDocuments {
+		return nil, errors.Wrapf(errcode.NoRowsFoundError, "%s", err)
+	} else if err != nil {
+		return nil, errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return deploy, nil
+}
+
+func (d *Dao) UpdateSingleMultiClusterDeploy(ctx context.Context, id primitive.ObjectID, change bson.M) error {
+	_, err := d.Mongo.Collection(new(entity.MultiClusterDeploy).TableName()).
+		UpdateOne(ctx, bson.M{"_id": id}, change)
+	if err != nil {
+		return errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return nil
+}
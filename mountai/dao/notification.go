@@ -0,0 +1,215 @@
+package dao
+
+import (
+	"rulai/models/entity"
+	_errcode "rulai/utils/errcode"
+
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"gitlab.shanhai.int/sre/library/net/errcode"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func (d *Dao) CreateSingleNotificationChannel(ctx context.Context, channel *entity.NotificationChannel) error {
+	_, err := d.Mongo.Collection(new(entity.NotificationChannel).TableName()).
+		InsertOne(ctx, channel)
+	if err != nil {
+		return errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return nil
+}
+
+func (d *Dao) FindNotificationChannels(ctx context.Context, filter bson.M, opts ...*options.FindOptions) (
+	[]*entity.NotificationChannel, error) {
+	channels := make([]*entity.NotificationChannel, 0)
+	filter["delete_time"] = bson.M{
+		"$eq": primitive.Null{},
+	}
+
+	err := d.Mongo.ReadOnlyCollection(new(entity.NotificationChannel).TableName()).
+		Find(ctx, filter, opts...).
+		Decode(&channels)
+	if err != nil {
+		return nil, errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return channels, nil
+}
+
+func (d *Dao) FindSingleNotificationChannel(ctx context.Context, filter bson.M) (*entity.NotificationChannel, error) {
+	filter["delete_time"] = bson.M{"$eq": primitive.Null{}}
+
+	channel := new(entity.NotificationChannel)
+
+	err := d.Mongo.ReadOnlyCollection(channel.TableName()).
+		FindOne(ctx, filter).
+		Decode(channel)
+	if err == mongo.ErrNoDocuments {
+		return nil, errors.Wrapf(errcode.NoRowsFoundError, "%s", err)
+	} else if err != nil {
+		return nil, errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return channel, nil
+}
+
+func (d *Dao) UpdateSingleNotificationChannel(ctx context.Context, id string, change bson.M) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.Wrap(_errcode.InvalidHexStringError, err.Error())
+	}
+
+	_, err = d.Mongo.Collection(new(entity.NotificationChannel).TableName()).
+		UpdateOne(ctx, bson.M{
+			"_id": objectID,
+			"delete_time": bson.M{
+				"$eq": primitive.Null{},
+			},
+		}, change)
+	if err != nil {
+		return errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return nil
+}
+
+func (d *Dao) DeleteSingleNotificationChannelByID(ctx context.Context, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.Wrap(_errcode.InvalidHexStringError, err.Error())
+	}
+
+	_, err = d.Mongo.Collection(new(entity.NotificationChannel).TableName()).
+		UpdateOne(ctx, bson.M{
+			"_id": objectID,
+		}, bson.M{
+			"$set": bson.M{
+				"delete_time": time.Now(),
+			},
+		})
+	if err != nil {
+		return errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return nil
+}
+
+func (d *Dao) CreateSingleNotificationRule(ctx context.Context, rule *entity.NotificationRule) error {
+	_, err := d.Mongo.Collection(new(entity.NotificationRule).TableName()).
+		InsertOne(ctx, rule)
+	if err != nil {
+		return errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return nil
+}
+
+func (d *Dao) FindNotificationRules(ctx context.Context, filter bson.M, opts ...*options.FindOptions) (
+	[]*entity.NotificationRule, error) {
+	rules := make([]*entity.NotificationRule, 0)
+	filter["delete_time"] = bson.M{
+		"$eq": primitive.Null{},
+	}
+
+	err := d.Mongo.ReadOnlyCollection(new(entity.NotificationRule).TableName()).
+		Find(ctx, filter, opts...).
+		Decode(&rules)
+	if err != nil {
+		return nil, errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return rules, nil
+}
+
+func (d *Dao) FindSingleNotificationRule(ctx context.Context, filter bson.M) (*entity.NotificationRule, error) {
+	filter["delete_time"] = bson.M{"$eq": primitive.Null{}}
+
+	rule := new(entity.NotificationRule)
+
+	err := d.Mongo.ReadOnlyCollection(rule.TableName()).
+		FindOne(ctx, filter).
+		Decode(rule)
+	if err == mongo.ErrNoDocuments {
+		return nil, errors.Wrapf(errcode.NoRowsFoundError, "%s", err)
+	} else if err != nil {
+		return nil, errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return rule, nil
+}
+
+func (d *Dao) UpdateSingleNotificationRule(ctx context.Context, id string, change bson.M) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.Wrap(_errcode.InvalidHexStringError, err.Error())
+	}
+
+	_, err = d.Mongo.Collection(new(entity.NotificationRule).TableName()).
+		UpdateOne(ctx, bson.M{
+			"_id": objectID,
+			"delete_time": bson.M{
+				"$eq": primitive.Null{},
+			},
+		}, change)
+	if err != nil {
+		return errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return nil
+}
+
+// DeleteNotificationRules 批量软删除通知规则
+func (d *Dao) DeleteNotificationRules(ctx context.Context, filter bson.M) error {
+	filter["delete_time"] = bson.M{"$eq": primitive.Null{}}
+
+	_, err := d.Mongo.Collection(new(entity.NotificationRule).TableName()).
+		UpdateMany(ctx, filter, bson.M{
+			"$set": bson.M{
+				"delete_time": time.Now(),
+			},
+		})
+	if err != nil {
+		return errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return nil
+}
+
+func (d *Dao) CreateNotificationDelivery(ctx context.Context, delivery *entity.NotificationDelivery) error {
+	_, err := d.Mongo.Collection(new(entity.NotificationDelivery).TableName()).
+		InsertOne(ctx, delivery)
+	if err != nil {
+		return errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return nil
+}
+
+func (d *Dao) FindNotificationDeliveries(ctx context.Context, filter bson.M, opts ...*options.FindOptions) (
+	[]*entity.NotificationDelivery, error) {
+	deliveries := make([]*entity.NotificationDelivery, 0)
+	err := d.Mongo.ReadOnlyCollection(new(entity.NotificationDelivery).TableName()).
+		Find(ctx, filter, opts...).
+		Decode(&deliveries)
+	if err != nil {
+		return nil, errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return deliveries, nil
+}
+
+func (d *Dao) CountNotificationDeliveries(ctx context.Context, filter bson.M, opts ...*options.CountOptions) (int, error) {
+	count, err := d.Mongo.ReadOnlyCollection(new(entity.NotificationDelivery).TableName()).
+		CountDocuments(ctx, filter, opts...)
+	if err != nil {
+		return 0, errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return int(count), nil
+}
@@ -0,0 +1,183 @@
+package dao
+
+import (
+	"rulai/models/entity"
+	_errcode "rulai/utils/errcode"
+
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"gitlab.shanhai.int/sre/library/net/errcode"
+	"gitlab.shanhai.int/sre/library/net/redlock"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	PipelineRunWorkerLockKey = "pipeline_run_worker:"
+)
+
+// GetPipelineRunWorkerLock 获取流水线运行的分布式锁
+func (d *Dao) GetPipelineRunWorkerLock(ctx context.Context, id string) *redlock.Mutex {
+	return d.Redlock.NewMutex(fmt.Sprintf("%s%s", PipelineRunWorkerLockKey, id))
+}
+
+func (d *Dao) CreateSinglePipeline(ctx context.Context, pipeline *entity.Pipeline) error {
+	_, err := d.Mongo.Collection(new(entity.Pipeline).TableName()).
+		InsertOne(ctx, pipeline)
+	if err != nil {
+		return errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return nil
+}
+
+func (d *Dao) FindPipelines(ctx context.Context, filter bson.M, opts ...*options.FindOptions) ([]*entity.Pipeline, error) {
+	pipelines := make([]*entity.Pipeline, 0)
+	filter["delete_time"] = bson.M{
+		"$eq": primitive.Null{},
+	}
+
+	err := d.Mongo.ReadOnlyCollection(new(entity.Pipeline).TableName()).
+		Find(ctx, filter, opts...).
+		Decode(&pipelines)
+	if err != nil {
+		return nil, errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return pipelines, nil
+}
+
+func (d *Dao) CountPipelines(ctx context.Context, filter bson.M, opts ...*options.CountOptions) (int, error) {
+	filter["delete_time"] = bson.M{
+		"$eq": primitive.Null{},
+	}
+
+	count, err := d.Mongo.ReadOnlyCollection(new(entity.Pipeline).TableName()).
+		CountDocuments(ctx, filter, opts...)
+	if err != nil {
+		return 0, errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return int(count), nil
+}
+
+func (d *Dao) FindSinglePipeline(ctx context.Context, filter bson.M) (*entity.Pipeline, error) {
+	filter["delete_time"] = bson.M{"$eq": primitive.Null{}}
+
+	pipeline := new(entity.Pipeline)
+
+	err := d.Mongo.ReadOnlyCollection(pipeline.TableName()).
+		FindOne(ctx, filter).
+		Decode(pipeline)
+	if err == mongo.ErrNoDocuments {
+		return nil, errors.Wrapf(errcode.NoRowsFoundError, "%s", err)
+	} else if err != nil {
+		return nil, errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return pipeline, nil
+}
+
+func (d *Dao) UpdateSinglePipeline(ctx context.Context, id string, change bson.M) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.Wrap(_errcode.InvalidHexStringError, err.Error())
+	}
+
+	_, err = d.Mongo.Collection(new(entity.Pipeline).TableName()).
+		UpdateOne(ctx, bson.M{
+			"_id": objectID,
+			"delete_time": bson.M{
+				"$eq": primitive.Null{},
+			},
+		}, change)
+	if err != nil {
+		return errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return nil
+}
+
+func (d *Dao) DeleteSinglePipelineByID(ctx context.Context, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.Wrap(_errcode.InvalidHexStringError, err.Error())
+	}
+
+	_, err = d.Mongo.Collection(new(entity.Pipeline).TableName()).
+		UpdateOne(ctx, bson.M{
+			"_id": objectID,
+		}, bson.M{
+			"$set": bson.M{
+				"delete_time": time.Now(),
+			},
+		})
+	if err != nil {
+		return errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return nil
+}
+
+func (d *Dao) CreateSinglePipelineRun(ctx context.Context, run *entity.PipelineRun) error {
+	_, err := d.Mongo.Collection(new(entity.PipelineRun).TableName()).
+		InsertOne(ctx, run)
+	if err != nil {
+		return errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return nil
+}
+
+func (d *Dao) FindPipelineRuns(ctx context.Context, filter bson.M, opts ...*options.FindOptions) ([]*entity.PipelineRun, error) {
+	runs := make([]*entity.PipelineRun, 0)
+	err := d.Mongo.ReadOnlyCollection(new(entity.PipelineRun).TableName()).
+		Find(ctx, filter, opts...).
+		Decode(&runs)
+	if err != nil {
+		return nil, errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return runs, nil
+}
+
+func (d *Dao) CountPipelineRuns(ctx context.Context, filter bson.M, opts ...*options.CountOptions) (int, error) {
+	count, err := d.Mongo.ReadOnlyCollection(new(entity.PipelineRun).TableName()).
+		CountDocuments(ctx, filter, opts...)
+	if err != nil {
+		return 0, errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return int(count), nil
+}
+
+func (d *Dao) FindSinglePipelineRun(ctx context.Context, filter bson.M) (*entity.PipelineRun, error) {
+	run := new(entity.PipelineRun)
+
+	// 流水线推进依赖最新的运行状态, 从主库读取
+	err := d.Mongo.Collection(run.TableName()).
+		FindOne(ctx, filter).
+		Decode(run)
+	if err == mongo.ErrNoDocuments {
+		return nil, errors.Wrapf(errcode.NoRowsFoundError, "%s", err)
+	} else if err != nil {
+		return nil, errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return run, nil
+}
+
+func (d *Dao) UpdateSinglePipelineRun(ctx context.Context, id primitive.ObjectID, change bson.M) error {
+	_, err := d.Mongo.Collection(new(entity.PipelineRun).TableName()).
+		UpdateOne(ctx, bson.M{"_id": id}, change)
+	if err != nil {
+		return errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return nil
+}
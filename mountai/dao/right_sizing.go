@@ -0,0 +1,68 @@
+package dao
+
+import (
+	"rulai/models/entity"
+
+	"context"
+
+	"github.com/pkg/errors"
+	"gitlab.shanhai.int/sre/library/net/errcode"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// UpsertRightSizingRecommendation 创建或覆盖应用环境集群的资源规格推荐
+// 覆盖时需保持 _id 不变
+func (d *Dao) UpsertRightSizingRecommendation(ctx context.Context, recommendation *entity.RightSizingRecommendation) error {
+	_, err := d.Mongo.Collection(recommendation.TableName()).
+		ReplaceOne(ctx, bson.M{
+			"app_id":       recommendation.AppID,
+			"env_name":     recommendation.EnvName,
+			"cluster_name": recommendation.ClusterName,
+		}, recommendation, options.Replace().SetUpsert(true))
+	if err != nil {
+		return errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return nil
+}
+
+func (d *Dao) FindRightSizingRecommendations(ctx context.Context, filter bson.M, opts ...*options.FindOptions) (
+	[]*entity.RightSizingRecommendation, error) {
+	recommendations := make([]*entity.RightSizingRecommendation, 0)
+
+	err := d.Mongo.ReadOnlyCollection(new(entity.RightSizingRecommendation).TableName()).
+		Find(ctx, filter, opts...).
+		Decode(&recommendations)
+	if err != nil {
+		return nil, errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return recommendations, nil
+}
+
+func (d *Dao) CountRightSizingRecommendations(ctx context.Context, filter bson.M, opts ...*options.CountOptions) (int, error) {
+	count, err := d.Mongo.ReadOnlyCollection(new(entity.RightSizingRecommendation).TableName()).
+		CountDocuments(ctx, filter, opts...)
+	if err != nil {
+		return 0, errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return int(count), nil
+}
+
+func (d *Dao) FindSingleRightSizingRecommendation(ctx context.Context, filter bson.M) (*entity.RightSizingRecommendation, error) {
+	recommendation := new(entity.RightSizingRecommendation)
+
+	err := d.Mongo.ReadOnlyCollection(recommendation.TableName()).
+		FindOne(ctx, filter).
+		Decode(recommendation)
+	if err == mongo.ErrNoDocuments {
+		return nil, errors.Wrapf(errcode.NoRowsFoundError, "%s", err)
+	} else if err != nil {
+		return nil, errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return recommendation, nil
+}
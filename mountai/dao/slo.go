@@ -0,0 +1,114 @@
+package dao
+
+import (
+	"rulai/models/entity"
+	_errcode "rulai/utils/errcode"
+
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"gitlab.shanhai.int/sre/library/net/errcode"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func (d *Dao) CreateSingleSLO(ctx context.Context, slo *entity.SLO) error {
+	_, err := d.Mongo.Collection(new(entity.SLO).TableName()).
+		InsertOne(ctx, slo)
+	if err != nil {
+		return errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return nil
+}
+
+func (d *Dao) FindSLOs(ctx context.Context, filter bson.M, opts ...*options.FindOptions) ([]*entity.SLO, error) {
+	slos := make([]*entity.SLO, 0)
+	filter["delete_time"] = bson.M{
+		"$eq": primitive.Null{},
+	}
+
+	err := d.Mongo.ReadOnlyCollection(new(entity.SLO).TableName()).
+		Find(ctx, filter, opts...).
+		Decode(&slos)
+	if err != nil {
+		return nil, errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return slos, nil
+}
+
+func (d *Dao) CountSLOs(ctx context.Context, filter bson.M, opts ...*options.CountOptions) (int, error) {
+	filter["delete_time"] = bson.M{
+		"$eq": primitive.Null{},
+	}
+
+	count, err := d.Mongo.ReadOnlyCollection(new(entity.SLO).TableName()).
+		CountDocuments(ctx, filter, opts...)
+	if err != nil {
+		return 0, errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return int(count), nil
+}
+
+func (d *Dao) FindSingleSLO(ctx context.Context, filter bson.M) (*entity.SLO, error) {
+	filter["delete_time"] = bson.M{"$eq": primitive.Null{}}
+
+	slo := new(entity.SLO)
+
+	err := d.Mongo.ReadOnlyCollection(slo.TableName()).
+		FindOne(ctx, filter).
+		Decode(slo)
+	if err == mongo.ErrNoDocuments {
+		return nil, errors.Wrapf(errcode.NoRowsFoundError, "%s", err)
+	} else if err != nil {
+		return nil, errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return slo, nil
+}
+
+func (d *Dao) UpdateSingleSLO(ctx context.Context, id string, change bson.M) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.Wrap(_errcode.InvalidHexStringError, err.Error())
+	}
+
+	_, err = d.Mongo.Collection(new(entity.SLO).TableName()).
+		UpdateOne(ctx, bson.M{
+			"_id": objectID,
+			"delete_time": bson.M{
+				"$eq": primitive.Null{},
+			},
+		}, change)
+	if err != nil {
+		return errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return nil
+}
+
+func (d *Dao) DeleteSingleSLOByID(ctx context.Context, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.Wrap(_errcode.InvalidHexStringError, err.Error())
+	}
+
+	_, err = d.Mongo.Collection(new(entity.SLO).TableName()).
+		UpdateOne(ctx, bson.M{
+			"_id": objectID,
+		}, bson.M{
+			"$set": bson.M{
+				"delete_time": time.Now(),
+			},
+		})
+	if err != nil {
+		return errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-redsync/redsync"
+	"github.com/gomodule/redigo/redis"
+	"github.com/pkg/errors"
+	"gitlab.shanhai.int/sre/library/net/errcode"
+	"gitlab.shanhai.int/sre/library/net/redlock"
+)
+
+const (
+	TaskStatusWorkerShardLockKey = "task_status_worker_shard:"
+	// 存活的状态处理副本, score 为最近一次心跳的时间戳
+	TaskStatusWorkerReplicasKey = "task_status_worker_replicas"
+)
+
+// GetTaskStatusWorkerShardLease 获取分片租约, 租约只尝试获取一次, 需在过期前续约
+func (d *Dao) GetTaskStatusWorkerShardLease(ctx context.Context, shard int, expiry time.Duration) *redlock.Mutex {
+	return d.Redlock.NewMutex(fmt.Sprintf("%s%d", TaskStatusWorkerShardLockKey, shard),
+		redsync.SetExpiry(expiry), redsync.SetTries(1))
+}
+
+// HeartbeatTaskStatusWorkerReplica 上报副本心跳, 并清理心跳已过期的副本
+func (d *Dao) HeartbeatTaskStatusWorkerReplica(ctx context.Context, replicaID string, expiry time.Duration) error {
+	con := d.Redis.Get()
+	defer con.Close()
+
+	now := time.Now()
+	_, err := con.Do(ctx, "zadd", TaskStatusWorkerReplicasKey, now.Unix(), replicaID)
+	if err != nil {
+		return errors.Wrapf(errcode.RedisError, "%s", err)
+	}
+
+	_, err = con.Do(ctx, "zremrangebyscore", TaskStatusWorkerReplicasKey, "-inf", now.Add(-expiry).Unix())
+	if err != nil {
+		return errors.Wrapf(errcode.RedisError, "%s", err)
+	}
+
+	return nil
+}
+
+// GetTaskStatusWorkerReplicas 获取心跳未过期的副本
+func (d *Dao) GetTaskStatusWorkerReplicas(ctx context.Context, expiry time.Duration) ([]string, error) {
+	con := d.Redis.Get()
+	defer con.Close()
+
+	replicas, err := redis.Strings(con.Do(ctx, "zrangebyscore", TaskStatusWorkerReplicasKey,
+		time.Now().Add(-expiry).Unix(), "+inf"))
+	if err == redis.ErrNil {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, errors.Wrapf(errcode.RedisError, "%s", err)
+	}
+
+	return replicas, nil
+}
+
+// RemoveTaskStatusWorkerReplica 副本退出时移除, 其余副本无需等待心跳过期即可接管分片
+func (d *Dao) RemoveTaskStatusWorkerReplica(ctx context.Context, replicaID string) error {
+	con := d.Redis.Get()
+	defer con.Close()
+
+	_, err := con.Do(ctx, "zrem", TaskStatusWorkerReplicasKey, replicaID)
+	if err != nil {
+		return errors.Wrapf(errcode.RedisError, "%s", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,98 @@
+package dao
+
+import (
+	"rulai/models/entity"
+
+	"context"
+
+	"github.com/pkg/errors"
+	"gitlab.shanhai.int/sre/library/net/errcode"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func (d *Dao) CreateTaskEvent(ctx context.Context, event *entity.TaskEvent) error {
+	_, err := d.Mongo.Collection(new(entity.TaskEvent).TableName()).
+		InsertOne(ctx, event)
+	if err != nil {
+		return errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return nil
+}
+
+func (d *Dao) FindTaskEvents(ctx context.Context, filter bson.M, opts ...*options.FindOptions) ([]*entity.TaskEvent, error) {
+	events := make([]*entity.TaskEvent, 0)
+	err := d.Mongo.ReadOnlyCollection(new(entity.TaskEvent).TableName()).
+		Find(ctx, filter, opts...).
+		Decode(&events)
+	if err != nil {
+		return nil, errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return events, nil
+}
+
+func (d *Dao) FindLatestTaskEvent(ctx context.Context, filter bson.M) (*entity.TaskEvent, error) {
+	event := new(entity.TaskEvent)
+	err := d.Mongo.ReadOnlyCollection(event.TableName()).
+		FindOne(ctx, filter, options.FindOne().SetSort(bson.M{"_id": -1})).
+		Decode(event)
+	if err == mongo.ErrNoDocuments {
+		return nil, errors.Wrapf(errcode.NoRowsFoundError, "%s", err)
+	} else if err != nil {
+		return nil, errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return event, nil
+}
+
+// AggregateTaskPhaseStats 按项目, 环境, 集群, 任务类型及阶段统计状态变化事件的耗时
+func (d *Dao) AggregateTaskPhaseStats(ctx context.Context, filter bson.M) ([]*entity.TaskPhaseStat, error) {
+	filter["$expr"] = bson.M{"$ne": bson.A{"$from_status", "$to_status"}}
+
+	res := make([]*entity.TaskPhaseStat, 0)
+	err := d.Mongo.ReadOnlyCollection(new(entity.TaskEvent).TableName()).
+		Aggregate(ctx, bson.A{
+			bson.M{
+				"$match": filter,
+			},
+			bson.M{
+				"$group": bson.M{
+					"_id": bson.M{
+						"project_id":   "$project_id",
+						"env_name":     "$env_name",
+						"cluster_name": "$cluster_name",
+						"action":       "$action",
+						"status":       "$from_status",
+					},
+					"count":           bson.M{"$sum": 1},
+					"avg_duration_ms": bson.M{"$avg": "$duration_ms"},
+					"max_duration_ms": bson.M{"$max": "$duration_ms"},
+				},
+			},
+			bson.M{
+				"$project": bson.M{
+					"_id":             0,
+					"project_id":      "$_id.project_id",
+					"env_name":        "$_id.env_name",
+					"cluster_name":    "$_id.cluster_name",
+					"action":          "$_id.action",
+					"status":          "$_id.status",
+					"count":           1,
+					"avg_duration_ms": 1,
+					"max_duration_ms": 1,
+				},
+			},
+			bson.M{
+				"$sort": bson.M{"avg_duration_ms": -1},
+			},
+		}).
+		Decode(&res)
+	if err != nil {
+		return nil, errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return res, nil
+}
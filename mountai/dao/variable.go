@@ -114,3 +114,46 @@ func (d *Dao) FindSingleVariable(ctx context.Context, filter bson.M) (*entity.Va
 
 	return variable, nil
 }
+
+func (d *Dao) CreateVariableAudits(ctx context.Context, audits []*entity.VariableAudit) error {
+	if len(audits) == 0 {
+		return nil
+	}
+
+	docs := make([]interface{}, len(audits))
+	for i := range audits {
+		docs[i] = audits[i]
+	}
+
+	_, err := d.Mongo.Collection(new(entity.VariableAudit).TableName()).
+		InsertMany(ctx, docs)
+	if err != nil {
+		return errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return nil
+}
+
+func (d *Dao) FindVariableAudits(ctx context.Context, filter bson.M,
+	opts ...*options.FindOptions) ([]*entity.VariableAudit, error) {
+	audits := make([]*entity.VariableAudit, 0)
+
+	err := d.Mongo.ReadOnlyCollection(new(entity.VariableAudit).TableName()).
+		Find(ctx, filter, opts...).
+		Decode(&audits)
+	if err != nil {
+		return nil, errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return audits, nil
+}
+
+func (d *Dao) CountVariableAudits(ctx context.Context, filter bson.M) (int, error) {
+	res, err := d.Mongo.ReadOnlyCollection(new(entity.VariableAudit).TableName()).
+		CountDocuments(ctx, filter)
+	if err != nil {
+		return 0, errors.Wrapf(errcode.MongoError, "%s", err)
+	}
+
+	return int(res), nil
+}
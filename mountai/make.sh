@@ -30,6 +30,8 @@ function make() {
 
   go build -v -o target/http cmd/http/http.go
   go build -v -o target/status_worker cmd/status_worker/status_worker.go
+  go build -v -o target/pipeline_worker cmd/pipeline_worker/pipeline_worker.go
+  go build -v -o target/multi_cluster_deploy_worker cmd/multi_cluster_deploy_worker/multi_cluster_deploy_worker.go
   go build -v -o target/app_op_consumer cmd/app_op_consumer/app_op_consumer.go
 }
 
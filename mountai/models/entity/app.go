@@ -19,6 +19,10 @@ const (
 	AppTypeCronJob AppType = "CronJob"
 	// 一次性任务类型
 	AppTypeOneTimeJob AppType = "OneTimeJob"
+	// 有状态服务类型
+	AppTypeStatefulSet AppType = "StatefulSet"
+	// 守护进程类型, 每个节点运行一个实例
+	AppTypeDaemonSet AppType = "DaemonSet"
 )
 
 // AppServiceType 应用的服务类型
@@ -178,6 +182,8 @@ type AppEnv struct {
 	EnableBranchChangeNotification bool `bson:"enable_branch_change_notification" json:"enable_branch_change_notification"`
 	// 是否支持热部署
 	EnableHotReload bool `bson:"enable_hot_reload" json:"enable_hot_reload"`
+	// 全量部署失败时自动回滚至上一个成功版本
+	EnableAutoRollback bool `bson:"enable_auto_rollback" json:"enable_auto_rollback"`
 }
 
 // ServiceLoadBalancer Restful 应用，如果通过 LB 方式暴露服务
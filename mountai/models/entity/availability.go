@@ -0,0 +1,85 @@
+package entity
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	// TopologyKeyZone 可用区拓扑键
+	TopologyKeyZone = "topology.kubernetes.io/zone"
+	// TopologyKeyHostname 节点拓扑键
+	TopologyKeyHostname = "kubernetes.io/hostname"
+
+	// DefaultPDBMaxUnavailable PodDisruptionBudget 默认最大不可用 pod 数量
+	DefaultPDBMaxUnavailable = 1
+	// DefaultPDBMinReplicas 创建 PodDisruptionBudget 的默认最小实例数
+	DefaultPDBMinReplicas = 2
+)
+
+// TopologySpreadConstraintTemplate 拓扑分布约束渲染模版
+type TopologySpreadConstraintTemplate struct {
+	// 拓扑键
+	TopologyKey string
+	// 最大偏差
+	MaxSkew int32
+	// 无法满足时的策略
+	WhenUnsatisfiable v1.UnsatisfiableConstraintAction
+}
+
+// Validate 校验拓扑分布约束
+func (t *TopologySpreadConstraintTemplate) Validate() error {
+	if t.TopologyKey == "" {
+		return errors.New("topology key is empty")
+	}
+	if t.MaxSkew <= 0 {
+		return errors.Errorf("max skew of topology key %s should be positive", t.TopologyKey)
+	}
+
+	switch t.WhenUnsatisfiable {
+	case v1.DoNotSchedule, v1.ScheduleAnyway:
+	default:
+		return errors.Errorf("when unsatisfiable %s of topology key %s is not supported",
+			t.WhenUnsatisfiable, t.TopologyKey)
+	}
+
+	return nil
+}
+
+// IsPDBSupportedAppType 应用类型是否支持 PodDisruptionBudget, 目前只有 Deployment 类应用需要
+func IsPDBSupportedAppType(appType AppType) bool {
+	return appType == AppTypeService || appType == AppTypeWorker
+}
+
+// ParsePDBMaxUnavailable 解析最大不可用 pod 数量或比例
+// 为空时使用默认值, 数量需小于实例数, 比例需在 1%-99% 之间, 否则节点驱逐时将同时停止所有实例
+func ParsePDBMaxUnavailable(value string, replicas int) (intstr.IntOrString, error) {
+	if value == "" {
+		value = strconv.Itoa(DefaultPDBMaxUnavailable)
+	}
+
+	if strings.HasSuffix(value, "%") {
+		percent, err := strconv.Atoi(strings.TrimSuffix(value, "%"))
+		if err != nil {
+			return intstr.IntOrString{}, errors.Errorf("max unavailable %s is invalid", value)
+		}
+		if percent <= 0 || percent >= 100 {
+			return intstr.IntOrString{}, errors.Errorf("max unavailable %s should be between 1%% and 99%%", value)
+		}
+		return intstr.FromString(value), nil
+	}
+
+	count, err := strconv.Atoi(value)
+	if err != nil {
+		return intstr.IntOrString{}, errors.Errorf("max unavailable %s is invalid", value)
+	}
+	if count <= 0 || count >= replicas {
+		return intstr.IntOrString{}, errors.Errorf("max unavailable %s should be between 1 and %d", value, replicas-1)
+	}
+
+	return intstr.FromInt(count), nil
+}
@@ -0,0 +1,63 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestEntity_ParsePDBMaxUnavailable(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		res, err := ParsePDBMaxUnavailable("", 3)
+		assert.Nil(t, err)
+		assert.Equal(t, intstr.FromInt(DefaultPDBMaxUnavailable), res)
+	})
+
+	t.Run("count", func(t *testing.T) {
+		res, err := ParsePDBMaxUnavailable("2", 3)
+		assert.Nil(t, err)
+		assert.Equal(t, intstr.FromInt(2), res)
+
+		// 不能驱逐全部实例
+		_, err = ParsePDBMaxUnavailable("3", 3)
+		assert.NotNil(t, err)
+
+		_, err = ParsePDBMaxUnavailable("0", 3)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("percent", func(t *testing.T) {
+		res, err := ParsePDBMaxUnavailable("25%", 4)
+		assert.Nil(t, err)
+		assert.Equal(t, intstr.FromString("25%"), res)
+
+		_, err = ParsePDBMaxUnavailable("100%", 4)
+		assert.NotNil(t, err)
+
+		_, err = ParsePDBMaxUnavailable("a%", 4)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestEntity_TopologySpreadConstraintTemplate_Validate(t *testing.T) {
+	tpl := &TopologySpreadConstraintTemplate{
+		TopologyKey:       TopologyKeyZone,
+		MaxSkew:           1,
+		WhenUnsatisfiable: v1.ScheduleAnyway,
+	}
+	assert.Nil(t, tpl.Validate())
+
+	tpl.MaxSkew = 0
+	assert.NotNil(t, tpl.Validate())
+
+	tpl.MaxSkew, tpl.WhenUnsatisfiable = 1, "Never"
+	assert.NotNil(t, tpl.Validate())
+}
+
+func TestEntity_IsPDBSupportedAppType(t *testing.T) {
+	assert.True(t, IsPDBSupportedAppType(AppTypeService))
+	assert.True(t, IsPDBSupportedAppType(AppTypeWorker))
+	assert.False(t, IsPDBSupportedAppType(AppTypeCronJob))
+}
@@ -0,0 +1,41 @@
+package entity
+
+import (
+	"time"
+)
+
+const (
+	// TaskBlueVersionSuffix 蓝绿部署蓝色版本后缀
+	TaskBlueVersionSuffix = "-blue"
+	// TaskGreenVersionSuffix 蓝绿部署绿色版本后缀
+	TaskGreenVersionSuffix = "-green"
+
+	// BlueGreenDefaultKeepWarmSeconds 切换后旧版本默认保留时长
+	BlueGreenDefaultKeepWarmSeconds = 1800
+	// BlueGreenMaxKeepWarmSeconds 切换后旧版本最长保留时长
+	BlueGreenMaxKeepWarmSeconds = 86400
+	// BlueGreenIdleCleanLookback 清理闲置版本时回溯的任务时间范围, 需大于最长保留时长
+	BlueGreenIdleCleanLookback = time.Hour * 48
+
+	// K8sLabelVersion 部署版本的label键值, 蓝绿部署通过该label切换 Service 流量
+	K8sLabelVersion = "version"
+)
+
+// GetBlueGreenTargetVersion 根据当前运行版本获取蓝绿部署的目标版本
+// 当前为蓝色时部署绿色, 其余情况(包括全量部署的版本)部署蓝色
+func GetBlueGreenTargetVersion(baseVersion, activeVersion string) string {
+	if activeVersion == baseVersion+TaskBlueVersionSuffix {
+		return baseVersion + TaskGreenVersionSuffix
+	}
+
+	return baseVersion + TaskBlueVersionSuffix
+}
+
+// GetBlueGreenKeepWarmDuration 获取切换后旧版本保留时长, 未配置时使用默认值
+func GetBlueGreenKeepWarmDuration(keepWarmSeconds int) time.Duration {
+	if keepWarmSeconds <= 0 {
+		return time.Duration(BlueGreenDefaultKeepWarmSeconds) * time.Second
+	}
+
+	return time.Duration(keepWarmSeconds) * time.Second
+}
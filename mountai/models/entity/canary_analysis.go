@@ -0,0 +1,84 @@
+package entity
+
+import (
+	"time"
+)
+
+const (
+	// ProgressiveCanaryMaxSteps 渐进式金丝雀最大步数
+	ProgressiveCanaryMaxSteps = 10
+	// ProgressiveCanaryMinBakeSeconds 每一步最短观察时长
+	ProgressiveCanaryMinBakeSeconds = 30
+	// ProgressiveCanaryMaxBakeSeconds 每一步最长观察时长, 需小于任务阶段超时时间
+	ProgressiveCanaryMaxBakeSeconds = int(TaskExecuteTimeout/time.Second) - 60
+	// ProgressiveCanaryDefaultStepPodCount 每一步默认增加的金丝雀实例数
+	ProgressiveCanaryDefaultStepPodCount = 1
+)
+
+// ProgressiveCanaryConfig 渐进式金丝雀配置
+type ProgressiveCanaryConfig struct {
+	// 步数
+	Steps int `bson:"steps" json:"steps"`
+	// 每一步增加的金丝雀实例数
+	StepPodCount int `bson:"step_pod_count" json:"step_pod_count"`
+	// 每一步的观察时长 单位秒
+	BakeSeconds int `bson:"bake_seconds" json:"bake_seconds"`
+	// 错误率阈值, 0表示不校验
+	MaxErrorRate float64 `bson:"max_error_rate" json:"max_error_rate"`
+	// p99延迟阈值 单位毫秒, 0表示不校验
+	MaxLatencyMs float64 `bson:"max_latency_ms" json:"max_latency_ms"`
+}
+
+// GetStepPodCount 获取指定步骤的金丝雀实例数
+func (c *ProgressiveCanaryConfig) GetStepPodCount(step int) int {
+	stepPodCount := c.StepPodCount
+	if stepPodCount <= 0 {
+		stepPodCount = ProgressiveCanaryDefaultStepPodCount
+	}
+	return stepPodCount * step
+}
+
+// GetCurrentStep 根据已记录的判定结果获取当前步骤, 从1开始
+func (c *ProgressiveCanaryConfig) GetCurrentStep(verdicts []*CanaryStepVerdict) int {
+	return len(verdicts) + 1
+}
+
+// GetBakeNextStatus 根据当前步骤的判定结果获取观察结束后的任务状态
+// 未通过时清理金丝雀, 最后一步通过时自动全量, 否则扩容至下一步
+func (c *ProgressiveCanaryConfig) GetBakeNextStatus(verdict *CanaryStepVerdict) TaskStatus {
+	if !verdict.Passed {
+		return TaskStatusCanaryAbortUnderway
+	}
+	if verdict.Step >= c.Steps {
+		return TaskStatusCanaryAnalysisFinish
+	}
+	return TaskStatusCanaryStepScaleUnderway
+}
+
+// CanaryStepVerdict 渐进式金丝雀单步判定结果
+type CanaryStepVerdict struct {
+	// 步骤, 从1开始
+	Step int `bson:"step" json:"step"`
+	// 金丝雀实例数
+	PodCount int `bson:"pod_count" json:"pod_count"`
+	// 错误率
+	ErrorRate float64 `bson:"error_rate" json:"error_rate"`
+	// p99延迟 单位毫秒
+	LatencyMs float64 `bson:"latency_ms" json:"latency_ms"`
+	// 是否通过
+	Passed bool `bson:"passed" json:"passed"`
+	// 判定原因
+	Reason string `bson:"reason" json:"reason"`
+	// 判定时间
+	CheckTime *time.Time `bson:"check_time" json:"check_time"`
+}
+
+// CanaryMetricTemplate 金丝雀指标查询模版
+type CanaryMetricTemplate struct {
+	// 命名空间
+	Namespace string
+	// 金丝雀部署名
+	DeploymentName string
+	// 统计时间
+	CountTime string
+}
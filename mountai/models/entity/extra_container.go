@@ -0,0 +1,173 @@
+package entity
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// MaxExtraContainers 初始化容器及边车容器各自的最大数量
+const MaxExtraContainers = 5
+
+// ExtraContainerType 附加容器类型
+type ExtraContainerType string
+
+const (
+	// ExtraContainerTypeMain 应用主容器
+	ExtraContainerTypeMain ExtraContainerType = "main"
+	// ExtraContainerTypeInit 初始化容器, 在主容器启动前依次执行完成, 如数据库迁移, 预热
+	ExtraContainerTypeInit ExtraContainerType = "init"
+	// ExtraContainerTypeSidecar 边车容器, 与主容器一同运行, 如日志采集, 本地代理
+	ExtraContainerTypeSidecar ExtraContainerType = "sidecar"
+)
+
+// ExtraContainer 除应用主容器外的附加容器
+type ExtraContainer struct {
+	// 容器名, 同一 pod 内唯一
+	Name string `bson:"name" json:"name"`
+	// 镜像
+	Image string `bson:"image" json:"image"`
+	// 运行指令, 为空时使用镜像的 entrypoint
+	Command string `bson:"command" json:"command"`
+	// 环境变量
+	Vars map[string]string `bson:"vars" json:"vars"`
+
+	CPURequest CPUResourceType `bson:"cpu_request" json:"cpu_request"`
+	CPULimit   CPUResourceType `bson:"cpu_limit" json:"cpu_limit"`
+	MemRequest MemResourceType `bson:"mem_request" json:"mem_request"`
+	MemLimit   MemResourceType `bson:"mem_limit" json:"mem_limit"`
+}
+
+// Validate 校验附加容器配置, 资源规格需在项目可用规格内
+func (c *ExtraContainer) Validate(spec *ProjectResourceSpec) error {
+	if errs := validation.IsDNS1123Label(c.Name); len(errs) > 0 {
+		return errors.Errorf("container name %s is invalid: %s", c.Name, strings.Join(errs, ","))
+	}
+	if c.Image == "" {
+		return errors.Errorf("image of container %s is empty", c.Name)
+	}
+	if c.CPURequest == "" || c.CPULimit == "" {
+		return errors.Errorf("cpu resource of container %s is empty", c.Name)
+	}
+	if c.MemRequest == "" || c.MemLimit == "" {
+		return errors.Errorf("memory resource of container %s is empty", c.Name)
+	}
+
+	if spec != nil {
+		if !containsCPUResource(spec.CPURequestList, c.CPURequest) || !containsCPUResource(spec.CPULimitList, c.CPULimit) {
+			return errors.Errorf("cpu resource of container %s is not in project resource spec", c.Name)
+		}
+		if !containsMemResource(spec.MemRequestList, c.MemRequest) || !containsMemResource(spec.MemLimitList, c.MemLimit) {
+			return errors.Errorf("memory resource of container %s is not in project resource spec", c.Name)
+		}
+	}
+
+	cpuRequest, err := c.CPURequest.Cores()
+	if err != nil {
+		return err
+	}
+	cpuLimit, err := c.CPULimit.Cores()
+	if err != nil {
+		return err
+	}
+	if cpuRequest > cpuLimit {
+		return errors.Errorf("cpu request of container %s is greater than limit", c.Name)
+	}
+
+	memRequest, err := c.MemRequest.Bytes()
+	if err != nil {
+		return err
+	}
+	memLimit, err := c.MemLimit.Bytes()
+	if err != nil {
+		return err
+	}
+	if memRequest > memLimit {
+		return errors.Errorf("memory request of container %s is greater than limit", c.Name)
+	}
+
+	return nil
+}
+
+// AppTypeSupportsSidecars 定时任务及一次性任务的 pod 需所有容器退出才能结束, 不支持常驻的边车容器
+func AppTypeSupportsSidecars(appType AppType) bool {
+	return appType == AppTypeService || appType == AppTypeWorker
+}
+
+// ValidateExtraContainers 校验初始化容器及边车容器, 容器名不能与主容器及彼此重复
+func ValidateExtraContainers(mainContainerName string, initContainers, sidecars []*ExtraContainer,
+	spec *ProjectResourceSpec) error {
+	if len(initContainers) > MaxExtraContainers || len(sidecars) > MaxExtraContainers {
+		return errors.Errorf("init containers or sidecars should not be more than %d", MaxExtraContainers)
+	}
+
+	names := map[string]bool{mainContainerName: true}
+	for _, containers := range [][]*ExtraContainer{initContainers, sidecars} {
+		for _, c := range containers {
+			if c == nil {
+				return errors.New("container is empty")
+			}
+			if names[c.Name] {
+				return errors.Errorf("container name %s is duplicated", c.Name)
+			}
+			names[c.Name] = true
+
+			if err := c.Validate(spec); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func containsCPUResource(list []CPUResourceType, target CPUResourceType) bool {
+	for _, item := range list {
+		if item == target {
+			return true
+		}
+	}
+	return false
+}
+
+func containsMemResource(list []MemResourceType, target MemResourceType) bool {
+	for _, item := range list {
+		if item == target {
+			return true
+		}
+	}
+	return false
+}
+
+// ExtraContainerTemplate 附加容器渲染模版
+type ExtraContainerTemplate struct {
+	// 容器名
+	Name string
+	// 镜像名
+	ImageName
+	// 运行指令
+	Command string
+	// 环境变量
+	Env []*EnvTemplate
+	// 存储卷挂载
+	VolumeMounts []*VolumeMountTemplate
+
+	// 最大CPU
+	CPULimit CPUResourceType
+	// 最大内存
+	MemoryLimit MemResourceType
+	// 请求CPU
+	CPURequest CPUResourceType
+	// 请求内存
+	MemoryRequest MemResourceType
+}
+
+// unifyExtraContainerImageNames 统一附加容器的镜像仓库地址
+func unifyExtraContainerImageNames(imageRegistryHostWithNamespace string, containers ...[]*ExtraContainerTemplate) {
+	for _, list := range containers {
+		for _, c := range list {
+			c.UnifyImageName(imageRegistryHostWithNamespace)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestExtraContainer(name string) *ExtraContainer {
+	return &ExtraContainer{
+		Name:       name,
+		Image:      "busybox:1.36",
+		CPURequest: CPUResourceNano,
+		CPULimit:   CPUResourceLarge,
+		MemRequest: MemResourceNano,
+		MemLimit:   MemResourceLarge,
+	}
+}
+
+func TestEntity_ExtraContainer_Validate(t *testing.T) {
+	spec := &ProjectResourceSpec{
+		CPURequestList: CPUStgRequestResourceList,
+		CPULimitList:   CPUStgLimitResourceList,
+		MemRequestList: MemStgRequestResourceList,
+		MemLimitList:   MemStgLimitResourceList,
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		assert.Nil(t, newTestExtraContainer("migrate").Validate(spec))
+	})
+
+	t.Run("invalid name", func(t *testing.T) {
+		assert.NotNil(t, newTestExtraContainer("Log_Shipper").Validate(spec))
+	})
+
+	t.Run("empty image", func(t *testing.T) {
+		c := newTestExtraContainer("proxy")
+		c.Image = ""
+		assert.NotNil(t, c.Validate(spec))
+	})
+
+	t.Run("not in spec", func(t *testing.T) {
+		c := newTestExtraContainer("proxy")
+		c.CPURequest = CPUResourceSmall
+		assert.NotNil(t, c.Validate(spec))
+	})
+
+	t.Run("request greater than limit", func(t *testing.T) {
+		c := newTestExtraContainer("proxy")
+		c.MemRequest, c.MemLimit = MemResourceLarge, MemResourceTiny
+		assert.NotNil(t, c.Validate(nil))
+	})
+}
+
+func TestEntity_ValidateExtraContainers(t *testing.T) {
+	initContainers := []*ExtraContainer{newTestExtraContainer("migrate")}
+	sidecars := []*ExtraContainer{newTestExtraContainer("proxy")}
+	assert.Nil(t, ValidateExtraContainers("demo-app", initContainers, sidecars, nil))
+
+	// 与主容器重名
+	assert.NotNil(t, ValidateExtraContainers("proxy", initContainers, sidecars, nil))
+
+	// 初始化容器与边车容器重名
+	assert.NotNil(t, ValidateExtraContainers("demo-app", initContainers,
+		[]*ExtraContainer{newTestExtraContainer("migrate")}, nil))
+
+	tooMany := make([]*ExtraContainer, MaxExtraContainers+1)
+	for i := range tooMany {
+		tooMany[i] = newTestExtraContainer(string(rune('a' + i)))
+	}
+	assert.NotNil(t, ValidateExtraContainers("demo-app", nil, tooMany, nil))
+}
+
+func TestEntity_AppTypeSupportsSidecars(t *testing.T) {
+	assert.True(t, AppTypeSupportsSidecars(AppTypeService))
+	assert.True(t, AppTypeSupportsSidecars(AppTypeWorker))
+	assert.False(t, AppTypeSupportsSidecars(AppTypeCronJob))
+	assert.False(t, AppTypeSupportsSidecars(AppTypeOneTimeJob))
+}
+
+func TestEntity_DeploymentTemplate_UnifyImageName(t *testing.T) {
+	tpl := &DeploymentTemplate{
+		ImageName: "crpi-592g7buyguepbrqd-vpc.cn-shanghai.personal.cr.aliyuncs.com/infra/app:v1",
+		Sidecars: []*ExtraContainerTemplate{
+			{ImageName: "crpi-592g7buyguepbrqd-vpc.cn-shanghai.personal.cr.aliyuncs.com/infra/proxy:v1"},
+		},
+	}
+	tpl.UnifyImageName("swr.cn-east-3.myhuaweicloud.com/infra")
+
+	assert.Equal(t, ImageName("swr.cn-east-3.myhuaweicloud.com/infra/app:v1"), tpl.ImageName)
+	assert.Equal(t, ImageName("swr.cn-east-3.myhuaweicloud.com/infra/proxy:v1"), tpl.Sidecars[0].ImageName)
+}
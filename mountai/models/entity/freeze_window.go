@@ -0,0 +1,158 @@
+package entity
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"time"
+)
+
+const (
+	FreezeOverrideTmpName = "freezeOverrideMsg"
+	FreezeOverrideMsg     = `
+### {{.Title}}
+>- [项目名] {{.ProjectName}}
+>- [应用名] {{.AppName}}
+>- [环境名] {{.Env}}
+>- [操作人] {{.UserName}}
+>- [操作] {{.Action}}
+>- [时间] {{.OpTime}}
+#### [进入AMS, 查看详情]({{.DetailURL}})
+`
+)
+
+// TaskActionFreezeList 封网期间受限制的任务类型, 回滚/停止/重启等止损操作不受限制
+var TaskActionFreezeList = &TaskActionList{
+	TaskActionFullDeploy,
+	TaskActionCanaryDeploy,
+	TaskActionFullCanaryDeploy,
+	TaskActionBlueGreenDeploy,
+	TaskActionUpdateHPA,
+	TaskActionReloadConfig,
+	TaskActionEnableInClusterDNS,
+	TaskActionDisableInClusterDNS,
+	TaskActionDelete,
+}
+
+// FreezeWindowScope 封网范围, 各字段为空表示不限制, 多个字段同时设置时需全部满足
+type FreezeWindowScope struct {
+	EnvNames   []AppEnvName `bson:"env_names" json:"env_names"`
+	TeamIDs    []string     `bson:"team_ids" json:"team_ids"`
+	ProjectIDs []string     `bson:"project_ids" json:"project_ids"`
+	// 项目标签, 项目包含任一标签即满足
+	Labels []string `bson:"labels" json:"labels"`
+}
+
+// 封网窗口
+type FreezeWindow struct {
+	ID          primitive.ObjectID `bson:"_id" json:"_id"`
+	Name        string             `bson:"name" json:"name"`
+	Description string             `bson:"description" json:"description"`
+	// 封网开始及结束时间
+	StartTime *time.Time         `bson:"start_time" json:"start_time"`
+	EndTime   *time.Time         `bson:"end_time" json:"end_time"`
+	Scope     *FreezeWindowScope `bson:"scope" json:"scope"`
+	// 创建人id
+	OperatorID string `bson:"operator_id" json:"operator_id"`
+
+	CreateTime *time.Time `bson:"create_time" json:"create_time"`
+	UpdateTime *time.Time `bson:"update_time" json:"update_time"`
+	// 软删除
+	DeleteTime *time.Time `bson:"delete_time" json:"delete_time"`
+}
+
+func (*FreezeWindow) TableName() string {
+	return "freeze_window"
+}
+
+func (w *FreezeWindow) GenerateObjectIDString(args map[string]interface{}) string {
+	return w.ID.Hex()
+}
+
+// Covers 判断时间是否处于封网窗口内
+func (w *FreezeWindow) Covers(t time.Time) bool {
+	if w.StartTime == nil || w.EndTime == nil {
+		return false
+	}
+
+	return !t.Before(*w.StartTime) && t.Before(*w.EndTime)
+}
+
+// Matches 判断项目是否处于封网范围内
+func (w *FreezeWindow) Matches(envName AppEnvName, teamID, projectID string, labels []string) bool {
+	if w.Scope == nil {
+		return true
+	}
+
+	if len(w.Scope.EnvNames) > 0 && !containsEnvName(w.Scope.EnvNames, envName) {
+		return false
+	}
+
+	if len(w.Scope.TeamIDs) > 0 && !containsString(w.Scope.TeamIDs, teamID) {
+		return false
+	}
+
+	if len(w.Scope.ProjectIDs) > 0 && !containsString(w.Scope.ProjectIDs, projectID) {
+		return false
+	}
+
+	if len(w.Scope.Labels) > 0 {
+		for _, label := range labels {
+			if containsString(w.Scope.Labels, label) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return true
+}
+
+// RequiresSRE 未限定项目的全局窗口或包含生产环境的窗口需由 SRE 创建, 其余由项目负责人创建
+func (s *FreezeWindowScope) RequiresSRE() bool {
+	if s == nil || len(s.ProjectIDs) == 0 {
+		return true
+	}
+
+	return len(s.EnvNames) == 0 || containsEnvName(s.EnvNames, AppEnvPrd)
+}
+
+func containsEnvName(list []AppEnvName, envName AppEnvName) bool {
+	for _, item := range list {
+		if item == envName {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// 封网期间强制变更记录
+type FreezeOverrideRecord struct {
+	ID             primitive.ObjectID `bson:"_id" json:"_id"`
+	FreezeWindowID string             `bson:"freeze_window_id" json:"freeze_window_id"`
+	TaskID         string             `bson:"task_id" json:"task_id"`
+	Env            AppEnvName         `bson:"env" json:"env"`
+	ProjectID      string             `bson:"project_id" json:"project_id"`
+	AppID          string             `bson:"app_id" json:"app_id"`
+	TaskActionType string             `bson:"task_action_type" json:"task_action_type"`
+	Reason         string             `bson:"reason" json:"reason"`
+	Operator       string             `bson:"operator" json:"operator"`
+	CreateTime     *time.Time         `bson:"create_time" json:"create_time"`
+	UpdateTime     *time.Time         `bson:"update_time" json:"update_time"`
+}
+
+func (*FreezeOverrideRecord) TableName() string {
+	return "freeze_override_record"
+}
+
+func (r *FreezeOverrideRecord) GenerateObjectIDString(args map[string]interface{}) string {
+	return r.ID.Hex()
+}
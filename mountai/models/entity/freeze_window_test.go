@@ -0,0 +1,58 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEntity_FreezeWindow(t *testing.T) {
+	start := time.Date(2024, 10, 1, 0, 0, 0, 0, time.Local)
+	end := start.Add(time.Hour * 24 * 7)
+	window := &FreezeWindow{StartTime: &start, EndTime: &end}
+
+	t.Run("covers", func(t *testing.T) {
+		assert.True(t, window.Covers(start))
+		assert.True(t, window.Covers(start.Add(time.Hour)))
+		assert.False(t, window.Covers(end))
+		assert.False(t, window.Covers(start.Add(-time.Second)))
+	})
+
+	t.Run("no scope", func(t *testing.T) {
+		assert.True(t, window.Matches(AppEnvPrd, "team", "project", nil))
+	})
+
+	t.Run("scope", func(t *testing.T) {
+		window.Scope = &FreezeWindowScope{
+			EnvNames: []AppEnvName{AppEnvPrd},
+			Labels:   []string{string(ProjectLabelP0)},
+		}
+
+		assert.True(t, window.Matches(AppEnvPrd, "team", "project", []string{"online", "P0"}))
+		assert.False(t, window.Matches(AppEnvPrd, "team", "project", []string{"P1"}))
+		assert.False(t, window.Matches(AppEnvStg, "team", "project", []string{"P0"}))
+
+		window.Scope = &FreezeWindowScope{TeamIDs: []string{"team"}}
+		assert.True(t, window.Matches(AppEnvStg, "team", "project", nil))
+		assert.False(t, window.Matches(AppEnvStg, "other", "project", nil))
+	})
+
+	t.Run("requires sre", func(t *testing.T) {
+		var scope *FreezeWindowScope
+		assert.True(t, scope.RequiresSRE())
+
+		// 未限定项目
+		scope = &FreezeWindowScope{EnvNames: []AppEnvName{AppEnvStg}, TeamIDs: []string{"team"}}
+		assert.True(t, scope.RequiresSRE())
+
+		// 项目范围但包含生产环境
+		scope = &FreezeWindowScope{ProjectIDs: []string{"project"}}
+		assert.True(t, scope.RequiresSRE())
+		scope.EnvNames = []AppEnvName{AppEnvStg, AppEnvPrd}
+		assert.True(t, scope.RequiresSRE())
+
+		scope.EnvNames = []AppEnvName{AppEnvStg, AppEnvPre}
+		assert.False(t, scope.RequiresSRE())
+	})
+}
@@ -0,0 +1,132 @@
+package entity
+
+import "strings"
+
+const (
+	// HPAMaxCustomMetrics 自定义扩缩容指标的最大数量
+	HPAMaxCustomMetrics = 5
+	// HPAMaxStabilizationWindowSeconds 扩缩容稳定窗口最大时长, 与 k8s 限制一致
+	HPAMaxStabilizationWindowSeconds = 3600
+	// HPAMaxScalingPolicyPeriodSeconds 扩缩容策略周期最大时长, 与 k8s 限制一致
+	HPAMaxScalingPolicyPeriodSeconds = 1800
+)
+
+// HPAMetricSourceType 自定义扩缩容指标来源类型
+type HPAMetricSourceType string
+
+const (
+	// HPAMetricSourceTypePods 按 pod 平均的自定义指标, 如 QPS
+	HPAMetricSourceTypePods HPAMetricSourceType = "Pods"
+	// HPAMetricSourceTypeObject 描述单个 k8s 对象的指标, 如 Ingress 的请求数
+	HPAMetricSourceTypeObject HPAMetricSourceType = "Object"
+	// HPAMetricSourceTypeExternal 集群外部指标, 如 kafka 消费堆积
+	HPAMetricSourceTypeExternal HPAMetricSourceType = "External"
+)
+
+// HPAMetricTargetType 扩缩容指标目标值类型
+type HPAMetricTargetType string
+
+const (
+	// HPAMetricTargetTypeValue 指标总值
+	HPAMetricTargetTypeValue HPAMetricTargetType = "Value"
+	// HPAMetricTargetTypeAverageValue 指标按 pod 平均值
+	HPAMetricTargetTypeAverageValue HPAMetricTargetType = "AverageValue"
+)
+
+// HPAMetricSourceTargetTypes 各指标来源支持的目标值类型
+var HPAMetricSourceTargetTypes = map[HPAMetricSourceType][]HPAMetricTargetType{
+	HPAMetricSourceTypePods:     {HPAMetricTargetTypeAverageValue},
+	HPAMetricSourceTypeObject:   {HPAMetricTargetTypeValue, HPAMetricTargetTypeAverageValue},
+	HPAMetricSourceTypeExternal: {HPAMetricTargetTypeValue, HPAMetricTargetTypeAverageValue},
+}
+
+// HPAMetricSpec 自定义扩缩容指标
+type HPAMetricSpec struct {
+	// 指标来源类型
+	Type HPAMetricSourceType `bson:"type" json:"type"`
+	// 指标名
+	MetricName string `bson:"metric_name" json:"metric_name"`
+	// 指标标签筛选
+	MetricSelector map[string]string `bson:"metric_selector" json:"metric_selector"`
+	// 指标描述的对象, 仅 Object 类型需要
+	DescribedObject *HPAMetricObjectReference `bson:"described_object" json:"described_object"`
+	// 目标值类型
+	TargetType HPAMetricTargetType `bson:"target_type" json:"target_type"`
+	// 目标值, k8s quantity 格式, 如 "100" "500m"
+	TargetValue string `bson:"target_value" json:"target_value"`
+}
+
+// HPAMetricObjectReference 指标描述的 k8s 对象
+type HPAMetricObjectReference struct {
+	APIVersion string `bson:"api_version" json:"api_version"`
+	Kind       string `bson:"kind" json:"kind"`
+	Name       string `bson:"name" json:"name"`
+}
+
+// SourceKey 指标来源在 HPA spec 中的字段名
+func (m *HPAMetricSpec) SourceKey() string {
+	return strings.ToLower(string(m.Type))
+}
+
+// TargetKey 目标值在 HPA spec 中的字段名
+func (m *HPAMetricSpec) TargetKey() string {
+	if m.TargetType == HPAMetricTargetTypeAverageValue {
+		return "averageValue"
+	}
+	return "value"
+}
+
+// SupportsTargetType 指标来源是否支持该目标值类型
+func (m *HPAMetricSpec) SupportsTargetType() bool {
+	for _, t := range HPAMetricSourceTargetTypes[m.Type] {
+		if t == m.TargetType {
+			return true
+		}
+	}
+	return false
+}
+
+// HPAScalingPolicyType 扩缩容策略类型
+type HPAScalingPolicyType string
+
+const (
+	// HPAScalingPolicyTypePods 每个周期变更的实例数
+	HPAScalingPolicyTypePods HPAScalingPolicyType = "Pods"
+	// HPAScalingPolicyTypePercent 每个周期变更的实例百分比
+	HPAScalingPolicyTypePercent HPAScalingPolicyType = "Percent"
+)
+
+// HPAScalingSelectPolicy 多个扩缩容策略的选择方式
+type HPAScalingSelectPolicy string
+
+const (
+	HPAScalingSelectPolicyMax      HPAScalingSelectPolicy = "Max"
+	HPAScalingSelectPolicyMin      HPAScalingSelectPolicy = "Min"
+	HPAScalingSelectPolicyDisabled HPAScalingSelectPolicy = "Disabled"
+)
+
+// HPABehavior 扩缩容行为策略
+type HPABehavior struct {
+	// 扩容策略
+	ScaleUp *HPAScalingRules `bson:"scale_up" json:"scale_up"`
+	// 缩容策略
+	ScaleDown *HPAScalingRules `bson:"scale_down" json:"scale_down"`
+}
+
+// HPAScalingRules 单方向的扩缩容策略
+type HPAScalingRules struct {
+	// 稳定窗口时长 单位秒, 为空时使用 k8s 默认值(扩容0秒, 缩容300秒)
+	StabilizationWindowSeconds *int32 `bson:"stabilization_window_seconds" json:"stabilization_window_seconds"`
+	// 多个策略的选择方式, 为空时为 Max
+	SelectPolicy HPAScalingSelectPolicy `bson:"select_policy" json:"select_policy"`
+	// 策略列表
+	Policies []*HPAScalingPolicy `bson:"policies" json:"policies"`
+}
+
+// HPAScalingPolicy 扩缩容策略
+type HPAScalingPolicy struct {
+	Type  HPAScalingPolicyType `bson:"type" json:"type"`
+	Value int32                `bson:"value" json:"value"`
+	// 策略周期 单位秒
+	PeriodSeconds int32 `bson:"period_seconds" json:"period_seconds"`
+}
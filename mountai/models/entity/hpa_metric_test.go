@@ -0,0 +1,31 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEntity_HPAMetricSpec(t *testing.T) {
+	t.Run("pods", func(t *testing.T) {
+		m := &HPAMetricSpec{Type: HPAMetricSourceTypePods, TargetType: HPAMetricTargetTypeAverageValue}
+		assert.Equal(t, "pods", m.SourceKey())
+		assert.Equal(t, "averageValue", m.TargetKey())
+		assert.True(t, m.SupportsTargetType())
+
+		m.TargetType = HPAMetricTargetTypeValue
+		assert.False(t, m.SupportsTargetType())
+	})
+
+	t.Run("external", func(t *testing.T) {
+		m := &HPAMetricSpec{Type: HPAMetricSourceTypeExternal, TargetType: HPAMetricTargetTypeValue}
+		assert.Equal(t, "external", m.SourceKey())
+		assert.Equal(t, "value", m.TargetKey())
+		assert.True(t, m.SupportsTargetType())
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		m := &HPAMetricSpec{Type: "Resource", TargetType: HPAMetricTargetTypeValue}
+		assert.False(t, m.SupportsTargetType())
+	})
+}
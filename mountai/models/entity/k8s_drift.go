@@ -0,0 +1,338 @@
+package entity
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	v2 "k8s.io/api/autoscaling/v2"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+)
+
+const (
+	// K8sDriftDefaultLookback 默认回溯部署任务的时间范围
+	K8sDriftDefaultLookback = 30 * 24 * time.Hour
+	// K8sDriftMissingValue 对象或字段不存在时的展示值
+	K8sDriftMissingValue = "<none>"
+)
+
+// K8sDriftStatus 配置漂移状态
+type K8sDriftStatus string
+
+const (
+	// K8sDriftStatusDrifted 线上对象与最近部署的期望状态不一致
+	K8sDriftStatusDrifted K8sDriftStatus = "drifted"
+	// K8sDriftStatusHealed 已自动重新应用期望状态
+	K8sDriftStatusHealed K8sDriftStatus = "healed"
+	// K8sDriftStatusResolved 线上对象已恢复一致
+	K8sDriftStatusResolved K8sDriftStatus = "resolved"
+)
+
+// K8sDriftItem 单个字段的漂移
+type K8sDriftItem struct {
+	Kind     string `bson:"kind" json:"kind"`
+	Field    string `bson:"field" json:"field"`
+	Expected string `bson:"expected" json:"expected"`
+	Actual   string `bson:"actual" json:"actual"`
+}
+
+// K8sDrift 应用在某环境集群的配置漂移
+// 同一应用环境集群同时只有一条未解决的记录, 漂移内容变化时更新记录并重新通知
+type K8sDrift struct {
+	ID          primitive.ObjectID `bson:"_id" json:"_id"`
+	ProjectID   string             `bson:"project_id" json:"project_id"`
+	AppID       string             `bson:"app_id" json:"app_id"`
+	EnvName     AppEnvName         `bson:"env_name" json:"env_name"`
+	ClusterName ClusterName        `bson:"cluster_name" json:"cluster_name"`
+	Namespace   string             `bson:"namespace" json:"namespace"`
+	// 期望状态来源的部署任务
+	TaskID  string          `bson:"task_id" json:"task_id"`
+	Version string          `bson:"version" json:"version"`
+	Items   []*K8sDriftItem `bson:"items" json:"items"`
+	Status  K8sDriftStatus  `bson:"status" json:"status"`
+	// 自动修复失败原因
+	HealError string `bson:"heal_error" json:"heal_error"`
+	// 最近一次检测到漂移的时间
+	DetectTime *time.Time `bson:"detect_time" json:"detect_time"`
+
+	CreateTime *time.Time `bson:"create_time" json:"create_time"`
+	UpdateTime *time.Time `bson:"update_time" json:"update_time"`
+}
+
+func (*K8sDrift) TableName() string {
+	return "k8s_drift"
+}
+
+func (d *K8sDrift) GenerateObjectIDString(args map[string]interface{}) string {
+	return d.ID.Hex()
+}
+
+// EqualK8sDriftItems 两次检测的漂移内容是否相同
+func EqualK8sDriftItems(a, b []*K8sDriftItem) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if *a[i] != *b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// DiffK8sReplicas 比较副本数, 开启自动扩缩容时副本数由 HPA 管理, 不做比较
+func DiffK8sReplicas(kind string, expected, live *int32) []*K8sDriftItem {
+	if expected == nil || live == nil || *expected == *live {
+		return nil
+	}
+
+	return []*K8sDriftItem{{
+		Kind:     kind,
+		Field:    "spec.replicas",
+		Expected: fmt.Sprint(*expected),
+		Actual:   fmt.Sprint(*live),
+	}}
+}
+
+// DiffK8sPodTemplate 比较 Pod 模板中容器的镜像, 资源及环境变量
+// 仅比较期望状态中声明的内容, 线上额外增加的容器或环境变量同样视为漂移
+func DiffK8sPodTemplate(kind string, expected, live *v1.PodTemplateSpec) []*K8sDriftItem {
+	items := make([]*K8sDriftItem, 0)
+
+	liveContainers := make(map[string]*v1.Container, len(live.Spec.Containers))
+	for i := range live.Spec.Containers {
+		liveContainers[live.Spec.Containers[i].Name] = &live.Spec.Containers[i]
+	}
+
+	for i := range expected.Spec.Containers {
+		e := &expected.Spec.Containers[i]
+		prefix := fmt.Sprintf("spec.template.spec.containers[%s]", e.Name)
+
+		l, ok := liveContainers[e.Name]
+		if !ok {
+			items = append(items, &K8sDriftItem{Kind: kind, Field: prefix, Expected: e.Name, Actual: K8sDriftMissingValue})
+			continue
+		}
+		delete(liveContainers, e.Name)
+
+		if e.Image != l.Image {
+			items = append(items, &K8sDriftItem{Kind: kind, Field: prefix + ".image", Expected: e.Image, Actual: l.Image})
+		}
+
+		items = append(items, diffK8sResourceList(kind, prefix+".resources.requests",
+			e.Resources.Requests, l.Resources.Requests)...)
+		items = append(items, diffK8sResourceList(kind, prefix+".resources.limits",
+			e.Resources.Limits, l.Resources.Limits)...)
+		items = append(items, diffK8sEnvVars(kind, prefix+".env", e.Env, l.Env)...)
+	}
+
+	extra := make([]string, 0, len(liveContainers))
+	for name := range liveContainers {
+		extra = append(extra, name)
+	}
+	sort.Strings(extra)
+	for _, name := range extra {
+		items = append(items, &K8sDriftItem{
+			Kind:     kind,
+			Field:    fmt.Sprintf("spec.template.spec.containers[%s]", name),
+			Expected: K8sDriftMissingValue,
+			Actual:   name,
+		})
+	}
+
+	return items
+}
+
+func diffK8sResourceList(kind, field string, expected, live v1.ResourceList) []*K8sDriftItem {
+	names := make([]string, 0, len(expected)+len(live))
+	for name := range expected {
+		names = append(names, string(name))
+	}
+	for name := range live {
+		if _, ok := expected[name]; !ok {
+			names = append(names, string(name))
+		}
+	}
+	sort.Strings(names)
+
+	items := make([]*K8sDriftItem, 0)
+	for _, name := range names {
+		e, eok := expected[v1.ResourceName(name)]
+		l, lok := live[v1.ResourceName(name)]
+		if eok && lok && e.Cmp(l) == 0 {
+			continue
+		}
+
+		item := &K8sDriftItem{
+			Kind:     kind,
+			Field:    fmt.Sprintf("%s.%s", field, name),
+			Expected: K8sDriftMissingValue,
+			Actual:   K8sDriftMissingValue,
+		}
+		if eok {
+			item.Expected = e.String()
+		}
+		if lok {
+			item.Actual = l.String()
+		}
+		items = append(items, item)
+	}
+
+	return items
+}
+
+func diffK8sEnvVars(kind, field string, expected, live []v1.EnvVar) []*K8sDriftItem {
+	liveEnv := make(map[string]*v1.EnvVar, len(live))
+	for i := range live {
+		liveEnv[live[i].Name] = &live[i]
+	}
+
+	items := make([]*K8sDriftItem, 0)
+	for i := range expected {
+		e := &expected[i]
+		l, ok := liveEnv[e.Name]
+		delete(liveEnv, e.Name)
+
+		switch {
+		case !ok:
+			items = append(items, &K8sDriftItem{
+				Kind: kind, Field: fmt.Sprintf("%s[%s]", field, e.Name), Expected: e.Value, Actual: K8sDriftMissingValue,
+			})
+		case e.ValueFrom != nil || l.ValueFrom != nil:
+			// 引用类环境变量仅比较引用来源, 不展示值
+			if !equality.Semantic.DeepEqual(e.ValueFrom, l.ValueFrom) || e.Value != l.Value {
+				items = append(items, &K8sDriftItem{
+					Kind: kind, Field: fmt.Sprintf("%s[%s].valueFrom", field, e.Name),
+					Expected: describeK8sEnvSource(e), Actual: describeK8sEnvSource(l),
+				})
+			}
+		case e.Value != l.Value:
+			items = append(items, &K8sDriftItem{
+				Kind: kind, Field: fmt.Sprintf("%s[%s]", field, e.Name), Expected: e.Value, Actual: l.Value,
+			})
+		}
+	}
+
+	extra := make([]string, 0, len(liveEnv))
+	for name := range liveEnv {
+		extra = append(extra, name)
+	}
+	sort.Strings(extra)
+	for _, name := range extra {
+		items = append(items, &K8sDriftItem{
+			Kind: kind, Field: fmt.Sprintf("%s[%s]", field, name), Expected: K8sDriftMissingValue,
+			Actual: describeK8sEnvSource(liveEnv[name]),
+		})
+	}
+
+	return items
+}
+
+func describeK8sEnvSource(env *v1.EnvVar) string {
+	if env.ValueFrom == nil {
+		return env.Value
+	}
+
+	switch {
+	case env.ValueFrom.SecretKeyRef != nil:
+		return fmt.Sprintf("secret:%s/%s", env.ValueFrom.SecretKeyRef.Name, env.ValueFrom.SecretKeyRef.Key)
+	case env.ValueFrom.ConfigMapKeyRef != nil:
+		return fmt.Sprintf("configmap:%s/%s", env.ValueFrom.ConfigMapKeyRef.Name, env.ValueFrom.ConfigMapKeyRef.Key)
+	case env.ValueFrom.FieldRef != nil:
+		return fmt.Sprintf("field:%s", env.ValueFrom.FieldRef.FieldPath)
+	case env.ValueFrom.ResourceFieldRef != nil:
+		return fmt.Sprintf("resource:%s", env.ValueFrom.ResourceFieldRef.Resource)
+	default:
+		return "valueFrom"
+	}
+}
+
+// DiffK8sHPA 比较 HPA 的实例数范围及指标, expected 为空表示不应存在 HPA
+func DiffK8sHPA(expected, live *v2.HorizontalPodAutoscaler) []*K8sDriftItem {
+	kind := K8sObjectKindHPA
+	switch {
+	case expected == nil && live == nil:
+		return nil
+	case expected == nil:
+		return []*K8sDriftItem{{Kind: kind, Field: "metadata.name", Expected: K8sDriftMissingValue, Actual: live.Name}}
+	case live == nil:
+		return []*K8sDriftItem{{Kind: kind, Field: "metadata.name", Expected: expected.Name, Actual: K8sDriftMissingValue}}
+	}
+
+	items := make([]*K8sDriftItem, 0)
+	if expected.Spec.MinReplicas != nil && (live.Spec.MinReplicas == nil ||
+		*expected.Spec.MinReplicas != *live.Spec.MinReplicas) {
+		item := &K8sDriftItem{
+			Kind:     kind,
+			Field:    "spec.minReplicas",
+			Expected: fmt.Sprint(*expected.Spec.MinReplicas),
+			Actual:   K8sDriftMissingValue,
+		}
+		if live.Spec.MinReplicas != nil {
+			item.Actual = fmt.Sprint(*live.Spec.MinReplicas)
+		}
+		items = append(items, item)
+	}
+
+	if expected.Spec.MaxReplicas != live.Spec.MaxReplicas {
+		items = append(items, &K8sDriftItem{
+			Kind:     kind,
+			Field:    "spec.maxReplicas",
+			Expected: fmt.Sprint(expected.Spec.MaxReplicas),
+			Actual:   fmt.Sprint(live.Spec.MaxReplicas),
+		})
+	}
+
+	if !equality.Semantic.DeepEqual(expected.Spec.Metrics, live.Spec.Metrics) {
+		items = append(items, &K8sDriftItem{
+			Kind:     kind,
+			Field:    "spec.metrics",
+			Expected: describeK8sHPAMetrics(expected.Spec.Metrics),
+			Actual:   describeK8sHPAMetrics(live.Spec.Metrics),
+		})
+	}
+
+	return items
+}
+
+func describeK8sHPAMetrics(metrics []v2.MetricSpec) string {
+	if len(metrics) == 0 {
+		return K8sDriftMissingValue
+	}
+
+	res := make([]string, len(metrics))
+	for i, metric := range metrics {
+		var name string
+		var target v2.MetricTarget
+		switch {
+		case metric.Resource != nil:
+			name, target = string(metric.Resource.Name), metric.Resource.Target
+		case metric.Pods != nil:
+			name, target = metric.Pods.Metric.Name, metric.Pods.Target
+		case metric.Object != nil:
+			name, target = metric.Object.Metric.Name, metric.Object.Target
+		case metric.External != nil:
+			name, target = metric.External.Metric.Name, metric.External.Target
+		case metric.ContainerResource != nil:
+			name, target = string(metric.ContainerResource.Name), metric.ContainerResource.Target
+		}
+
+		value := string(target.Type)
+		switch {
+		case target.AverageUtilization != nil:
+			value = fmt.Sprintf("%d%%", *target.AverageUtilization)
+		case target.AverageValue != nil:
+			value = target.AverageValue.String()
+		case target.Value != nil:
+			value = target.Value.String()
+		}
+		res[i] = fmt.Sprintf("%s/%s=%s", metric.Type, name, value)
+	}
+
+	return strings.Join(res, ",")
+}
@@ -0,0 +1,109 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v2 "k8s.io/api/autoscaling/v2"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newDriftTestPodTemplate(image, cpu string, env ...v1.EnvVar) *v1.PodTemplateSpec {
+	return &v1.PodTemplateSpec{
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{{
+				Name:  "app",
+				Image: image,
+				Env:   env,
+				Resources: v1.ResourceRequirements{
+					Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse(cpu)},
+				},
+			}},
+		},
+	}
+}
+
+func TestEntity_DiffK8sPodTemplate(t *testing.T) {
+	t.Run("no drift", func(t *testing.T) {
+		expected := newDriftTestPodTemplate("app:v1", "0.5", v1.EnvVar{Name: "A", Value: "1"})
+		live := newDriftTestPodTemplate("app:v1", "500m", v1.EnvVar{Name: "A", Value: "1"})
+		assert.Empty(t, DiffK8sPodTemplate(K8sObjectKindDeployment, expected, live))
+	})
+
+	t.Run("image resource and env drift", func(t *testing.T) {
+		expected := newDriftTestPodTemplate("app:v1", "0.5", v1.EnvVar{Name: "A", Value: "1"})
+		live := newDriftTestPodTemplate("app:v2", "1", v1.EnvVar{Name: "A", Value: "2"}, v1.EnvVar{Name: "B", Value: "3"})
+
+		items := DiffK8sPodTemplate(K8sObjectKindDeployment, expected, live)
+		assert.Equal(t, []*K8sDriftItem{
+			{Kind: K8sObjectKindDeployment, Field: "spec.template.spec.containers[app].image", Expected: "app:v1", Actual: "app:v2"},
+			{Kind: K8sObjectKindDeployment, Field: "spec.template.spec.containers[app].resources.requests.cpu", Expected: "500m", Actual: "1"},
+			{Kind: K8sObjectKindDeployment, Field: "spec.template.spec.containers[app].env[A]", Expected: "1", Actual: "2"},
+			{Kind: K8sObjectKindDeployment, Field: "spec.template.spec.containers[app].env[B]", Expected: K8sDriftMissingValue, Actual: "3"},
+		}, items)
+	})
+
+	t.Run("missing container", func(t *testing.T) {
+		expected := newDriftTestPodTemplate("app:v1", "0.5")
+		live := newDriftTestPodTemplate("app:v1", "0.5")
+		live.Spec.Containers[0].Name = "other"
+
+		items := DiffK8sPodTemplate(K8sObjectKindDeployment, expected, live)
+		assert.Len(t, items, 2)
+		assert.Equal(t, K8sDriftMissingValue, items[0].Actual)
+		assert.Equal(t, K8sDriftMissingValue, items[1].Expected)
+	})
+}
+
+func TestEntity_DiffK8sReplicas(t *testing.T) {
+	two, three := int32(2), int32(3)
+	assert.Empty(t, DiffK8sReplicas(K8sObjectKindDeployment, &two, &two))
+	assert.Empty(t, DiffK8sReplicas(K8sObjectKindDeployment, nil, &two))
+	assert.Len(t, DiffK8sReplicas(K8sObjectKindDeployment, &two, &three), 1)
+}
+
+func TestEntity_DiffK8sHPA(t *testing.T) {
+	minReplicas, utilization := int32(2), int32(60)
+	newHPA := func(max int32) *v2.HorizontalPodAutoscaler {
+		return &v2.HorizontalPodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{Name: "app"},
+			Spec: v2.HorizontalPodAutoscalerSpec{
+				MinReplicas: &minReplicas,
+				MaxReplicas: max,
+				Metrics: []v2.MetricSpec{{
+					Type: v2.ResourceMetricSourceType,
+					Resource: &v2.ResourceMetricSource{
+						Name:   v1.ResourceCPU,
+						Target: v2.MetricTarget{Type: v2.UtilizationMetricType, AverageUtilization: &utilization},
+					},
+				}},
+			},
+		}
+	}
+
+	assert.Empty(t, DiffK8sHPA(nil, nil))
+	assert.Empty(t, DiffK8sHPA(newHPA(5), newHPA(5)))
+	assert.Equal(t, []*K8sDriftItem{
+		{Kind: K8sObjectKindHPA, Field: "spec.maxReplicas", Expected: "5", Actual: "10"},
+	}, DiffK8sHPA(newHPA(5), newHPA(10)))
+	assert.Equal(t, K8sDriftMissingValue, DiffK8sHPA(newHPA(5), nil)[0].Actual)
+	assert.Equal(t, K8sDriftMissingValue, DiffK8sHPA(nil, newHPA(5))[0].Expected)
+
+	live := newHPA(5)
+	live.Spec.Metrics[0].Resource.Target.AverageUtilization = &minReplicas
+	items := DiffK8sHPA(newHPA(5), live)
+	assert.Equal(t, "Resource/cpu=60%", items[0].Expected)
+	assert.Equal(t, "Resource/cpu=2%", items[0].Actual)
+}
+
+func TestEntity_EqualK8sDriftItems(t *testing.T) {
+	a := []*K8sDriftItem{{Kind: K8sObjectKindDeployment, Field: "spec.replicas", Expected: "1", Actual: "2"}}
+	b := []*K8sDriftItem{{Kind: K8sObjectKindDeployment, Field: "spec.replicas", Expected: "1", Actual: "2"}}
+	assert.True(t, EqualK8sDriftItems(a, b))
+
+	b[0].Actual = "3"
+	assert.False(t, EqualK8sDriftItems(a, b))
+	assert.False(t, EqualK8sDriftItems(a, nil))
+}
@@ -16,12 +16,15 @@ const (
 	K8sObjectKindConfigMap       = "ConfigMap"
 	K8sObjectKindCronHPA         = "CronHorizontalPodAutoscaler"
 	K8sObjectKindCronJob         = "CronJob"
+	K8sObjectKindDaemonSet       = "DaemonSet"
 	K8sObjectKindDeployment      = "Deployment"
 	K8sObjectKindHPA             = "HorizontalPodAutoscaler"
 	K8sObjectKindIngress         = "Ingress"
 	K8sObjectKindJob             = "Job"
+	K8sObjectKindPDB             = "PodDisruptionBudget"
 	K8sObjectKindReplicaSet      = "ReplicaSet"
 	K8sObjectKindService         = "Service"
+	K8sObjectKindStatefulSet     = "StatefulSet"
 	K8sObjectKindVirtualService  = "VirtualService"
 )
 
@@ -30,12 +33,15 @@ var AMSSupportedK8sObjectKindsByAllVendors = map[string]struct{}{
 	K8sObjectKindConfigMap:      {},
 	K8sObjectKindCronHPA:        {}, // CronHPA 虽然是阿里云开源, 但目前所有集群均安装使用
 	K8sObjectKindCronJob:        {},
+	K8sObjectKindDaemonSet:      {},
 	K8sObjectKindDeployment:     {},
 	K8sObjectKindHPA:            {},
 	K8sObjectKindIngress:        {},
 	K8sObjectKindJob:            {},
+	K8sObjectKindPDB:            {},
 	K8sObjectKindReplicaSet:     {},
 	K8sObjectKindService:        {},
+	K8sObjectKindStatefulSet:    {},
 	K8sObjectKindVirtualService: {},
 }
 
@@ -153,6 +159,16 @@ type DeploymentTemplate struct {
 	ContainerName string
 	// 环境变量
 	Env []*EnvTemplate
+	// 引用 K8s Secret 的环境变量
+	SecretEnv []*SecretEnvTemplate
+	// 初始化容器
+	InitContainers []*ExtraContainerTemplate
+	// 边车容器
+	Sidecars []*ExtraContainerTemplate
+	// 存储卷
+	Volumes []*VolumeTemplate
+	// 主容器存储卷挂载
+	VolumeMounts []*VolumeMountTemplate
 	// 日志仓库名
 	LogStoreName string
 	// 配置文件名
@@ -197,6 +213,8 @@ type DeploymentTemplate struct {
 	NodeAffinity NodeAffinityTemplate
 	// 关闭pod反亲和性
 	DisableHighAvailability bool
+	// 拓扑分布约束, 按环境配置
+	TopologySpreadConstraints []*TopologySpreadConstraintTemplate
 
 	LocalDNS                string
 	ProgressDeadlineSeconds int
@@ -204,8 +222,29 @@ type DeploymentTemplate struct {
 
 func (tpl *DeploymentTemplate) Kind() string { return K8sObjectKindDeployment }
 
+func (tpl *DeploymentTemplate) UnifyImageName(imageRegistryHostWithNamespace string) {
+	tpl.ImageName.UnifyImageName(imageRegistryHostWithNamespace)
+	unifyExtraContainerImageNames(imageRegistryHostWithNamespace, tpl.InitContainers, tpl.Sidecars)
+}
+
 func (tpl *DeploymentTemplate) SetAPIVersion(ver string) { tpl.APIVersion = ver }
 
+// StatefulSet渲染模版, 复用Deployment的容器及调度配置
+type StatefulSetTemplate struct {
+	DeploymentTemplate
+	// 管理 pod 网络标识的 headless Service 名
+	ServiceName string
+}
+
+func (tpl *StatefulSetTemplate) Kind() string { return K8sObjectKindStatefulSet }
+
+// DaemonSet渲染模版, 每个节点运行一个实例, 副本数不生效
+type DaemonSetTemplate struct {
+	DeploymentTemplate
+}
+
+func (tpl *DaemonSetTemplate) Kind() string { return K8sObjectKindDaemonSet }
+
 // 环境变量模版
 type EnvTemplate struct {
 	// 变量名
@@ -214,6 +253,16 @@ type EnvTemplate struct {
 	Value string
 }
 
+// SecretEnvTemplate 引用 K8s Secret 的环境变量模板
+type SecretEnvTemplate struct {
+	// 变量名
+	Name string
+	// Secret 名
+	SecretName string
+	// Secret 中的键
+	Key string
+}
+
 // Service渲染模版
 type ServiceTemplate struct {
 	// k8s API 版本
@@ -279,6 +328,12 @@ type CronJobTemplate struct {
 	ContainerName string
 	// 环境变量
 	Env []*EnvTemplate
+	// 初始化容器, 不支持边车容器, 否则 pod 无法结束
+	InitContainers []*ExtraContainerTemplate
+	// 存储卷
+	Volumes []*VolumeTemplate
+	// 主容器存储卷挂载
+	VolumeMounts []*VolumeMountTemplate
 	// 日志仓库名
 	LogStoreName string
 	// 配置文件名
@@ -332,6 +387,11 @@ type CronJobTemplate struct {
 
 func (tpl *CronJobTemplate) Kind() string { return K8sObjectKindCronJob }
 
+func (tpl *CronJobTemplate) UnifyImageName(imageRegistryHostWithNamespace string) {
+	tpl.ImageName.UnifyImageName(imageRegistryHostWithNamespace)
+	unifyExtraContainerImageNames(imageRegistryHostWithNamespace, tpl.InitContainers)
+}
+
 func (tpl *CronJobTemplate) SetAPIVersion(ver string) { tpl.APIVersion = ver }
 
 // 应用配置ConfigMap渲染模版
@@ -416,6 +476,10 @@ type HPATemplate struct {
 	CPUTarget int32
 	// 扩容的内存限制
 	MemTarget int32
+	// 自定义扩缩容指标
+	Metrics []*HPAMetricSpec
+	// 扩缩容行为策略
+	Behavior *HPABehavior
 }
 
 func (tpl *HPATemplate) Kind() string { return K8sObjectKindHPA }
@@ -486,6 +550,12 @@ type JobTemplate struct {
 	ContainerName string
 	// 环境变量
 	Env []*EnvTemplate
+	// 初始化容器, 不支持边车容器, 否则 pod 无法结束
+	InitContainers []*ExtraContainerTemplate
+	// 存储卷
+	Volumes []*VolumeTemplate
+	// 主容器存储卷挂载
+	VolumeMounts []*VolumeMountTemplate
 	// 日志仓库名
 	LogStoreName string
 	// 配置文件名
@@ -522,6 +592,11 @@ type JobTemplate struct {
 
 func (tpl *JobTemplate) Kind() string { return K8sObjectKindJob }
 
+func (tpl *JobTemplate) UnifyImageName(imageRegistryHostWithNamespace string) {
+	tpl.ImageName.UnifyImageName(imageRegistryHostWithNamespace)
+	unifyExtraContainerImageNames(imageRegistryHostWithNamespace, tpl.InitContainers)
+}
+
 func (tpl *JobTemplate) SetAPIVersion(ver string) { tpl.APIVersion = ver }
 
 // IngressTemplate 渲染模版
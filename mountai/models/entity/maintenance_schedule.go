@@ -0,0 +1,132 @@
+package entity
+
+import (
+	"github.com/pkg/errors"
+	"github.com/robfig/cron/v3"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"time"
+)
+
+const (
+	// MaintenanceScheduleMinInterval 定时运维计划相邻两次执行的最小间隔
+	MaintenanceScheduleMinInterval = 10 * time.Minute
+	// MaintenanceScheduleMisfireTolerance 超过计划时间该时长仍未执行时视为错过, 不再补执行
+	MaintenanceScheduleMisfireTolerance = 10 * time.Minute
+)
+
+// MaintenanceScheduleActions 支持定时执行的运维行为
+var MaintenanceScheduleActions = &TaskActionList{
+	TaskActionRestart,
+	TaskActionStop,
+	TaskActionResume,
+	TaskActionUpdateHPA,
+}
+
+// MaintenanceScheduleExecutionStatus 定时运维计划执行状态
+type MaintenanceScheduleExecutionStatus string
+
+const (
+	// MaintenanceScheduleExecutionStatusSuccess 已创建任务, 任务执行结果见任务详情
+	MaintenanceScheduleExecutionStatusSuccess MaintenanceScheduleExecutionStatus = "success"
+	// MaintenanceScheduleExecutionStatusSkipped 因存在未完成任务, 封网或错过执行时间等原因跳过
+	MaintenanceScheduleExecutionStatusSkipped MaintenanceScheduleExecutionStatus = "skipped"
+	// MaintenanceScheduleExecutionStatusFail 校验或创建任务失败
+	MaintenanceScheduleExecutionStatusFail MaintenanceScheduleExecutionStatus = "fail"
+)
+
+// MaintenanceSchedule 定时运维计划, 按 cron 表达式周期性创建运维任务
+type MaintenanceSchedule struct {
+	ID          primitive.ObjectID `bson:"_id" json:"_id"`
+	Name        string             `bson:"name" json:"name"`
+	ProjectID   string             `bson:"project_id" json:"project_id"`
+	AppID       string             `bson:"app_id" json:"app_id"`
+	EnvName     AppEnvName         `bson:"env_name" json:"env_name"`
+	ClusterName ClusterName        `bson:"cluster_name" json:"cluster_name"`
+	Action      TaskAction         `bson:"action" json:"action"`
+	// 标准五位 cron 表达式, 按服务所在时区解析
+	Cron string `bson:"cron" json:"cron"`
+	// 最小/最大实例数, 仅 update_hpa 使用
+	MinPodCount int `bson:"min_pod_count" json:"min_pod_count"`
+	MaxPodCount int `bson:"max_pod_count" json:"max_pod_count"`
+	// 负责人id, 以负责人身份创建任务并校验权限
+	OwnerID string `bson:"owner_id" json:"owner_id"`
+	// 结束时间, 为空表示不结束
+	EndTime *time.Time `bson:"end_time" json:"end_time"`
+	// 是否停用
+	Disabled bool `bson:"disabled" json:"disabled"`
+	// 下次执行时间, 为空表示已结束
+	NextRunTime *time.Time `bson:"next_run_time" json:"next_run_time"`
+	LastRunTime *time.Time `bson:"last_run_time" json:"last_run_time"`
+
+	CreateTime *time.Time `bson:"create_time" json:"create_time"`
+	UpdateTime *time.Time `bson:"update_time" json:"update_time"`
+	// 软删除
+	DeleteTime *time.Time `bson:"delete_time" json:"delete_time"`
+}
+
+func (*MaintenanceSchedule) TableName() string {
+	return "maintenance_schedule"
+}
+
+func (s *MaintenanceSchedule) GenerateObjectIDString(args map[string]interface{}) string {
+	return s.ID.Hex()
+}
+
+// GetNextRunTime 获取 from 之后的下次执行时间, 超过结束时间时返回 nil
+func (s *MaintenanceSchedule) GetNextRunTime(from time.Time) (*time.Time, error) {
+	schedule, err := ParseMaintenanceCron(s.Cron)
+	if err != nil {
+		return nil, err
+	}
+
+	next := schedule.Next(from)
+	if s.EndTime != nil && next.After(*s.EndTime) {
+		return nil, nil
+	}
+
+	return &next, nil
+}
+
+// ParseMaintenanceCron 解析定时运维计划的 cron 表达式, 并校验执行间隔
+func ParseMaintenanceCron(spec string) (cron.Schedule, error) {
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid cron %s", spec)
+	}
+
+	// 以当前时间起的若干次执行估算最小间隔
+	prev := schedule.Next(time.Now())
+	for i := 0; i < 10; i++ {
+		next := schedule.Next(prev)
+		if next.Sub(prev) < MaintenanceScheduleMinInterval {
+			return nil, errors.Errorf("interval of cron %s is less than %s", spec, MaintenanceScheduleMinInterval)
+		}
+		prev = next
+	}
+
+	return schedule, nil
+}
+
+// MaintenanceScheduleExecution 定时运维计划执行记录
+type MaintenanceScheduleExecution struct {
+	ID         primitive.ObjectID `bson:"_id" json:"_id"`
+	ScheduleID string             `bson:"schedule_id" json:"schedule_id"`
+	AppID      string             `bson:"app_id" json:"app_id"`
+	// 创建的任务id, 跳过或失败时为空
+	TaskID string                             `bson:"task_id" json:"task_id"`
+	Status MaintenanceScheduleExecutionStatus `bson:"status" json:"status"`
+	// 跳过或失败原因
+	Message string `bson:"message" json:"message"`
+	// 计划执行时间
+	ScheduledTime *time.Time `bson:"scheduled_time" json:"scheduled_time"`
+	CreateTime    *time.Time `bson:"create_time" json:"create_time"`
+}
+
+func (*MaintenanceScheduleExecution) TableName() string {
+	return "maintenance_schedule_execution"
+}
+
+func (e *MaintenanceScheduleExecution) GenerateObjectIDString(args map[string]interface{}) string {
+	return e.ID.Hex()
+}
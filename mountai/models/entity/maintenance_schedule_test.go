@@ -0,0 +1,42 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEntity_ParseMaintenanceCron(t *testing.T) {
+	_, err := ParseMaintenanceCron("0 4 * * *")
+	assert.Nil(t, err)
+
+	_, err = ParseMaintenanceCron("*/5 * * * *")
+	assert.NotNil(t, err)
+
+	_, err = ParseMaintenanceCron("0 25 * * *")
+	assert.NotNil(t, err)
+}
+
+func TestEntity_MaintenanceSchedule_GetNextRunTime(t *testing.T) {
+	from := time.Date(2023, 5, 1, 3, 0, 0, 0, time.Local)
+	schedule := &MaintenanceSchedule{Cron: "0 4 * * *"}
+
+	next, err := schedule.GetNextRunTime(from)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2023, 5, 1, 4, 0, 0, 0, time.Local), *next)
+
+	t.Run("after end time", func(t *testing.T) {
+		end := time.Date(2023, 5, 1, 3, 30, 0, 0, time.Local)
+		schedule := &MaintenanceSchedule{Cron: "0 4 * * *", EndTime: &end}
+
+		next, err := schedule.GetNextRunTime(from)
+		assert.Nil(t, err)
+		assert.Nil(t, next)
+	})
+}
+
+func TestEntity_MaintenanceScheduleActions(t *testing.T) {
+	assert.True(t, MaintenanceScheduleActions.Contains(TaskActionRestart))
+	assert.False(t, MaintenanceScheduleActions.Contains(TaskActionFullDeploy))
+}
@@ -0,0 +1,81 @@
+package entity
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"time"
+)
+
+// MultiClusterDeployStatus 多集群部署状态
+type MultiClusterDeployStatus string
+
+const (
+	MultiClusterDeployStatusRunning MultiClusterDeployStatus = "running"
+	MultiClusterDeployStatusSuccess MultiClusterDeployStatus = "success"
+	MultiClusterDeployStatusFail    MultiClusterDeployStatus = "fail"
+)
+
+// MultiClusterDeployWaveStatus 多集群部署单个批次的状态
+type MultiClusterDeployWaveStatus string
+
+const (
+	MultiClusterDeployWaveStatusPending MultiClusterDeployWaveStatus = "pending"
+	MultiClusterDeployWaveStatusRunning MultiClusterDeployWaveStatus = "running"
+	MultiClusterDeployWaveStatusSuccess MultiClusterDeployWaveStatus = "success"
+	MultiClusterDeployWaveStatusFail    MultiClusterDeployWaveStatus = "fail"
+	// 前序批次失败, 本批次不再执行
+	MultiClusterDeployWaveStatusHalted MultiClusterDeployWaveStatus = "halted"
+)
+
+// TaskActionMultiClusterDeployList 支持多集群分批部署的任务类型
+var TaskActionMultiClusterDeployList = &TaskActionList{
+	TaskActionFullDeploy,
+	TaskActionCanaryDeploy,
+	TaskActionReloadConfig,
+	TaskActionRestart,
+}
+
+// MultiClusterDeployTask 单个集群的部署任务
+type MultiClusterDeployTask struct {
+	ClusterName ClusterName `bson:"cluster_name" json:"cluster_name"`
+	TaskID      string      `bson:"task_id" json:"task_id"`
+}
+
+// MultiClusterDeployWave 多集群部署批次, 同一批次的集群并行部署
+type MultiClusterDeployWave struct {
+	Tasks      []*MultiClusterDeployTask    `bson:"tasks" json:"tasks"`
+	Status     MultiClusterDeployWaveStatus `bson:"status" json:"status"`
+	StartTime  *time.Time                   `bson:"start_time" json:"start_time"`
+	FinishTime *time.Time                   `bson:"finish_time" json:"finish_time"`
+}
+
+// 多集群部署, 按批次依次部署应用在各集群的任务, 任一批次失败则中止后续批次
+type MultiClusterDeploy struct {
+	ID          primitive.ObjectID        `bson:"_id" json:"_id"`
+	ProjectID   string                    `bson:"project_id" json:"project_id"`
+	AppID       string                    `bson:"app_id" json:"app_id"`
+	EnvName     AppEnvName                `bson:"env_name" json:"env_name"`
+	Action      TaskAction                `bson:"action" json:"action"`
+	Description string                    `bson:"description" json:"description"`
+	Status      MultiClusterDeployStatus  `bson:"status" json:"status"`
+	CurrentWave int                       `bson:"current_wave" json:"current_wave"`
+	Waves       []*MultiClusterDeployWave `bson:"waves" json:"waves"`
+	// 失败原因
+	Message    string     `bson:"message" json:"message"`
+	OperatorID string     `bson:"operator_id" json:"operator_id"`
+	CreateTime *time.Time `bson:"create_time" json:"create_time"`
+	UpdateTime *time.Time `bson:"update_time" json:"update_time"`
+}
+
+func (*MultiClusterDeploy) TableName() string {
+	return "multi_cluster_deploy"
+}
+
+// GetCurrentWave 获取当前执行中的批次, 全部完成时返回 nil
+func (d *MultiClusterDeploy) GetCurrentWave() *MultiClusterDeployWave {
+	if d.CurrentWave < 0 || d.CurrentWave >= len(d.Waves) {
+		return nil
+	}
+
+	return d.Waves[d.CurrentWave]
+}
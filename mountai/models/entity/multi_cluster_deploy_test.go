@@ -0,0 +1,30 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEntity_MultiClusterDeploy(t *testing.T) {
+	deploy := &MultiClusterDeploy{
+		Waves: []*MultiClusterDeployWave{
+			{Tasks: []*MultiClusterDeployTask{{ClusterName: DefaultClusterName}}},
+			{Tasks: []*MultiClusterDeployTask{{ClusterName: "hw"}}},
+		},
+	}
+
+	t.Run("first wave", func(t *testing.T) {
+		assert.Equal(t, DefaultClusterName, deploy.GetCurrentWave().Tasks[0].ClusterName)
+	})
+
+	t.Run("second wave", func(t *testing.T) {
+		deploy.CurrentWave = 1
+		assert.Equal(t, ClusterName("hw"), deploy.GetCurrentWave().Tasks[0].ClusterName)
+	})
+
+	t.Run("finished", func(t *testing.T) {
+		deploy.CurrentWave = 2
+		assert.Nil(t, deploy.GetCurrentWave())
+	})
+}
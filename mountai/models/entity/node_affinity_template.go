@@ -123,7 +123,7 @@ func GenerateNodeAffinityTemplate(appType AppType, labelCfg NodeAffinityLabelCon
 func AddExclusiveExpressions(appType AppType, exclusive NodeLabelValueType,
 	expressions []AffinityMatchExpression) []AffinityMatchExpression {
 	switch appType {
-	case AppTypeService, AppTypeWorker:
+	case AppTypeService, AppTypeWorker, AppTypeStatefulSet:
 		if string(exclusive) == "" {
 			expressions = append(expressions, AffinityMatchExpression{
 				Key:      AffinityMatchExpressionKeyExclusiveForDeployment,
@@ -0,0 +1,187 @@
+package entity
+
+import (
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// NotificationChannelType 通知渠道类型
+type NotificationChannelType string
+
+const (
+	// NotificationChannelTypeWebhook 通用 HTTP webhook, 请求体使用 HMAC-SHA256 签名
+	NotificationChannelTypeWebhook NotificationChannelType = "webhook"
+	// NotificationChannelTypeSlack Slack incoming webhook
+	NotificationChannelTypeSlack NotificationChannelType = "slack"
+	// NotificationChannelTypeEmail SMTP 邮件
+	NotificationChannelTypeEmail NotificationChannelType = "email"
+	// NotificationChannelTypeFeishu 飞书群机器人, 内置渠道, 不支持项目配置
+	NotificationChannelTypeFeishu NotificationChannelType = "feishu"
+	// NotificationChannelTypeDingTalk 钉钉工作通知, 内置渠道, 不支持项目配置
+	NotificationChannelTypeDingTalk NotificationChannelType = "dingtalk"
+)
+
+// NotificationConfigurableChannelTypes 支持项目自行配置的通知渠道类型
+var NotificationConfigurableChannelTypes = []NotificationChannelType{
+	NotificationChannelTypeWebhook,
+	NotificationChannelTypeSlack,
+	NotificationChannelTypeEmail,
+}
+
+// IsConfigurable 是否支持项目自行配置
+func (t NotificationChannelType) IsConfigurable() bool {
+	for _, item := range NotificationConfigurableChannelTypes {
+		if item == t {
+			return true
+		}
+	}
+	return false
+}
+
+// NotificationEvent 通知事件, 操作成功时为订阅操作类型本身, 失败时追加 NotificationEventFailedSuffix
+type NotificationEvent string
+
+// NotificationEventFailedSuffix 操作失败事件后缀
+const NotificationEventFailedSuffix = "_failed"
+
+// NotificationEventK8sDrift 线上 k8s 对象与最近部署的期望状态不一致, 不对应任务操作
+const NotificationEventK8sDrift NotificationEvent = "k8s_drift"
+
+// GetNotificationEvent 根据订阅操作类型及任务状态获取通知事件, 任务未结束时返回空
+func GetNotificationEvent(action SubscribeAction, status TaskStatus) NotificationEvent {
+	switch status {
+	case TaskStatusSuccess:
+		return NotificationEvent(action)
+	case TaskStatusFail:
+		return NotificationEvent(string(action) + NotificationEventFailedSuffix)
+	default:
+		return ""
+	}
+}
+
+// IsValid 是否为已知的通知事件
+func (e NotificationEvent) IsValid() bool {
+	if e == NotificationEventK8sDrift {
+		return true
+	}
+
+	action := SubscribeAction(strings.TrimSuffix(string(e), NotificationEventFailedSuffix))
+	for _, item := range SubscribeActions {
+		if item == action {
+			return true
+		}
+	}
+	return false
+}
+
+// NotificationChannel 项目通知渠道
+type NotificationChannel struct {
+	ID        primitive.ObjectID      `bson:"_id" json:"_id"`
+	ProjectID string                  `bson:"project_id" json:"project_id"`
+	Name      string                  `bson:"name" json:"name"`
+	Type      NotificationChannelType `bson:"type" json:"type"`
+	// webhook/slack 地址
+	URL string `bson:"url" json:"url"`
+	// webhook 签名密钥, 不对外返回
+	Secret string `bson:"secret" json:"-"`
+	// 邮件收件人
+	Emails []string `bson:"emails" json:"emails"`
+	// 创建人id
+	OperatorID string `bson:"operator_id" json:"operator_id"`
+
+	CreateTime *time.Time `bson:"create_time" json:"create_time"`
+	UpdateTime *time.Time `bson:"update_time" json:"update_time"`
+	// 软删除
+	DeleteTime *time.Time `bson:"delete_time" json:"delete_time"`
+}
+
+func (*NotificationChannel) TableName() string {
+	return "notification_channel"
+}
+
+func (c *NotificationChannel) GenerateObjectIDString(args map[string]interface{}) string {
+	return c.ID.Hex()
+}
+
+// NotificationRule 项目通知路由规则, 命中规则的事件发送至对应渠道
+type NotificationRule struct {
+	ID        primitive.ObjectID `bson:"_id" json:"_id"`
+	ProjectID string             `bson:"project_id" json:"project_id"`
+	ChannelID string             `bson:"channel_id" json:"channel_id"`
+	// 环境, 为空表示所有环境
+	EnvNames []AppEnvName        `bson:"env_names" json:"env_names"`
+	Events   []NotificationEvent `bson:"events" json:"events"`
+	// 创建人id
+	OperatorID string `bson:"operator_id" json:"operator_id"`
+
+	CreateTime *time.Time `bson:"create_time" json:"create_time"`
+	UpdateTime *time.Time `bson:"update_time" json:"update_time"`
+	// 软删除
+	DeleteTime *time.Time `bson:"delete_time" json:"delete_time"`
+}
+
+func (*NotificationRule) TableName() string {
+	return "notification_rule"
+}
+
+func (r *NotificationRule) GenerateObjectIDString(args map[string]interface{}) string {
+	return r.ID.Hex()
+}
+
+// Matches 判断事件是否命中规则
+func (r *NotificationRule) Matches(envName AppEnvName, event NotificationEvent) bool {
+	if len(r.EnvNames) > 0 {
+		matched := false
+		for _, item := range r.EnvNames {
+			if item == envName {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	for _, item := range r.Events {
+		if item == event {
+			return true
+		}
+	}
+	return false
+}
+
+// NotificationDeliveryStatus 通知投递状态
+type NotificationDeliveryStatus string
+
+const (
+	NotificationDeliveryStatusSuccess NotificationDeliveryStatus = "success"
+	NotificationDeliveryStatusFail    NotificationDeliveryStatus = "fail"
+)
+
+// NotificationDelivery 通知投递记录
+type NotificationDelivery struct {
+	ID        primitive.ObjectID `bson:"_id" json:"_id"`
+	ProjectID string             `bson:"project_id" json:"project_id"`
+	// 内置渠道无渠道及规则id
+	ChannelID   string                     `bson:"channel_id" json:"channel_id"`
+	ChannelType NotificationChannelType    `bson:"channel_type" json:"channel_type"`
+	RuleID      string                     `bson:"rule_id" json:"rule_id"`
+	Event       NotificationEvent          `bson:"event" json:"event"`
+	AppID       string                     `bson:"app_id" json:"app_id"`
+	Env         AppEnvName                 `bson:"env" json:"env"`
+	TaskID      string                     `bson:"task_id" json:"task_id"`
+	Status      NotificationDeliveryStatus `bson:"status" json:"status"`
+	// 发送次数, 含重试
+	Attempts int `bson:"attempts" json:"attempts"`
+	// 最后一次失败原因
+	Error string `bson:"error" json:"error"`
+
+	CreateTime *time.Time `bson:"create_time" json:"create_time"`
+}
+
+func (*NotificationDelivery) TableName() string {
+	return "notification_delivery"
+}
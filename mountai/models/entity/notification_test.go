@@ -0,0 +1,38 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEntity_GetNotificationEvent(t *testing.T) {
+	assert.Equal(t, NotificationEvent("deploy"), GetNotificationEvent(SubscribeActionDeploy, TaskStatusSuccess))
+	assert.Equal(t, NotificationEvent("deploy_failed"), GetNotificationEvent(SubscribeActionDeploy, TaskStatusFail))
+	assert.Equal(t, NotificationEvent(""), GetNotificationEvent(SubscribeActionDeploy, TaskStatusInit))
+}
+
+func TestEntity_NotificationEvent_IsValid(t *testing.T) {
+	assert.True(t, NotificationEvent("restart").IsValid())
+	assert.True(t, NotificationEvent("rollback_failed").IsValid())
+	assert.True(t, NotificationEventK8sDrift.IsValid())
+	assert.False(t, NotificationEvent("unknown").IsValid())
+	assert.False(t, NotificationEvent("deploy_failed_failed").IsValid())
+}
+
+func TestEntity_NotificationRule_Matches(t *testing.T) {
+	t.Run("all envs", func(t *testing.T) {
+		rule := &NotificationRule{Events: []NotificationEvent{"deploy_failed"}}
+		assert.True(t, rule.Matches(AppEnvPrd, "deploy_failed"))
+		assert.False(t, rule.Matches(AppEnvPrd, "deploy"))
+	})
+
+	t.Run("env limited", func(t *testing.T) {
+		rule := &NotificationRule{
+			EnvNames: []AppEnvName{AppEnvPrd},
+			Events:   []NotificationEvent{"deploy"},
+		}
+		assert.True(t, rule.Matches(AppEnvPrd, "deploy"))
+		assert.False(t, rule.Matches(AppEnvStg, "deploy"))
+	})
+}
@@ -0,0 +1,192 @@
+package entity
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"fmt"
+	"time"
+)
+
+// PipelineGateType 流水线阶段间的卡点类型
+type PipelineGateType string
+
+const (
+	// PipelineGateTypeManual 人工确认, 由项目负责人放行
+	PipelineGateTypeManual PipelineGateType = "manual"
+	// PipelineGateTypeDingApproval 钉钉审批, 阶段任务走部署审批流程
+	PipelineGateTypeDingApproval PipelineGateType = "ding_approval"
+	// PipelineGateTypeSoak 上一阶段成功后至少观察一段时间
+	PipelineGateTypeSoak PipelineGateType = "soak"
+	// PipelineGateTypeHealthCheck 上一阶段的 Deployment 全部就绪
+	PipelineGateTypeHealthCheck PipelineGateType = "health_check"
+)
+
+// PipelineRunStatus 流水线运行状态
+type PipelineRunStatus string
+
+const (
+	PipelineRunStatusRunning  PipelineRunStatus = "running"
+	PipelineRunStatusSuccess  PipelineRunStatus = "success"
+	PipelineRunStatusFail     PipelineRunStatus = "fail"
+	PipelineRunStatusCanceled PipelineRunStatus = "canceled"
+)
+
+// PipelineStageStatus 流水线运行中单个阶段的状态
+type PipelineStageStatus string
+
+const (
+	PipelineStageStatusPending   PipelineStageStatus = "pending"
+	PipelineStageStatusGating    PipelineStageStatus = "gating"
+	PipelineStageStatusDeploying PipelineStageStatus = "deploying"
+	PipelineStageStatusSuccess   PipelineStageStatus = "success"
+	PipelineStageStatusFail      PipelineStageStatus = "fail"
+	PipelineStageStatusCanceled  PipelineStageStatus = "canceled"
+)
+
+// PipelineGate 进入阶段前需要通过的卡点
+type PipelineGate struct {
+	Type PipelineGateType `bson:"type" json:"type"`
+	// 观察时长, 仅 soak 类型有效
+	SoakSeconds int `bson:"soak_seconds" json:"soak_seconds"`
+}
+
+// PipelineEnvStage 流水线的环境阶段, 按顺序将同一镜像部署至各环境
+type PipelineEnvStage struct {
+	EnvName     AppEnvName  `bson:"env_name" json:"env_name"`
+	ClusterName ClusterName `bson:"cluster_name" json:"cluster_name"`
+	// 为空表示无需卡点, 第一个阶段的卡点在运行开始时生效
+	Gate *PipelineGate `bson:"gate" json:"gate"`
+}
+
+// 部署流水线
+type Pipeline struct {
+	ID          primitive.ObjectID  `bson:"_id" json:"_id"`
+	Name        string              `bson:"name" json:"name"`
+	Description string              `bson:"description" json:"description"`
+	ProjectID   string              `bson:"project_id" json:"project_id"`
+	AppID       string              `bson:"app_id" json:"app_id"`
+	Stages      []*PipelineEnvStage `bson:"stages" json:"stages"`
+	// 创建人id
+	OperatorID string `bson:"operator_id" json:"operator_id"`
+
+	CreateTime *time.Time `bson:"create_time" json:"create_time"`
+	UpdateTime *time.Time `bson:"update_time" json:"update_time"`
+	// 软删除
+	DeleteTime *time.Time `bson:"delete_time" json:"delete_time"`
+}
+
+func (*Pipeline) TableName() string {
+	return "pipeline"
+}
+
+// PipelineRunStage 流水线运行中单个阶段的执行情况
+type PipelineRunStage struct {
+	EnvName     AppEnvName          `bson:"env_name" json:"env_name"`
+	ClusterName ClusterName         `bson:"cluster_name" json:"cluster_name"`
+	Gate        *PipelineGate       `bson:"gate" json:"gate"`
+	Status      PipelineStageStatus `bson:"status" json:"status"`
+	TaskID      string              `bson:"task_id" json:"task_id"`
+	// 人工卡点的放行人
+	ApprovedBy string `bson:"approved_by" json:"approved_by"`
+	// 阶段当前等待或失败的原因
+	Message    string     `bson:"message" json:"message"`
+	StartTime  *time.Time `bson:"start_time" json:"start_time"`
+	FinishTime *time.Time `bson:"finish_time" json:"finish_time"`
+}
+
+// 部署流水线的一次运行, 各阶段使用同一镜像版本及配置
+type PipelineRun struct {
+	ID             primitive.ObjectID  `bson:"_id" json:"_id"`
+	PipelineID     string              `bson:"pipeline_id" json:"pipeline_id"`
+	ProjectID      string              `bson:"project_id" json:"project_id"`
+	AppID          string              `bson:"app_id" json:"app_id"`
+	ImageVersion   string              `bson:"image_version" json:"image_version"`
+	ConfigCommitID string              `bson:"config_commit_id" json:"config_commit_id"`
+	Description    string              `bson:"description" json:"description"`
+	Status         PipelineRunStatus   `bson:"status" json:"status"`
+	CurrentStage   int                 `bson:"current_stage" json:"current_stage"`
+	Stages         []*PipelineRunStage `bson:"stages" json:"stages"`
+	OperatorID     string              `bson:"operator_id" json:"operator_id"`
+	CreateTime     *time.Time          `bson:"create_time" json:"create_time"`
+	UpdateTime     *time.Time          `bson:"update_time" json:"update_time"`
+}
+
+func (*PipelineRun) TableName() string {
+	return "pipeline_run"
+}
+
+// GetCurrentStage 获取当前执行中的阶段, 运行结束时返回 nil
+func (r *PipelineRun) GetCurrentStage() *PipelineRunStage {
+	if r.CurrentStage < 0 || r.CurrentStage >= len(r.Stages) {
+		return nil
+	}
+
+	return r.Stages[r.CurrentStage]
+}
+
+// GetPreviousStage 获取当前阶段的上一个阶段, 当前为第一个阶段时返回 nil
+func (r *PipelineRun) GetPreviousStage() *PipelineRunStage {
+	if r.CurrentStage <= 0 || r.CurrentStage > len(r.Stages) {
+		return nil
+	}
+
+	return r.Stages[r.CurrentStage-1]
+}
+
+// StartCurrentStage 开始当前阶段, 需要等待卡点时进入卡点状态并返回 false
+func (r *PipelineRun) StartCurrentStage(now time.Time) bool {
+	stage := r.GetCurrentStage()
+	stage.StartTime = &now
+	// 钉钉审批在阶段任务上进行, 无需等待
+	if stage.Gate != nil && stage.Gate.Type != PipelineGateTypeDingApproval {
+		stage.Status = PipelineStageStatusGating
+		return false
+	}
+
+	return true
+}
+
+// DeployCurrentStage 当前阶段的任务创建完成, 进入部署状态
+func (r *PipelineRun) DeployCurrentStage(taskID string) {
+	stage := r.GetCurrentStage()
+	stage.TaskID = taskID
+	stage.Status = PipelineStageStatusDeploying
+	stage.Message = ""
+}
+
+// FinishCurrentStage 当前阶段成功, 进入下一阶段, 所有阶段完成时运行成功
+func (r *PipelineRun) FinishCurrentStage(now time.Time) {
+	stage := r.GetCurrentStage()
+	stage.Status = PipelineStageStatusSuccess
+	stage.Message = ""
+	stage.FinishTime = &now
+
+	r.CurrentStage++
+	if r.GetCurrentStage() == nil {
+		r.Status = PipelineRunStatusSuccess
+	}
+}
+
+// FailCurrentStage 当前阶段失败, 运行随之失败
+func (r *PipelineRun) FailCurrentStage(msg string, now time.Time) {
+	stage := r.GetCurrentStage()
+	stage.Status = PipelineStageStatusFail
+	stage.Message = msg
+	stage.FinishTime = &now
+
+	r.Status = PipelineRunStatusFail
+}
+
+// UpdateCurrentStageByTask 根据阶段任务的状态推进部署中的当前阶段, 任务未结束时不变
+func (r *PipelineRun) UpdateCurrentStageByTask(taskStatus TaskStatus, approvalRefused bool, now time.Time) {
+	stage := r.GetCurrentStage()
+
+	switch {
+	case taskStatus == TaskStatusSuccess:
+		r.FinishCurrentStage(now)
+	case taskStatus == TaskStatusFail:
+		r.FailCurrentStage(fmt.Sprintf("task(%s) failed", stage.TaskID), now)
+	case approvalRefused:
+		r.FailCurrentStage(fmt.Sprintf("task(%s) approval refused", stage.TaskID), now)
+	}
+}
@@ -0,0 +1,114 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEntity_PipelineRun(t *testing.T) {
+	run := &PipelineRun{
+		Stages: []*PipelineRunStage{
+			{EnvName: AppEnvFat},
+			{EnvName: AppEnvStg},
+		},
+	}
+
+	t.Run("first stage", func(t *testing.T) {
+		assert.Equal(t, AppEnvFat, run.GetCurrentStage().EnvName)
+		assert.Nil(t, run.GetPreviousStage())
+	})
+
+	t.Run("second stage", func(t *testing.T) {
+		run.CurrentStage = 1
+		assert.Equal(t, AppEnvStg, run.GetCurrentStage().EnvName)
+		assert.Equal(t, AppEnvFat, run.GetPreviousStage().EnvName)
+	})
+
+	t.Run("finished", func(t *testing.T) {
+		run.CurrentStage = 2
+		assert.Nil(t, run.GetCurrentStage())
+		assert.Equal(t, AppEnvStg, run.GetPreviousStage().EnvName)
+	})
+}
+
+func TestEntity_PipelineRunAdvance(t *testing.T) {
+	now := time.Now()
+	newRun := func() *PipelineRun {
+		return &PipelineRun{
+			Status: PipelineRunStatusRunning,
+			Stages: []*PipelineRunStage{
+				{EnvName: AppEnvFat, Status: PipelineStageStatusPending},
+				{EnvName: AppEnvStg, Status: PipelineStageStatusPending, Gate: &PipelineGate{Type: PipelineGateTypeManual}},
+				{EnvName: AppEnvPrd, Status: PipelineStageStatusPending, Gate: &PipelineGate{Type: PipelineGateTypeDingApproval}},
+			},
+		}
+	}
+
+	t.Run("success", func(t *testing.T) {
+		run := newRun()
+
+		// 无卡点时直接部署
+		assert.True(t, run.StartCurrentStage(now))
+		run.DeployCurrentStage("task-fat")
+		assert.Equal(t, PipelineStageStatusDeploying, run.Stages[0].Status)
+
+		// 任务未结束时不变
+		run.UpdateCurrentStageByTask(TaskStatusCreateFullDeploymentUnderway, false, now)
+		assert.Equal(t, 0, run.CurrentStage)
+		assert.Equal(t, PipelineStageStatusDeploying, run.Stages[0].Status)
+
+		run.UpdateCurrentStageByTask(TaskStatusSuccess, false, now)
+		assert.Equal(t, 1, run.CurrentStage)
+		assert.Equal(t, PipelineStageStatusSuccess, run.Stages[0].Status)
+		assert.Equal(t, PipelineRunStatusRunning, run.Status)
+
+		// 人工卡点需要等待
+		assert.False(t, run.StartCurrentStage(now))
+		assert.Equal(t, PipelineStageStatusGating, run.Stages[1].Status)
+		run.DeployCurrentStage("task-stg")
+		run.UpdateCurrentStageByTask(TaskStatusSuccess, false, now)
+
+		// 钉钉审批在阶段任务上进行, 无需等待
+		assert.True(t, run.StartCurrentStage(now))
+		run.DeployCurrentStage("task-prd")
+		run.UpdateCurrentStageByTask(TaskStatusSuccess, false, now)
+		assert.Nil(t, run.GetCurrentStage())
+		assert.Equal(t, PipelineRunStatusSuccess, run.Status)
+	})
+
+	t.Run("task failed", func(t *testing.T) {
+		run := newRun()
+		assert.True(t, run.StartCurrentStage(now))
+		run.DeployCurrentStage("task-fat")
+
+		run.UpdateCurrentStageByTask(TaskStatusFail, false, now)
+		assert.Equal(t, 0, run.CurrentStage)
+		assert.Equal(t, PipelineStageStatusFail, run.Stages[0].Status)
+		assert.Equal(t, "task(task-fat) failed", run.Stages[0].Message)
+		assert.Equal(t, PipelineRunStatusFail, run.Status)
+	})
+
+	t.Run("approval refused", func(t *testing.T) {
+		run := newRun()
+		run.CurrentStage = 2
+		assert.True(t, run.StartCurrentStage(now))
+		run.DeployCurrentStage("task-prd")
+
+		run.UpdateCurrentStageByTask(TaskStatusInit, true, now)
+		assert.Equal(t, PipelineStageStatusFail, run.Stages[2].Status)
+		assert.Equal(t, "task(task-prd) approval refused", run.Stages[2].Message)
+		assert.Equal(t, PipelineRunStatusFail, run.Status)
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		run := newRun()
+		assert.True(t, run.StartCurrentStage(now))
+
+		run.FailCurrentStage("no permission", now)
+		assert.Equal(t, PipelineStageStatusFail, run.Stages[0].Status)
+		assert.Equal(t, "no permission", run.Stages[0].Message)
+		assert.Equal(t, PipelineRunStatusFail, run.Status)
+	})
+}
@@ -0,0 +1,190 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	// RightSizingDefaultCountTime 默认统计资源使用量的时间
+	RightSizingDefaultCountTime = "7d"
+	// RightSizingDefaultLookback 默认回溯部署任务的时间范围
+	RightSizingDefaultLookback = 30 * 24 * time.Hour
+	// RightSizingDefaultHeadroom 默认推荐值相对使用量的冗余比例
+	RightSizingDefaultHeadroom = 0.2
+	// RightSizingUsageQuantile 统计使用量的分位数
+	RightSizingUsageQuantile = 0.95
+
+	rightSizingGiBBytes = 1 << 30
+)
+
+// RightSizingRecommendation 应用资源规格推荐
+// cpu 按 p95 使用量推荐, 内存不可压缩, 按最大使用量推荐
+type RightSizingRecommendation struct {
+	ID          primitive.ObjectID `bson:"_id" json:"_id"`
+	ProjectID   string             `bson:"project_id" json:"project_id"`
+	AppID       string             `bson:"app_id" json:"app_id"`
+	EnvName     AppEnvName         `bson:"env_name" json:"env_name"`
+	ClusterName ClusterName        `bson:"cluster_name" json:"cluster_name"`
+	// 统计时的最新部署任务id
+	TaskID string `bson:"task_id" json:"task_id"`
+	// 实例数, 开启自动扩缩容时为最小实例数
+	PodCount int `bson:"pod_count" json:"pod_count"`
+	// 统计时间
+	CountTime string `bson:"count_time" json:"count_time"`
+
+	CPURequest CPUResourceType `bson:"cpu_request" json:"cpu_request"`
+	CPULimit   CPUResourceType `bson:"cpu_limit" json:"cpu_limit"`
+	MemRequest MemResourceType `bson:"mem_request" json:"mem_request"`
+	MemLimit   MemResourceType `bson:"mem_limit" json:"mem_limit"`
+
+	// 单实例 cpu 使用量 单位核
+	CPUUsageP95 float64 `bson:"cpu_usage_p95" json:"cpu_usage_p95"`
+	CPUUsageMax float64 `bson:"cpu_usage_max" json:"cpu_usage_max"`
+	// 单实例内存使用量 单位字节
+	MemUsageP95 float64 `bson:"mem_usage_p95" json:"mem_usage_p95"`
+	MemUsageMax float64 `bson:"mem_usage_max" json:"mem_usage_max"`
+	// 使用量占 request/limit 的比例
+	CPUP95RequestRate float64 `bson:"cpu_p95_request_rate" json:"cpu_p95_request_rate"`
+	CPUMaxLimitRate   float64 `bson:"cpu_max_limit_rate" json:"cpu_max_limit_rate"`
+	MemP95RequestRate float64 `bson:"mem_p95_request_rate" json:"mem_p95_request_rate"`
+	MemMaxLimitRate   float64 `bson:"mem_max_limit_rate" json:"mem_max_limit_rate"`
+
+	RecommendedCPURequest CPUResourceType `bson:"recommended_cpu_request" json:"recommended_cpu_request"`
+	RecommendedMemRequest MemResourceType `bson:"recommended_mem_request" json:"recommended_mem_request"`
+	// 按推荐值调整后每月的费用变化, 负数表示节省
+	MonthlyCostDelta float64 `bson:"monthly_cost_delta" json:"monthly_cost_delta"`
+
+	CreateTime *time.Time `bson:"create_time" json:"create_time"`
+	UpdateTime *time.Time `bson:"update_time" json:"update_time"`
+}
+
+func (*RightSizingRecommendation) TableName() string {
+	return "right_sizing_recommendation"
+}
+
+func (r *RightSizingRecommendation) GenerateObjectIDString(args map[string]interface{}) string {
+	return r.ID.Hex()
+}
+
+// NeedResize 推荐值与当前值是否不同
+func (r *RightSizingRecommendation) NeedResize() bool {
+	return r.RecommendedCPURequest != r.CPURequest || r.RecommendedMemRequest != r.MemRequest
+}
+
+// RightSizingUsageTemplate 资源使用量查询模版
+type RightSizingUsageTemplate struct {
+	// 命名空间正则, 同时匹配 istio 命名空间
+	Namespace string
+	// 容器标签名
+	// prd集群为 container_name
+	// 其他集群为 container
+	ContainerLabelName string
+	// 容器名
+	ContainerName string
+	// 统计时间
+	CountTime string
+	// 分位数, 1 表示最大值
+	Quantile float64
+}
+
+// Cores cpu 核数
+func (c CPUResourceType) Cores() (float64, error) {
+	q, err := resource.ParseQuantity(string(c))
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid cpu resource %s", c)
+	}
+
+	return q.AsApproximateFloat64(), nil
+}
+
+// Bytes 内存字节数
+func (m MemResourceType) Bytes() (float64, error) {
+	q, err := resource.ParseQuantity(string(m))
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid memory resource %s", m)
+	}
+
+	return q.AsApproximateFloat64(), nil
+}
+
+// RecommendCPURequest 从可用规格中选出满足使用量及冗余的最小规格, 不超过 limit
+// 没有满足的规格时返回不超过 limit 的最大规格
+func RecommendCPURequest(candidates []CPUResourceType, usage, headroom float64, limit CPUResourceType) (CPUResourceType, error) {
+	values := make([]float64, len(candidates))
+	for i, candidate := range candidates {
+		cores, err := candidate.Cores()
+		if err != nil {
+			return "", err
+		}
+		values[i] = cores
+	}
+
+	limitCores, err := limit.Cores()
+	if err != nil {
+		return "", err
+	}
+
+	i := pickResourceSpec(values, usage*(1+headroom), limitCores)
+	if i < 0 {
+		return "", errors.Errorf("no cpu request spec is available under limit %s", limit)
+	}
+
+	return candidates[i], nil
+}
+
+// RecommendMemRequest 从可用规格中选出满足使用量及冗余的最小规格, 不超过 limit
+// 没有满足的规格时返回不超过 limit 的最大规格
+func RecommendMemRequest(candidates []MemResourceType, usage, headroom float64, limit MemResourceType) (MemResourceType, error) {
+	values := make([]float64, len(candidates))
+	for i, candidate := range candidates {
+		bytes, err := candidate.Bytes()
+		if err != nil {
+			return "", err
+		}
+		values[i] = bytes
+	}
+
+	limitBytes, err := limit.Bytes()
+	if err != nil {
+		return "", err
+	}
+
+	i := pickResourceSpec(values, usage*(1+headroom), limitBytes)
+	if i < 0 {
+		return "", errors.Errorf("no memory request spec is available under limit %s", limit)
+	}
+
+	return candidates[i], nil
+}
+
+// pickResourceSpec 返回不小于 target 的最小值下标, 都小于 target 时返回最大值下标, 忽略超过 max 的值
+func pickResourceSpec(values []float64, target, max float64) int {
+	fit, largest := -1, -1
+	for i, v := range values {
+		if v > max {
+			continue
+		}
+
+		if v >= target && (fit < 0 || v < values[fit]) {
+			fit = i
+		}
+		if largest < 0 || v > values[largest] {
+			largest = i
+		}
+	}
+
+	if fit >= 0 {
+		return fit
+	}
+	return largest
+}
+
+// EstimateMonthlyCostDelta 估算调整 request 后每月的费用变化
+// cpuPrice 为每核每月单价, memPrice 为每 GiB 每月单价
+func EstimateMonthlyCostDelta(cpuDeltaCores, memDeltaBytes float64, podCount int, cpuPrice, memPrice float64) float64 {
+	return (cpuDeltaCores*cpuPrice + memDeltaBytes/rightSizingGiBBytes*memPrice) * float64(podCount)
+}
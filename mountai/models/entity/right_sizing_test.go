@@ -0,0 +1,60 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEntity_RecommendCPURequest(t *testing.T) {
+	t.Run("smallest fit", func(t *testing.T) {
+		res, err := RecommendCPURequest(CPUPrdRequestResourceList, 0.3, 0.2, CPUResourceLarge)
+		assert.Nil(t, err)
+		assert.Equal(t, CPUResourceType(CPUResourceSmall), res)
+	})
+
+	t.Run("exceeds all specs", func(t *testing.T) {
+		res, err := RecommendCPURequest(CPUPrdRequestResourceList, 3, 0.2, CPUResourceLarge)
+		assert.Nil(t, err)
+		assert.Equal(t, CPUResourceType(CPUResourceLarge), res)
+	})
+
+	t.Run("capped by limit", func(t *testing.T) {
+		res, err := RecommendCPURequest(CPUPrdRequestResourceList, 1.5, 0.2, CPUResourceMedium)
+		assert.Nil(t, err)
+		assert.Equal(t, CPUResourceType(CPUResourceMedium), res)
+	})
+
+	t.Run("invalid limit", func(t *testing.T) {
+		_, err := RecommendCPURequest(CPUPrdRequestResourceList, 0.3, 0.2, "abc")
+		assert.NotNil(t, err)
+	})
+}
+
+func TestEntity_RecommendMemRequest(t *testing.T) {
+	res, err := RecommendMemRequest(MemPrdRequestResourceList, 600*1024*1024, 0.2, MemResourceLarge)
+	assert.Nil(t, err)
+	assert.Equal(t, MemResourceType(MemResourceSmall), res)
+
+	res, err = RecommendMemRequest(MemStgRequestResourceList, 0, 0.2, MemResourceLarge)
+	assert.Nil(t, err)
+	assert.Equal(t, MemResourceType(MemResourcePico), res)
+}
+
+func TestEntity_EstimateMonthlyCostDelta(t *testing.T) {
+	delta := EstimateMonthlyCostDelta(-0.5, -1<<30, 4, 100, 20)
+	assert.InDelta(t, -280, delta, 1e-9)
+}
+
+func TestEntity_RightSizingRecommendation_NeedResize(t *testing.T) {
+	r := &RightSizingRecommendation{
+		CPURequest:            CPUResourceMedium,
+		MemRequest:            MemResourceSmall,
+		RecommendedCPURequest: CPUResourceMedium,
+		RecommendedMemRequest: MemResourceSmall,
+	}
+	assert.False(t, r.NeedResize())
+
+	r.RecommendedCPURequest = CPUResourceSmall
+	assert.True(t, r.NeedResize())
+}
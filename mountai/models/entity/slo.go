@@ -0,0 +1,117 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// SLODefaultWindowDays SLO 默认统计窗口
+	SLODefaultWindowDays = 28
+	// SLOMaxWindowDays SLO 最大统计窗口
+	SLOMaxWindowDays = 90
+	// SLOMaxCountPerAppEnv 每个应用环境的 SLO 最大数量
+	SLOMaxCountPerAppEnv = 10
+)
+
+// SLOBurnRateWindows 计算错误预算消耗速率的滚动窗口, 统计窗口本身总会参与计算
+var SLOBurnRateWindows = []time.Duration{time.Hour, 6 * time.Hour, 24 * time.Hour}
+
+// SLOType SLO 类型
+type SLOType string
+
+const (
+	// SLOTypeAvailability 可用性, 内置查询以 5xx 响应占比作为错误率
+	SLOTypeAvailability SLOType = "availability"
+	// SLOTypeLatency 延迟, 内置查询以 p99 延迟超过阈值的分钟占比作为错误率
+	SLOTypeLatency SLOType = "latency"
+)
+
+// SLO 应用服务等级目标
+type SLO struct {
+	ID      primitive.ObjectID `bson:"_id" json:"_id"`
+	AppID   string             `bson:"app_id" json:"app_id"`
+	EnvName AppEnvName         `bson:"env_name" json:"env_name"`
+	Name    string             `bson:"name" json:"name"`
+	Type    SLOType            `bson:"type" json:"type"`
+	// 目标达成率, 如 0.999
+	Objective float64 `bson:"objective" json:"objective"`
+	// 统计窗口 单位天
+	WindowDays int `bson:"window_days" json:"window_days"`
+	// p99 延迟阈值 单位毫秒, 仅延迟类型使用
+	LatencyThresholdMs float64 `bson:"latency_threshold_ms" json:"latency_threshold_ms"`
+	// 自定义 PromQL 模板, 需返回窗口内的错误率(0~1), 为空时使用内置查询
+	// 支持变量: {{.Namespace}} {{.PodRegex}} {{.Window}} {{.LatencyThresholdMs}}
+	ErrorRatioQuery string `bson:"error_ratio_query" json:"error_ratio_query"`
+	// 创建人id
+	OperatorID string `bson:"operator_id" json:"operator_id"`
+
+	CreateTime *time.Time `bson:"create_time" json:"create_time"`
+	UpdateTime *time.Time `bson:"update_time" json:"update_time"`
+	// 软删除
+	DeleteTime *time.Time `bson:"delete_time" json:"delete_time"`
+}
+
+func (*SLO) TableName() string {
+	return "slo"
+}
+
+func (s *SLO) GenerateObjectIDString(args map[string]interface{}) string {
+	return s.ID.Hex()
+}
+
+// Window 统计窗口时长
+func (s *SLO) Window() time.Duration {
+	windowDays := s.WindowDays
+	if windowDays <= 0 {
+		windowDays = SLODefaultWindowDays
+	}
+	return time.Duration(windowDays) * 24 * time.Hour
+}
+
+// ErrorBudget 统计窗口内允许的错误率
+func (s *SLO) ErrorBudget() float64 {
+	return 1 - s.Objective
+}
+
+// BurnRate 错误预算消耗速率, 1 表示按当前错误率统计窗口结束时恰好耗尽
+func (s *SLO) BurnRate(errorRatio float64) float64 {
+	budget := s.ErrorBudget()
+	if budget <= 0 {
+		if errorRatio > 0 {
+			return 1
+		}
+		return 0
+	}
+	return errorRatio / budget
+}
+
+// RemainingBudget 统计窗口内剩余错误预算比例, 小于等于0表示已耗尽
+func (s *SLO) RemainingBudget(errorRatio float64) float64 {
+	return 1 - s.BurnRate(errorRatio)
+}
+
+// SLOMetricTemplate SLO 错误率查询模版
+type SLOMetricTemplate struct {
+	// 命名空间正则, 同时匹配 istio 命名空间
+	Namespace string
+	// 应用 pod 名正则
+	PodRegex string
+	// 统计时间
+	Window string
+	// p99 延迟阈值 单位毫秒
+	LatencyThresholdMs float64
+}
+
+// FormatPromDuration 将时长格式化为 PromQL 时间范围
+func FormatPromDuration(d time.Duration) string {
+	if d%(24*time.Hour) == 0 {
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	}
+	if d%time.Hour == 0 {
+		return fmt.Sprintf("%dh", d/time.Hour)
+	}
+	return fmt.Sprintf("%ds", d/time.Second)
+}
@@ -0,0 +1,35 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEntity_SLO_ErrorBudget(t *testing.T) {
+	slo := &SLO{Objective: 0.99}
+
+	assert.InDelta(t, 0.01, slo.ErrorBudget(), 1e-9)
+	assert.InDelta(t, 0.5, slo.BurnRate(0.005), 1e-9)
+	assert.InDelta(t, 0.5, slo.RemainingBudget(0.005), 1e-9)
+	assert.InDelta(t, 0, slo.RemainingBudget(0.01), 1e-9)
+	assert.True(t, slo.RemainingBudget(0.02) < 0)
+
+	t.Run("no budget", func(t *testing.T) {
+		slo := &SLO{Objective: 1}
+		assert.Equal(t, float64(0), slo.BurnRate(0))
+		assert.Equal(t, float64(1), slo.BurnRate(0.001))
+	})
+}
+
+func TestEntity_SLO_Window(t *testing.T) {
+	assert.Equal(t, 28*24*time.Hour, (&SLO{}).Window())
+	assert.Equal(t, 7*24*time.Hour, (&SLO{WindowDays: 7}).Window())
+}
+
+func TestEntity_FormatPromDuration(t *testing.T) {
+	assert.Equal(t, "28d", FormatPromDuration(28*24*time.Hour))
+	assert.Equal(t, "6h", FormatPromDuration(6*time.Hour))
+	assert.Equal(t, "90s", FormatPromDuration(90*time.Second))
+}
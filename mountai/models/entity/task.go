@@ -39,6 +39,12 @@ const (
 	TaskActionDisableInClusterDNS TaskAction = "disable_in_cluster_dns"
 	// 恢复集群内DNS解析
 	TaskActionEnableInClusterDNS TaskAction = "enable_in_cluster_dns"
+	// 回滚至上一个成功版本
+	TaskActionRollback TaskAction = "rollback"
+	// 蓝绿部署
+	TaskActionBlueGreenDeploy TaskAction = "blue_green_deploy"
+	// 蓝绿部署切回旧版本
+	TaskActionBlueGreenSwitchBack TaskAction = "blue_green_switch_back"
 )
 
 // LaunchType specifies the launch type of a pod
@@ -100,6 +106,18 @@ const (
 	TaskStatusCreateAliLogConfigFinish TaskStatus = "create-ali_log_config-finish"
 	// TaskStatusCreateLogStoreIndexFinish 创建日志索引完成
 	TaskStatusCreateLogStoreIndexFinish TaskStatus = "create-log_store_index-finish"
+	// TaskStatusCreateSecretUnderway 创建密钥变量K8s Secret中
+	TaskStatusCreateSecretUnderway TaskStatus = "create-secret-underway"
+	// TaskStatusCreateSecretFinish 创建密钥变量K8s Secret阶段完成
+	TaskStatusCreateSecretFinish TaskStatus = "create-secret-finish"
+	// TaskStatusCreatePVCUnderway 创建持久化存储卷声明中
+	TaskStatusCreatePVCUnderway TaskStatus = "create-pvc-underway"
+	// TaskStatusCreatePVCFinish 创建持久化存储卷声明阶段完成
+	TaskStatusCreatePVCFinish TaskStatus = "create-pvc-finish"
+	// TaskStatusCreatePDBUnderway 创建K8s PodDisruptionBudget中
+	TaskStatusCreatePDBUnderway TaskStatus = "create-pdb-underway"
+	// TaskStatusCreatePDBFinish 创建K8s PodDisruptionBudget阶段完成
+	TaskStatusCreatePDBFinish TaskStatus = "create-pdb-finish"
 	// TaskStatusSyncColdStorageDeliverTaskFinish 投递任务同步完成
 	TaskStatusSyncColdStorageDeliverTaskFinish TaskStatus = "sync-cold_storage-deliver-task-finish"
 	// TaskStatusCreateK8sServiceUnderway 创建K8s Service中
@@ -162,6 +180,10 @@ const (
 	TaskStatusCleanHPAUnderway TaskStatus = "clean-hpa-underway"
 	// TaskStatusCleanHPAFinish 清理K8s HPA阶段完成
 	TaskStatusCleanHPAFinish TaskStatus = "clean-hpa-finish"
+	// TaskStatusCleanPDBUnderway 清理K8s PodDisruptionBudget中
+	TaskStatusCleanPDBUnderway TaskStatus = "clean-pdb-underway"
+	// TaskStatusCleanPDBFinish 清理K8s PodDisruptionBudget阶段完成
+	TaskStatusCleanPDBFinish TaskStatus = "clean-pdb-finish"
 	// TaskStatusCleanK8sServiceUnderway 清理K8s Service中
 	TaskStatusCleanK8sServiceUnderway TaskStatus = "clean-k8s_service-underway"
 	// TaskStatusCleanK8sServiceFinish 清理K8s Service阶段完成
@@ -218,6 +240,51 @@ const (
 	TaskStatusUpdateInClusterDNSUnderway TaskStatus = "update-incluster-dns-underway"
 	// TaskStatusUpdateInClusterDNSFinish 集群内DNS解析更新完成
 	TaskStatusUpdateInClusterDNSFinish TaskStatus = "update-incluster-dns-finish"
+
+	// TaskStatusCanaryStepScaleUnderway 渐进式金丝雀扩容至当前步骤实例数中
+	TaskStatusCanaryStepScaleUnderway TaskStatus = "canary-step_scale-underway"
+	// TaskStatusCanaryStepBakeUnderway 渐进式金丝雀当前步骤观察中
+	TaskStatusCanaryStepBakeUnderway TaskStatus = "canary-step_bake-underway"
+	// TaskStatusCanaryAnalysisFinish 渐进式金丝雀所有步骤通过
+	TaskStatusCanaryAnalysisFinish TaskStatus = "canary-analysis-finish"
+	// TaskStatusCanaryAbortUnderway 渐进式金丝雀未通过, 清理金丝雀部署中
+	TaskStatusCanaryAbortUnderway TaskStatus = "canary-abort-underway"
+
+	// TaskStatusCreateFullStatefulSetUnderway 可插拔工作负载
+	// K8s StatefulSet 创建中
+	TaskStatusCreateFullStatefulSetUnderway TaskStatus = "create-full_statefulset-underway"
+	// TaskStatusCreateFullStatefulSetFinish K8s StatefulSet 创建阶段完成
+	TaskStatusCreateFullStatefulSetFinish TaskStatus = "create-full_statefulset-finish"
+	// TaskStatusRestartStatefulSetUnderway 重启K8s StatefulSet中
+	TaskStatusRestartStatefulSetUnderway TaskStatus = "restart-statefulset-underway"
+	// TaskStatusRestartStatefulSetFinish 重启K8s StatefulSet阶段完成
+	TaskStatusRestartStatefulSetFinish TaskStatus = "restart-statefulset-finish"
+	// TaskStatusUpdateStatefulSetScaleUnderway 更新K8s StatefulSet实例数中
+	TaskStatusUpdateStatefulSetScaleUnderway TaskStatus = "update-statefulset_scale-underway"
+	// TaskStatusUpdateStatefulSetScaleFinish 更新K8s StatefulSet实例数阶段完成
+	TaskStatusUpdateStatefulSetScaleFinish TaskStatus = "update-statefulset_scale-finish"
+	// TaskStatusCleanStatefulSetUnderway 清理K8s StatefulSet中
+	TaskStatusCleanStatefulSetUnderway TaskStatus = "clean-statefulset-underway"
+	// TaskStatusCleanStatefulSetFinish 清理K8s StatefulSet阶段完成
+	TaskStatusCleanStatefulSetFinish TaskStatus = "clean-statefulset-finish"
+	// TaskStatusCreateFullDaemonSetUnderway K8s DaemonSet 创建中
+	TaskStatusCreateFullDaemonSetUnderway TaskStatus = "create-full_daemonset-underway"
+	// TaskStatusCreateFullDaemonSetFinish K8s DaemonSet 创建阶段完成
+	TaskStatusCreateFullDaemonSetFinish TaskStatus = "create-full_daemonset-finish"
+	// TaskStatusRestartDaemonSetUnderway 重启K8s DaemonSet中
+	TaskStatusRestartDaemonSetUnderway TaskStatus = "restart-daemonset-underway"
+	// TaskStatusRestartDaemonSetFinish 重启K8s DaemonSet阶段完成
+	TaskStatusRestartDaemonSetFinish TaskStatus = "restart-daemonset-finish"
+	// TaskStatusCleanDaemonSetUnderway 清理K8s DaemonSet中
+	TaskStatusCleanDaemonSetUnderway TaskStatus = "clean-daemonset-underway"
+	// TaskStatusCleanDaemonSetFinish 清理K8s DaemonSet阶段完成
+	TaskStatusCleanDaemonSetFinish TaskStatus = "clean-daemonset-finish"
+
+	// TaskStatusBlueGreenSwitchUnderway 蓝绿部署流量切换中
+	TaskStatusBlueGreenSwitchUnderway TaskStatus = "blue_green-switch-underway"
+	// TaskStatusBlueGreenSwitchFinish 蓝绿部署流量切换完成
+	TaskStatusBlueGreenSwitchFinish TaskStatus = "blue_green-switch-finish"
+
 	// TaskStatusSuccess 最终态
 	// 成功
 	TaskStatusSuccess TaskStatus = "success"
@@ -259,6 +326,15 @@ var (
 	TaskActionInitDeployList = []TaskAction{
 		TaskActionFullDeploy,
 		TaskActionCanaryDeploy,
+		TaskActionBlueGreenDeploy,
+	}
+	// TaskActionFinalVersionList 可作为最终部署版本的行为列表
+	TaskActionFinalVersionList = []TaskAction{
+		TaskActionFullDeploy,
+		TaskActionCanaryDeploy,
+		TaskActionRollback,
+		TaskActionBlueGreenDeploy,
+		TaskActionBlueGreenSwitchBack,
 	}
 	// TaskActionSystemList 系统行为列表
 	TaskActionSystemList = []TaskAction{
@@ -319,6 +395,20 @@ var (
 		TaskActionFullDeploy,
 		TaskActionDelete,
 	}
+	// TaskActionStatefulSetBatchList StatefulSet批量操作相关行为列表
+	TaskActionStatefulSetBatchList = []TaskAction{
+		TaskActionFullDeploy,
+		TaskActionStop,
+		TaskActionRestart,
+		TaskActionDelete,
+		TaskActionResume,
+	}
+	// TaskActionDaemonSetBatchList DaemonSet批量操作相关行为列表
+	TaskActionDaemonSetBatchList = []TaskAction{
+		TaskActionFullDeploy,
+		TaskActionRestart,
+		TaskActionDelete,
+	}
 )
 
 // TaskActionList the list of action
@@ -341,6 +431,11 @@ var (
 		TaskActionEnableInClusterDNS,
 		TaskActionDisableInClusterDNS,
 	}
+	// TaskActionBlueGreenList 蓝绿部署相关的操作
+	TaskActionBlueGreenList = &TaskActionList{
+		TaskActionBlueGreenDeploy,
+		TaskActionBlueGreenSwitchBack,
+	}
 )
 
 // Task 任务
@@ -377,6 +472,16 @@ type Task struct {
 	Param *TaskParam `bson:"param" json:"param"`
 	// 命名空间
 	Namespace string `bson:"namespace" json:"namespace"`
+	// 关联任务id, 如回滚任务对应的失败任务
+	RelatedTaskID string `bson:"related_task_id" json:"related_task_id"`
+	// 渐进式金丝雀每一步的判定结果
+	CanaryVerdicts []*CanaryStepVerdict `bson:"canary_verdicts" json:"canary_verdicts"`
+	// 蓝绿部署切换前的运行版本, 保留期内可切回
+	PreviousVersion string `bson:"previous_version" json:"previous_version"`
+	// 封网期间强制变更的原因
+	FreezeOverrideReason string `bson:"freeze_override_reason" json:"freeze_override_reason"`
+	// 错误预算耗尽时强制部署的原因
+	ErrorBudgetOverrideReason string `bson:"error_budget_override_reason" json:"error_budget_override_reason"`
 	// 是否暂停
 	Suspend    bool       `bson:"suspend" json:"suspend"`
 	CreateTime *time.Time `bson:"create_time" json:"create_time"`
@@ -455,6 +560,12 @@ func GetTaskActionDisplay(action TaskAction) string {
 		return "禁用集群DNS解析"
 	case TaskActionEnableInClusterDNS:
 		return "启用集群DNS解析"
+	case TaskActionRollback:
+		return "回滚"
+	case TaskActionBlueGreenDeploy:
+		return "蓝绿部署"
+	case TaskActionBlueGreenSwitchBack:
+		return "蓝绿切回"
 	default:
 		return "未知"
 	}
@@ -502,6 +613,18 @@ func GetTaskStatusDisplay(action TaskAction, status TaskStatus, suspend bool) st
 		return "日志配置创建阶段结束"
 	case TaskStatusCreateLogStoreIndexFinish:
 		return "日志索引创建完成"
+	case TaskStatusCreateSecretUnderway:
+		return "密钥变量Secret创建中"
+	case TaskStatusCreateSecretFinish:
+		return "密钥变量Secret创建阶段完成"
+	case TaskStatusCreatePVCUnderway:
+		return "持久化存储卷声明创建中"
+	case TaskStatusCreatePVCFinish:
+		return "持久化存储卷声明创建阶段完成"
+	case TaskStatusCreatePDBUnderway:
+		return "PodDisruptionBudget创建中"
+	case TaskStatusCreatePDBFinish:
+		return "PodDisruptionBudget创建阶段完成"
 	case TaskStatusSyncColdStorageDeliverTaskFinish:
 		return "日志冷存投递任务同步完成"
 	case TaskStatusCreateK8sServiceUnderway:
@@ -524,6 +647,10 @@ func GetTaskStatusDisplay(action TaskAction, status TaskStatus, suspend bool) st
 		return "清理HPA中"
 	case TaskStatusCleanHPAFinish:
 		return "清理HPA阶段完成"
+	case TaskStatusCleanPDBUnderway:
+		return "清理PodDisruptionBudget中"
+	case TaskStatusCleanPDBFinish:
+		return "清理PodDisruptionBudget阶段完成"
 	case TaskStatusCreateCronHPAUnderway:
 		return "cronHPA创建中"
 	case TaskStatusCreateCronHPAFinish:
@@ -588,6 +715,22 @@ func GetTaskStatusDisplay(action TaskAction, status TaskStatus, suspend bool) st
 		return "重启部署中"
 	case TaskStatusRestartDeploymentFinish:
 		return "重启部署阶段完成"
+	case TaskStatusCreateFullStatefulSetUnderway, TaskStatusCreateFullDaemonSetUnderway:
+		return "全量部署创建中"
+	case TaskStatusCreateFullStatefulSetFinish, TaskStatusCreateFullDaemonSetFinish:
+		return "全量部署创建阶段结束"
+	case TaskStatusRestartStatefulSetUnderway, TaskStatusRestartDaemonSetUnderway:
+		return "重启部署中"
+	case TaskStatusRestartStatefulSetFinish, TaskStatusRestartDaemonSetFinish:
+		return "重启部署阶段完成"
+	case TaskStatusUpdateStatefulSetScaleUnderway:
+		return "更新部署实例数中"
+	case TaskStatusUpdateStatefulSetScaleFinish:
+		return "更新部署实例数阶段完成"
+	case TaskStatusCleanStatefulSetUnderway, TaskStatusCleanDaemonSetUnderway:
+		return "清理部署中"
+	case TaskStatusCleanStatefulSetFinish, TaskStatusCleanDaemonSetFinish:
+		return "清理部署阶段完成"
 	case TaskStatusSuccess:
 		if action == TaskActionStop {
 			return "暂停成功"
@@ -617,6 +760,18 @@ func GetTaskStatusDisplay(action TaskAction, status TaskStatus, suspend bool) st
 		return "更新 Kong upstream 中"
 	case TaskStatusUpdateKongUpstreamFinish:
 		return "更新 Kong upstream 完成"
+	case TaskStatusCanaryStepScaleUnderway:
+		return "金丝雀扩容中"
+	case TaskStatusCanaryStepBakeUnderway:
+		return "金丝雀指标观察中"
+	case TaskStatusCanaryAnalysisFinish:
+		return "金丝雀指标校验通过"
+	case TaskStatusCanaryAbortUnderway:
+		return "金丝雀指标未通过, 清理中"
+	case TaskStatusBlueGreenSwitchUnderway:
+		return "蓝绿流量切换中"
+	case TaskStatusBlueGreenSwitchFinish:
+		return "蓝绿流量切换完成"
 	default:
 		return "未知"
 	}
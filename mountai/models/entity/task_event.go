@@ -0,0 +1,51 @@
+package entity
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"time"
+)
+
+// 任务状态流转事件, 记录每一次状态变化及出错重试
+type TaskEvent struct {
+	ID          primitive.ObjectID `bson:"_id" json:"_id"`
+	TaskID      string             `bson:"task_id" json:"task_id"`
+	AppID       string             `bson:"app_id" json:"app_id"`
+	ProjectID   string             `bson:"project_id" json:"project_id"`
+	EnvName     AppEnvName         `bson:"env_name" json:"env_name"`
+	ClusterName ClusterName        `bson:"cluster_name" json:"cluster_name"`
+	Action      TaskAction         `bson:"action" json:"action"`
+	// 出错时 FromStatus 与 ToStatus 相同
+	FromStatus TaskStatus `bson:"from_status" json:"from_status"`
+	ToStatus   TaskStatus `bson:"to_status" json:"to_status"`
+	// 处理该事件的 worker 实例
+	Worker     string `bson:"worker" json:"worker"`
+	Error      string `bson:"error" json:"error"`
+	RetryCount int    `bson:"retry_count" json:"retry_count"`
+	// FromStatus 阶段的耗时(毫秒), 从上一次状态变化开始计算
+	DurationMs int64 `bson:"duration_ms" json:"duration_ms"`
+	// 本次流转涉及的 K8s 对象版本, key 为 Kind/Name, value 为 resourceVersion
+	ObjectVersions map[string]string `bson:"object_versions" json:"object_versions"`
+	CreateTime     *time.Time        `bson:"create_time" json:"create_time"`
+}
+
+func (*TaskEvent) TableName() string {
+	return "task_event"
+}
+
+// IsTransition 是否为状态变化事件
+func (e *TaskEvent) IsTransition() bool {
+	return e.FromStatus != e.ToStatus
+}
+
+// TaskPhaseStat 任务阶段耗时统计
+type TaskPhaseStat struct {
+	ProjectID     string      `bson:"project_id" json:"project_id"`
+	EnvName       AppEnvName  `bson:"env_name" json:"env_name"`
+	ClusterName   ClusterName `bson:"cluster_name" json:"cluster_name"`
+	Action        TaskAction  `bson:"action" json:"action"`
+	Status        TaskStatus  `bson:"status" json:"status"`
+	Count         int         `bson:"count" json:"count"`
+	AvgDurationMs float64     `bson:"avg_duration_ms" json:"avg_duration_ms"`
+	MaxDurationMs int64       `bson:"max_duration_ms" json:"max_duration_ms"`
+}
@@ -109,6 +109,8 @@ type TaskParam struct {
 	ConfigRenameMode ConfigRenameMode `bson:"config_rename_mode" json:"config_rename_mode"`
 	// 环境变量
 	Vars map[string]string `bson:"vars" json:"vars"`
+	// 注入为环境变量的项目密钥变量名, 部署时写入按版本创建的 K8s Secret
+	SecretVars []string `bson:"secret_vars" json:"secret_vars"`
 	// 应用配置挂载路径
 	ConfigMountPath string `bson:"config_mount_path" json:"config_mount_path"`
 	// create oss storage to store log no time limits
@@ -148,7 +150,7 @@ type TaskParam struct {
 	// 因为所有k8s节点至少都会标记有 importance 标签
 	// 所以亲和性标签至少必须设置 importance，且必须为允许的枚举值
 	NodeAffinityLabelConfig NodeAffinityLabelConfig `bson:"node_affinity_label_config" json:"node_affinity_label_config"`
-	// 关闭pod反亲和性
+	// 关闭pod反亲和性, 拓扑分布约束及 PodDisruptionBudget
 	DisableHighAvailability bool `bson:"disable_high_availability" json:"disable_high_availability"`
 	// 关闭金丝雀发布
 	DisableCanary bool `bson:"disable_canary" json:"disable_canary"`
@@ -172,6 +174,23 @@ type TaskParam struct {
 	CronScaleJobGroups []*CronScaleJobGroup `bson:"cron_scale_job_groups" json:"cron_scale_job_groups"`
 	// 定时扩缩容排除日期，五位时间模板，最小粒度为"天"，更小粒度填充"*"
 	CronScaleJobExcludeDates []string `bson:"cron_scale_job_exclude_dates" json:"cron_scale_job_exclude_dates"`
+	// 渐进式金丝雀配置, 为空时使用普通金丝雀发布
+	ProgressiveCanary *ProgressiveCanaryConfig `bson:"progressive_canary" json:"progressive_canary"`
+	// 蓝绿部署旧版本保留时长 单位秒, 为0时使用默认值
+	BlueGreenKeepWarmSeconds int `bson:"blue_green_keep_warm_seconds" json:"blue_green_keep_warm_seconds"`
+	// 自定义扩缩容指标, 与 CPU/内存使用率共同生效
+	HPAMetrics []*HPAMetricSpec `bson:"hpa_metrics" json:"hpa_metrics"`
+	// 扩缩容行为策略
+	HPABehavior *HPABehavior `bson:"hpa_behavior" json:"hpa_behavior"`
+	// 初始化容器, 在主容器启动前依次执行
+	InitContainers []*ExtraContainer `bson:"init_containers" json:"init_containers"`
+	// 边车容器, 与主容器一同运行
+	// 一次性任务及定时任务的边车容器需在主容器结束后自行退出, 否则任务无法完成
+	Sidecars []*ExtraContainer `bson:"sidecars" json:"sidecars"`
+	// 存储卷
+	Volumes []*AppVolume `bson:"volumes" json:"volumes"`
+	// 删除最后一个部署或清理应用时是否删除持久化存储卷, 默认保留, 仅删除及清理操作使用
+	DeleteVolumes bool `bson:"delete_volumes" json:"delete_volumes"`
 
 	// 执行命令
 	CronCommand string `bson:"cron_command" json:"cron_command"`
@@ -214,6 +233,8 @@ type TaskParam struct {
 	CleanedAliLogConfigName string `bson:"cleaned_ali_log_config_name,omitempty" json:"cleaned_ali_log_config_name,omitempty"`
 	// 清理的阿里云日志仓库名
 	CleanedAliLogStoreName string `bson:"cleaned_ali_log_store_name,omitempty" json:"cleaned_ali_log_store_name,omitempty"`
+	// 清理的版本, 不为空时只清理该版本的资源(蓝绿部署闲置版本)
+	CleanedVersion string `bson:"cleaned_version,omitempty" json:"cleaned_version,omitempty"`
 }
 
 // CronScaleJobGroup 扩缩容任务组
@@ -2,6 +2,7 @@ package entity
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -23,3 +24,49 @@ func TestEntity_TaskActionList(t *testing.T) {
 		assert.True(t, list.Contains(TaskActionCanaryDeploy))
 	})
 }
+
+func TestEntity_TaskActionRollback(t *testing.T) {
+	assert.Equal(t, "回滚", GetTaskActionDisplay(TaskActionRollback))
+	assert.Equal(t, SubscribeActionRollback, TransformSubscribeAction(TaskActionRollback))
+
+	list := TaskActionList(TaskActionFinalVersionList)
+	assert.True(t, list.Contains(TaskActionRollback))
+	assert.False(t, list.Contains(TaskActionUpdateHPA))
+}
+
+func TestEntity_ProgressiveCanaryConfig(t *testing.T) {
+	cfg := &ProgressiveCanaryConfig{Steps: 3}
+	assert.Equal(t, 2, cfg.GetStepPodCount(2))
+
+	cfg.StepPodCount = 2
+	assert.Equal(t, 6, cfg.GetStepPodCount(3))
+
+	t.Run("bake", func(t *testing.T) {
+		verdicts := []*CanaryStepVerdict{{Step: 1, Passed: true}}
+		step := cfg.GetCurrentStep(verdicts)
+		assert.Equal(t, 2, step)
+		assert.Equal(t, TaskStatusCanaryStepScaleUnderway, cfg.GetBakeNextStatus(&CanaryStepVerdict{Step: step, Passed: true}))
+	})
+
+	t.Run("promote", func(t *testing.T) {
+		verdicts := []*CanaryStepVerdict{{Step: 1, Passed: true}, {Step: 2, Passed: true}}
+		step := cfg.GetCurrentStep(verdicts)
+		assert.Equal(t, 3, step)
+		assert.Equal(t, TaskStatusCanaryAnalysisFinish, cfg.GetBakeNextStatus(&CanaryStepVerdict{Step: step, Passed: true}))
+	})
+
+	t.Run("abort", func(t *testing.T) {
+		assert.Equal(t, 1, cfg.GetCurrentStep(nil))
+		assert.Equal(t, TaskStatusCanaryAbortUnderway, cfg.GetBakeNextStatus(&CanaryStepVerdict{Step: 1}))
+		assert.Equal(t, TaskStatusCanaryAbortUnderway, cfg.GetBakeNextStatus(&CanaryStepVerdict{Step: 3}))
+	})
+}
+
+func TestEntity_BlueGreen(t *testing.T) {
+	assert.Equal(t, "p-a-blue", GetBlueGreenTargetVersion("p-a", "p-a"))
+	assert.Equal(t, "p-a-green", GetBlueGreenTargetVersion("p-a", "p-a-blue"))
+	assert.Equal(t, "p-a-blue", GetBlueGreenTargetVersion("p-a", "p-a-green"))
+
+	assert.Equal(t, time.Duration(BlueGreenDefaultKeepWarmSeconds)*time.Second, GetBlueGreenKeepWarmDuration(0))
+	assert.Equal(t, time.Minute, GetBlueGreenKeepWarmDuration(60))
+}
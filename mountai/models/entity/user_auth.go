@@ -30,6 +30,10 @@ const (
 	OperateTypeSetAppClusterKongWeights OperateType = "setAppClusterKongWeights"
 	// 删除job操作类型
 	OperateTypeDeleteJob OperateType = "deleteJob"
+	// 管理项目通知渠道及规则类型
+	OperateTypeManageNotification OperateType = "manageNotification"
+	// 管理应用 SLO 类型
+	OperateTypeManageSLO OperateType = "manageSLO"
 )
 
 const (
@@ -36,8 +36,25 @@ const (
 
 	// SubscribeActionReloadConfig
 	SubscribeActionReloadConfig SubscribeAction = "reload_config"
+
+	// SubscribeActionRollback 回滚
+	SubscribeActionRollback SubscribeAction = "rollback"
 )
 
+// SubscribeActions 所有订阅操作类型
+var SubscribeActions = []SubscribeAction{
+	SubscribeActionDeploy,
+	SubscribeActionStop,
+	SubscribeActionRestart,
+	SubscribeActionResume,
+	SubscribeActionDelete,
+	SubscribeActionClean,
+	SubscribeActionManualLaunch,
+	SubscribeActionUpdateHPA,
+	SubscribeActionReloadConfig,
+	SubscribeActionRollback,
+}
+
 // SubscribeEventMsg kafka消息
 type SubscribeEventMsg struct {
 	ActionType SubscribeAction `json:"action_type"`
@@ -90,6 +107,8 @@ func GetSubscribeActionDisplay(action SubscribeAction) string {
 		return "弹性伸缩"
 	case SubscribeActionReloadConfig:
 		return "热加载配置"
+	case SubscribeActionRollback:
+		return "回滚"
 	default:
 		return "未知"
 	}
@@ -98,7 +117,7 @@ func GetSubscribeActionDisplay(action SubscribeAction) string {
 // TransformSubscribeAction transform subscription action
 func TransformSubscribeAction(taskAction TaskAction) SubscribeAction {
 	switch taskAction {
-	case TaskActionFullDeploy, TaskActionCanaryDeploy, TaskActionFullCanaryDeploy:
+	case TaskActionFullDeploy, TaskActionCanaryDeploy, TaskActionFullCanaryDeploy, TaskActionBlueGreenDeploy:
 		return SubscribeActionDeploy
 	case TaskActionStop:
 		return SubscribeActionStop
@@ -114,6 +133,8 @@ func TransformSubscribeAction(taskAction TaskAction) SubscribeAction {
 		return SubscribeActionUpdateHPA
 	case TaskActionReloadConfig:
 		return SubscribeActionReloadConfig
+	case TaskActionRollback, TaskActionBlueGreenSwitchBack:
+		return SubscribeActionRollback
 	default:
 		return "unknown"
 	}
@@ -3,6 +3,7 @@ package entity
 import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 
+	"regexp"
 	"time"
 )
 
@@ -12,6 +13,17 @@ const (
 	ProjectVariableType VariableType = iota + 1
 )
 
+// SecretVariableMask 密钥变量值的掩码, 密钥变量值不会通过接口返回
+const SecretVariableMask = "******"
+
+// 密钥变量需注入为环境变量, 变量名需符合环境变量命名规范
+var secretVariableKeyRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// IsValidSecretVariableKey 校验密钥变量名
+func IsValidSecretVariableKey(key string) bool {
+	return secretVariableKeyRegexp.MatchString(key)
+}
+
 // 变量 (目前用于渲染镜像参数模版)
 type Variable struct {
 	ID primitive.ObjectID `bson:"_id" json:"_id"`
@@ -25,8 +37,12 @@ type Variable struct {
 	Type VariableType `bson:"type" json:"type"`
 	// 变量名
 	Key string `bson:"key" json:"key"`
-	// 变量值
+	// 变量值, 密钥变量为信封加密后的密文
 	Value string `bson:"value" json:"value"`
+	// 是否为密钥变量
+	Secret bool `bson:"secret" json:"secret"`
+	// 密钥变量被主密钥加密的数据密钥
+	EncryptedDataKey string `bson:"encrypted_data_key" json:"-"`
 
 	CreateTime *time.Time `bson:"create_time" json:"create_time"`
 	UpdateTime *time.Time `bson:"update_time" json:"update_time"`
@@ -37,3 +53,29 @@ type Variable struct {
 func (*Variable) TableName() string {
 	return "variable"
 }
+
+// VariableAuditAction 变量审计行为
+type VariableAuditAction string
+
+const (
+	// VariableAuditActionDeploy 部署时读取密钥变量
+	VariableAuditActionDeploy VariableAuditAction = "deploy"
+)
+
+// VariableAudit 密钥变量读取审计记录
+type VariableAudit struct {
+	ID         primitive.ObjectID  `bson:"_id" json:"_id"`
+	VariableID string              `bson:"variable_id" json:"variable_id"`
+	ProjectID  string              `bson:"project_id" json:"project_id"`
+	Key        string              `bson:"key" json:"key"`
+	Action     VariableAuditAction `bson:"action" json:"action"`
+	// 读取的发起人, 部署时为任务操作人
+	OperatorID string `bson:"operator_id" json:"operator_id"`
+	// 关联的任务id
+	TaskID     string     `bson:"task_id" json:"task_id"`
+	CreateTime *time.Time `bson:"create_time" json:"create_time"`
+}
+
+func (*VariableAudit) TableName() string {
+	return "variable_audit"
+}
@@ -0,0 +1,15 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEntity_IsValidSecretVariableKey(t *testing.T) {
+	assert.True(t, IsValidSecretVariableKey("DB_PASSWORD"))
+	assert.True(t, IsValidSecretVariableKey("_token1"))
+	assert.False(t, IsValidSecretVariableKey("1TOKEN"))
+	assert.False(t, IsValidSecretVariableKey("db-password"))
+	assert.False(t, IsValidSecretVariableKey(""))
+}
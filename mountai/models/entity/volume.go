@@ -0,0 +1,213 @@
+package entity
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// MaxAppVolumes 应用存储卷的最大数量
+const MaxAppVolumes = 10
+
+// AppVolumeType 应用存储卷类型
+type AppVolumeType string
+
+const (
+	// AppVolumeTypePVC 持久化存储卷, 同一应用的各版本共用, 删除最后一个部署或清理应用时按需删除
+	AppVolumeTypePVC AppVolumeType = "pvc"
+	// AppVolumeTypeEmptyDir 临时存储卷, 随 pod 销毁
+	AppVolumeTypeEmptyDir AppVolumeType = "empty_dir"
+	// AppVolumeTypeSecret 投射的 Secret 存储卷
+	AppVolumeTypeSecret AppVolumeType = "secret"
+)
+
+// reservedVolumeNames 模板中已使用的存储卷名
+var reservedVolumeNames = map[string]bool{
+	"tz-config":  true,
+	"tz-info":    true,
+	"app-config": true,
+}
+
+// reservedMountPaths 模板中已使用的挂载路径
+var reservedMountPaths = map[string]bool{
+	"/etc/localtime":      true,
+	"/usr/share/zoneinfo": true,
+}
+
+// AppVolume 应用存储卷
+type AppVolume struct {
+	// 存储卷名, 同一应用内唯一
+	Name string        `bson:"name" json:"name"`
+	Type AppVolumeType `bson:"type" json:"type"`
+	// 主容器挂载路径
+	MountPath string `bson:"mount_path" json:"mount_path"`
+	// 是否只读挂载
+	ReadOnly bool `bson:"read_only" json:"read_only"`
+	// 同时挂载该存储卷的附加容器名, 挂载路径与主容器相同
+	Containers []string `bson:"containers" json:"containers"`
+
+	// 存储类, 仅 pvc 使用, 为空时使用集群默认存储类
+	StorageClassName string `bson:"storage_class_name" json:"storage_class_name"`
+	// 容量, k8s quantity 格式, 如 "10Gi", 仅 pvc 使用
+	Size string `bson:"size" json:"size"`
+	// 访问模式, 仅 pvc 使用
+	// 部署时新旧版本 pod 同时挂载, 常驻服务及后台任务不支持 ReadWriteOnce, 需使用 ReadWriteMany 或 ReadOnlyMany
+	AccessMode v1.PersistentVolumeAccessMode `bson:"access_mode" json:"access_mode"`
+
+	// 容量上限, 仅 empty_dir 使用, 为空表示不限制
+	SizeLimit string `bson:"size_limit" json:"size_limit"`
+	// 存储介质, 仅 empty_dir 使用, Memory 表示使用内存
+	Medium v1.StorageMedium `bson:"medium" json:"medium"`
+
+	// 投射的 Secret 名, 仅 secret 使用
+	SecretNames []string `bson:"secret_names" json:"secret_names"`
+}
+
+// Validate 校验存储卷配置
+func (v *AppVolume) Validate() error {
+	if errs := validation.IsDNS1123Label(v.Name); len(errs) > 0 {
+		return errors.Errorf("volume name %s is invalid: %s", v.Name, strings.Join(errs, ","))
+	}
+	if reservedVolumeNames[v.Name] {
+		return errors.Errorf("volume name %s is reserved", v.Name)
+	}
+	if !strings.HasPrefix(v.MountPath, "/") {
+		return errors.Errorf("mount path of volume %s should be absolute", v.Name)
+	}
+	if reservedMountPaths[v.MountPath] {
+		return errors.Errorf("mount path %s of volume %s is reserved", v.MountPath, v.Name)
+	}
+
+	switch v.Type {
+	case AppVolumeTypePVC:
+		size, err := resource.ParseQuantity(v.Size)
+		if err != nil {
+			return errors.Errorf("size of volume %s is invalid: %s", v.Name, err)
+		}
+		if size.Sign() <= 0 {
+			return errors.Errorf("size of volume %s should be positive", v.Name)
+		}
+
+		switch v.AccessMode {
+		case v1.ReadWriteOnce, v1.ReadOnlyMany, v1.ReadWriteMany:
+		default:
+			return errors.Errorf("access mode %s of volume %s is not supported", v.AccessMode, v.Name)
+		}
+	case AppVolumeTypeEmptyDir:
+		if v.SizeLimit != "" {
+			limit, err := resource.ParseQuantity(v.SizeLimit)
+			if err != nil {
+				return errors.Errorf("size limit of volume %s is invalid: %s", v.Name, err)
+			}
+			if limit.Sign() <= 0 {
+				return errors.Errorf("size limit of volume %s should be positive", v.Name)
+			}
+		}
+
+		if v.Medium != v1.StorageMediumDefault && v.Medium != v1.StorageMediumMemory {
+			return errors.Errorf("medium %s of volume %s is not supported", v.Medium, v.Name)
+		}
+	case AppVolumeTypeSecret:
+		if len(v.SecretNames) == 0 {
+			return errors.Errorf("secret names of volume %s is empty", v.Name)
+		}
+		for _, name := range v.SecretNames {
+			if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+				return errors.Errorf("secret name %s of volume %s is invalid", name, v.Name)
+			}
+		}
+	default:
+		return errors.Errorf("type %s of volume %s is not supported", v.Type, v.Name)
+	}
+
+	return nil
+}
+
+// ValidateAppVolumes 校验应用存储卷, 存储卷名及挂载路径不能重复, 挂载的附加容器需存在
+func ValidateAppVolumes(volumes []*AppVolume, initContainers, sidecars []*ExtraContainer) error {
+	if len(volumes) > MaxAppVolumes {
+		return errors.Errorf("volumes should not be more than %d", MaxAppVolumes)
+	}
+
+	containers := make(map[string]bool)
+	for _, list := range [][]*ExtraContainer{initContainers, sidecars} {
+		for _, c := range list {
+			if c != nil {
+				containers[c.Name] = true
+			}
+		}
+	}
+
+	names, mountPaths := make(map[string]bool), make(map[string]bool)
+	for _, v := range volumes {
+		if v == nil {
+			return errors.New("volume is empty")
+		}
+		if names[v.Name] {
+			return errors.Errorf("volume name %s is duplicated", v.Name)
+		}
+		names[v.Name] = true
+		if mountPaths[v.MountPath] {
+			return errors.Errorf("mount path %s is duplicated", v.MountPath)
+		}
+		mountPaths[v.MountPath] = true
+
+		if err := v.Validate(); err != nil {
+			return err
+		}
+
+		for _, name := range v.Containers {
+			if !containers[name] {
+				return errors.Errorf("container %s of volume %s is not found", name, v.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ValidateAppVolumeAccessModes 校验 pvc 访问模式
+// 常驻服务及后台任务部署时新版本 Deployment 与旧版本同时运行, 调度到不同节点时 ReadWriteOnce 的 pvc 无法挂载(Multi-Attach)
+func ValidateAppVolumeAccessModes(appType AppType, volumes []*AppVolume) error {
+	if appType != AppTypeService && appType != AppTypeWorker {
+		return nil
+	}
+
+	for _, v := range volumes {
+		if v != nil && v.Type == AppVolumeTypePVC && v.AccessMode == v1.ReadWriteOnce {
+			return errors.Errorf("access mode of volume %s should be %s or %s for %s",
+				v.Name, v1.ReadWriteMany, v1.ReadOnlyMany, appType)
+		}
+	}
+
+	return nil
+}
+
+// VolumeTemplate 存储卷渲染模版
+type VolumeTemplate struct {
+	// 存储卷名
+	Name string
+	// 类型
+	Type AppVolumeType
+	// pvc 名
+	ClaimName string
+	// 临时存储卷容量上限
+	SizeLimit string
+	// 临时存储卷存储介质
+	Medium v1.StorageMedium
+	// 投射的 Secret 名
+	SecretNames []string
+}
+
+// VolumeMountTemplate 存储卷挂载渲染模版
+type VolumeMountTemplate struct {
+	// 存储卷名
+	Name string
+	// 挂载路径
+	MountPath string
+	// 是否只读
+	ReadOnly bool
+}
@@ -0,0 +1,88 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestEntity_AppVolume_Validate(t *testing.T) {
+	t.Run("pvc", func(t *testing.T) {
+		v := &AppVolume{Name: "data", Type: AppVolumeTypePVC, MountPath: "/data", Size: "10Gi", AccessMode: v1.ReadWriteOnce}
+		assert.Nil(t, v.Validate())
+
+		v.Size = "0"
+		assert.NotNil(t, v.Validate())
+
+		v.Size, v.AccessMode = "10Gi", "ReadWriteSometimes"
+		assert.NotNil(t, v.Validate())
+	})
+
+	t.Run("empty dir", func(t *testing.T) {
+		v := &AppVolume{Name: "scratch", Type: AppVolumeTypeEmptyDir, MountPath: "/tmp/scratch"}
+		assert.Nil(t, v.Validate())
+
+		v.Medium, v.SizeLimit = v1.StorageMediumMemory, "512Mi"
+		assert.Nil(t, v.Validate())
+
+		v.SizeLimit = "abc"
+		assert.NotNil(t, v.Validate())
+	})
+
+	t.Run("secret", func(t *testing.T) {
+		v := &AppVolume{Name: "certs", Type: AppVolumeTypeSecret, MountPath: "/etc/certs", ReadOnly: true}
+		assert.NotNil(t, v.Validate())
+
+		v.SecretNames = []string{"tls-cert", "ca-cert"}
+		assert.Nil(t, v.Validate())
+	})
+
+	t.Run("reserved", func(t *testing.T) {
+		v := &AppVolume{Name: "tz-info", Type: AppVolumeTypeEmptyDir, MountPath: "/data"}
+		assert.NotNil(t, v.Validate())
+
+		v.Name, v.MountPath = "data", "/etc/localtime"
+		assert.NotNil(t, v.Validate())
+	})
+
+	t.Run("relative mount path", func(t *testing.T) {
+		v := &AppVolume{Name: "data", Type: AppVolumeTypeEmptyDir, MountPath: "data"}
+		assert.NotNil(t, v.Validate())
+	})
+}
+
+func TestEntity_ValidateAppVolumes(t *testing.T) {
+	sidecars := []*ExtraContainer{{Name: "log-shipper"}}
+	volumes := []*AppVolume{
+		{Name: "logs", Type: AppVolumeTypeEmptyDir, MountPath: "/var/log/app", Containers: []string{"log-shipper"}},
+		{Name: "data", Type: AppVolumeTypePVC, MountPath: "/data", Size: "1Gi", AccessMode: v1.ReadWriteMany},
+	}
+	assert.Nil(t, ValidateAppVolumes(volumes, nil, sidecars))
+
+	// 挂载的附加容器不存在
+	assert.NotNil(t, ValidateAppVolumes(volumes, nil, nil))
+
+	// 挂载路径重复
+	volumes[1].MountPath = "/var/log/app"
+	assert.NotNil(t, ValidateAppVolumes(volumes, nil, sidecars))
+}
+
+func TestEntity_ValidateAppVolumeAccessModes(t *testing.T) {
+	volumes := []*AppVolume{
+		{Name: "logs", Type: AppVolumeTypeEmptyDir, MountPath: "/var/log/app"},
+		{Name: "data", Type: AppVolumeTypePVC, MountPath: "/data", Size: "1Gi", AccessMode: v1.ReadWriteOnce},
+	}
+
+	// 常驻服务及后台任务新旧版本同时挂载
+	assert.NotNil(t, ValidateAppVolumeAccessModes(AppTypeService, volumes))
+	assert.NotNil(t, ValidateAppVolumeAccessModes(AppTypeWorker, volumes))
+	assert.Nil(t, ValidateAppVolumeAccessModes(AppTypeCronJob, volumes))
+	assert.Nil(t, ValidateAppVolumeAccessModes(AppTypeOneTimeJob, volumes))
+
+	volumes[1].AccessMode = v1.ReadWriteMany
+	assert.Nil(t, ValidateAppVolumeAccessModes(AppTypeService, volumes))
+
+	volumes[1].AccessMode = v1.ReadOnlyMany
+	assert.Nil(t, ValidateAppVolumeAccessModes(AppTypeWorker, volumes))
+}
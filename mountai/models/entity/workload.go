@@ -0,0 +1,79 @@
+package entity
+
+// WorkloadPhases 工作负载在任务状态机中各阶段对应的任务状态
+type WorkloadPhases struct {
+	CreateUnderway  TaskStatus
+	CreateFinish    TaskStatus
+	RestartUnderway TaskStatus
+	RestartFinish   TaskStatus
+	// 不支持伸缩的工作负载为空
+	ScaleUnderway TaskStatus
+	ScaleFinish   TaskStatus
+	CleanUnderway TaskStatus
+	CleanFinish   TaskStatus
+}
+
+// WorkloadType 可插拔的工作负载类型
+// 新增类型只需在此注册, 并在 service 层实现对应的 workload 接口, 无需修改各处按应用类型的分支
+type WorkloadType struct {
+	AppType AppType
+	// k8s 资源类型
+	Kind string
+	// 监控面板中的工作负载类型
+	MonitorType string
+	// 支持的批量操作行为
+	BatchActions TaskActionList
+	// 状态机阶段
+	Phases WorkloadPhases
+}
+
+// SupportsAction 是否支持该任务行为
+func (w *WorkloadType) SupportsAction(action TaskAction) bool {
+	return w.BatchActions.Contains(action)
+}
+
+// Scalable 是否支持伸缩实例数
+func (w *WorkloadType) Scalable() bool {
+	return w.Phases.ScaleUnderway != ""
+}
+
+// workloadTypes 已注册的可插拔工作负载类型
+// Service/Worker/CronJob/OneTimeJob 仍由原有流程处理
+var workloadTypes = map[AppType]*WorkloadType{
+	AppTypeStatefulSet: {
+		AppType:      AppTypeStatefulSet,
+		Kind:         K8sObjectKindStatefulSet,
+		MonitorType:  "statefulset",
+		BatchActions: TaskActionStatefulSetBatchList,
+		Phases: WorkloadPhases{
+			CreateUnderway:  TaskStatusCreateFullStatefulSetUnderway,
+			CreateFinish:    TaskStatusCreateFullStatefulSetFinish,
+			RestartUnderway: TaskStatusRestartStatefulSetUnderway,
+			RestartFinish:   TaskStatusRestartStatefulSetFinish,
+			ScaleUnderway:   TaskStatusUpdateStatefulSetScaleUnderway,
+			ScaleFinish:     TaskStatusUpdateStatefulSetScaleFinish,
+			CleanUnderway:   TaskStatusCleanStatefulSetUnderway,
+			CleanFinish:     TaskStatusCleanStatefulSetFinish,
+		},
+	},
+	AppTypeDaemonSet: {
+		AppType:      AppTypeDaemonSet,
+		Kind:         K8sObjectKindDaemonSet,
+		MonitorType:  "daemonset",
+		BatchActions: TaskActionDaemonSetBatchList,
+		Phases: WorkloadPhases{
+			CreateUnderway:  TaskStatusCreateFullDaemonSetUnderway,
+			CreateFinish:    TaskStatusCreateFullDaemonSetFinish,
+			RestartUnderway: TaskStatusRestartDaemonSetUnderway,
+			RestartFinish:   TaskStatusRestartDaemonSetFinish,
+			CleanUnderway:   TaskStatusCleanDaemonSetUnderway,
+			CleanFinish:     TaskStatusCleanDaemonSetFinish,
+		},
+	},
+}
+
+// GetWorkloadType 获取应用类型对应的可插拔工作负载类型
+func GetWorkloadType(appType AppType) (*WorkloadType, bool) {
+	w, ok := workloadTypes[appType]
+	return w, ok
+}
@@ -0,0 +1,32 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEntity_GetWorkloadType(t *testing.T) {
+	t.Run("statefulset", func(t *testing.T) {
+		w, ok := GetWorkloadType(AppTypeStatefulSet)
+		assert.True(t, ok)
+		assert.Equal(t, K8sObjectKindStatefulSet, w.Kind)
+		assert.True(t, w.Scalable())
+		assert.True(t, w.SupportsAction(TaskActionStop))
+		assert.False(t, w.SupportsAction(TaskActionCanaryDeploy))
+	})
+
+	t.Run("daemonset", func(t *testing.T) {
+		w, ok := GetWorkloadType(AppTypeDaemonSet)
+		assert.True(t, ok)
+		assert.Equal(t, K8sObjectKindDaemonSet, w.Kind)
+		assert.False(t, w.Scalable())
+		assert.True(t, w.SupportsAction(TaskActionRestart))
+		assert.False(t, w.SupportsAction(TaskActionStop))
+	})
+
+	t.Run("builtin", func(t *testing.T) {
+		_, ok := GetWorkloadType(AppTypeService)
+		assert.False(t, ok)
+	})
+}
@@ -23,7 +23,7 @@ const MaxDescriptionLength = 1000
 // CreateAppReq 创建应用请求参数
 type CreateAppReq struct {
 	Name                           string                      `json:"name" binding:"required"`
-	Type                           entity.AppType              `json:"type" binding:"required,oneof=Service Worker CronJob OneTimeJob"`
+	Type                           entity.AppType              `json:"type" binding:"required,oneof=Service Worker CronJob OneTimeJob StatefulSet DaemonSet"`
 	ServiceType                    entity.AppServiceType       `json:"service_type" binding:"omitempty,oneof=Restful GRPC"`
 	ServiceExposeType              entity.AppServiceExposeType `json:"service_expose_type" binding:"omitempty,oneof=Ingress LB"`
 	ProjectID                      string                      `json:"project_id" binding:"required"`
@@ -99,6 +99,7 @@ type UpdateAppEnvReq struct {
 	LogTailName                    string    `json:"log_tail_name"`
 	EnableBranchChangeNotification null.Bool `json:"enable_branch_change_notification"`
 	EnableHotReload                null.Bool `json:"enable_hot_reload"`
+	EnableAutoRollback             null.Bool `json:"enable_auto_rollback"`
 }
 
 // GetAppsReq 获取应用列表请求参数
@@ -150,6 +151,8 @@ type CalculateAppRecommendReq struct {
 
 type DeleteAppReq struct {
 	DeleteSentry bool `form:"delete_sentry" json:"delete_sentry"`
+	// 是否删除持久化存储卷, 默认保留
+	DeleteVolumes bool `form:"delete_volumes" json:"delete_volumes"`
 }
 
 // SetAppClusterQDNSWeightsReq 设置应用环境所有集群在 QDNS 统一接入规则中的权重
@@ -0,0 +1,42 @@
+package req
+
+import (
+	"rulai/models"
+	"rulai/models/entity"
+
+	"gitlab.shanhai.int/sre/library/base/null"
+)
+
+// CreateFreezeWindowReq 创建封网窗口请求, 时间均为秒级时间戳
+type CreateFreezeWindowReq struct {
+	Name        string                    `json:"name" binding:"required,min=1"`
+	Description string                    `json:"description"`
+	StartTime   int64                     `json:"start_time" binding:"required"`
+	EndTime     int64                     `json:"end_time" binding:"required"`
+	Scope       *entity.FreezeWindowScope `json:"scope"`
+	OperatorID  string
+}
+
+// UpdateFreezeWindowReq 更新封网窗口请求
+type UpdateFreezeWindowReq struct {
+	Name        string                    `json:"name"`
+	Description null.String               `json:"description"`
+	StartTime   int64                     `json:"start_time"`
+	EndTime     int64                     `json:"end_time"`
+	Scope       *entity.FreezeWindowScope `json:"scope"`
+	OperatorID  string
+}
+
+// GetFreezeWindowsReq 获取封网窗口列表请求
+type GetFreezeWindowsReq struct {
+	models.BaseListRequest
+	EnvName entity.AppEnvName `form:"env_name" json:"env_name"`
+	// 仅返回未结束的窗口
+	Active bool `form:"active" json:"active"`
+}
+
+// GetFreezeOverrideRecordsReq 获取封网强制变更记录请求
+type GetFreezeOverrideRecordsReq struct {
+	models.BaseListRequest
+	ProjectID string `form:"project_id" json:"project_id"`
+}
@@ -0,0 +1,16 @@
+package req
+
+import (
+	"rulai/models"
+	"rulai/models/entity"
+)
+
+// GetK8sDriftsReq 获取配置漂移列表请求
+type GetK8sDriftsReq struct {
+	models.BaseListRequest
+	ProjectID   string                `form:"project_id" json:"project_id"`
+	AppID       string                `form:"app_id" json:"app_id"`
+	EnvName     entity.AppEnvName     `form:"env_name" json:"env_name"`
+	ClusterName entity.ClusterName    `form:"cluster_name" json:"cluster_name"`
+	Status      entity.K8sDriftStatus `form:"status" json:"status"`
+}
@@ -0,0 +1,26 @@
+package req
+
+// GetPDBDetailReq 获取 PodDisruptionBudget 详情请求参数
+type GetPDBDetailReq struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Env       string `json:"env"`
+}
+
+// GetPDBsReq 获取 PodDisruptionBudget 列表请求参数
+type GetPDBsReq struct {
+	Namespace   string `json:"namespace"`
+	ProjectName string `json:"project_name"`
+	AppName     string `json:"app_name"`
+	Version     string `json:"version"`
+	Env         string `json:"env"`
+}
+
+// DeletePDBsReq 批量删除 PodDisruptionBudget 请求参数
+type DeletePDBsReq struct {
+	Namespace      string `json:"namespace"`
+	ProjectName    string `json:"project_name"`
+	AppName        string `json:"app_name"`
+	InverseVersion string `json:"inverse_version"`
+	Env            string `json:"env"`
+}
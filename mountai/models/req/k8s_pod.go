@@ -24,6 +24,8 @@ type GetPodLogReq struct {
 	Name          string `json:"name"`
 	Env           string `json:"env"`
 	ContainerName string `json:"container_name"`
+	// 是否获取上一次运行(重启前)的日志
+	Previous bool `json:"previous"`
 }
 
 type ExecPodReq struct {
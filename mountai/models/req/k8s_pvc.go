@@ -0,0 +1,16 @@
+package req
+
+// GetPVCDetailReq 获取持久化存储卷声明详情请求参数
+type GetPVCDetailReq struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Env       string `json:"env"`
+}
+
+// ListPVCsReq 获取应用持久化存储卷声明列表请求参数
+type ListPVCsReq struct {
+	Namespace   string `json:"namespace"`
+	ProjectName string `json:"project_name"`
+	AppName     string `json:"app_name"`
+	Env         string `json:"env"`
+}
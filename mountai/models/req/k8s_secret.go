@@ -0,0 +1,16 @@
+package req
+
+// GetSecretDetailReq 获取密钥变量 Secret 详情请求参数
+type GetSecretDetailReq struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Env       string `json:"env"`
+}
+
+// ListSecretsReq 获取密钥变量 Secret 列表请求参数
+type ListSecretsReq struct {
+	Namespace   string `json:"namespace"`
+	ProjectName string `json:"project_name"`
+	AppName     string `json:"app_name"`
+	Env         string `json:"env"`
+}
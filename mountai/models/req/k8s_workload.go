@@ -0,0 +1,24 @@
+package req
+
+// GetWorkloadDetailReq 获取可插拔工作负载(StatefulSet/DaemonSet)详情请求参数
+type GetWorkloadDetailReq struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Env       string `json:"env"`
+}
+
+// GetWorkloadsReq 批量获取可插拔工作负载列表请求参数
+type GetWorkloadsReq struct {
+	Namespace   string `json:"namespace"`
+	ProjectName string `json:"project_name"`
+	AppName     string `json:"app_name"`
+	Version     string `json:"version"`
+	Env         string `json:"env"`
+}
+
+// DeleteWorkloadReq 删除可插拔工作负载请求参数
+type DeleteWorkloadReq struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Env       string `json:"env"`
+}
@@ -0,0 +1,49 @@
+package req
+
+import (
+	"rulai/models"
+	"rulai/models/entity"
+
+	"gitlab.shanhai.int/sre/library/base/null"
+)
+
+// CreateMaintenanceScheduleReq 创建定时运维计划请求
+type CreateMaintenanceScheduleReq struct {
+	Name        string             `json:"name" binding:"required,min=1"`
+	AppID       string             `json:"app_id" binding:"required"`
+	EnvName     entity.AppEnvName  `json:"env_name" binding:"required"`
+	ClusterName entity.ClusterName `json:"cluster_name"`
+	Action      entity.TaskAction  `json:"action" binding:"required"`
+	Cron        string             `json:"cron" binding:"required"`
+	// 最小/最大实例数, 仅 update_hpa 使用
+	MinPodCount int `json:"min_pod_count"`
+	MaxPodCount int `json:"max_pod_count"`
+	// 结束时间戳, 为 0 表示不结束
+	EndTime   int64  `json:"end_time"`
+	ProjectID string `json:"-"`
+	OwnerID   string `json:"-"`
+}
+
+// UpdateMaintenanceScheduleReq 更新定时运维计划请求
+type UpdateMaintenanceScheduleReq struct {
+	Name        string     `json:"name"`
+	Cron        string     `json:"cron"`
+	MinPodCount int        `json:"min_pod_count"`
+	MaxPodCount int        `json:"max_pod_count"`
+	EndTime     null.Int64 `json:"end_time"`
+	Disabled    null.Bool  `json:"disabled"`
+}
+
+// GetMaintenanceSchedulesReq 获取定时运维计划列表请求
+type GetMaintenanceSchedulesReq struct {
+	models.BaseListRequest
+	ProjectID string            `form:"project_id" json:"project_id"`
+	AppID     string            `form:"app_id" json:"app_id"`
+	EnvName   entity.AppEnvName `form:"env_name" json:"env_name"`
+}
+
+// GetMaintenanceScheduleExecutionsReq 获取定时运维计划执行记录请求
+type GetMaintenanceScheduleExecutionsReq struct {
+	models.BaseListRequest
+	Status entity.MaintenanceScheduleExecutionStatus `form:"status" json:"status"`
+}
@@ -0,0 +1,63 @@
+package req
+
+import (
+	"rulai/models"
+	"rulai/models/entity"
+
+	"gitlab.shanhai.int/sre/library/base/null"
+)
+
+// CreateNotificationChannelReq 创建项目通知渠道请求
+type CreateNotificationChannelReq struct {
+	Name string                         `json:"name" binding:"required,min=1"`
+	Type entity.NotificationChannelType `json:"type" binding:"required"`
+	// webhook/slack 地址
+	URL string `json:"url"`
+	// webhook 签名密钥
+	Secret string `json:"secret"`
+	// 邮件收件人
+	Emails     []string `json:"emails"`
+	ProjectID  string   `json:"-"`
+	OperatorID string   `json:"-"`
+}
+
+// UpdateNotificationChannelReq 更新项目通知渠道请求, 渠道类型不可修改
+type UpdateNotificationChannelReq struct {
+	Name   string      `json:"name"`
+	URL    string      `json:"url"`
+	Secret null.String `json:"secret"`
+	Emails []string    `json:"emails"`
+}
+
+// CreateNotificationRuleReq 创建项目通知规则请求
+type CreateNotificationRuleReq struct {
+	ChannelID  string                     `json:"channel_id" binding:"required"`
+	EnvNames   []entity.AppEnvName        `json:"env_names"`
+	Events     []entity.NotificationEvent `json:"events" binding:"required,min=1"`
+	ProjectID  string                     `json:"-"`
+	OperatorID string                     `json:"-"`
+}
+
+// UpdateNotificationRuleReq 更新项目通知规则请求
+type UpdateNotificationRuleReq struct {
+	ChannelID string                     `json:"channel_id"`
+	EnvNames  []entity.AppEnvName        `json:"env_names"`
+	Events    []entity.NotificationEvent `json:"events"`
+}
+
+// GetNotificationDeliveriesReq 获取通知投递记录请求
+type GetNotificationDeliveriesReq struct {
+	models.BaseListRequest
+	ChannelID string                            `form:"channel_id" json:"channel_id"`
+	Event     entity.NotificationEvent          `form:"event" json:"event"`
+	Status    entity.NotificationDeliveryStatus `form:"status" json:"status"`
+}
+
+// NotificationMessage 通知消息, 同时作为 webhook 请求体
+type NotificationMessage struct {
+	*AppOpMessage
+	Event     entity.NotificationEvent `json:"event"`
+	ProjectID string                   `json:"project_id"`
+	AppID     string                   `json:"app_id"`
+	TaskID    string                   `json:"task_id"`
+}
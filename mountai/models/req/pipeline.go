@@ -0,0 +1,46 @@
+package req
+
+import (
+	"rulai/models"
+	"rulai/models/entity"
+
+	"gitlab.shanhai.int/sre/library/base/null"
+)
+
+// CreatePipelineReq 创建部署流水线请求
+type CreatePipelineReq struct {
+	Name        string                     `json:"name" binding:"required,min=1"`
+	Description string                     `json:"description"`
+	AppID       string                     `json:"app_id" binding:"required"`
+	Stages      []*entity.PipelineEnvStage `json:"stages" binding:"required,min=1"`
+	ProjectID   string                     `json:"-"`
+	OperatorID  string                     `json:"-"`
+}
+
+// UpdatePipelineReq 更新部署流水线请求
+type UpdatePipelineReq struct {
+	Name        string                     `json:"name"`
+	Description null.String                `json:"description"`
+	Stages      []*entity.PipelineEnvStage `json:"stages"`
+}
+
+// GetPipelinesReq 获取部署流水线列表请求
+type GetPipelinesReq struct {
+	models.BaseListRequest
+	ProjectID string `form:"project_id" json:"project_id"`
+	AppID     string `form:"app_id" json:"app_id"`
+}
+
+// CreatePipelineRunReq 运行部署流水线请求
+type CreatePipelineRunReq struct {
+	ImageVersion   string `json:"image_version" binding:"required"`
+	ConfigCommitID string `json:"config_commit_id"`
+	Description    string `json:"description"`
+	OperatorID     string `json:"-"`
+}
+
+// GetPipelineRunsReq 获取部署流水线运行历史请求
+type GetPipelineRunsReq struct {
+	models.BaseListRequest
+	Status entity.PipelineRunStatus `form:"status" json:"status"`
+}
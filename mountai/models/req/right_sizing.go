@@ -0,0 +1,30 @@
+package req
+
+import (
+	"rulai/models"
+	"rulai/models/entity"
+)
+
+// GetRightSizingReportReq 获取资源规格推荐报告请求
+type GetRightSizingReportReq struct {
+	models.BaseListRequest
+	ProjectID   string             `form:"project_id" json:"project_id"`
+	AppID       string             `form:"app_id" json:"app_id"`
+	EnvName     entity.AppEnvName  `form:"env_name" json:"env_name"`
+	ClusterName entity.ClusterName `form:"cluster_name" json:"cluster_name"`
+	// 仅返回需要调整规格的推荐
+	OnlyResize bool `form:"only_resize" json:"only_resize"`
+}
+
+// ApplyRightSizingReq 按推荐规格创建部署任务请求
+type ApplyRightSizingReq struct {
+	Description string `json:"description"`
+	// 部署方式及审批, 含义与创建任务一致, 生产环境必须指定部署方式
+	DeployType   entity.TaskDeployType `json:"deploy_type"`
+	ScheduleTime int64                 `json:"schedule_time"`
+	Approval     *ApprovalReq          `json:"approval"`
+	// 封网期间强制变更的原因
+	FreezeOverrideReason string `json:"freeze_override_reason"`
+	// 错误预算耗尽时强制部署的原因
+	ErrorBudgetOverrideReason string `json:"error_budget_override_reason"`
+}
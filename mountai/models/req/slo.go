@@ -0,0 +1,37 @@
+package req
+
+import (
+	"rulai/models/entity"
+
+	"gitlab.shanhai.int/sre/library/base/null"
+)
+
+// CreateSLOReq 创建应用 SLO 请求
+type CreateSLOReq struct {
+	EnvName   entity.AppEnvName `json:"env_name" binding:"required"`
+	Name      string            `json:"name" binding:"required,min=1"`
+	Type      entity.SLOType    `json:"type" binding:"required"`
+	Objective float64           `json:"objective" binding:"required"`
+	// 统计窗口 单位天, 为空时为 28 天
+	WindowDays int `json:"window_days"`
+	// p99 延迟阈值 单位毫秒, 仅延迟类型使用
+	LatencyThresholdMs float64 `json:"latency_threshold_ms"`
+	// 自定义 PromQL 模板, 为空时使用内置查询
+	ErrorRatioQuery string `json:"error_ratio_query"`
+	AppID           string `json:"-"`
+	OperatorID      string `json:"-"`
+}
+
+// UpdateSLOReq 更新应用 SLO 请求, 环境及类型不可修改
+type UpdateSLOReq struct {
+	Name               string      `json:"name"`
+	Objective          float64     `json:"objective"`
+	WindowDays         int         `json:"window_days"`
+	LatencyThresholdMs float64     `json:"latency_threshold_ms"`
+	ErrorRatioQuery    null.String `json:"error_ratio_query"`
+}
+
+// GetAppSLOReq 获取应用 SLO 状态请求
+type GetAppSLOReq struct {
+	EnvName entity.AppEnvName `form:"env_name" json:"env_name" binding:"required"`
+}
@@ -23,10 +23,13 @@ type GetRunningStatusDetailReq struct {
 
 // GetRunningPodLogsReq 获取正在运行中的 pod 日志请求参数
 type GetRunningPodLogsReq struct {
-	EnvName       entity.AppEnvName  `form:"env_name"  json:"env_name" binding:"required"`
-	ClusterName   entity.ClusterName `form:"cluster_name" json:"cluster_name" binding:"required"`
-	Namespace     string             `form:"namespace" json:"namespace"`
-	ContainerName string             `form:"container_name" json:"container_name" binding:"required"`
+	EnvName     entity.AppEnvName  `form:"env_name"  json:"env_name" binding:"required"`
+	ClusterName entity.ClusterName `form:"cluster_name" json:"cluster_name" binding:"required"`
+	Namespace   string             `form:"namespace" json:"namespace"`
+	// 容器名, 为空时为应用主容器
+	ContainerName string `form:"container_name" json:"container_name"`
+	// 是否获取上一次运行(重启前)的日志
+	Previous bool `form:"previous" json:"previous"`
 }
 
 // GetRunningStatusDescriptionReq 获取应用运行状态信息请求参数
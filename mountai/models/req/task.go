@@ -26,6 +26,18 @@ type CreateTaskReq struct {
 	// 部署时忽略分支不匹配异常（强制部署）
 	IgnoreExpectedBranch bool   `json:"ignore_expected_branch"`
 	Namespace            string `json:"namespace" default:"stg"` // 创建任务时,携带命名空间
+	// 关联任务id, 回滚时为触发回滚的失败任务
+	RelatedTaskID string `json:"related_task_id"`
+	// 蓝绿部署切换前的运行版本, 由服务端填充
+	PreviousVersion string `json:"-"`
+	// 封网期间强制变更的原因
+	FreezeOverrideReason string `json:"freeze_override_reason"`
+	// 强制变更时命中的封网窗口id, 由服务端填充
+	FreezeWindowID string `json:"-"`
+	// 错误预算耗尽时强制部署的原因
+	ErrorBudgetOverrideReason string `json:"error_budget_override_reason"`
+	// 创建后暂停执行, 多集群部署的后续批次由服务端填充
+	Suspend bool `json:"-"`
 }
 
 // CreateTaskParamReq : 创建任务参数请求
@@ -52,6 +64,8 @@ type CreateTaskParamReq struct {
 	MemLimit entity.MemResourceType `json:"mem_limit"`
 	// 环境变量
 	Vars map[string]string `json:"vars"`
+	// 注入为环境变量的项目密钥变量名
+	SecretVars []string `json:"secret_vars"`
 	// 是否支持监控
 	IsSupportMetrics bool `json:"is_support_metrics"`
 	// 监控端口
@@ -89,7 +103,7 @@ type CreateTaskParamReq struct {
 	IsSupportStickySession bool `json:"is_support_sticky_session"`
 	// 会话保持cookie过期时间 单位秒
 	SessionCookieMaxAge int `json:"session_cookie_max_age"`
-	// 关闭pod反亲和性
+	// 关闭pod反亲和性, 拓扑分布约束及 PodDisruptionBudget
 	DisableHighAvailability bool `json:"disable_high_availability"`
 	// 关闭金丝雀发布(仅存储)
 	DisableCanary bool `json:"disable_canary"`
@@ -126,6 +140,22 @@ type CreateTaskParamReq struct {
 	CronScaleJobGroups []*entity.CronScaleJobGroup `json:"cron_scale_job_groups"`
 	// ScaleJobExcludeDate 定时扩缩容排除日期，五位时间模板，最小粒度为"天"，更小粒度填充"*"
 	CronScaleJobExcludeDates []string `json:"cron_scale_job_exclude_dates"`
+	// 渐进式金丝雀配置, 为空时使用普通金丝雀发布
+	ProgressiveCanary *entity.ProgressiveCanaryConfig `json:"progressive_canary"`
+	// 蓝绿部署旧版本保留时长 单位秒
+	BlueGreenKeepWarmSeconds int `json:"blue_green_keep_warm_seconds"`
+	// 自定义扩缩容指标(Pods/Object/External)
+	HPAMetrics []*entity.HPAMetricSpec `json:"hpa_metrics"`
+	// 扩缩容行为策略
+	HPABehavior *entity.HPABehavior `json:"hpa_behavior"`
+	// 初始化容器
+	InitContainers []*entity.ExtraContainer `json:"init_containers"`
+	// 边车容器
+	Sidecars []*entity.ExtraContainer `json:"sidecars"`
+	// 存储卷
+	Volumes []*entity.AppVolume `json:"volumes"`
+	// 删除最后一个部署或清理应用时是否删除持久化存储卷, 默认保留
+	DeleteVolumes bool `json:"delete_volumes"`
 
 	// 用于清理工作
 	CleanedProjectName          string                      `json:"cleaned_project_name,omitempty"`
@@ -137,6 +167,7 @@ type CreateTaskParamReq struct {
 	CleanedAliAlarmName         string                      `json:"cleaned_ali_alarm_name,omitempty"`
 	CleanedAliLogConfigName     string                      `json:"cleaned_ali_log_config_name,omitempty"`
 	CleanedAliLogStoreName      string                      `json:"cleaned_ali_log_store_name,omitempty"`
+	CleanedVersion              string                      `json:"cleaned_version,omitempty"`
 }
 
 // NodeAffinityLabelConfig : 节点亲和性标签配置
@@ -224,6 +255,8 @@ type UpdateTaskReq struct {
 	ApprovalInstanceID string                    `json:"-"`
 	ApprovalStatus     entity.TaskApprovalStatus `json:"-"`
 	Status             entity.TaskStatus         `json:"-"`
+	// 渐进式金丝雀判定结果, 为空表示不更新
+	CanaryVerdicts []*entity.CanaryStepVerdict `json:"-"`
 }
 
 // BatchCreateTaskReq : 批量创建任务请求
@@ -235,6 +268,10 @@ type BatchCreateTaskReq struct {
 	Action                entity.TaskAction   `json:"action" binding:"required"`
 	IgnoreApprovalProcess null.Bool           `json:"ignore_approval_process"`
 	Param                 *CreateTaskParamReq `json:"param" binding:"required"`
+	// 封网期间强制变更的原因
+	FreezeOverrideReason string `json:"freeze_override_reason"`
+	// 错误预算耗尽时强制部署的原因
+	ErrorBudgetOverrideReason string `json:"error_budget_override_reason"`
 }
 
 // Approval information.
@@ -244,3 +281,37 @@ type ApprovalReq struct {
 	OperationEngineers []*entity.DingDingUserDetail `json:"operation_engineers"`
 	ProductManagers    []*entity.DingDingUserDetail `json:"product_managers"`
 }
+
+// GetTaskPhaseStatsReq 获取任务阶段耗时统计请求, 时间均为秒级时间戳
+type GetTaskPhaseStatsReq struct {
+	ProjectID    string             `form:"project_id" json:"project_id"`
+	EnvName      entity.AppEnvName  `form:"env_name" json:"env_name"`
+	ClusterName  entity.ClusterName `form:"cluster_name" json:"cluster_name"`
+	Action       entity.TaskAction  `form:"action" json:"action"`
+	MinTimestamp int64              `form:"min_timestamp" json:"min_timestamp"`
+	MaxTimestamp int64              `form:"max_timestamp" json:"max_timestamp"`
+}
+
+// CreateMultiClusterDeployReq 多集群分批部署请求
+// 各集群以该集群上一次成功的任务为基本参数, Waves 为空时默认集群先行, 其余集群随后
+type CreateMultiClusterDeployReq struct {
+	AppID       string              `json:"app_id" binding:"required"`
+	EnvName     entity.AppEnvName   `json:"env_name" binding:"required"`
+	Action      entity.TaskAction   `json:"action" binding:"required"`
+	Description string              `json:"description"`
+	Param       *CreateTaskParamReq `json:"param" binding:"required"`
+	// 部署批次, 未列出的支持集群追加为最后一个批次
+	Waves [][]entity.ClusterName `json:"waves"`
+	// 封网期间强制变更的原因
+	FreezeOverrideReason string `json:"freeze_override_reason"`
+	// 错误预算耗尽时强制部署的原因
+	ErrorBudgetOverrideReason string `json:"error_budget_override_reason"`
+}
+
+// GetMultiClusterDeploysReq 获取多集群部署列表请求
+type GetMultiClusterDeploysReq struct {
+	models.BaseListRequest
+	AppID   string                          `form:"app_id" json:"app_id"`
+	EnvName entity.AppEnvName               `form:"env_name" json:"env_name"`
+	Status  entity.MultiClusterDeployStatus `form:"status" json:"status"`
+}
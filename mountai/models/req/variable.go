@@ -17,6 +17,14 @@ type CreateVariableReq struct {
 	Type       entity.VariableType `json:"type"`
 	ProjectID  string              `json:"project_id"`
 	OperatorID string
+	// 是否为密钥变量, 创建后不可修改
+	Secret bool `json:"secret"`
+}
+
+// GetVariableAuditsReq 获取密钥变量审计记录请求参数
+type GetVariableAuditsReq struct {
+	models.BaseListRequest
+	VariableID string
 }
 
 type GetVariablesReq struct {
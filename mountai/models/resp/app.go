@@ -51,6 +51,7 @@ type AppEnvDetailResp struct {
 	ServiceProtocol                string `json:"service_protocol"`
 	EnableBranchChangeNotification bool   `json:"enable_branch_change_notification"`
 	EnableHotReload                bool   `json:"enable_hot_reload"`
+	EnableAutoRollback             bool   `json:"enable_auto_rollback"`
 }
 
 // AppListResp 应用列表返回值
@@ -100,6 +101,7 @@ type AppDisplayDetailResp struct {
 	ServiceProtocol                string `json:"service_protocol"`
 	EnableBranchChangeNotification bool   `json:"enable_branch_change_notification"`
 	EnableHotReload                bool   `json:"enable_hot_reload"`
+	EnableAutoRollback             bool   `json:"enable_auto_rollback"`
 	AppEnvExtraDetailResp
 
 	RunningStatus      []*RunningStatusListResp     `json:"running_status"`
@@ -0,0 +1,32 @@
+package resp
+
+import (
+	"rulai/models/entity"
+)
+
+// FreezeWindowDetail 封网窗口详情
+type FreezeWindowDetail struct {
+	ID          string                    `json:"id" deepcopy:"objectid"`
+	Name        string                    `json:"name"`
+	Description string                    `json:"description"`
+	StartTime   string                    `json:"start_time" deepcopy:"timeformat:2006-01-02 15:04:05"`
+	EndTime     string                    `json:"end_time" deepcopy:"timeformat:2006-01-02 15:04:05"`
+	Scope       *entity.FreezeWindowScope `json:"scope"`
+	OperatorID  string                    `json:"operator_id"`
+	CreateTime  string                    `json:"create_time" deepcopy:"timeformat:2006-01-02 15:04:05"`
+	UpdateTime  string                    `json:"update_time" deepcopy:"timeformat:2006-01-02 15:04:05"`
+}
+
+// FreezeOverrideRecordDetail 封网强制变更记录详情
+type FreezeOverrideRecordDetail struct {
+	ID             string            `json:"id" deepcopy:"objectid"`
+	FreezeWindowID string            `json:"freeze_window_id"`
+	TaskID         string            `json:"task_id"`
+	Env            entity.AppEnvName `json:"env"`
+	ProjectID      string            `json:"project_id"`
+	AppID          string            `json:"app_id"`
+	TaskActionType string            `json:"task_action_type"`
+	Reason         string            `json:"reason"`
+	Operator       string            `json:"operator"`
+	CreateTime     string            `json:"create_time" deepcopy:"timeformat:2006-01-02 15:04:05"`
+}
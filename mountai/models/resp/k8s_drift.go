@@ -0,0 +1,23 @@
+package resp
+
+import (
+	"rulai/models/entity"
+)
+
+// K8sDriftDetail 配置漂移详情
+type K8sDriftDetail struct {
+	ID          string                 `json:"id" deepcopy:"objectid"`
+	ProjectID   string                 `json:"project_id"`
+	AppID       string                 `json:"app_id"`
+	EnvName     entity.AppEnvName      `json:"env_name"`
+	ClusterName entity.ClusterName     `json:"cluster_name"`
+	Namespace   string                 `json:"namespace"`
+	TaskID      string                 `json:"task_id"`
+	Version     string                 `json:"version"`
+	Items       []*entity.K8sDriftItem `json:"items"`
+	Status      entity.K8sDriftStatus  `json:"status"`
+	HealError   string                 `json:"heal_error"`
+	DetectTime  string                 `json:"detect_time" deepcopy:"timeformat:2006-01-02 15:04:05"`
+	CreateTime  string                 `json:"create_time" deepcopy:"timeformat:2006-01-02 15:04:05"`
+	UpdateTime  string                 `json:"update_time" deepcopy:"timeformat:2006-01-02 15:04:05"`
+}
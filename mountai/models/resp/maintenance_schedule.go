@@ -0,0 +1,38 @@
+package resp
+
+import (
+	"rulai/models/entity"
+)
+
+// MaintenanceScheduleDetail 定时运维计划详情
+type MaintenanceScheduleDetail struct {
+	ID          string             `json:"id" deepcopy:"objectid"`
+	Name        string             `json:"name"`
+	ProjectID   string             `json:"project_id"`
+	AppID       string             `json:"app_id"`
+	EnvName     entity.AppEnvName  `json:"env_name"`
+	ClusterName entity.ClusterName `json:"cluster_name"`
+	Action      entity.TaskAction  `json:"action"`
+	Cron        string             `json:"cron"`
+	MinPodCount int                `json:"min_pod_count"`
+	MaxPodCount int                `json:"max_pod_count"`
+	OwnerID     string             `json:"owner_id"`
+	EndTime     string             `json:"end_time" deepcopy:"timeformat:2006-01-02 15:04:05"`
+	Disabled    bool               `json:"disabled"`
+	NextRunTime string             `json:"next_run_time" deepcopy:"timeformat:2006-01-02 15:04:05"`
+	LastRunTime string             `json:"last_run_time" deepcopy:"timeformat:2006-01-02 15:04:05"`
+	CreateTime  string             `json:"create_time" deepcopy:"timeformat:2006-01-02 15:04:05"`
+	UpdateTime  string             `json:"update_time" deepcopy:"timeformat:2006-01-02 15:04:05"`
+}
+
+// MaintenanceScheduleExecutionDetail 定时运维计划执行记录详情
+type MaintenanceScheduleExecutionDetail struct {
+	ID            string                                    `json:"id" deepcopy:"objectid"`
+	ScheduleID    string                                    `json:"schedule_id"`
+	AppID         string                                    `json:"app_id"`
+	TaskID        string                                    `json:"task_id"`
+	Status        entity.MaintenanceScheduleExecutionStatus `json:"status"`
+	Message       string                                    `json:"message"`
+	ScheduledTime string                                    `json:"scheduled_time" deepcopy:"timeformat:2006-01-02 15:04:05"`
+	CreateTime    string                                    `json:"create_time" deepcopy:"timeformat:2006-01-02 15:04:05"`
+}
@@ -0,0 +1,38 @@
+package resp
+
+import (
+	"rulai/models/entity"
+)
+
+// MultiClusterDeployTaskDetail 多集群部署中单个集群的任务
+type MultiClusterDeployTaskDetail struct {
+	ClusterName   entity.ClusterName `json:"cluster_name"`
+	TaskID        string             `json:"task_id"`
+	Status        entity.TaskStatus  `json:"status"`
+	StatusDisplay string             `json:"status_display"`
+}
+
+// MultiClusterDeployWaveDetail 多集群部署批次详情
+type MultiClusterDeployWaveDetail struct {
+	Tasks      []*MultiClusterDeployTaskDetail     `json:"tasks"`
+	Status     entity.MultiClusterDeployWaveStatus `json:"status"`
+	StartTime  string                              `json:"start_time" deepcopy:"timeformat:2006-01-02 15:04:05"`
+	FinishTime string                              `json:"finish_time" deepcopy:"timeformat:2006-01-02 15:04:05"`
+}
+
+// MultiClusterDeployDetail 多集群部署详情, Status 为各批次汇总后的状态
+type MultiClusterDeployDetail struct {
+	ID          string                          `json:"id" deepcopy:"objectid"`
+	ProjectID   string                          `json:"project_id"`
+	AppID       string                          `json:"app_id"`
+	EnvName     entity.AppEnvName               `json:"env_name"`
+	Action      entity.TaskAction               `json:"action"`
+	Description string                          `json:"description"`
+	Status      entity.MultiClusterDeployStatus `json:"status"`
+	CurrentWave int                             `json:"current_wave"`
+	Waves       []*MultiClusterDeployWaveDetail `json:"waves"`
+	Message     string                          `json:"message"`
+	OperatorID  string                          `json:"operator_id"`
+	CreateTime  string                          `json:"create_time" deepcopy:"timeformat:2006-01-02 15:04:05"`
+	UpdateTime  string                          `json:"update_time" deepcopy:"timeformat:2006-01-02 15:04:05"`
+}
@@ -0,0 +1,47 @@
+package resp
+
+import (
+	"rulai/models/entity"
+)
+
+// NotificationChannelDetail 项目通知渠道详情, 不返回签名密钥
+type NotificationChannelDetail struct {
+	ID         string                         `json:"id" deepcopy:"objectid"`
+	ProjectID  string                         `json:"project_id"`
+	Name       string                         `json:"name"`
+	Type       entity.NotificationChannelType `json:"type"`
+	URL        string                         `json:"url"`
+	Emails     []string                       `json:"emails"`
+	OperatorID string                         `json:"operator_id"`
+	CreateTime string                         `json:"create_time" deepcopy:"timeformat:2006-01-02 15:04:05"`
+	UpdateTime string                         `json:"update_time" deepcopy:"timeformat:2006-01-02 15:04:05"`
+}
+
+// NotificationRuleDetail 项目通知规则详情
+type NotificationRuleDetail struct {
+	ID         string                     `json:"id" deepcopy:"objectid"`
+	ProjectID  string                     `json:"project_id"`
+	ChannelID  string                     `json:"channel_id"`
+	EnvNames   []entity.AppEnvName        `json:"env_names"`
+	Events     []entity.NotificationEvent `json:"events"`
+	OperatorID string                     `json:"operator_id"`
+	CreateTime string                     `json:"create_time" deepcopy:"timeformat:2006-01-02 15:04:05"`
+	UpdateTime string                     `json:"update_time" deepcopy:"timeformat:2006-01-02 15:04:05"`
+}
+
+// NotificationDeliveryDetail 通知投递记录详情
+type NotificationDeliveryDetail struct {
+	ID          string                            `json:"id" deepcopy:"objectid"`
+	ProjectID   string                            `json:"project_id"`
+	ChannelID   string                            `json:"channel_id"`
+	ChannelType entity.NotificationChannelType    `json:"channel_type"`
+	RuleID      string                            `json:"rule_id"`
+	Event       entity.NotificationEvent          `json:"event"`
+	AppID       string                            `json:"app_id"`
+	Env         entity.AppEnvName                 `json:"env"`
+	TaskID      string                            `json:"task_id"`
+	Status      entity.NotificationDeliveryStatus `json:"status"`
+	Attempts    int                               `json:"attempts"`
+	Error       string                            `json:"error"`
+	CreateTime  string                            `json:"create_time" deepcopy:"timeformat:2006-01-02 15:04:05"`
+}
@@ -0,0 +1,35 @@
+package resp
+
+import (
+	"rulai/models/entity"
+)
+
+// PipelineDetail 部署流水线详情
+type PipelineDetail struct {
+	ID          string                     `json:"id" deepcopy:"objectid"`
+	Name        string                     `json:"name"`
+	Description string                     `json:"description"`
+	ProjectID   string                     `json:"project_id"`
+	AppID       string                     `json:"app_id"`
+	Stages      []*entity.PipelineEnvStage `json:"stages"`
+	OperatorID  string                     `json:"operator_id"`
+	CreateTime  string                     `json:"create_time" deepcopy:"timeformat:2006-01-02 15:04:05"`
+	UpdateTime  string                     `json:"update_time" deepcopy:"timeformat:2006-01-02 15:04:05"`
+}
+
+// PipelineRunDetail 部署流水线运行详情
+type PipelineRunDetail struct {
+	ID             string                     `json:"id" deepcopy:"objectid"`
+	PipelineID     string                     `json:"pipeline_id"`
+	ProjectID      string                     `json:"project_id"`
+	AppID          string                     `json:"app_id"`
+	ImageVersion   string                     `json:"image_version"`
+	ConfigCommitID string                     `json:"config_commit_id"`
+	Description    string                     `json:"description"`
+	Status         entity.PipelineRunStatus   `json:"status"`
+	CurrentStage   int                        `json:"current_stage"`
+	Stages         []*entity.PipelineRunStage `json:"stages"`
+	OperatorID     string                     `json:"operator_id"`
+	CreateTime     string                     `json:"create_time" deepcopy:"timeformat:2006-01-02 15:04:05"`
+	UpdateTime     string                     `json:"update_time" deepcopy:"timeformat:2006-01-02 15:04:05"`
+}
@@ -0,0 +1,38 @@
+package resp
+
+import (
+	"rulai/models/entity"
+)
+
+// RightSizingRecommendationDetail 资源规格推荐详情
+type RightSizingRecommendationDetail struct {
+	ID          string                 `json:"id" deepcopy:"objectid"`
+	ProjectID   string                 `json:"project_id"`
+	AppID       string                 `json:"app_id"`
+	EnvName     entity.AppEnvName      `json:"env_name"`
+	ClusterName entity.ClusterName     `json:"cluster_name"`
+	TaskID      string                 `json:"task_id"`
+	PodCount    int                    `json:"pod_count"`
+	CountTime   string                 `json:"count_time"`
+	CPURequest  entity.CPUResourceType `json:"cpu_request"`
+	CPULimit    entity.CPUResourceType `json:"cpu_limit"`
+	MemRequest  entity.MemResourceType `json:"mem_request"`
+	MemLimit    entity.MemResourceType `json:"mem_limit"`
+
+	CPUUsageP95       float64 `json:"cpu_usage_p95"`
+	CPUUsageMax       float64 `json:"cpu_usage_max"`
+	MemUsageP95       float64 `json:"mem_usage_p95"`
+	MemUsageMax       float64 `json:"mem_usage_max"`
+	CPUP95RequestRate float64 `json:"cpu_p95_request_rate"`
+	CPUMaxLimitRate   float64 `json:"cpu_max_limit_rate"`
+	MemP95RequestRate float64 `json:"mem_p95_request_rate"`
+	MemMaxLimitRate   float64 `json:"mem_max_limit_rate"`
+
+	RecommendedCPURequest entity.CPUResourceType `json:"recommended_cpu_request"`
+	RecommendedMemRequest entity.MemResourceType `json:"recommended_mem_request"`
+	NeedResize            bool                   `json:"need_resize"`
+	MonthlyCostDelta      float64                `json:"monthly_cost_delta"`
+
+	CreateTime string `json:"create_time" deepcopy:"timeformat:2006-01-02 15:04:05"`
+	UpdateTime string `json:"update_time" deepcopy:"timeformat:2006-01-02 15:04:05"`
+}
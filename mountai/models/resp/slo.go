@@ -0,0 +1,44 @@
+package resp
+
+import (
+	"rulai/models/entity"
+)
+
+// AppSLOResp 应用 SLO 状态
+type AppSLOResp struct {
+	AppID   string            `json:"app_id"`
+	EnvName entity.AppEnvName `json:"env_name"`
+	// 任一 SLO 错误预算耗尽
+	BudgetExhausted bool             `json:"budget_exhausted"`
+	SLOs            []*SLOStatusResp `json:"slos"`
+}
+
+// SLOStatusResp 单个 SLO 的错误预算状态
+type SLOStatusResp struct {
+	ID                 string         `json:"id" deepcopy:"objectid"`
+	Name               string         `json:"name"`
+	Type               entity.SLOType `json:"type"`
+	Objective          float64        `json:"objective"`
+	WindowDays         int            `json:"window_days"`
+	LatencyThresholdMs float64        `json:"latency_threshold_ms"`
+	ErrorRatioQuery    string         `json:"error_ratio_query"`
+	OperatorID         string         `json:"operator_id"`
+	CreateTime         string         `json:"create_time" deepcopy:"timeformat:2006-01-02 15:04:05"`
+	UpdateTime         string         `json:"update_time" deepcopy:"timeformat:2006-01-02 15:04:05"`
+	// 统计窗口内的错误率
+	ErrorRatio float64 `json:"error_ratio"`
+	// 剩余错误预算比例, 小于等于0表示已耗尽
+	RemainingBudget float64 `json:"remaining_budget"`
+	Exhausted       bool    `json:"exhausted"`
+	// 各滚动窗口的错误预算消耗速率
+	BurnRates []*SLOBurnRateResp `json:"burn_rates"`
+	// 查询失败原因, 查询失败时不参与部署拦截
+	Error string `json:"error"`
+}
+
+// SLOBurnRateResp 滚动窗口的错误预算消耗速率
+type SLOBurnRateResp struct {
+	Window     string  `json:"window"`
+	ErrorRatio float64 `json:"error_ratio"`
+	BurnRate   float64 `json:"burn_rate"`
+}
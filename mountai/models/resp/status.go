@@ -52,6 +52,23 @@ type RunningStatusPodDetailResp struct {
 	CreateTime   string      `json:"create_time"`
 	ShellURL     string      `json:"shell_url"`
 	Namespace    string      `json:"namespace"`
+	// 各容器状态, 包括初始化容器及边车容器
+	Containers []*RunningStatusContainerResp `json:"containers"`
+}
+
+// RunningStatusContainerResp 容器运行状态
+type RunningStatusContainerResp struct {
+	Name         string                    `json:"name"`
+	Type         entity.ExtraContainerType `json:"type"`
+	Image        string                    `json:"image"`
+	Ready        bool                      `json:"ready"`
+	RestartCount int                       `json:"restart_count"`
+	// 状态 waiting/running/terminated
+	State   string `json:"state"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+	// 退出码, 仅 terminated 状态有效
+	ExitCode int `json:"exit_code"`
 }
 
 type RunningStatusJobDetailResp struct {
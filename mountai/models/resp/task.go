@@ -32,8 +32,18 @@ type TaskDetailResp struct {
 	Suspend       bool                    `json:"suspend"`
 	Param         *TaskParamDetailResp    `json:"param"`
 	Namespace     string                  `json:"namespace"`
+	RelatedTaskID string                  `json:"related_task_id"`
 	CreateTime    string                  `json:"create_time" deepcopy:"timeformat:2006-01-02 15:04:05"`
 	UpdateTime    string                  `json:"update_time" deepcopy:"timeformat:2006-01-02 15:04:05"`
+
+	// 渐进式金丝雀每一步的判定结果
+	CanaryVerdicts []*entity.CanaryStepVerdict `json:"canary_verdicts"`
+	// 蓝绿部署切换前的运行版本
+	PreviousVersion string `json:"previous_version"`
+	// 封网期间强制变更的原因
+	FreezeOverrideReason string `json:"freeze_override_reason"`
+	// 错误预算耗尽时强制部署的原因
+	ErrorBudgetOverrideReason string `json:"error_budget_override_reason"`
 }
 
 func (t *TaskDetailResp) GetNamespace(enableIstio bool) string {
@@ -82,6 +92,7 @@ type TaskParamDetailResp struct {
 	IsSupportMetrics              bool                              `json:"is_support_metrics"`
 	MetricsPort                   int                               `json:"metrics_port"`
 	Vars                          map[string]string                 `json:"vars"`
+	SecretVars                    []string                          `json:"secret_vars"`
 	PreStopCommand                string                            `json:"pre_stop_command"`
 	TerminationGracePeriodSeconds entity.TerminationGracePeriodSpan `json:"termination_grace_period_sec"`
 	CoverCommand                  string                            `json:"cover_command"`
@@ -95,7 +106,7 @@ type TaskParamDetailResp struct {
 	IsSupportStickySession bool `json:"is_support_sticky_session"`
 	// 会话保持cookie过期时间 单位秒
 	SessionCookieMaxAge int `json:"session_cookie_max_age"`
-	// 关闭pod反亲和性
+	// 关闭pod反亲和性, 拓扑分布约束及 PodDisruptionBudget
 	DisableHighAvailability bool `json:"disable_high_availability"`
 	// 关闭金丝雀发布
 	DisableCanary bool `json:"disable_canary"`
@@ -120,6 +131,22 @@ type TaskParamDetailResp struct {
 	CronScaleJobGroups []*entity.CronScaleJobGroup `json:"cron_scale_job_groups"`
 	// 定时扩缩容排除日期，五位时间模板，最小粒度为"天"，更小粒度填充"*"
 	CronScaleJobExcludeDates []string `json:"cron_scale_job_exclude_dates"`
+	// 渐进式金丝雀配置
+	ProgressiveCanary *entity.ProgressiveCanaryConfig `json:"progressive_canary"`
+	// 蓝绿部署旧版本保留时长
+	BlueGreenKeepWarmSeconds int `json:"blue_green_keep_warm_seconds"`
+	// 自定义扩缩容指标
+	HPAMetrics []*entity.HPAMetricSpec `json:"hpa_metrics"`
+	// 扩缩容行为策略
+	HPABehavior *entity.HPABehavior `json:"hpa_behavior"`
+	// 初始化容器
+	InitContainers []*entity.ExtraContainer `json:"init_containers"`
+	// 边车容器
+	Sidecars []*entity.ExtraContainer `json:"sidecars"`
+	// 存储卷
+	Volumes []*entity.AppVolume `json:"volumes"`
+	// 是否删除持久化存储卷
+	DeleteVolumes bool `json:"delete_volumes"`
 
 	CronCommand            string                    `json:"cron_command"`
 	CronParam              string                    `json:"cron_param"`
@@ -142,6 +169,7 @@ type TaskParamDetailResp struct {
 	CleanedAliAlarmName         string                      `json:"cleaned_ali_alarm_name,omitempty"`
 	CleanedAliLogConfigName     string                      `json:"cleaned_ali_log_config_name,omitempty"`
 	CleanedAliLogStoreName      string                      `json:"cleaned_ali_log_store_name,omitempty"`
+	CleanedVersion              string                      `json:"cleaned_version,omitempty"`
 }
 
 // ActiveTaskResp 活跃任务返回值
@@ -0,0 +1,22 @@
+package resp
+
+import (
+	"rulai/models/entity"
+)
+
+// TaskEventDetail 任务状态流转事件
+type TaskEventDetail struct {
+	ID             string             `json:"id" deepcopy:"objectid"`
+	TaskID         string             `json:"task_id"`
+	EnvName        entity.AppEnvName  `json:"env_name"`
+	ClusterName    entity.ClusterName `json:"cluster_name"`
+	Action         entity.TaskAction  `json:"action"`
+	FromStatus     entity.TaskStatus  `json:"from_status"`
+	ToStatus       entity.TaskStatus  `json:"to_status"`
+	Worker         string             `json:"worker"`
+	Error          string             `json:"error"`
+	RetryCount     int                `json:"retry_count"`
+	DurationMs     int64              `json:"duration_ms"`
+	ObjectVersions map[string]string  `json:"object_versions"`
+	CreateTime     string             `json:"create_time" deepcopy:"timeformat:2006-01-02 15:04:05"`
+}
@@ -0,0 +1,41 @@
+package resp
+
+// K8sObjectPreviewAction 预览中 K8s 对象的变更类型
+type K8sObjectPreviewAction string
+
+const (
+	K8sObjectPreviewActionCreate    K8sObjectPreviewAction = "create"
+	K8sObjectPreviewActionUpdate    K8sObjectPreviewAction = "update"
+	K8sObjectPreviewActionUnchanged K8sObjectPreviewAction = "unchanged"
+)
+
+// K8sObjectFieldDiffType 字段变更类型
+type K8sObjectFieldDiffType string
+
+const (
+	K8sObjectFieldDiffTypeAdded   K8sObjectFieldDiffType = "added"
+	K8sObjectFieldDiffTypeChanged K8sObjectFieldDiffType = "changed"
+	K8sObjectFieldDiffTypeRemoved K8sObjectFieldDiffType = "removed"
+)
+
+// K8sObjectFieldDiff K8s 对象字段级别的差异
+type K8sObjectFieldDiff struct {
+	Path string                 `json:"path"`
+	Type K8sObjectFieldDiffType `json:"type"`
+	Old  interface{}            `json:"old,omitempty"`
+	New  interface{}            `json:"new,omitempty"`
+}
+
+// K8sObjectPreview 任务将要 apply 的单个 K8s 对象与线上对象的差异
+type K8sObjectPreview struct {
+	Kind      string                 `json:"kind"`
+	Namespace string                 `json:"namespace"`
+	Name      string                 `json:"name"`
+	Action    K8sObjectPreviewAction `json:"action"`
+	Diffs     []*K8sObjectFieldDiff  `json:"diffs"`
+}
+
+// TaskPreviewResp 任务变更预览
+type TaskPreviewResp struct {
+	Objects []*K8sObjectPreview `json:"objects"`
+}
@@ -1,11 +1,25 @@
 package resp
 
+import "rulai/models/entity"
+
 type Variable struct {
 	ID         string           `json:"id" deepcopy:"objectid"`
 	Key        string           `json:"key"`
 	Value      string           `json:"value"`
+	Secret     bool             `json:"secret"`
 	Owner      *UserProfileResp `json:"owner"`
 	Editor     *UserProfileResp `json:"editor"`
 	CreateTime string           `json:"create_time" deepcopy:"timeformat:2006-01-02 15:04:05"`
 	UpdateTime string           `json:"update_time" deepcopy:"timeformat:2006-01-02 15:04:05"`
 }
+
+// VariableAuditResp 密钥变量审计记录
+type VariableAuditResp struct {
+	ID         string                     `json:"id" deepcopy:"objectid"`
+	VariableID string                     `json:"variable_id"`
+	Key        string                     `json:"key"`
+	Action     entity.VariableAuditAction `json:"action"`
+	Operator   *UserProfileResp           `json:"operator"`
+	TaskID     string                     `json:"task_id"`
+	CreateTime string                     `json:"create_time" deepcopy:"timeformat:2006-01-02 15:04:05"`
+}
@@ -242,6 +242,7 @@ func DeleteApp(c *gin.Context) {
 					CleanedServiceName:          serviceName,
 					CleanedAliAlarmName:         env.AliAlarmName,
 					CleanedAliLogConfigName:     app.AliLogConfigName,
+					DeleteVolumes:               deleteReq.DeleteVolumes,
 				},
 			}, operatorID)
 			if err != nil {
@@ -324,6 +325,7 @@ func GetAppDetail(c *gin.Context) {
 		res.ServiceProtocol = currentEnv.ServiceProtocol
 		res.EnableBranchChangeNotification = currentEnv.EnableBranchChangeNotification
 		res.EnableHotReload = currentEnv.EnableHotReload
+		res.EnableAutoRollback = currentEnv.EnableAutoRollback
 
 		extra, e := service.SVC.GetAppExtraInfo(c, getReq.ClusterName, project, app, getReq.EnvName)
 		if e != nil {
@@ -583,6 +585,7 @@ func CorrectAppName(c *gin.Context) {
 								CleanedAppType:              app.Type,
 								CleanedAppServiceType:       app.ServiceType,
 								CleanedAppServiceExposeType: app.ServiceExposeType,
+								// 重命名后新应用名的 PVC 不包含旧数据, 不删除旧 PVC 以便迁移
 							},
 						}, operatorID)
 						if err != nil {
@@ -968,6 +971,15 @@ func checkRunningStatus(ctx context.Context, clusterName entity.ClusterName, env
 	case entity.AppTypeOneTimeJob:
 
 	default:
+		if _, ok := entity.GetWorkloadType(appType); ok {
+			return service.SVC.CheckWorkloadsExistance(ctx, clusterName, appType, &req.GetWorkloadsReq{
+				Namespace:   string(envName),
+				ProjectName: projectName,
+				AppName:     appName,
+				Env:         string(envName),
+			})
+		}
+
 		exists, err := service.SVC.CheckDeploymentsExistance(ctx, clusterName, envName,
 			&req.GetDeploymentsReq{
 				Namespace:   string(envName),
@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"rulai/models"
+	"rulai/models/entity"
+	"rulai/models/req"
+	"rulai/service"
+	"rulai/utils"
+	_errcode "rulai/utils/errcode"
+	"rulai/utils/response"
+
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"gitlab.shanhai.int/sre/library/net/errcode"
+)
+
+func GetFreezeWindows(c *gin.Context) {
+	getReq := new(req.GetFreezeWindowsReq)
+	err := c.ShouldBindQuery(getReq)
+	if err != nil {
+		response.JSON(c, nil, errors.Wrapf(errcode.InvalidParams, "%s", err))
+		return
+	}
+
+	res, count, err := service.SVC.GetFreezeWindows(c, getReq)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	response.JSON(c, models.BaseListResponse{
+		List:  res,
+		Limit: getReq.Limit,
+		Page:  getReq.Page,
+		Count: count,
+	}, nil)
+}
+
+func CreateFreezeWindow(c *gin.Context) {
+	operatorID, ok := c.Value(utils.ContextUserIDKey).(string)
+	if !ok {
+		response.JSON(c, nil, errors.Wrap(errcode.InvalidParams, "operator id is invalid"))
+		return
+	}
+
+	createReq := new(req.CreateFreezeWindowReq)
+	err := c.ShouldBindJSON(createReq)
+	if err != nil {
+		response.JSON(c, nil, errors.Wrapf(errcode.InvalidParams, "%s", err))
+		return
+	}
+
+	if createReq.StartTime >= createReq.EndTime {
+		response.JSON(c, nil, errors.Wrap(errcode.InvalidParams, "start time must be before end time"))
+		return
+	}
+
+	err = validateFreezeWindowScopePermission(c, createReq.Scope, operatorID)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	createReq.OperatorID = operatorID
+
+	err = service.SVC.CreateSingleFreezeWindow(c, createReq)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	response.JSON(c, nil, nil)
+}
+
+func UpdateFreezeWindow(c *gin.Context) {
+	freezeWindowID := c.Param("freeze_window_id")
+
+	window, err := getAndValidateFreezeWindowPermission(c, freezeWindowID)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	updateReq := new(req.UpdateFreezeWindowReq)
+	err = c.ShouldBindJSON(updateReq)
+	if err != nil {
+		response.JSON(c, nil, errors.Wrapf(errcode.InvalidParams, "%s", err))
+		return
+	}
+
+	startTime, endTime := window.StartTime.Unix(), window.EndTime.Unix()
+	if updateReq.StartTime != 0 {
+		startTime = updateReq.StartTime
+	}
+	if updateReq.EndTime != 0 {
+		endTime = updateReq.EndTime
+	}
+	if startTime >= endTime {
+		response.JSON(c, nil, errors.Wrap(errcode.InvalidParams, "start time must be before end time"))
+		return
+	}
+
+	// 修改范围时按新范围校验权限
+	if updateReq.Scope != nil {
+		err = validateFreezeWindowScopePermission(c, updateReq.Scope, window.OperatorID)
+		if err != nil {
+			response.JSON(c, nil, err)
+			return
+		}
+	}
+
+	err = service.SVC.UpdateSingleFreezeWindow(c, freezeWindowID, updateReq)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	response.JSON(c, nil, nil)
+}
+
+func DeleteFreezeWindow(c *gin.Context) {
+	freezeWindowID := c.Param("freeze_window_id")
+
+	_, err := getAndValidateFreezeWindowPermission(c, freezeWindowID)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	err = service.SVC.DeleteSingleFreezeWindowByID(c, freezeWindowID)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	response.JSON(c, nil, nil)
+}
+
+func GetFreezeOverrideRecords(c *gin.Context) {
+	getReq := new(req.GetFreezeOverrideRecordsReq)
+	err := c.ShouldBindQuery(getReq)
+	if err != nil {
+		response.JSON(c, nil, errors.Wrapf(errcode.InvalidParams, "%s", err))
+		return
+	}
+
+	res, err := service.SVC.GetFreezeOverrideRecords(c, c.Param("freeze_window_id"), getReq)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	response.JSON(c, res, nil)
+}
+
+func getAndValidateFreezeWindowPermission(c context.Context, freezeWindowID string) (*entity.FreezeWindow, error) {
+	operatorID, ok := c.Value(utils.ContextUserIDKey).(string)
+	if !ok {
+		return nil, errors.Wrap(errcode.InvalidParams, "operator id is invalid")
+	}
+
+	window, err := service.SVC.FindSingleFreezeWindowByID(c, freezeWindowID)
+	if err != nil {
+		return nil, err
+	}
+
+	if operatorID != window.OperatorID {
+		return nil, errors.Wrapf(_errcode.GitlabUserNoPermissionError, "没有权限操作不属于自己的封网窗口")
+	}
+
+	return window, nil
+}
+
+// validateFreezeWindowScopePermission 全局及生产环境的封网窗口仅 SRE 可创建, 项目范围的窗口需为所有项目的负责人
+func validateFreezeWindowScopePermission(ctx context.Context, scope *entity.FreezeWindowScope, operatorID string) error {
+	if service.SVC.IsSREUser(operatorID) {
+		return nil
+	}
+
+	if scope.RequiresSRE() {
+		return errors.Wrap(_errcode.GitlabUserNoPermissionError, "只有SRE可以创建全局或生产环境的封网窗口")
+	}
+
+	for _, projectID := range scope.ProjectIDs {
+		project, err := service.SVC.GetProjectDetail(ctx, projectID)
+		if err != nil {
+			return err
+		}
+
+		isOwner := false
+		for _, owner := range project.Owners {
+			if owner.ID == operatorID {
+				isOwner = true
+				break
+			}
+		}
+		if !isOwner {
+			return errors.Wrapf(_errcode.GitlabUserNoPermissionError, "不是项目(%s)的负责人, 没有权限创建封网窗口", project.Name)
+		}
+	}
+
+	return nil
+}
+
+func CheckFreezeWindow(ctx *gin.Context) {
+	id := ctx.Param("freeze_window_id")
+
+	if id == "" {
+		return
+	}
+
+	_, err := service.SVC.FindSingleFreezeWindowByID(ctx, id)
+	if errcode.EqualError(_errcode.InvalidHexStringError, err) || errcode.EqualError(errcode.NoRowsFoundError, err) {
+		ctx.Abort()
+		response.JSON(ctx, nil, errors.Wrap(_errcode.NotFoundError, "freeze_window id 不存在"))
+
+		return
+	}
+	if err != nil {
+		ctx.Abort()
+		response.JSON(ctx, nil, err)
+
+		return
+	}
+}
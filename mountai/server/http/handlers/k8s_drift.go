@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"rulai/models"
+	"rulai/models/req"
+	"rulai/service"
+	"rulai/utils/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"gitlab.shanhai.int/sre/library/net/errcode"
+)
+
+func GetK8sDrifts(c *gin.Context) {
+	getReq := new(req.GetK8sDriftsReq)
+	err := c.ShouldBindQuery(getReq)
+	if err != nil {
+		response.JSON(c, nil, errors.Wrapf(errcode.InvalidParams, "%s", err))
+		return
+	}
+
+	res, count, err := service.SVC.GetK8sDrifts(c, getReq)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	response.JSON(c, models.BaseListResponse{
+		List:  res,
+		Limit: getReq.Limit,
+		Page:  getReq.Page,
+		Count: count,
+	}, nil)
+}
@@ -0,0 +1,277 @@
+package handlers
+
+import (
+	"rulai/models"
+	"rulai/models/entity"
+	"rulai/models/req"
+	"rulai/service"
+	"rulai/utils"
+	_errcode "rulai/utils/errcode"
+	"rulai/utils/response"
+
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"gitlab.shanhai.int/sre/library/net/errcode"
+)
+
+func GetMaintenanceSchedules(c *gin.Context) {
+	getReq := new(req.GetMaintenanceSchedulesReq)
+	err := c.ShouldBindQuery(getReq)
+	if err != nil {
+		response.JSON(c, nil, errors.Wrapf(errcode.InvalidParams, "%s", err))
+		return
+	}
+
+	res, count, err := service.SVC.GetMaintenanceSchedules(c, getReq)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	response.JSON(c, models.BaseListResponse{
+		List:  res,
+		Limit: getReq.Limit,
+		Page:  getReq.Page,
+		Count: count,
+	}, nil)
+}
+
+func GetMaintenanceScheduleDetail(c *gin.Context) {
+	res, err := service.SVC.GetMaintenanceScheduleDetail(c, c.Param("schedule_id"))
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	response.JSON(c, res, nil)
+}
+
+// CreateMaintenanceSchedule 创建定时运维计划, 创建人为计划负责人
+func CreateMaintenanceSchedule(c *gin.Context) {
+	operatorID, ok := c.Value(utils.ContextUserIDKey).(string)
+	if !ok {
+		response.JSON(c, nil, errors.Wrap(errcode.InvalidParams, "operator id is invalid"))
+		return
+	}
+
+	createReq := new(req.CreateMaintenanceScheduleReq)
+	err := c.ShouldBindJSON(createReq)
+	if err != nil {
+		response.JSON(c, nil, errors.Wrapf(errcode.InvalidParams, "%s", err))
+		return
+	}
+
+	if !entity.MaintenanceScheduleActions.Contains(createReq.Action) {
+		response.JSON(c, nil, errors.Wrapf(errcode.InvalidParams, "action %s is not supported by schedule", createReq.Action))
+		return
+	}
+
+	err = validateMaintenanceScheduleParams(createReq.Action, createReq.Cron, createReq.EndTime,
+		createReq.MinPodCount, createReq.MaxPodCount)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	createReq.ClusterName, err = service.SVC.CheckAndUnifyClusterName(c, createReq.ClusterName, createReq.EnvName)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	app, err := service.SVC.GetAppDetail(c, createReq.AppID)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	project, err := service.SVC.GetProjectDetail(c, app.ProjectID)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	// 与手动创建任务的权限一致
+	err = service.SVC.ValidateMaintenanceSchedulePermission(c, project, app, createReq.EnvName, createReq.Action, operatorID)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	createReq.ProjectID = app.ProjectID
+	createReq.OwnerID = operatorID
+
+	res, err := service.SVC.CreateSingleMaintenanceSchedule(c, createReq)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	response.JSON(c, res, nil)
+}
+
+func UpdateMaintenanceSchedule(c *gin.Context) {
+	schedule, err := getAndValidateMaintenanceSchedulePermission(c, c.Param("schedule_id"))
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	updateReq := new(req.UpdateMaintenanceScheduleReq)
+	err = c.ShouldBindJSON(updateReq)
+	if err != nil {
+		response.JSON(c, nil, errors.Wrapf(errcode.InvalidParams, "%s", err))
+		return
+	}
+
+	cron, endTime := schedule.Cron, int64(0)
+	if updateReq.Cron != "" {
+		cron = updateReq.Cron
+	}
+	if !updateReq.EndTime.IsZero() {
+		endTime = updateReq.EndTime.ValueOrZero()
+	}
+	minPodCount, maxPodCount := schedule.MinPodCount, schedule.MaxPodCount
+	if updateReq.MinPodCount > 0 {
+		minPodCount = updateReq.MinPodCount
+	}
+	if updateReq.MaxPodCount > 0 {
+		maxPodCount = updateReq.MaxPodCount
+	}
+
+	err = validateMaintenanceScheduleParams(schedule.Action, cron, endTime, minPodCount, maxPodCount)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	err = service.SVC.UpdateSingleMaintenanceSchedule(c, schedule, updateReq)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	response.JSON(c, nil, nil)
+}
+
+func DeleteMaintenanceSchedule(c *gin.Context) {
+	scheduleID := c.Param("schedule_id")
+
+	_, err := getAndValidateMaintenanceSchedulePermission(c, scheduleID)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	err = service.SVC.DeleteSingleMaintenanceScheduleByID(c, scheduleID)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	response.JSON(c, nil, nil)
+}
+
+func GetMaintenanceScheduleExecutions(c *gin.Context) {
+	getReq := new(req.GetMaintenanceScheduleExecutionsReq)
+	err := c.ShouldBindQuery(getReq)
+	if err != nil {
+		response.JSON(c, nil, errors.Wrapf(errcode.InvalidParams, "%s", err))
+		return
+	}
+
+	res, count, err := service.SVC.GetMaintenanceScheduleExecutions(c, c.Param("schedule_id"), getReq)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	response.JSON(c, models.BaseListResponse{
+		List:  res,
+		Limit: getReq.Limit,
+		Page:  getReq.Page,
+		Count: count,
+	}, nil)
+}
+
+// validateMaintenanceScheduleParams 校验 cron 表达式, 结束时间及扩缩容参数
+func validateMaintenanceScheduleParams(action entity.TaskAction, cron string, endTime int64,
+	minPodCount, maxPodCount int) error {
+	_, err := entity.ParseMaintenanceCron(cron)
+	if err != nil {
+		return errors.Wrap(errcode.InvalidParams, err.Error())
+	}
+
+	if endTime > 0 && endTime <= time.Now().Unix() {
+		return errors.Wrap(errcode.InvalidParams, "end time is before now")
+	}
+
+	if action != entity.TaskActionUpdateHPA {
+		return nil
+	}
+
+	if minPodCount == 0 {
+		return errors.Wrap(errcode.InvalidParams, "min_pod_count is 0")
+	}
+	if maxPodCount == 0 {
+		return errors.Wrap(errcode.InvalidParams, "max_pod_count is 0")
+	}
+	if maxPodCount < minPodCount {
+		return errors.Wrap(errcode.InvalidParams, "max_pod_count < min_pod_count")
+	}
+
+	return nil
+}
+
+// getAndValidateMaintenanceSchedulePermission 仅计划负责人或项目负责人可以修改计划
+func getAndValidateMaintenanceSchedulePermission(c context.Context, scheduleID string) (*entity.MaintenanceSchedule, error) {
+	operatorID, ok := c.Value(utils.ContextUserIDKey).(string)
+	if !ok {
+		return nil, errors.Wrap(errcode.InvalidParams, "operator id is invalid")
+	}
+
+	schedule, err := service.SVC.FindSingleMaintenanceScheduleByID(c, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+
+	if operatorID == schedule.OwnerID {
+		return schedule, nil
+	}
+
+	project, err := service.SVC.GetProjectDetail(c, schedule.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isProjectOwner(project, operatorID) {
+		return nil, errors.Wrapf(_errcode.GitlabUserNoPermissionError, "没有权限操作不属于自己的定时运维计划")
+	}
+
+	return schedule, nil
+}
+
+func CheckMaintenanceSchedule(ctx *gin.Context) {
+	id := ctx.Param("schedule_id")
+
+	if id == "" {
+		return
+	}
+
+	_, err := service.SVC.FindSingleMaintenanceScheduleByID(ctx, id)
+	if errcode.EqualError(_errcode.InvalidHexStringError, err) || errcode.EqualError(errcode.NoRowsFoundError, err) {
+		ctx.Abort()
+		response.JSON(ctx, nil, errors.Wrap(_errcode.NotFoundError, "maintenance schedule id 不存在"))
+
+		return
+	}
+	if err != nil {
+		ctx.Abort()
+		response.JSON(ctx, nil, err)
+
+		return
+	}
+}
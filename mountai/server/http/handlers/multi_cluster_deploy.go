@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"rulai/models"
+	"rulai/models/entity"
+	"rulai/models/req"
+	"rulai/models/resp"
+	"rulai/service"
+	"rulai/utils"
+	_errcode "rulai/utils/errcode"
+	"rulai/utils/response"
+
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"gitlab.shanhai.int/sre/library/base/deepcopy.v2"
+	"gitlab.shanhai.int/sre/library/net/errcode"
+)
+
+func CreateMultiClusterDeploy(c *gin.Context) {
+	createReq := new(req.CreateMultiClusterDeployReq)
+	err := c.ShouldBindJSON(createReq)
+	if err != nil {
+		response.JSON(c, nil, errors.Wrapf(errcode.InvalidParams, "%s", err))
+		return
+	}
+
+	// 校验 config
+	err = checkAndUnifyConfigParams(createReq.Param)
+	if err != nil {
+		response.JSON(c, nil, errors.Wrap(errcode.InvalidParams, err.Error()))
+		return
+	}
+
+	// 获取操作人id
+	operatorID, ok := c.Value(utils.ContextUserIDKey).(string)
+	if !ok {
+		response.JSON(c, nil, errors.Wrap(errcode.InvalidParams, "operator id is invalid"))
+		return
+	}
+
+	if !entity.TaskActionMultiClusterDeployList.Contains(createReq.Action) {
+		response.JSON(c, nil, errors.Wrapf(errcode.InvalidParams, "action %s is not supported by multi cluster deploy",
+			createReq.Action))
+		return
+	}
+
+	// 校验应用
+	app, err := service.SVC.GetAppDetail(c, createReq.AppID)
+	if errcode.EqualError(_errcode.InvalidHexStringError, err) || errcode.EqualError(errcode.NoRowsFoundError, err) {
+		response.JSON(c, nil, errors.Wrap(errcode.InvalidParams, err.Error()))
+		return
+	}
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	if app.Type != entity.AppTypeService && app.Type != entity.AppTypeWorker {
+		response.JSON(c, nil, errors.Wrapf(errcode.InvalidParams, "app type %s is not supported by multi cluster deploy",
+			app.Type))
+		return
+	}
+
+	// 校验项目
+	project, err := service.SVC.GetProjectDetail(c, app.ProjectID)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	// 校验集群名
+	for _, wave := range createReq.Waves {
+		for i := range wave {
+			wave[i], err = service.SVC.CheckAndUnifyClusterName(c, wave[i], createReq.EnvName)
+			if err != nil {
+				response.JSON(c, nil, err)
+				return
+			}
+		}
+	}
+
+	waves, err := service.SVC.GetMultiClusterDeployWaves(c, project.ID, createReq.EnvName, createReq.Waves)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	waveReqs, err := transMultiClusterDeployToWaveTasks(c, operatorID, createReq, project, app, waves)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	res, err := service.SVC.CreateMultiClusterDeploy(c, project, app, createReq, waveReqs, operatorID)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	response.JSON(c, res, nil)
+}
+
+// transMultiClusterDeployToWaveTasks 生成各批次集群的任务请求, 校验错误汇总后返回
+func transMultiClusterDeployToWaveTasks(ctx context.Context, operatorID string, createReq *req.CreateMultiClusterDeployReq,
+	project *resp.ProjectDetailResp, app *resp.AppDetailResp,
+	waves [][]entity.ClusterName) ([][]*req.CreateTaskReq, error) {
+	res := make([][]*req.CreateTaskReq, 0, len(waves))
+	errGroup := errcode.NewGroup(_errcode.BatchCreateTaskWarningsError)
+	for _, wave := range waves {
+		taskReqs := make([]*req.CreateTaskReq, 0, len(wave))
+		for _, clusterName := range wave {
+			// 获取上次成功任务(同集群)
+			latestSuccessTask, err := service.SVC.GetLatestDeploySuccessTaskFinalVersion(ctx, &req.GetLatestTaskReq{
+				AppID:       app.ID,
+				EnvName:     createReq.EnvName,
+				ClusterName: clusterName,
+			})
+			if err != nil {
+				errGroup = errGroup.AddChildren(errors.Wrap(err, "cluster_name="+string(clusterName)))
+				continue
+			}
+
+			// 以上次成功任务作为基本参数
+			taskReq := new(req.CreateTaskReq)
+			err = deepcopy.Copy(latestSuccessTask).To(taskReq)
+			if err != nil {
+				return nil, errors.Wrap(errcode.InternalError, err.Error())
+			}
+
+			// 额外赋值
+			taskReq.EnvName = createReq.EnvName
+			taskReq.ClusterName = clusterName
+			taskReq.Action = createReq.Action
+			taskReq.AppID = app.ID
+			taskReq.OperatorID = operatorID
+			taskReq.Description = createReq.Description
+			taskReq.Param.ConfigRenameMode = createReq.Param.ConfigRenameMode
+			taskReq.Param.ConfigRenamePrefix = createReq.Param.ConfigRenamePrefix
+			switch taskReq.Action {
+			case entity.TaskActionCanaryDeploy, entity.TaskActionFullDeploy:
+				taskReq.Version = ""
+				taskReq.Param.ImageVersion = createReq.Param.ImageVersion
+			}
+			if createReq.Param.ConfigCommitID != "" {
+				taskReq.Param.ConfigCommitID = createReq.Param.ConfigCommitID
+			}
+			taskReq.IgnoreExpectedBranch = true
+
+			// 校验是否允许创建任务
+			err = validateIsAllowCreateTask(ctx, taskReq, project, app, operatorID)
+			if err != nil {
+				errGroup = errGroup.AddChildren(errors.Wrap(err, "cluster_name="+string(clusterName)))
+				continue
+			}
+
+			// Set empty.
+			taskReq.Approval, taskReq.DeployType, taskReq.ScheduleTime = new(req.ApprovalReq), "", 0
+
+			// 校验封网窗口
+			taskReq.FreezeOverrideReason = createReq.FreezeOverrideReason
+			err = service.SVC.CheckTaskFreezeWindow(ctx, project, taskReq, operatorID)
+			if err != nil {
+				errGroup = errGroup.AddChildren(errors.Wrap(err, "cluster_name="+string(clusterName)))
+				continue
+			}
+
+			// 校验错误预算
+			taskReq.ErrorBudgetOverrideReason = createReq.ErrorBudgetOverrideReason
+			err = service.SVC.CheckTaskErrorBudget(ctx, project, app, taskReq, operatorID)
+			if err != nil {
+				errGroup = errGroup.AddChildren(errors.Wrap(err, "cluster_name="+string(clusterName)))
+				continue
+			}
+
+			taskReqs = append(taskReqs, taskReq)
+		}
+
+		res = append(res, taskReqs)
+	}
+
+	if len(errGroup.Details()) > 0 {
+		return nil, errGroup
+	}
+
+	return res, nil
+}
+
+func GetMultiClusterDeploys(c *gin.Context) {
+	getReq := new(req.GetMultiClusterDeploysReq)
+	err := c.ShouldBindQuery(getReq)
+	if err != nil {
+		response.JSON(c, nil, errors.Wrapf(errcode.InvalidParams, "%s", err))
+		return
+	}
+
+	res, count, err := service.SVC.GetMultiClusterDeploys(c, getReq)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	response.JSON(c, models.BaseListResponse{
+		List:  res,
+		Limit: getReq.Limit,
+		Page:  getReq.Page,
+		Count: count,
+	}, nil)
+}
+
+func GetMultiClusterDeployDetail(c *gin.Context) {
+	res, err := service.SVC.GetMultiClusterDeployDetail(c, c.Param("deploy_id"))
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	response.JSON(c, res, nil)
+}
+
+func CheckMultiClusterDeploy(ctx *gin.Context) {
+	id := ctx.Param("deploy_id")
+
+	if id == "" {
+		return
+	}
+
+	_, err := service.SVC.FindSingleMultiClusterDeployByID(ctx, id)
+	if errcode.EqualError(_errcode.InvalidHexStringError, err) || errcode.EqualError(errcode.NoRowsFoundError, err) {
+		ctx.Abort()
+		response.JSON(ctx, nil, errors.Wrap(_errcode.NotFoundError, "multi cluster deploy id 不存在"))
+
+		return
+	}
+	if err != nil {
+		ctx.Abort()
+		response.JSON(ctx, nil, err)
+
+		return
+	}
+}
@@ -0,0 +1,376 @@
+package handlers
+
+import (
+	"rulai/models"
+	"rulai/models/entity"
+	"rulai/models/req"
+	"rulai/service"
+	"rulai/utils"
+	_errcode "rulai/utils/errcode"
+	"rulai/utils/response"
+
+	"net/mail"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"gitlab.shanhai.int/sre/library/net/errcode"
+)
+
+func GetNotificationChannels(c *gin.Context) {
+	projectID := c.Param("project_id")
+
+	err := validateManageNotificationPermission(c, projectID)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	res, err := service.SVC.GetNotificationChannels(c, projectID)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	response.JSON(c, res, nil)
+}
+
+func CreateNotificationChannel(c *gin.Context) {
+	operatorID, ok := c.Value(utils.ContextUserIDKey).(string)
+	if !ok {
+		response.JSON(c, nil, errors.Wrap(errcode.InvalidParams, "operator id is invalid"))
+		return
+	}
+
+	projectID := c.Param("project_id")
+
+	err := validateManageNotificationPermission(c, projectID)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	createReq := new(req.CreateNotificationChannelReq)
+	err = c.ShouldBindJSON(createReq)
+	if err != nil {
+		response.JSON(c, nil, errors.Wrapf(errcode.InvalidParams, "%s", err))
+		return
+	}
+
+	if !createReq.Type.IsConfigurable() {
+		response.JSON(c, nil, errors.Wrapf(errcode.InvalidParams, "unsupported notification channel type: %s", createReq.Type))
+		return
+	}
+
+	err = validateNotificationChannelTarget(createReq.Type, createReq.URL, createReq.Emails)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	createReq.ProjectID = projectID
+	createReq.OperatorID = operatorID
+
+	err = service.SVC.CreateNotificationChannel(c, createReq)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	response.JSON(c, nil, nil)
+}
+
+func UpdateNotificationChannel(c *gin.Context) {
+	channel, err := getAndValidateNotificationChannel(c)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	updateReq := new(req.UpdateNotificationChannelReq)
+	err = c.ShouldBindJSON(updateReq)
+	if err != nil {
+		response.JSON(c, nil, errors.Wrapf(errcode.InvalidParams, "%s", err))
+		return
+	}
+
+	targetURL, emails := channel.URL, channel.Emails
+	if updateReq.URL != "" {
+		targetURL = updateReq.URL
+	}
+	if len(updateReq.Emails) > 0 {
+		emails = updateReq.Emails
+	}
+
+	err = validateNotificationChannelTarget(channel.Type, targetURL, emails)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	err = service.SVC.UpdateNotificationChannel(c, channel.ID.Hex(), updateReq)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	response.JSON(c, nil, nil)
+}
+
+func DeleteNotificationChannel(c *gin.Context) {
+	channel, err := getAndValidateNotificationChannel(c)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	err = service.SVC.DeleteNotificationChannel(c, channel.ID.Hex())
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	response.JSON(c, nil, nil)
+}
+
+func GetNotificationRules(c *gin.Context) {
+	projectID := c.Param("project_id")
+
+	err := validateManageNotificationPermission(c, projectID)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	res, err := service.SVC.GetNotificationRules(c, projectID)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	response.JSON(c, res, nil)
+}
+
+func CreateNotificationRule(c *gin.Context) {
+	operatorID, ok := c.Value(utils.ContextUserIDKey).(string)
+	if !ok {
+		response.JSON(c, nil, errors.Wrap(errcode.InvalidParams, "operator id is invalid"))
+		return
+	}
+
+	projectID := c.Param("project_id")
+
+	err := validateManageNotificationPermission(c, projectID)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	createReq := new(req.CreateNotificationRuleReq)
+	err = c.ShouldBindJSON(createReq)
+	if err != nil {
+		response.JSON(c, nil, errors.Wrapf(errcode.InvalidParams, "%s", err))
+		return
+	}
+
+	err = validateNotificationRule(c, projectID, createReq.ChannelID, createReq.Events)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	createReq.ProjectID = projectID
+	createReq.OperatorID = operatorID
+
+	err = service.SVC.CreateNotificationRule(c, createReq)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	response.JSON(c, nil, nil)
+}
+
+func UpdateNotificationRule(c *gin.Context) {
+	projectID := c.Param("project_id")
+
+	rule, err := getAndValidateNotificationRule(c)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	updateReq := new(req.UpdateNotificationRuleReq)
+	err = c.ShouldBindJSON(updateReq)
+	if err != nil {
+		response.JSON(c, nil, errors.Wrapf(errcode.InvalidParams, "%s", err))
+		return
+	}
+
+	channelID, events := rule.ChannelID, rule.Events
+	if updateReq.ChannelID != "" {
+		channelID = updateReq.ChannelID
+	}
+	if len(updateReq.Events) > 0 {
+		events = updateReq.Events
+	}
+
+	err = validateNotificationRule(c, projectID, channelID, events)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	err = service.SVC.UpdateNotificationRule(c, rule.ID.Hex(), updateReq)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	response.JSON(c, nil, nil)
+}
+
+func DeleteNotificationRule(c *gin.Context) {
+	rule, err := getAndValidateNotificationRule(c)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	err = service.SVC.DeleteNotificationRuleByID(c, rule.ID.Hex())
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	response.JSON(c, nil, nil)
+}
+
+func GetNotificationDeliveries(c *gin.Context) {
+	getReq := new(req.GetNotificationDeliveriesReq)
+	err := c.ShouldBindQuery(getReq)
+	if err != nil {
+		response.JSON(c, nil, errors.Wrapf(errcode.InvalidParams, "%s", err))
+		return
+	}
+
+	res, count, err := service.SVC.GetNotificationDeliveries(c, c.Param("project_id"), getReq)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	response.JSON(c, models.BaseListResponse{
+		List:  res,
+		Limit: getReq.Limit,
+		Page:  getReq.Page,
+		Count: count,
+	}, nil)
+}
+
+// validateManageNotificationPermission 校验操作人是否有权限管理项目通知
+func validateManageNotificationPermission(c *gin.Context, projectID string) error {
+	operatorID, ok := c.Value(utils.ContextUserIDKey).(string)
+	if !ok {
+		return errors.Wrap(errcode.InvalidParams, "operator id is invalid")
+	}
+
+	return service.SVC.ValidateHasPermission(c, &req.ValidateHasPermissionReq{
+		OperateType: entity.OperateTypeManageNotification,
+		ProjectID:   projectID,
+		OperatorID:  operatorID,
+	})
+}
+
+// getAndValidateNotificationChannel 获取路径中的通知渠道并校验权限
+func getAndValidateNotificationChannel(c *gin.Context) (*entity.NotificationChannel, error) {
+	projectID := c.Param("project_id")
+
+	err := validateManageNotificationPermission(c, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	channel, err := service.SVC.FindSingleNotificationChannelByID(c, c.Param("channel_id"))
+	if err != nil {
+		return nil, err
+	}
+
+	if channel.ProjectID != projectID {
+		return nil, errors.Wrap(_errcode.NotFoundError, "notification channel id 不存在")
+	}
+
+	return channel, nil
+}
+
+// getAndValidateNotificationRule 获取路径中的通知规则并校验权限
+func getAndValidateNotificationRule(c *gin.Context) (*entity.NotificationRule, error) {
+	projectID := c.Param("project_id")
+
+	err := validateManageNotificationPermission(c, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	rule, err := service.SVC.FindSingleNotificationRuleByID(c, c.Param("rule_id"))
+	if err != nil {
+		return nil, err
+	}
+
+	if rule.ProjectID != projectID {
+		return nil, errors.Wrap(_errcode.NotFoundError, "notification rule id 不存在")
+	}
+
+	return rule, nil
+}
+
+// validateNotificationChannelTarget 校验通知渠道的发送地址
+func validateNotificationChannelTarget(channelType entity.NotificationChannelType, targetURL string, emails []string) error {
+	switch channelType {
+	case entity.NotificationChannelTypeWebhook, entity.NotificationChannelTypeSlack:
+		u, err := url.ParseRequestURI(targetURL)
+		if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+			return errors.Wrapf(errcode.InvalidParams, "invalid notification url: %s", targetURL)
+		}
+
+		if channelType == entity.NotificationChannelTypeSlack && u.Scheme != "https" {
+			return errors.Wrap(errcode.InvalidParams, "slack webhook url must use https")
+		}
+
+	case entity.NotificationChannelTypeEmail:
+		if len(emails) == 0 {
+			return errors.Wrap(errcode.InvalidParams, "emails can not be empty")
+		}
+
+		for _, email := range emails {
+			if _, err := mail.ParseAddress(email); err != nil {
+				return errors.Wrapf(errcode.InvalidParams, "invalid email: %s", email)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateNotificationRule 校验通知规则的渠道及事件
+func validateNotificationRule(c *gin.Context, projectID, channelID string, events []entity.NotificationEvent) error {
+	for _, event := range events {
+		if !event.IsValid() {
+			return errors.Wrapf(errcode.InvalidParams, "invalid notification event: %s", event)
+		}
+	}
+
+	channel, err := service.SVC.FindSingleNotificationChannelByID(c, channelID)
+	if errcode.EqualError(_errcode.InvalidHexStringError, err) || errcode.EqualError(errcode.NoRowsFoundError, err) {
+		return errors.Wrapf(errcode.InvalidParams, "notification channel %s does not exist", channelID)
+	}
+	if err != nil {
+		return err
+	}
+
+	if channel.ProjectID != projectID {
+		return errors.Wrapf(errcode.InvalidParams, "notification channel %s does not belong to project", channelID)
+	}
+
+	return nil
+}
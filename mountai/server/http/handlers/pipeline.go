@@ -0,0 +1,432 @@
+package handlers
+
+import (
+	"rulai/models"
+	"rulai/models/entity"
+	"rulai/models/req"
+	"rulai/models/resp"
+	"rulai/service"
+	"rulai/utils"
+	_errcode "rulai/utils/errcode"
+	"rulai/utils/response"
+
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"gitlab.shanhai.int/sre/library/net/errcode"
+)
+
+func GetPipelines(c *gin.Context) {
+	getReq := new(req.GetPipelinesReq)
+	err := c.ShouldBindQuery(getReq)
+	if err != nil {
+		response.JSON(c, nil, errors.Wrapf(errcode.InvalidParams, "%s", err))
+		return
+	}
+
+	res, count, err := service.SVC.GetPipelines(c, getReq)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	response.JSON(c, models.BaseListResponse{
+		List:  res,
+		Limit: getReq.Limit,
+		Page:  getReq.Page,
+		Count: count,
+	}, nil)
+}
+
+func GetPipelineDetail(c *gin.Context) {
+	res, err := service.SVC.GetPipelineDetail(c, c.Param("pipeline_id"))
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	response.JSON(c, res, nil)
+}
+
+func CreatePipeline(c *gin.Context) {
+	operatorID, ok := c.Value(utils.ContextUserIDKey).(string)
+	if !ok {
+		response.JSON(c, nil, errors.Wrap(errcode.InvalidParams, "operator id is invalid"))
+		return
+	}
+
+	createReq := new(req.CreatePipelineReq)
+	err := c.ShouldBindJSON(createReq)
+	if err != nil {
+		response.JSON(c, nil, errors.Wrapf(errcode.InvalidParams, "%s", err))
+		return
+	}
+
+	app, err := service.SVC.GetAppDetail(c, createReq.AppID)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	// 流水线阶段均为全量部署, 仅支持常驻类型应用
+	if app.Type != entity.AppTypeService && app.Type != entity.AppTypeWorker {
+		response.JSON(c, nil, errors.Wrapf(errcode.InvalidParams, "app type %s is not supported by pipeline", app.Type))
+		return
+	}
+
+	err = service.SVC.ValidatePipelineStages(c, createReq.Stages)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	project, err := service.SVC.GetProjectDetail(c, app.ProjectID)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	// 阶段任务由流水线创建, 创建人需有各阶段环境的部署权限
+	err = service.SVC.ValidatePipelineStagesPermission(c, project, createReq.Stages, operatorID)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	createReq.ProjectID = app.ProjectID
+	createReq.OperatorID = operatorID
+
+	err = service.SVC.CreateSinglePipeline(c, createReq)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	response.JSON(c, nil, nil)
+}
+
+func UpdatePipeline(c *gin.Context) {
+	pipelineID := c.Param("pipeline_id")
+
+	pipeline, err := getAndValidatePipelinePermission(c, pipelineID)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	updateReq := new(req.UpdatePipelineReq)
+	err = c.ShouldBindJSON(updateReq)
+	if err != nil {
+		response.JSON(c, nil, errors.Wrapf(errcode.InvalidParams, "%s", err))
+		return
+	}
+
+	if len(updateReq.Stages) > 0 {
+		err = service.SVC.ValidatePipelineStages(c, updateReq.Stages)
+		if err != nil {
+			response.JSON(c, nil, err)
+			return
+		}
+
+		project, e := service.SVC.GetProjectDetail(c, pipeline.ProjectID)
+		if e != nil {
+			response.JSON(c, nil, e)
+			return
+		}
+
+		operatorID, _ := c.Value(utils.ContextUserIDKey).(string)
+		err = service.SVC.ValidatePipelineStagesPermission(c, project, updateReq.Stages, operatorID)
+		if err != nil {
+			response.JSON(c, nil, err)
+			return
+		}
+	}
+
+	err = service.SVC.UpdateSinglePipeline(c, pipelineID, updateReq)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	response.JSON(c, nil, nil)
+}
+
+func DeletePipeline(c *gin.Context) {
+	pipelineID := c.Param("pipeline_id")
+
+	_, err := getAndValidatePipelinePermission(c, pipelineID)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	running, err := service.SVC.HasRunningPipelineRun(c, pipelineID)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+	if running {
+		response.JSON(c, nil, errors.Wrap(errcode.InvalidParams, "pipeline has a running run"))
+		return
+	}
+
+	err = service.SVC.DeleteSinglePipelineByID(c, pipelineID)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	response.JSON(c, nil, nil)
+}
+
+func CreatePipelineRun(c *gin.Context) {
+	operatorID, ok := c.Value(utils.ContextUserIDKey).(string)
+	if !ok {
+		response.JSON(c, nil, errors.Wrap(errcode.InvalidParams, "operator id is invalid"))
+		return
+	}
+
+	createReq := new(req.CreatePipelineRunReq)
+	err := c.ShouldBindJSON(createReq)
+	if err != nil {
+		response.JSON(c, nil, errors.Wrapf(errcode.InvalidParams, "%s", err))
+		return
+	}
+
+	pipeline, err := service.SVC.FindSinglePipelineByID(c, c.Param("pipeline_id"))
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	err = validatePipelineRunStages(c, pipeline, createReq, operatorID)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	createReq.OperatorID = operatorID
+
+	res, err := service.SVC.CreatePipelineRun(c, pipeline, createReq)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	response.JSON(c, res, nil)
+}
+
+func GetPipelineRuns(c *gin.Context) {
+	getReq := new(req.GetPipelineRunsReq)
+	err := c.ShouldBindQuery(getReq)
+	if err != nil {
+		response.JSON(c, nil, errors.Wrapf(errcode.InvalidParams, "%s", err))
+		return
+	}
+
+	res, count, err := service.SVC.GetPipelineRuns(c, c.Param("pipeline_id"), getReq)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	response.JSON(c, models.BaseListResponse{
+		List:  res,
+		Limit: getReq.Limit,
+		Page:  getReq.Page,
+		Count: count,
+	}, nil)
+}
+
+func GetPipelineRunDetail(c *gin.Context) {
+	res, err := service.SVC.GetPipelineRunDetail(c, c.Param("run_id"))
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	response.JSON(c, res, nil)
+}
+
+func ApprovePipelineRun(c *gin.Context) {
+	operatorID, ok := c.Value(utils.ContextUserIDKey).(string)
+	if !ok {
+		response.JSON(c, nil, errors.Wrap(errcode.InvalidParams, "operator id is invalid"))
+		return
+	}
+
+	run, err := service.SVC.FindSinglePipelineRunByID(c, c.Param("run_id"))
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	project, err := service.SVC.GetProjectDetail(c, run.ProjectID)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	// 人工卡点仅项目负责人可以放行
+	if !isProjectOwner(project, operatorID) {
+		response.JSON(c, nil, errors.Wrap(_errcode.CreateTaskNoPermissionError, "only project owners can approve pipeline"))
+		return
+	}
+
+	err = service.SVC.ApprovePipelineRunGate(c, run, operatorID)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	response.JSON(c, nil, nil)
+}
+
+func CancelPipelineRun(c *gin.Context) {
+	operatorID, ok := c.Value(utils.ContextUserIDKey).(string)
+	if !ok {
+		response.JSON(c, nil, errors.Wrap(errcode.InvalidParams, "operator id is invalid"))
+		return
+	}
+
+	run, err := service.SVC.FindSinglePipelineRunByID(c, c.Param("run_id"))
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	if run.OperatorID != operatorID {
+		project, e := service.SVC.GetProjectDetail(c, run.ProjectID)
+		if e != nil {
+			response.JSON(c, nil, e)
+			return
+		}
+
+		if !isProjectOwner(project, operatorID) {
+			response.JSON(c, nil, errors.Wrap(_errcode.GitlabUserNoPermissionError, "没有权限取消不属于自己的流水线运行"))
+			return
+		}
+	}
+
+	err = service.SVC.CancelPipelineRun(c, run, operatorID)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	response.JSON(c, nil, nil)
+}
+
+// validatePipelineRunStages 按各阶段将要创建的任务校验运行人的权限及参数, 与创建任务接口的校验一致
+func validatePipelineRunStages(c context.Context, pipeline *entity.Pipeline,
+	createReq *req.CreatePipelineRunReq, operatorID string) error {
+	app, err := service.SVC.GetAppDetail(c, pipeline.AppID)
+	if err != nil {
+		return err
+	}
+
+	project, err := service.SVC.GetProjectDetail(c, app.ProjectID)
+	if err != nil {
+		return err
+	}
+
+	for i, stage := range pipeline.Stages {
+		taskReq, err := service.SVC.BuildPipelineStageTaskReq(c, app, stage.EnvName, stage.ClusterName,
+			createReq.ImageVersion, createReq.ConfigCommitID)
+		if errcode.EqualError(_errcode.NoRequiredTaskError, err) {
+			return errors.Wrapf(errcode.InvalidParams, "stage %d: no successful deployment to inherit params from", i)
+		}
+		if err != nil {
+			return err
+		}
+
+		err = service.SVC.ValidatePipelineStageTask(c, project, taskReq, operatorID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func isProjectOwner(project *resp.ProjectDetailResp, userID string) bool {
+	for _, owner := range project.Owners {
+		if owner.ID == userID {
+			return true
+		}
+	}
+
+	return false
+}
+
+func getAndValidatePipelinePermission(c context.Context, pipelineID string) (*entity.Pipeline, error) {
+	operatorID, ok := c.Value(utils.ContextUserIDKey).(string)
+	if !ok {
+		return nil, errors.Wrap(errcode.InvalidParams, "operator id is invalid")
+	}
+
+	pipeline, err := service.SVC.FindSinglePipelineByID(c, pipelineID)
+	if err != nil {
+		return nil, err
+	}
+
+	if operatorID == pipeline.OperatorID {
+		return pipeline, nil
+	}
+
+	project, err := service.SVC.GetProjectDetail(c, pipeline.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isProjectOwner(project, operatorID) {
+		return nil, errors.Wrapf(_errcode.GitlabUserNoPermissionError, "没有权限操作不属于自己的流水线")
+	}
+
+	return pipeline, nil
+}
+
+func CheckPipeline(ctx *gin.Context) {
+	id := ctx.Param("pipeline_id")
+
+	if id == "" {
+		return
+	}
+
+	_, err := service.SVC.FindSinglePipelineByID(ctx, id)
+	if errcode.EqualError(_errcode.InvalidHexStringError, err) || errcode.EqualError(errcode.NoRowsFoundError, err) {
+		ctx.Abort()
+		response.JSON(ctx, nil, errors.Wrap(_errcode.NotFoundError, "pipeline id 不存在"))
+
+		return
+	}
+	if err != nil {
+		ctx.Abort()
+		response.JSON(ctx, nil, err)
+
+		return
+	}
+}
+
+func CheckPipelineRun(ctx *gin.Context) {
+	id := ctx.Param("run_id")
+
+	if id == "" {
+		return
+	}
+
+	_, err := service.SVC.FindSinglePipelineRunByID(ctx, id)
+	if errcode.EqualError(_errcode.InvalidHexStringError, err) || errcode.EqualError(errcode.NoRowsFoundError, err) {
+		ctx.Abort()
+		response.JSON(ctx, nil, errors.Wrap(_errcode.NotFoundError, "pipeline run id 不存在"))
+
+		return
+	}
+	if err != nil {
+		ctx.Abort()
+		response.JSON(ctx, nil, err)
+
+		return
+	}
+}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"rulai/models"
+	"rulai/models/entity"
+	"rulai/models/req"
+	"rulai/service"
+	"rulai/utils"
+	_errcode "rulai/utils/errcode"
+	"rulai/utils/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"gitlab.shanhai.int/sre/library/base/deepcopy.v2"
+	"gitlab.shanhai.int/sre/library/net/errcode"
+)
+
+func GetRightSizingReport(c *gin.Context) {
+	getReq := new(req.GetRightSizingReportReq)
+	err := c.ShouldBindQuery(getReq)
+	if err != nil {
+		response.JSON(c, nil, errors.Wrapf(errcode.InvalidParams, "%s", err))
+		return
+	}
+
+	res, count, err := service.SVC.GetRightSizingReport(c, getReq)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	response.JSON(c, models.BaseListResponse{
+		List:  res,
+		Limit: getReq.Limit,
+		Page:  getReq.Page,
+		Count: count,
+	}, nil)
+}
+
+// ApplyRightSizingRecommendation 以最新部署参数为基础, 按推荐规格创建全量部署任务
+func ApplyRightSizingRecommendation(c *gin.Context) {
+	applyReq := new(req.ApplyRightSizingReq)
+	err := c.ShouldBindJSON(applyReq)
+	if err != nil {
+		response.JSON(c, nil, errors.Wrapf(errcode.InvalidParams, "%s", err))
+		return
+	}
+
+	operatorID, ok := c.Value(utils.ContextUserIDKey).(string)
+	if !ok {
+		response.JSON(c, nil, errors.Wrap(errcode.InvalidParams, "operator id is invalid"))
+		return
+	}
+
+	recommendation, err := service.SVC.FindSingleRightSizingRecommendationByID(c, c.Param("recommendation_id"))
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	if !recommendation.NeedResize() {
+		response.JSON(c, nil, errors.Wrap(errcode.InvalidParams, "resource requests are already recommended values"))
+		return
+	}
+
+	app, err := service.SVC.GetAppDetail(c, recommendation.AppID)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	project, err := service.SVC.GetProjectDetail(c, app.ProjectID)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	latestSuccessTask, err := service.SVC.GetLatestDeploySuccessTaskFinalVersion(c, &req.GetLatestTaskReq{
+		AppID:       app.ID,
+		EnvName:     recommendation.EnvName,
+		ClusterName: recommendation.ClusterName,
+	})
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	// 推荐生成后有新的部署时, 推荐基于的参数已过期
+	if latestSuccessTask.ID != recommendation.TaskID {
+		response.JSON(c, nil, errors.Wrap(errcode.InvalidParams, "app has been deployed since the recommendation was generated"))
+		return
+	}
+
+	// 以上次成功任务作为基本参数
+	createReq := new(req.CreateTaskReq)
+	err = deepcopy.Copy(latestSuccessTask).To(createReq)
+	if err != nil {
+		response.JSON(c, nil, errors.Wrap(errcode.InternalError, err.Error()))
+		return
+	}
+
+	createReq.Action = entity.TaskActionFullDeploy
+	createReq.AppID = app.ID
+	createReq.OperatorID = operatorID
+	createReq.Version = ""
+	createReq.Description = applyReq.Description
+	createReq.Param.CPURequest = recommendation.RecommendedCPURequest
+	createReq.Param.MemRequest = recommendation.RecommendedMemRequest
+	createReq.IgnoreExpectedBranch = true
+
+	// 校验是否允许创建任务
+	err = validateIsAllowCreateTask(c, createReq, project, app, operatorID)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	// 与创建任务接口一致, 由调用方指定部署方式及审批, 生产环境不允许绕过审批校验
+	if createReq.EnvName == entity.AppEnvPrd && applyReq.DeployType == "" {
+		response.JSON(c, nil, errors.Wrap(errcode.InvalidParams, "deploy type is required when env is prd"))
+		return
+	}
+
+	createReq.Approval, createReq.DeployType, createReq.ScheduleTime =
+		applyReq.Approval, applyReq.DeployType, applyReq.ScheduleTime
+	if createReq.Approval == nil {
+		createReq.Approval = new(req.ApprovalReq)
+		createReq.Approval.Type = entity.SkipTaskApprovalType
+	}
+
+	if err = validateApprovalParams(c, createReq, project, app, operatorID); err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	// 校验封网窗口
+	createReq.FreezeOverrideReason = applyReq.FreezeOverrideReason
+	if err = service.SVC.CheckTaskFreezeWindow(c, project, createReq, operatorID); err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	// 校验错误预算
+	createReq.ErrorBudgetOverrideReason = applyReq.ErrorBudgetOverrideReason
+	if err = service.SVC.CheckTaskErrorBudget(c, project, app, createReq, operatorID); err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	res, err := service.SVC.CreateTask(c, project, app, createReq, operatorID)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	response.JSON(c, res, nil)
+}
+
+func CheckRightSizingRecommendation(ctx *gin.Context) {
+	id := ctx.Param("recommendation_id")
+
+	if id == "" {
+		return
+	}
+
+	_, err := service.SVC.FindSingleRightSizingRecommendationByID(ctx, id)
+	if errcode.EqualError(_errcode.InvalidHexStringError, err) || errcode.EqualError(errcode.NoRowsFoundError, err) {
+		ctx.Abort()
+		response.JSON(ctx, nil, errors.Wrap(_errcode.NotFoundError, "right sizing recommendation id 不存在"))
+
+		return
+	}
+	if err != nil {
+		ctx.Abort()
+		response.JSON(ctx, nil, err)
+
+		return
+	}
+}
@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"rulai/models/entity"
+	"rulai/models/req"
+	"rulai/models/resp"
+	"rulai/service"
+	"rulai/utils"
+	_errcode "rulai/utils/errcode"
+	"rulai/utils/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"gitlab.shanhai.int/sre/library/net/errcode"
+)
+
+func GetAppSLOStatus(c *gin.Context) {
+	getReq := new(req.GetAppSLOReq)
+	err := c.ShouldBindQuery(getReq)
+	if err != nil {
+		response.JSON(c, nil, errors.Wrapf(errcode.InvalidParams, "%s", err))
+		return
+	}
+
+	app, err := service.SVC.GetAppDetail(c, c.Param("id"))
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	project, err := service.SVC.GetProjectDetail(c, app.ProjectID)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	res, err := service.SVC.GetAppSLOStatus(c, project, app, getReq.EnvName)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	response.JSON(c, res, nil)
+}
+
+func CreateSLO(c *gin.Context) {
+	operatorID, ok := c.Value(utils.ContextUserIDKey).(string)
+	if !ok {
+		response.JSON(c, nil, errors.Wrap(errcode.InvalidParams, "operator id is invalid"))
+		return
+	}
+
+	app, err := getAndValidateSLOApp(c)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	createReq := new(req.CreateSLOReq)
+	err = c.ShouldBindJSON(createReq)
+	if err != nil {
+		response.JSON(c, nil, errors.Wrapf(errcode.InvalidParams, "%s", err))
+		return
+	}
+
+	if _, ok = app.Env[createReq.EnvName]; !ok {
+		response.JSON(c, nil, errors.Wrapf(errcode.InvalidParams, "env(%s) not found in app(%s)", createReq.EnvName, app.ID))
+		return
+	}
+
+	if createReq.Type != entity.SLOTypeAvailability && createReq.Type != entity.SLOTypeLatency {
+		response.JSON(c, nil, errors.Wrapf(errcode.InvalidParams, "unsupported slo type: %s", createReq.Type))
+		return
+	}
+
+	err = validateSLOParams(createReq.Type, createReq.Objective, createReq.WindowDays, createReq.LatencyThresholdMs)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	count, err := service.SVC.CountAppSLOs(c, app.ID, createReq.EnvName)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	if count >= entity.SLOMaxCountPerAppEnv {
+		response.JSON(c, nil, errors.Wrapf(errcode.InvalidParams,
+			"slo count of app(%s) in env(%s) exceeds %d", app.ID, createReq.EnvName, entity.SLOMaxCountPerAppEnv))
+		return
+	}
+
+	createReq.AppID = app.ID
+	createReq.OperatorID = operatorID
+
+	err = service.SVC.CreateSLO(c, createReq)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	response.JSON(c, nil, nil)
+}
+
+func UpdateSLO(c *gin.Context) {
+	slo, err := getAndValidateSLO(c)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	updateReq := new(req.UpdateSLOReq)
+	err = c.ShouldBindJSON(updateReq)
+	if err != nil {
+		response.JSON(c, nil, errors.Wrapf(errcode.InvalidParams, "%s", err))
+		return
+	}
+
+	objective, windowDays, latencyThresholdMs := slo.Objective, slo.WindowDays, slo.LatencyThresholdMs
+	if updateReq.Objective != 0 {
+		objective = updateReq.Objective
+	}
+	if updateReq.WindowDays != 0 {
+		windowDays = updateReq.WindowDays
+	}
+	if updateReq.LatencyThresholdMs != 0 {
+		latencyThresholdMs = updateReq.LatencyThresholdMs
+	}
+
+	err = validateSLOParams(slo.Type, objective, windowDays, latencyThresholdMs)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	err = service.SVC.UpdateSLO(c, slo.ID.Hex(), updateReq)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	response.JSON(c, nil, nil)
+}
+
+func DeleteSLO(c *gin.Context) {
+	slo, err := getAndValidateSLO(c)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	err = service.SVC.DeleteSLOByID(c, slo.ID.Hex())
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	response.JSON(c, nil, nil)
+}
+
+// getAndValidateSLOApp 获取路径中的应用并校验管理 SLO 的权限
+func getAndValidateSLOApp(c *gin.Context) (*resp.AppDetailResp, error) {
+	operatorID, ok := c.Value(utils.ContextUserIDKey).(string)
+	if !ok {
+		return nil, errors.Wrap(errcode.InvalidParams, "operator id is invalid")
+	}
+
+	app, err := service.SVC.GetAppDetail(c, c.Param("id"))
+	if err != nil {
+		return nil, err
+	}
+
+	err = service.SVC.ValidateHasPermission(c, &req.ValidateHasPermissionReq{
+		OperateType: entity.OperateTypeManageSLO,
+		ProjectID:   app.ProjectID,
+		OperatorID:  operatorID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return app, nil
+}
+
+// getAndValidateSLO 获取路径中的 SLO 并校验权限
+func getAndValidateSLO(c *gin.Context) (*entity.SLO, error) {
+	app, err := getAndValidateSLOApp(c)
+	if err != nil {
+		return nil, err
+	}
+
+	slo, err := service.SVC.FindSingleSLOByID(c, c.Param("slo_id"))
+	if err != nil {
+		return nil, err
+	}
+
+	if slo.AppID != app.ID {
+		return nil, errors.Wrap(_errcode.NotFoundError, "slo id 不存在")
+	}
+
+	return slo, nil
+}
+
+// validateSLOParams 校验 SLO 目标及统计参数
+func validateSLOParams(sloType entity.SLOType, objective float64, windowDays int, latencyThresholdMs float64) error {
+	if objective <= 0 || objective >= 1 {
+		return errors.Wrapf(errcode.InvalidParams, "objective(%v) must be between 0 and 1", objective)
+	}
+
+	if windowDays < 0 || windowDays > entity.SLOMaxWindowDays {
+		return errors.Wrapf(errcode.InvalidParams, "window_days(%d) must be between 1 and %d", windowDays, entity.SLOMaxWindowDays)
+	}
+
+	if sloType == entity.SLOTypeLatency && latencyThresholdMs <= 0 {
+		return errors.Wrap(errcode.InvalidParams, "latency_threshold_ms is required for latency slo")
+	}
+
+	return nil
+}
@@ -132,6 +132,7 @@ func GetRunningPodLogs(c *gin.Context) {
 			Name:          podName,
 			Env:           string(getReq.EnvName),
 			ContainerName: getReq.ContainerName,
+			Previous:      getReq.Previous,
 		})
 	if err != nil {
 		response.JSON(c, nil, err)
@@ -99,8 +99,40 @@ func CreateTask(c *gin.Context) {
 	createReq.Namespace = service.SVC.GetNamespaceBase(service.SVC.GetApplicationIstioState(
 		context.Background(), createReq.EnvName, createReq.ClusterName, app), createReq.EnvName)
 
+	// 回滚任务使用上一次部署成功的任务参数
+	if createReq.Action == entity.TaskActionRollback {
+		if app.Type != entity.AppTypeService && app.Type != entity.AppTypeWorker {
+			response.JSON(c, nil, errors.Wrap(errcode.InvalidParams, "action is not supported"))
+			return
+		}
+		err = service.SVC.FillRollbackTaskReq(c, createReq)
+		if err != nil {
+			response.JSON(c, nil, err)
+			return
+		}
+	}
+
+	// 蓝绿部署及切回需要根据当前运行版本确定目标版本
+	if createReq.Action == entity.TaskActionBlueGreenDeploy || createReq.Action == entity.TaskActionBlueGreenSwitchBack {
+		if app.Type != entity.AppTypeService {
+			response.JSON(c, nil, errors.Wrap(errcode.InvalidParams, "action is only supported for service"))
+			return
+		}
+
+		if createReq.Action == entity.TaskActionBlueGreenDeploy {
+			err = service.SVC.FillBlueGreenTaskReq(c, project, app, createReq)
+		} else {
+			err = service.SVC.FillBlueGreenSwitchBackTaskReq(c, createReq)
+		}
+		if err != nil {
+			response.JSON(c, nil, err)
+			return
+		}
+	}
+
 	// 校验cpu/memory参数
-	if createReq.Action == entity.TaskActionFullDeploy || createReq.Action == entity.TaskActionCanaryDeploy {
+	if createReq.Action == entity.TaskActionFullDeploy || createReq.Action == entity.TaskActionCanaryDeploy ||
+		createReq.Action == entity.TaskActionBlueGreenDeploy {
 		err = service.SVC.ValidateResourceRequirements(&v1.ResourceRequirements{
 			Requests: v1.ResourceList{
 				v1.ResourceCPU:    resource.MustParse(string(createReq.Param.CPURequest)),
@@ -136,6 +168,18 @@ func CreateTask(c *gin.Context) {
 		return
 	}
 
+	// 校验封网窗口
+	if err = service.SVC.CheckTaskFreezeWindow(c, project, createReq, operatorID); err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	// 校验错误预算
+	if err = service.SVC.CheckTaskErrorBudget(c, project, app, createReq, operatorID); err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
 	// Create task.
 	res, err := service.SVC.CreateTask(c, project, app, createReq, operatorID)
 	if err != nil {
@@ -145,6 +189,72 @@ func CreateTask(c *gin.Context) {
 	response.JSON(c, res, nil)
 }
 
+// PreviewTask 预览任务将要变更的 K8s 对象, 不创建任务
+func PreviewTask(c *gin.Context) {
+	createReq := new(req.CreateTaskReq)
+	err := c.ShouldBindJSON(createReq)
+	if err != nil {
+		response.JSON(c, nil, errors.Wrapf(errcode.InvalidParams, "%s", err))
+		return
+	}
+
+	// 校验 config
+	err = checkAndUnifyConfigParams(createReq.Param)
+	if err != nil {
+		response.JSON(c, nil, errors.Wrap(errcode.InvalidParams, err.Error()))
+		return
+	}
+
+	operatorID, ok := c.Value(utils.ContextUserIDKey).(string)
+	if !ok {
+		response.JSON(c, nil, errors.Wrap(errcode.InvalidParams, "operator id is invalid"))
+		return
+	}
+
+	createReq.ClusterName, err = service.SVC.CheckAndUnifyClusterName(c, createReq.ClusterName, createReq.EnvName)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	app, err := service.SVC.GetAppDetail(c, createReq.AppID)
+	if errcode.EqualError(_errcode.InvalidHexStringError, err) || errcode.EqualError(errcode.NoRowsFoundError, err) {
+		response.JSON(c, nil, errors.Wrap(errcode.InvalidParams, err.Error()))
+		return
+	}
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	project, err := service.SVC.GetProjectDetail(c, app.ProjectID)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	// 预览包含解密后的配置及线上对象, 与创建任务的权限一致
+	err = service.SVC.ValidateHasPermission(c, &req.ValidateHasPermissionReq{
+		OperateType:       entity.OperateTypeCreateTask,
+		CreateTaskEnvName: createReq.EnvName,
+		CreateTaskAction:  createReq.Action,
+		ProjectID:         project.ID,
+		OperatorID:        operatorID,
+	})
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	res, err := service.SVC.PreviewTask(c, project, app, createReq, operatorID)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	response.JSON(c, res, nil)
+}
+
 // validateIsExpectBranch 验证当前部署的分支是否是预期分支
 func validateIsExpectBranch(ctx context.Context, createReq *req.CreateTaskReq) error {
 	// Step: 以下情况跳过验证
@@ -228,6 +338,11 @@ func validateIsAllowCreateTask(ctx context.Context, createReq *req.CreateTaskReq
 	}
 
 	action := createReq.Action
+	// 可插拔工作负载仅支持其注册的行为
+	if wt, ok := entity.GetWorkloadType(app.Type); ok && !wt.SupportsAction(action) {
+		return errors.Wrapf(errcode.InvalidParams, "action %s is not supported by %s", action, app.Type)
+	}
+
 	// 校验部署类操作
 	for _, deployAction := range entity.TaskActionInitDeployList {
 		if action != deployAction {
@@ -237,7 +352,7 @@ func validateIsAllowCreateTask(ctx context.Context, createReq *req.CreateTaskReq
 			action != entity.TaskActionFullDeploy {
 			return errors.Wrap(errcode.InvalidParams, "action should be full_deploy")
 		}
-		err = validateDeployTaskParams(ctx, createReq, app)
+		err = validateDeployTaskParams(ctx, createReq, project, app)
 		if err != nil {
 			return err
 		}
@@ -472,7 +587,7 @@ func validateUpdateHPA(_ context.Context, createReq *req.CreateTaskReq) (err err
 		return errors.Wrap(errcode.InvalidParams, e.Error())
 	}
 
-	return nil
+	return validateHPAMetricsAndBehavior(createReq.Param)
 }
 
 // validateManualLaunchCronJob 检测手动启动的参数
@@ -497,7 +612,7 @@ func validateManualLaunchCronJob(ctx context.Context, createReq *req.CreateTaskR
 
 // 校验部署任务的参数
 func validateDeployTaskParams(ctx context.Context, createReq *req.CreateTaskReq,
-	app *resp.AppDetailResp) error {
+	project *resp.ProjectDetailResp, app *resp.AppDetailResp) error {
 	param := createReq.Param
 
 	if param.ImageVersion == "" {
@@ -540,6 +655,10 @@ func validateDeployTaskParams(ctx context.Context, createReq *req.CreateTaskReq,
 		if param.FailedHistoryLimit == 0 {
 			return errors.Wrap(errcode.InvalidParams, "failed history limit is empty")
 		}
+	case entity.AppTypeStatefulSet:
+		if param.MinPodCount == 0 {
+			return errors.Wrap(errcode.InvalidParams, "min_pod_count is 0")
+		}
 	case entity.AppTypeService, entity.AppTypeWorker:
 		if tp == entity.AppTypeService {
 			err := validateServiceDeployTaskParams(ctx, createReq, app)
@@ -564,9 +683,47 @@ func validateDeployTaskParams(ctx context.Context, createReq *req.CreateTaskReq,
 			if err != nil {
 				return err
 			}
+
+			err = validateHPAMetricsAndBehavior(param)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if param.ProgressiveCanary != nil {
+		err := validateProgressiveCanary(createReq, app)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(param.SecretVars) > 0 {
+		err := validateSecretVars(ctx, createReq, app)
+		if err != nil {
+			return err
 		}
 	}
 
+	if len(param.InitContainers) > 0 || len(param.Sidecars) > 0 {
+		err := validateExtraContainers(createReq, project, app)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(param.Volumes) > 0 {
+		err := validateAppVolumes(createReq, app)
+		if err != nil {
+			return err
+		}
+	}
+
+	if param.BlueGreenKeepWarmSeconds < 0 || param.BlueGreenKeepWarmSeconds > entity.BlueGreenMaxKeepWarmSeconds {
+		return errors.Wrapf(errcode.InvalidParams, "blue green keep warm seconds should between 0 and %d",
+			entity.BlueGreenMaxKeepWarmSeconds)
+	}
+
 	// 校验分支是否是预期分支
 	if err := validateIsExpectBranch(ctx, createReq); err != nil {
 		return err
@@ -575,6 +732,112 @@ func validateDeployTaskParams(ctx context.Context, createReq *req.CreateTaskReq,
 	return nil
 }
 
+// validateExtraContainers 校验初始化容器及边车容器, 资源规格需在项目当前环境的可用规格内
+func validateExtraContainers(createReq *req.CreateTaskReq, project *resp.ProjectDetailResp, app *resp.AppDetailResp) error {
+	switch app.Type {
+	case entity.AppTypeService, entity.AppTypeWorker, entity.AppTypeCronJob, entity.AppTypeOneTimeJob:
+	default:
+		return errors.Wrapf(errcode.InvalidParams, "init containers and sidecars are not supported by %s", app.Type)
+	}
+
+	if len(createReq.Param.Sidecars) > 0 && !entity.AppTypeSupportsSidecars(app.Type) {
+		return errors.Wrapf(errcode.InvalidParams, "sidecars are not supported by %s, use init containers instead", app.Type)
+	}
+
+	var spec *entity.ProjectResourceSpec
+	if s, ok := project.ResourceSpec[createReq.EnvName]; ok {
+		spec = (*entity.ProjectResourceSpec)(&s)
+	}
+
+	err := entity.ValidateExtraContainers(utils.GetPodContainerName(project.Name, app.Name),
+		createReq.Param.InitContainers, createReq.Param.Sidecars, spec)
+	if err != nil {
+		return errors.Wrap(errcode.InvalidParams, err.Error())
+	}
+
+	return nil
+}
+
+// validateAppVolumes 校验应用存储卷
+func validateAppVolumes(createReq *req.CreateTaskReq, app *resp.AppDetailResp) error {
+	switch app.Type {
+	case entity.AppTypeService, entity.AppTypeWorker, entity.AppTypeCronJob, entity.AppTypeOneTimeJob:
+	default:
+		return errors.Wrapf(errcode.InvalidParams, "volumes are not supported by %s", app.Type)
+	}
+
+	param := createReq.Param
+	err := entity.ValidateAppVolumes(param.Volumes, param.InitContainers, param.Sidecars)
+	if err != nil {
+		return errors.Wrap(errcode.InvalidParams, err.Error())
+	}
+
+	err = entity.ValidateAppVolumeAccessModes(app.Type, param.Volumes)
+	if err != nil {
+		return errors.Wrap(errcode.InvalidParams, err.Error())
+	}
+
+	return nil
+}
+
+// validateProgressiveCanary 校验渐进式金丝雀配置
+func validateProgressiveCanary(createReq *req.CreateTaskReq, app *resp.AppDetailResp) error {
+	cfg := createReq.Param.ProgressiveCanary
+	if createReq.Action != entity.TaskActionCanaryDeploy || app.Type != entity.AppTypeService {
+		return errors.Wrap(errcode.InvalidParams, "progressive canary is only supported for service canary deploy")
+	}
+	if cfg.Steps < 1 || cfg.Steps > entity.ProgressiveCanaryMaxSteps {
+		return errors.Wrapf(errcode.InvalidParams, "progressive canary steps should between 1 and %d", entity.ProgressiveCanaryMaxSteps)
+	}
+	if cfg.BakeSeconds < entity.ProgressiveCanaryMinBakeSeconds || cfg.BakeSeconds > entity.ProgressiveCanaryMaxBakeSeconds {
+		return errors.Wrapf(errcode.InvalidParams, "progressive canary bake seconds should between %d and %d",
+			entity.ProgressiveCanaryMinBakeSeconds, entity.ProgressiveCanaryMaxBakeSeconds)
+	}
+	if cfg.StepPodCount < 0 || cfg.MaxErrorRate < 0 || cfg.MaxErrorRate > 1 || cfg.MaxLatencyMs < 0 {
+		return errors.Wrap(errcode.InvalidParams, "progressive canary threshold is invalid")
+	}
+	if cfg.MaxErrorRate == 0 && cfg.MaxLatencyMs == 0 {
+		return errors.Wrap(errcode.InvalidParams, "progressive canary requires max_error_rate or max_latency_ms")
+	}
+
+	return nil
+}
+
+// validateSecretVars 校验注入的密钥变量
+func validateSecretVars(ctx context.Context, createReq *req.CreateTaskReq, app *resp.AppDetailResp) error {
+	param := createReq.Param
+	if createReq.Action != entity.TaskActionFullDeploy && createReq.Action != entity.TaskActionCanaryDeploy {
+		return errors.Wrap(errcode.InvalidParams, "secret vars are only supported for full deploy and canary deploy")
+	}
+	if app.Type != entity.AppTypeService && app.Type != entity.AppTypeWorker {
+		return errors.Wrap(errcode.InvalidParams, "secret vars are only supported for service and worker")
+	}
+
+	keys := make(map[string]struct{}, len(param.SecretVars))
+	for _, key := range param.SecretVars {
+		if !entity.IsValidSecretVariableKey(key) {
+			return errors.Wrapf(errcode.InvalidParams, "secret var %s is not a valid env name", key)
+		}
+		if _, ok := keys[key]; ok {
+			return errors.Wrapf(errcode.InvalidParams, "secret var %s is duplicated", key)
+		}
+		if _, ok := param.Vars[key]; ok {
+			return errors.Wrapf(errcode.InvalidParams, "secret var %s conflicts with vars", key)
+		}
+		keys[key] = struct{}{}
+	}
+
+	variables, err := service.SVC.GetProjectSecretVariables(ctx, app.ProjectID, param.SecretVars)
+	if err != nil {
+		return err
+	}
+	if len(variables) != len(param.SecretVars) {
+		return errors.Wrap(errcode.InvalidParams, "secret vars not found in project")
+	}
+
+	return nil
+}
+
 // validateCronAutoScaleJobs 校验cronHPA扩缩容任务
 func validateCronAutoScaleJobs(param *req.CreateTaskParamReq) error {
 	if len(param.CronScaleJobGroups) == 0 && len(param.CronScaleJobExcludeDates) != 0 {
@@ -593,6 +856,90 @@ func validateCronAutoScaleJobs(param *req.CreateTaskParamReq) error {
 	return nil
 }
 
+// validateHPAMetricsAndBehavior 校验自定义扩缩容指标及扩缩容行为策略
+func validateHPAMetricsAndBehavior(param *req.CreateTaskParamReq) error {
+	if len(param.HPAMetrics) > entity.HPAMaxCustomMetrics {
+		return errors.Wrapf(errcode.InvalidParams, "hpa metrics should not be more than %d", entity.HPAMaxCustomMetrics)
+	}
+
+	for _, m := range param.HPAMetrics {
+		if m == nil {
+			return errors.Wrap(errcode.InvalidParams, "hpa metric is empty")
+		}
+		if _, ok := entity.HPAMetricSourceTargetTypes[m.Type]; !ok {
+			return errors.Wrapf(errcode.InvalidParams, "hpa metric type %s is not supported", m.Type)
+		}
+		if m.MetricName == "" {
+			return errors.Wrap(errcode.InvalidParams, "hpa metric name is empty")
+		}
+		if !m.SupportsTargetType() {
+			return errors.Wrapf(errcode.InvalidParams, "hpa %s metric does not support target type %s", m.Type, m.TargetType)
+		}
+
+		quantity, err := resource.ParseQuantity(m.TargetValue)
+		if err != nil {
+			return errors.Wrapf(errcode.InvalidParams, "hpa metric %s target value is invalid: %s", m.MetricName, err)
+		}
+		if quantity.Sign() <= 0 {
+			return errors.Wrapf(errcode.InvalidParams, "hpa metric %s target value should be positive", m.MetricName)
+		}
+
+		if m.Type == entity.HPAMetricSourceTypeObject {
+			if m.DescribedObject == nil || m.DescribedObject.Kind == "" || m.DescribedObject.Name == "" {
+				return errors.Wrapf(errcode.InvalidParams, "hpa object metric %s requires described object", m.MetricName)
+			}
+		} else if m.DescribedObject != nil {
+			return errors.Wrapf(errcode.InvalidParams, "hpa %s metric should not have described object", m.Type)
+		}
+	}
+
+	if param.HPABehavior == nil {
+		return nil
+	}
+
+	for _, rules := range []*entity.HPAScalingRules{param.HPABehavior.ScaleUp, param.HPABehavior.ScaleDown} {
+		err := validateHPAScalingRules(rules)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateHPAScalingRules 校验单方向的扩缩容策略
+func validateHPAScalingRules(rules *entity.HPAScalingRules) error {
+	if rules == nil {
+		return nil
+	}
+
+	if w := rules.StabilizationWindowSeconds; w != nil && (*w < 0 || *w > entity.HPAMaxStabilizationWindowSeconds) {
+		return errors.Wrapf(errcode.InvalidParams, "hpa stabilization window seconds should between 0 and %d",
+			entity.HPAMaxStabilizationWindowSeconds)
+	}
+
+	switch rules.SelectPolicy {
+	case "", entity.HPAScalingSelectPolicyMax, entity.HPAScalingSelectPolicyMin, entity.HPAScalingSelectPolicyDisabled:
+	default:
+		return errors.Wrapf(errcode.InvalidParams, "hpa select policy %s is not supported", rules.SelectPolicy)
+	}
+
+	for _, p := range rules.Policies {
+		if p == nil || (p.Type != entity.HPAScalingPolicyTypePods && p.Type != entity.HPAScalingPolicyTypePercent) {
+			return errors.Wrap(errcode.InvalidParams, "hpa scaling policy type is invalid")
+		}
+		if p.Value <= 0 {
+			return errors.Wrap(errcode.InvalidParams, "hpa scaling policy value should be positive")
+		}
+		if p.PeriodSeconds <= 0 || p.PeriodSeconds > entity.HPAMaxScalingPolicyPeriodSeconds {
+			return errors.Wrapf(errcode.InvalidParams, "hpa scaling policy period seconds should between 1 and %d",
+				entity.HPAMaxScalingPolicyPeriodSeconds)
+		}
+	}
+
+	return nil
+}
+
 // 校验服务部署任务的参数
 func validateServiceDeployTaskParams(ctx context.Context, createReq *req.CreateTaskReq,
 	app *resp.AppDetailResp) error {
@@ -693,6 +1040,35 @@ func GetTaskDetail(c *gin.Context) {
 	response.JSON(c, task, nil)
 }
 
+// GetTaskEvents : 获取任务状态流转事件
+func GetTaskEvents(c *gin.Context) {
+	events, err := service.SVC.GetTaskEvents(c, c.Param("id"))
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	response.JSON(c, events, nil)
+}
+
+// GetTaskPhaseStats : 获取任务阶段耗时统计
+func GetTaskPhaseStats(c *gin.Context) {
+	getReq := new(req.GetTaskPhaseStatsReq)
+	err := c.ShouldBindQuery(getReq)
+	if err != nil {
+		response.JSON(c, nil, errors.Wrapf(errcode.InvalidParams, "%s", err))
+		return
+	}
+
+	stats, err := service.SVC.GetTaskPhaseStats(c, getReq)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	response.JSON(c, stats, nil)
+}
+
 // GetTasks : 获取任务列表
 func GetTasks(c *gin.Context) {
 	getReq := new(req.GetTasksReq)
@@ -1035,6 +1411,22 @@ func transBatchCreateTaskToTasksMapping(ctx context.Context, operatorID string,
 		// Set empty.
 		createReq.Approval, createReq.DeployType, createReq.ScheduleTime = new(req.ApprovalReq), "", 0
 
+		// 校验封网窗口
+		createReq.FreezeOverrideReason = batchReq.FreezeOverrideReason
+		err = service.SVC.CheckTaskFreezeWindow(ctx, project, createReq, operatorID)
+		if err != nil {
+			errGroup = errGroup.AddChildren(errors.Wrap(err, "app_name="+app.Name))
+			continue
+		}
+
+		// 校验错误预算
+		createReq.ErrorBudgetOverrideReason = batchReq.ErrorBudgetOverrideReason
+		err = service.SVC.CheckTaskErrorBudget(ctx, project, appDetail, createReq, operatorID)
+		if err != nil {
+			errGroup = errGroup.AddChildren(errors.Wrap(err, "app_name="+app.Name))
+			continue
+		}
+
 		createTaskReqMap[app.ID] = createReq
 	}
 
@@ -1097,7 +1489,8 @@ func validateApprovalParams(ctx context.Context, createReq *req.CreateTaskReq, p
 	}
 
 	if createReq.Approval.Type != entity.SkipTaskApprovalType {
-		if createReq.Action != entity.TaskActionFullDeploy && createReq.Action != entity.TaskActionCanaryDeploy {
+		if createReq.Action != entity.TaskActionFullDeploy && createReq.Action != entity.TaskActionCanaryDeploy &&
+			createReq.Action != entity.TaskActionBlueGreenDeploy {
 			return errors.Wrap(errcode.InvalidParams, "task cant not create approval")
 		}
 		if operatorID == entity.K8sSystemUserID {
@@ -1180,7 +1573,7 @@ func validateApprovalParams(ctx context.Context, createReq *req.CreateTaskReq, p
 func CheckTask(ctx *gin.Context) {
 	id := ctx.Param("id")
 
-	if id == "" || id == "latest" { // 过滤特殊通配路由
+	if id == "" || id == "latest" || id == "phase_stats" { // 过滤特殊通配路由
 		return
 	}
 
@@ -1247,8 +1640,15 @@ func checkTaskAction(env entity.AppEnvName, action entity.TaskAction, apps []*re
 	}
 
 	for _, app := range apps {
+		actions, ok := actionMap[app.Type]
+		if !ok {
+			if wt, exists := entity.GetWorkloadType(app.Type); exists {
+				actions = wt.BatchActions
+			}
+		}
+
 		valid := false
-		for _, v := range actionMap[app.Type] {
+		for _, v := range actions {
 			if action == v {
 				valid = true
 				break
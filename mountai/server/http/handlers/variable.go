@@ -101,6 +101,11 @@ func UpdateVariable(c *gin.Context) {
 		return
 	}
 
+	if variable.Secret && updateReq.Key != "" && !entity.IsValidSecretVariableKey(updateReq.Key) {
+		response.JSON(c, nil, errors.Wrapf(errcode.InvalidParams, "密钥变量名需符合环境变量命名规范"))
+		return
+	}
+
 	if updateReq.Key != "" && variable.Key != updateReq.Key {
 		unique, intErr := service.SVC.CheckVariableKeyUnique(c, &req.GetVariablesReq{
 			Key:       updateReq.Key,
@@ -120,7 +125,7 @@ func UpdateVariable(c *gin.Context) {
 
 	updateReq.OperatorID = operatorID
 
-	err = service.SVC.UpdateSingleVariable(c, variableID, updateReq)
+	err = service.SVC.UpdateSingleVariable(c, variable, updateReq)
 	if err != nil {
 		response.JSON(c, nil, err)
 		return
@@ -179,6 +184,11 @@ func CreateVariable(c *gin.Context) {
 		return
 	}
 
+	if createReq.Secret && !entity.IsValidSecretVariableKey(createReq.Key) {
+		response.JSON(c, nil, errors.Wrapf(errcode.InvalidParams, "密钥变量名需符合环境变量命名规范"))
+		return
+	}
+
 	createReq.OperatorID = operatorID
 
 	variable, err := service.SVC.CreateSingleVariable(c, createReq)
@@ -187,6 +197,10 @@ func CreateVariable(c *gin.Context) {
 		return
 	}
 
+	if variable.Secret {
+		variable.Value = entity.SecretVariableMask
+	}
+
 	response.JSON(c, variable, nil)
 }
 
@@ -229,6 +243,57 @@ func DeleteVariable(c *gin.Context) {
 	response.JSON(c, nil, nil)
 }
 
+// GetVariableAudits 获取密钥变量的读取审计记录
+func GetVariableAudits(c *gin.Context) {
+	operatorID, ok := c.Value(utils.ContextUserIDKey).(string)
+	if !ok {
+		response.JSON(c, nil, errors.Wrap(errcode.InvalidParams, "operator id is invalid"))
+		return
+	}
+
+	getReq := new(req.GetVariableAuditsReq)
+	err := c.ShouldBindQuery(getReq)
+	if err != nil {
+		response.JSON(c, nil, errors.Wrapf(errcode.InvalidParams, "%s", err))
+		return
+	}
+
+	variable, err := service.SVC.FindSingleVariableByID(c, c.Param("variable_id"))
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	if variable.Type != entity.ProjectVariableType {
+		response.JSON(c, nil, errors.Wrapf(errcode.InvalidParams, "不支持type为%d的变量", variable.Type))
+		return
+	}
+
+	err = service.SVC.ValidateHasPermission(c, &req.ValidateHasPermissionReq{
+		OperateType: entity.OperateTypeReadVariableValue,
+		ProjectID:   variable.ProjectID,
+		OperatorID:  operatorID,
+	})
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	getReq.VariableID = variable.ID.Hex()
+	audits, count, err := service.SVC.GetVariableAudits(c, getReq)
+	if err != nil {
+		response.JSON(c, nil, err)
+		return
+	}
+
+	response.JSON(c, models.BaseListResponse{
+		List:  audits,
+		Limit: getReq.Limit,
+		Page:  getReq.Page,
+		Count: count,
+	}, nil)
+}
+
 func CheckVariable(ctx *gin.Context) {
 	id := ctx.Param("variable_id")
 
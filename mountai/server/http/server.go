@@ -84,6 +84,13 @@ func addAuthV1Router(authV1 *gin.RouterGroup) {
 	addNamespaceRouter(authV1.Group("/namespaces"))
 	addConfigRenamePrefixesRouter(authV1.Group("/config_rename_prefixes"))
 	addUpstreamV1Router(authV1.Group("/upstream"))
+	addFreezeWindowRouter(authV1.Group("/freeze_windows", handlers.CheckFreezeWindow))
+	addPipelineRouter(authV1.Group("/pipelines", handlers.CheckPipeline))
+	addPipelineRunRouter(authV1.Group("/pipeline_runs", handlers.CheckPipelineRun))
+	addMultiClusterDeployRouter(authV1.Group("/multi_cluster_deploys", handlers.CheckMultiClusterDeploy))
+	addRightSizingRouter(authV1.Group("/right_sizing", handlers.CheckRightSizingRecommendation))
+	addMaintenanceScheduleRouter(authV1.Group("/maintenance_schedules", handlers.CheckMaintenanceSchedule))
+	addK8sDriftRouter(authV1.Group("/k8s_drifts"))
 }
 
 func addGrafanaV1Router(grafanaV1 *gin.RouterGroup) {
@@ -106,12 +113,14 @@ func addProjectRouter(projects *gin.RouterGroup) {
 	addProjectCIJobRouter(projects.Group("/:project_id/ci_job"))
 	addProjectClusterRouter(projects.Group("/:project_id/clusters"))
 	addClustersWithWorkloadRouter(projects.Group("/:project_id/clusters_with_workload"))
+	addProjectNotificationRouter(projects.Group("/:project_id/notifications"))
 }
 
 func addVariableRouter(variable *gin.RouterGroup) {
 	variable.GET("", handlers.GetVariables)
 	variable.PUT("/:variable_id", handlers.UpdateVariable)
 	variable.DELETE("/:variable_id", handlers.DeleteVariable)
+	variable.GET("/:variable_id/audits", handlers.GetVariableAudits)
 	variable.POST("", handlers.CreateVariable)
 }
 
@@ -122,6 +131,54 @@ func addImageArgsTemplateRouter(imageArgsTemplate *gin.RouterGroup) {
 	imageArgsTemplate.POST("", handlers.CreateImageArgsTemplate)
 }
 
+func addFreezeWindowRouter(freezeWindow *gin.RouterGroup) {
+	freezeWindow.GET("", handlers.GetFreezeWindows)
+	freezeWindow.POST("", handlers.CreateFreezeWindow)
+	freezeWindow.PUT("/:freeze_window_id", handlers.UpdateFreezeWindow)
+	freezeWindow.DELETE("/:freeze_window_id", handlers.DeleteFreezeWindow)
+	freezeWindow.GET("/:freeze_window_id/overrides", handlers.GetFreezeOverrideRecords)
+}
+
+func addPipelineRouter(pipeline *gin.RouterGroup) {
+	pipeline.GET("", handlers.GetPipelines)
+	pipeline.POST("", handlers.CreatePipeline)
+	pipeline.GET("/:pipeline_id", handlers.GetPipelineDetail)
+	pipeline.PUT("/:pipeline_id", handlers.UpdatePipeline)
+	pipeline.DELETE("/:pipeline_id", handlers.DeletePipeline)
+	pipeline.GET("/:pipeline_id/runs", handlers.GetPipelineRuns)
+	pipeline.POST("/:pipeline_id/runs", handlers.CreatePipelineRun)
+}
+
+func addPipelineRunRouter(pipelineRun *gin.RouterGroup) {
+	pipelineRun.GET("/:run_id", handlers.GetPipelineRunDetail)
+	pipelineRun.POST("/:run_id/approve", handlers.ApprovePipelineRun)
+	pipelineRun.POST("/:run_id/cancel", handlers.CancelPipelineRun)
+}
+
+func addMultiClusterDeployRouter(deploy *gin.RouterGroup) {
+	deploy.GET("", handlers.GetMultiClusterDeploys)
+	deploy.POST("", handlers.CreateMultiClusterDeploy)
+	deploy.GET("/:deploy_id", handlers.GetMultiClusterDeployDetail)
+}
+
+func addRightSizingRouter(rightSizing *gin.RouterGroup) {
+	rightSizing.GET("", handlers.GetRightSizingReport)
+	rightSizing.POST("/:recommendation_id/apply", handlers.ApplyRightSizingRecommendation)
+}
+
+func addMaintenanceScheduleRouter(schedule *gin.RouterGroup) {
+	schedule.GET("", handlers.GetMaintenanceSchedules)
+	schedule.POST("", handlers.CreateMaintenanceSchedule)
+	schedule.GET("/:schedule_id", handlers.GetMaintenanceScheduleDetail)
+	schedule.PUT("/:schedule_id", handlers.UpdateMaintenanceSchedule)
+	schedule.DELETE("/:schedule_id", handlers.DeleteMaintenanceSchedule)
+	schedule.GET("/:schedule_id/executions", handlers.GetMaintenanceScheduleExecutions)
+}
+
+func addK8sDriftRouter(drift *gin.RouterGroup) {
+	drift.GET("", handlers.GetK8sDrifts)
+}
+
 func addProjectResourceRouter(resource *gin.RouterGroup) {
 	resource.GET("", handlers.GetProjectResources)
 	resource.PUT("", handlers.UpdateProjectResources)
@@ -160,6 +217,18 @@ func addClustersWithWorkloadRouter(cluster *gin.RouterGroup) {
 	cluster.GET("", handlers.GetProjectAppsClustersWithWorkload)
 }
 
+func addProjectNotificationRouter(notification *gin.RouterGroup) {
+	notification.GET("/channels", handlers.GetNotificationChannels)
+	notification.POST("/channels", handlers.CreateNotificationChannel)
+	notification.PUT("/channels/:channel_id", handlers.UpdateNotificationChannel)
+	notification.DELETE("/channels/:channel_id", handlers.DeleteNotificationChannel)
+	notification.GET("/rules", handlers.GetNotificationRules)
+	notification.POST("/rules", handlers.CreateNotificationRule)
+	notification.PUT("/rules/:rule_id", handlers.UpdateNotificationRule)
+	notification.DELETE("/rules/:rule_id", handlers.DeleteNotificationRule)
+	notification.GET("/deliveries", handlers.GetNotificationDeliveries)
+}
+
 func addProjectLabelsRouter(label *gin.RouterGroup) {
 	label.GET("", handlers.GetProjectLabels)
 }
@@ -177,6 +246,10 @@ func addAppRouter(apps *gin.RouterGroup) {
 	apps.POST("/:id/cluster_weights", handlers.SetAppClusterWeights)
 	apps.GET("/:id/cluster_weights", handlers.GetAppClusterWeights)
 	apps.GET("/:id/clusters_with_workload", handlers.GetAppClustersWithWorkload)
+	apps.GET("/:id/slo", handlers.GetAppSLOStatus)
+	apps.POST("/:id/slo", handlers.CreateSLO)
+	apps.PUT("/:id/slo/:slo_id", handlers.UpdateSLO)
+	apps.DELETE("/:id/slo/:slo_id", handlers.DeleteSLO)
 }
 
 // AddAppRouter app router
@@ -187,15 +260,19 @@ func addJobRouter(apps *gin.RouterGroup) {
 func addTaskRouter(task *gin.RouterGroup) {
 	task.POST("", handlers.CreateTask)
 	task.POST("/batch", handlers.BatchCreateTask)
+	task.POST("/preview", handlers.PreviewTask)
 	task.PUT("/:id", handlers.UpdateTask)
 	task.GET("", handlers.GetTasks)
 	task.DELETE("/:id", handlers.DeleteTask)
+	task.GET("/:id/events", handlers.GetTaskEvents)
 
 	// 避免路由冲突
 	task.GET("/:id", func(c *gin.Context) {
 		switch c.Param("id") {
 		case "latest":
 			handlers.GetLatestDeployTaskDetail(c)
+		case "phase_stats":
+			handlers.GetTaskPhaseStats(c)
 		default:
 			handlers.GetTaskDetail(c)
 		}
@@ -0,0 +1,14 @@
+package job
+
+import (
+	"rulai/service"
+
+	framework "gitlab.shanhai.int/sre/app-framework"
+)
+
+func CleanBlueGreenIdleVersionServer() framework.ServerInterface {
+	svr := new(framework.JobServer)
+	svr.SetJob("clean_blue_green_idle_version", service.SVC.CleanExpiredBlueGreenVersions)
+
+	return svr
+}
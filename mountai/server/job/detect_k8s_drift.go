@@ -0,0 +1,14 @@
+package job
+
+import (
+	"rulai/service"
+
+	framework "gitlab.shanhai.int/sre/app-framework"
+)
+
+func DetectK8sDriftServer() framework.ServerInterface {
+	svr := new(framework.JobServer)
+	svr.SetJob("detect_k8s_drift", service.SVC.DetectK8sDrifts)
+
+	return svr
+}
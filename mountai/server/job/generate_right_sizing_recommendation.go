@@ -0,0 +1,14 @@
+package job
+
+import (
+	"rulai/service"
+
+	framework "gitlab.shanhai.int/sre/app-framework"
+)
+
+func GenerateRightSizingRecommendationServer() framework.ServerInterface {
+	svr := new(framework.JobServer)
+	svr.SetJob("generate_right_sizing_recommendation", service.SVC.GenerateRightSizingRecommendations)
+
+	return svr
+}
@@ -0,0 +1,34 @@
+package worker
+
+import (
+	"rulai/service"
+
+	"context"
+	"time"
+
+	framework "gitlab.shanhai.int/sre/app-framework"
+	"gitlab.shanhai.int/sre/library/log"
+)
+
+const (
+	// 定时运维计划检查间隔
+	defaultMaintenanceScheduleInterval = time.Second * 30
+)
+
+// GetMaintenanceScheduleServer 定时运维计划常驻任务
+func GetMaintenanceScheduleServer() framework.ServerInterface {
+	svr := new(framework.JobServer)
+
+	svr.SetJob("maintenance_schedule", func(ctx context.Context) error {
+		ticker := time.NewTicker(defaultMaintenanceScheduleInterval)
+		for range ticker.C {
+			err := service.SVC.RunDueMaintenanceSchedules(ctx)
+			if err != nil {
+				log.Errorc(ctx, "run due maintenance schedules error: %+v", err)
+			}
+		}
+		return nil
+	})
+
+	return svr
+}
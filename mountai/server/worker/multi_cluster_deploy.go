@@ -0,0 +1,34 @@
+package worker
+
+import (
+	"rulai/service"
+
+	"context"
+	"time"
+
+	framework "gitlab.shanhai.int/sre/app-framework"
+	"gitlab.shanhai.int/sre/library/log"
+)
+
+const (
+	// 多集群部署推进间隔
+	defaultMultiClusterDeployAdvanceInterval = time.Second * 5
+)
+
+// GetMultiClusterDeployServer 多集群部署批次推进常驻任务
+func GetMultiClusterDeployServer() framework.ServerInterface {
+	svr := new(framework.JobServer)
+
+	svr.SetJob("multi_cluster_deploy", func(ctx context.Context) error {
+		ticker := time.NewTicker(defaultMultiClusterDeployAdvanceInterval)
+		for range ticker.C {
+			err := service.SVC.AdvanceMultiClusterDeploys(ctx)
+			if err != nil {
+				log.Errorc(ctx, "advance multi cluster deploys error: %+v", err)
+			}
+		}
+		return nil
+	})
+
+	return svr
+}
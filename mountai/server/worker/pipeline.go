@@ -0,0 +1,34 @@
+package worker
+
+import (
+	"rulai/service"
+
+	"context"
+	"time"
+
+	framework "gitlab.shanhai.int/sre/app-framework"
+	"gitlab.shanhai.int/sre/library/log"
+)
+
+const (
+	// 流水线推进间隔
+	defaultPipelineAdvanceInterval = time.Second * 5
+)
+
+// GetPipelineServer 部署流水线推进常驻任务
+func GetPipelineServer() framework.ServerInterface {
+	svr := new(framework.JobServer)
+
+	svr.SetJob("pipeline", func(ctx context.Context) error {
+		ticker := time.NewTicker(defaultPipelineAdvanceInterval)
+		for range ticker.C {
+			err := service.SVC.AdvancePipelineRuns(ctx)
+			if err != nil {
+				log.Errorc(ctx, "advance pipeline runs error: %+v", err)
+			}
+		}
+		return nil
+	})
+
+	return svr
+}
@@ -18,6 +18,7 @@ import (
 	"gitlab.shanhai.int/sre/library/base/null"
 	"gitlab.shanhai.int/sre/library/goroutine"
 	"gitlab.shanhai.int/sre/library/log"
+	"gitlab.shanhai.int/sre/library/net/metric"
 	"gitlab.shanhai.int/sre/library/net/tracing"
 )
 
@@ -34,8 +35,14 @@ func GetServer() framework.ServerInterface {
 	//
 	// 根据实际情况修改
 	// ====================
+	metric.CustomInit(service.StatusWorkerShardCollector...)
+
 	// 设置任务函数
 	svr.SetJob("status", func(ctx context.Context) error {
+		// 多副本按应用分片处理任务, 仅处理当前副本持有租约的分片
+		sharder := service.SVC.NewStatusWorkerSharder()
+		go sharder.Run(ctx)
+
 		ticker := time.NewTicker(time.Second)
 		for range ticker.C {
 			// 根据应用id及环境获取未完成的任务
@@ -79,10 +86,30 @@ func GetServer() framework.ServerInterface {
 				continue
 			}
 
+			scheduledTaskIDs := make(map[string]bool, len(scheduledTasks))
+			for _, task := range scheduledTasks {
+				scheduledTaskIDs[task.ID] = true
+			}
+
 			tasks := make([]*resp.TaskDetailResp, 0)
 			tasks = append(tasks, notFinalAndInitTasks...)
 			tasks = append(tasks, immediateTasks...)
 			tasks = append(tasks, scheduledTasks...)
+			// 先过滤出本实例负责的任务, 有副作用的处理只作用于本实例的任务
+			tasks = sharder.FilterOwnedTasks(tasks)
+
+			ownedTasks := make([]*resp.TaskDetailResp, 0, len(tasks))
+			ownedScheduledTasks := make([]*resp.TaskDetailResp, 0)
+			for _, task := range tasks {
+				if scheduledTaskIDs[task.ID] {
+					ownedScheduledTasks = append(ownedScheduledTasks, task)
+				} else {
+					ownedTasks = append(ownedTasks, task)
+				}
+			}
+
+			// 处于封网窗口内的定时任务延后执行
+			tasks = append(ownedTasks, service.SVC.HoldFrozenScheduledTasks(ctx, ownedScheduledTasks)...)
 
 			wg := goroutine.New("status-worker")
 			for _, task := range tasks {
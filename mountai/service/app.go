@@ -125,6 +125,9 @@ func (s *Service) generateUpdateAppMap(_ context.Context, updateReq *req.UpdateA
 			if !env.EnableHotReload.IsZero() {
 				change[fmt.Sprintf("env.%s.enable_hot_reload", name)] = env.EnableHotReload.ValueOrZero()
 			}
+			if !env.EnableAutoRollback.IsZero() {
+				change[fmt.Sprintf("env.%s.enable_auto_rollback", name)] = env.EnableAutoRollback.ValueOrZero()
+			}
 		}
 	}
 
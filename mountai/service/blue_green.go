@@ -0,0 +1,406 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"gitlab.shanhai.int/sre/library/base/null"
+	"gitlab.shanhai.int/sre/library/log"
+	"gitlab.shanhai.int/sre/library/net/errcode"
+	"istio.io/client-go/pkg/apis/networking/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"rulai/models/entity"
+	"rulai/models/req"
+	"rulai/models/resp"
+	"rulai/utils"
+	_errcode "rulai/utils/errcode"
+)
+
+// FillBlueGreenTaskReq 根据当前运行版本填充蓝绿部署的目标版本
+func (s *Service) FillBlueGreenTaskReq(ctx context.Context, project *resp.ProjectDetailResp,
+	app *resp.AppDetailResp, createReq *req.CreateTaskReq) error {
+	activeTask, err := s.GetLatestDeploySuccessTaskFinalVersion(ctx, &req.GetLatestTaskReq{
+		AppID:       createReq.AppID,
+		EnvName:     createReq.EnvName,
+		ClusterName: createReq.ClusterName,
+	})
+	if err != nil {
+		if errcode.EqualError(_errcode.NoRequiredTaskError, err) {
+			return errors.Wrap(errcode.InvalidParams, "blue green deploy requires a running version")
+		}
+		return err
+	}
+
+	// 金丝雀版本与全量版本同时接收流量, 无法确定切换前的版本
+	if activeTask.Action == entity.TaskActionCanaryDeploy {
+		return errors.Wrap(errcode.InvalidParams, "canary deploy is not finished")
+	}
+
+	baseVersion := s.GenerateTaskVersion(project.Name, app.Name, app.Type, entity.TaskActionFullDeploy, time.Now())
+	createReq.Version = entity.GetBlueGreenTargetVersion(baseVersion, activeTask.Version)
+	createReq.PreviousVersion = activeTask.Version
+
+	return nil
+}
+
+// FillBlueGreenSwitchBackTaskReq 填充蓝绿切回任务, 切回至上一次蓝绿切换前的版本并沿用该版本的运行参数
+func (s *Service) FillBlueGreenSwitchBackTaskReq(ctx context.Context, createReq *req.CreateTaskReq) error {
+	activeTask, err := s.GetLatestDeploySuccessTaskFinalVersion(ctx, &req.GetLatestTaskReq{
+		AppID:       createReq.AppID,
+		EnvName:     createReq.EnvName,
+		ClusterName: createReq.ClusterName,
+	})
+	if err != nil {
+		return err
+	}
+
+	if !entity.TaskActionBlueGreenList.Contains(activeTask.Action) || activeTask.PreviousVersion == "" {
+		return errors.Wrap(errcode.InvalidParams, "latest deploy is not a blue green deploy")
+	}
+
+	createReq.Version = activeTask.PreviousVersion
+	err = s.FillRollbackTaskReq(ctx, createReq)
+	if err != nil {
+		return err
+	}
+
+	createReq.PreviousVersion = activeTask.Version
+	createReq.Param.BlueGreenKeepWarmSeconds = activeTask.Param.BlueGreenKeepWarmSeconds
+
+	return nil
+}
+
+// SwitchBlueGreenTraffic 将应用流量一次性切换至指定版本
+// 启用 istio 的应用修改 VirtualService 的目标服务, 其余应用修改 Service 的 selector
+func (s *Service) SwitchBlueGreenTraffic(ctx context.Context, project *resp.ProjectDetailResp,
+	app *resp.AppDetailResp, task *resp.TaskDetailResp, version string) error {
+	if s.isBlueGreenSwitchedByVirtualService(ctx, app, task) {
+		err := s.ensureBlueGreenVersionService(ctx, app, task, version)
+		if err != nil {
+			return err
+		}
+
+		vs, err := s.getBlueGreenVirtualService(ctx, project, app, task)
+		if err != nil {
+			return err
+		}
+
+		applyVirtualService := vs.DeepCopy()
+		for _, route := range applyVirtualService.Spec.Http {
+			for _, dst := range route.Route {
+				if dst.Destination != nil {
+					dst.Destination.Host = version
+				}
+			}
+		}
+
+		_, err = s.PatchVirtualService(ctx, task.ClusterName, task.EnvName, applyVirtualService)
+		return err
+	}
+
+	services, err := s.getBlueGreenSwitchServices(ctx, project, app, task)
+	if err != nil {
+		return err
+	}
+
+	for i := range services {
+		_, err = s.PatchServiceSelectorVersion(ctx, task.ClusterName, string(task.EnvName),
+			services[i].GetNamespace(), services[i].GetName(), version)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CheckBlueGreenTrafficSwitched 检查应用流量是否已全部指向指定版本
+func (s *Service) CheckBlueGreenTrafficSwitched(ctx context.Context, project *resp.ProjectDetailResp,
+	app *resp.AppDetailResp, task *resp.TaskDetailResp, version string) (bool, error) {
+	if s.isBlueGreenSwitchedByVirtualService(ctx, app, task) {
+		vs, err := s.getBlueGreenVirtualService(ctx, project, app, task)
+		if err != nil {
+			return false, err
+		}
+
+		for _, route := range vs.Spec.Http {
+			for _, dst := range route.Route {
+				if dst.Destination != nil && dst.Destination.Host != version {
+					return false, nil
+				}
+			}
+		}
+
+		return true, nil
+	}
+
+	services, err := s.getBlueGreenSwitchServices(ctx, project, app, task)
+	if err != nil {
+		return false, err
+	}
+
+	for i := range services {
+		if services[i].Spec.Selector[entity.K8sLabelVersion] != version {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func (s *Service) isBlueGreenSwitchedByVirtualService(ctx context.Context, app *resp.AppDetailResp,
+	task *resp.TaskDetailResp) bool {
+	return app.ServiceExposeType == entity.AppServiceExposeTypeIngress &&
+		s.GetApplicationIstioState(ctx, task.EnvName, task.ClusterName, app)
+}
+
+func (s *Service) getBlueGreenVirtualService(ctx context.Context, project *resp.ProjectDetailResp,
+	app *resp.AppDetailResp, task *resp.TaskDetailResp) (*v1beta1.VirtualService, error) {
+	return s.GetVirtualServiceDetail(ctx, task.ClusterName, task.EnvName, &req.VirtualServiceReq{
+		Namespace: task.Namespace,
+		Name:      fmt.Sprintf("%s-%s", project.Name, app.Name),
+		Env:       string(task.EnvName),
+	})
+}
+
+// getBlueGreenSwitchServices 获取需要切换 selector 的服务, 不包括按版本创建的服务
+func (s *Service) getBlueGreenSwitchServices(ctx context.Context, project *resp.ProjectDetailResp,
+	app *resp.AppDetailResp, task *resp.TaskDetailResp) ([]v1.Service, error) {
+	list, err := s.GetServices(ctx, task.ClusterName, string(task.EnvName), &req.GetServicesReq{
+		Namespace:   task.Namespace,
+		ProjectName: project.Name,
+		AppName:     app.Name,
+		Env:         string(task.EnvName),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]v1.Service, 0, len(list))
+	for i := range list {
+		if _, ok := list[i].GetLabels()[entity.K8sLabelVersion]; ok {
+			continue
+		}
+		res = append(res, list[i])
+	}
+
+	if len(res) == 0 {
+		return nil, errors.Wrapf(_errcode.K8sResourceNotFoundError, "service of %s-%s not found", project.Name, app.Name)
+	}
+
+	return res, nil
+}
+
+// ensureBlueGreenVersionService 确保存在只选择指定版本的服务, 供 VirtualService 切换使用
+func (s *Service) ensureBlueGreenVersionService(ctx context.Context, app *resp.AppDetailResp,
+	task *resp.TaskDetailResp, version string) error {
+	_, err := s.GetServiceDetail(ctx, task.ClusterName, &req.GetServiceDetailReq{
+		Namespace: task.Namespace,
+		Name:      version,
+		Env:       string(task.EnvName),
+	})
+	if err == nil {
+		return nil
+	}
+	if !errcode.EqualError(_errcode.K8sResourceNotFoundError, err) {
+		return err
+	}
+
+	serviceName, err := s.GetCurrentServiceName(ctx, task.ClusterName, task.EnvName, app)
+	if err != nil {
+		return err
+	}
+
+	svc, err := s.GetServiceDetail(ctx, task.ClusterName, &req.GetServiceDetailReq{
+		Namespace: task.Namespace,
+		Name:      serviceName,
+		Env:       string(task.EnvName),
+	})
+	if err != nil {
+		return err
+	}
+
+	labels := make(map[string]string, len(svc.GetLabels())+1)
+	for k, v := range svc.GetLabels() {
+		labels[k] = v
+	}
+	labels[entity.K8sLabelVersion] = version
+
+	selector := make(map[string]string, len(svc.Spec.Selector)+1)
+	for k, v := range svc.Spec.Selector {
+		selector[k] = v
+	}
+	selector[entity.K8sLabelVersion] = version
+
+	ports := make([]v1.ServicePort, 0, len(svc.Spec.Ports))
+	for _, port := range svc.Spec.Ports {
+		port.NodePort = 0
+		ports = append(ports, port)
+	}
+
+	versionService := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      version,
+			Namespace: task.Namespace,
+			Labels:    labels,
+		},
+		Spec: v1.ServiceSpec{
+			Type:     v1.ServiceTypeClusterIP,
+			Ports:    ports,
+			Selector: selector,
+		},
+	}
+	// headless 服务保持一致
+	if svc.Spec.ClusterIP == v1.ClusterIPNone {
+		versionService.Spec.ClusterIP = v1.ClusterIPNone
+	}
+
+	_, err = s.CreateService(ctx, task.ClusterName, versionService, string(task.EnvName))
+	return err
+}
+
+// deleteBlueGreenVersionServices 删除按版本创建的服务, 版本为空时删除应用所有按版本创建的服务
+func (s *Service) deleteBlueGreenVersionServices(ctx context.Context, task *resp.TaskDetailResp,
+	projectName, appName, version string) (bool, error) {
+	list, err := s.GetServices(ctx, task.ClusterName, string(task.EnvName), &req.GetServicesReq{
+		Namespace:   task.Namespace,
+		ProjectName: projectName,
+		AppName:     appName,
+		Env:         string(task.EnvName),
+	})
+	if err != nil {
+		return false, err
+	}
+
+	deleted := false
+	for i := range list {
+		v, ok := list[i].GetLabels()[entity.K8sLabelVersion]
+		if !ok || (version != "" && v != version) {
+			continue
+		}
+
+		err = s.DeleteService(ctx, task.ClusterName, &req.DeleteServiceReq{
+			Namespace: list[i].GetNamespace(),
+			Name:      list[i].GetName(),
+			Env:       string(task.EnvName),
+		})
+		if err != nil {
+			return false, err
+		}
+		deleted = true
+	}
+
+	return deleted, nil
+}
+
+// CleanExpiredBlueGreenVersions 为超过保留时长的蓝绿部署旧版本创建清理任务
+func (s *Service) CleanExpiredBlueGreenVersions(ctx context.Context) error {
+	tasks, err := s.GetTasks(ctx, &req.GetTasksReq{
+		ActionList:   *entity.TaskActionBlueGreenList,
+		StatusList:   entity.TaskStatusSuccessStateList,
+		MinTimestamp: int(time.Now().Add(-entity.BlueGreenIdleCleanLookback).Unix()),
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, task := range tasks {
+		e := s.cleanBlueGreenIdleVersion(ctx, task)
+		if e != nil {
+			log.Errorc(ctx, "clean blue green idle version of task(%s) error: %s", task.ID, e)
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) cleanBlueGreenIdleVersion(ctx context.Context, task *resp.TaskDetailResp) error {
+	if task.PreviousVersion == "" {
+		return nil
+	}
+
+	updateTime, err := time.ParseInLocation(utils.DefaultTimeFormatLayout, task.UpdateTime, time.Local)
+	if err != nil {
+		return err
+	}
+	if time.Since(updateTime) < entity.GetBlueGreenKeepWarmDuration(task.Param.BlueGreenKeepWarmSeconds) {
+		return nil
+	}
+
+	// 之后有新的部署时由新的部署负责旧版本
+	latestTask, err := s.GetLatestSuccessTask(ctx, &req.GetLatestTaskReq{
+		AppID:       task.AppID,
+		EnvName:     task.EnvName,
+		ClusterName: task.ClusterName,
+		ActionList:  entity.TaskActionFinalVersionList,
+	})
+	if err != nil {
+		return err
+	}
+	if latestTask.ID != task.ID {
+		return nil
+	}
+
+	unFinishCount, err := s.GetTasksCount(ctx, &req.GetTasksReq{
+		AppID:             task.AppID,
+		EnvName:           task.EnvName,
+		StatusInverseList: entity.TaskStatusFinalStateList,
+		Suspend:           null.BoolFrom(false),
+	})
+	if err != nil {
+		return err
+	}
+	if unFinishCount > 0 {
+		return nil
+	}
+
+	err = s.CheckDeploymentExistance(ctx, task.ClusterName, task.EnvName, &req.GetDeploymentDetailReq{
+		Namespace: task.Namespace,
+		Name:      task.PreviousVersion,
+		Env:       string(task.EnvName),
+	})
+	if errcode.EqualError(_errcode.K8sResourceNotFoundError, err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	app, err := s.GetAppDetail(ctx, task.AppID)
+	if err != nil {
+		return err
+	}
+
+	project, err := s.GetProjectDetail(ctx, app.ProjectID)
+	if err != nil {
+		return err
+	}
+
+	cleanTask, err := s.CreateTask(ctx, project, app, &req.CreateTaskReq{
+		AppID:         task.AppID,
+		Version:       task.PreviousVersion,
+		Description:   fmt.Sprintf("蓝绿部署任务(%s)旧版本保留期结束, 自动清理", task.ID),
+		ClusterName:   task.ClusterName,
+		EnvName:       task.EnvName,
+		Action:        entity.TaskActionClean,
+		Namespace:     task.Namespace,
+		RelatedTaskID: task.ID,
+		Param: &req.CreateTaskParamReq{
+			CleanedProjectName: project.Name,
+			CleanedAppName:     app.Name,
+			CleanedAppType:     app.Type,
+			CleanedVersion:     task.PreviousVersion,
+		},
+	}, entity.K8sSystemUserID)
+	if err != nil {
+		return err
+	}
+
+	log.Infoc(ctx, "clean task(%s) created for blue green idle version %s", cleanTask.ID, task.PreviousVersion)
+
+	return nil
+}
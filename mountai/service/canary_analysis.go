@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gitlab.shanhai.int/sre/library/base/deepcopy.v2"
+	"gitlab.shanhai.int/sre/library/net/errcode"
+
+	"rulai/models/entity"
+	"rulai/models/req"
+	"rulai/models/resp"
+	_errcode "rulai/utils/errcode"
+)
+
+// getCanaryMetric 查询金丝雀指标, 无流量时视为0
+func (s *Service) getCanaryMetric(ctx context.Context, task *resp.TaskDetailResp, tplPath string) (float64, error) {
+	res, err := s.renderAndGetPrometheusData(ctx, task.EnvName, tplPath,
+		&entity.CanaryMetricTemplate{
+			Namespace:      task.Namespace,
+			DeploymentName: task.Version,
+			CountTime:      fmt.Sprintf("%ds", task.Param.ProgressiveCanary.BakeSeconds),
+		},
+	)
+	// 查不到则认为没有流量
+	if errcode.EqualError(_errcode.PrometheusQueryEmptyError, err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	// 无请求时错误率为 NaN
+	if math.IsNaN(res) || math.IsInf(res, 0) {
+		return 0, nil
+	}
+	return res, nil
+}
+
+// EvaluateCanaryStep 查询金丝雀指标并生成当前步骤的判定结果
+func (s *Service) EvaluateCanaryStep(ctx context.Context, task *resp.TaskDetailResp, step int) (*entity.CanaryStepVerdict, error) {
+	cfg := task.Param.ProgressiveCanary
+
+	errorRate, err := s.getCanaryMetric(ctx, task, "./template/prometheus/sql/CanaryErrorRate.sql")
+	if err != nil {
+		return nil, err
+	}
+	latency, err := s.getCanaryMetric(ctx, task, "./template/prometheus/sql/CanaryLatency.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	verdict := &entity.CanaryStepVerdict{
+		Step:      step,
+		PodCount:  cfg.GetStepPodCount(step),
+		ErrorRate: errorRate,
+		LatencyMs: latency,
+		Passed:    true,
+		CheckTime: &now,
+	}
+
+	reasons := make([]string, 0)
+	if cfg.MaxErrorRate > 0 && errorRate > cfg.MaxErrorRate {
+		reasons = append(reasons, fmt.Sprintf("错误率 %.4f 超过阈值 %.4f", errorRate, cfg.MaxErrorRate))
+	}
+	if cfg.MaxLatencyMs > 0 && latency > cfg.MaxLatencyMs {
+		reasons = append(reasons, fmt.Sprintf("p99延迟 %.2fms 超过阈值 %.2fms", latency, cfg.MaxLatencyMs))
+	}
+
+	if len(reasons) > 0 {
+		verdict.Passed = false
+		verdict.Reason = strings.Join(reasons, "; ")
+	} else {
+		verdict.Reason = fmt.Sprintf("错误率 %.4f, p99延迟 %.2fms, 指标正常", errorRate, latency)
+	}
+
+	return verdict, nil
+}
+
+// PromoteCanaryTask 渐进式金丝雀全部通过后, 自动创建基于金丝雀的全量部署任务
+func (s *Service) PromoteCanaryTask(ctx context.Context, project *resp.ProjectDetailResp,
+	app *resp.AppDetailResp, task *resp.TaskDetailResp) error {
+	createReq := new(req.CreateTaskReq)
+	err := deepcopy.Copy(task).To(createReq)
+	if err != nil {
+		return errors.Wrap(errcode.InternalError, err.Error())
+	}
+
+	createReq.Action = entity.TaskActionFullCanaryDeploy
+	createReq.Description = fmt.Sprintf("渐进式金丝雀任务(%s)校验通过, 自动全量", task.ID)
+	createReq.RelatedTaskID = task.ID
+	createReq.DeployType, createReq.ScheduleTime = "", 0
+	// 金丝雀任务已经过审批, 全量时跳过审批
+	createReq.Approval = &req.ApprovalReq{Type: entity.SkipTaskApprovalType}
+	createReq.Param.ProgressiveCanary = nil
+
+	_, err = s.CreateTask(ctx, project, app, createReq, entity.K8sSystemUserID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -264,7 +264,7 @@ func (s *Service) generateDingApprovalReq(ctx context.Context, task *entity.Task
 }
 
 // generateDingApprovalFormValues generates ding approval form values.
-func (s *Service) generateDingApprovalFormValues(_ context.Context, task *entity.Task, project *resp.ProjectDetailResp,
+func (s *Service) generateDingApprovalFormValues(ctx context.Context, task *entity.Task, project *resp.ProjectDetailResp,
 	app *resp.AppDetailResp) []*req.ApprovalFormComponentValue {
 	formValues := make([]*req.ApprovalFormComponentValue, 0)
 
@@ -299,6 +299,8 @@ func (s *Service) generateDingApprovalFormValues(_ context.Context, task *entity
 	formValuesMap["cpu限制"] = fmt.Sprintf("%s 核", task.Param.CPULimit)
 	formValuesMap["内存规格"] = string(task.Param.MemRequest)
 	formValuesMap["内存限制"] = string(task.Param.MemLimit)
+	// 附带变更预览, 供审批人确认将要变化的 K8s 对象
+	formValuesMap["变更预览"] = s.generateTaskPreviewSummary(ctx, task, project, app)
 
 	for name, value := range formValuesMap {
 		formValues = append(formValues, &req.ApprovalFormComponentValue{
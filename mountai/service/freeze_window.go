@@ -0,0 +1,325 @@
+package service
+
+import (
+	"rulai/dao"
+	"rulai/models/entity"
+	"rulai/models/req"
+	"rulai/models/resp"
+	"rulai/utils"
+	_errcode "rulai/utils/errcode"
+
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"gitlab.shanhai.int/sre/library/base/deepcopy.v2"
+	"gitlab.shanhai.int/sre/library/log"
+	"gitlab.shanhai.int/sre/library/net/errcode"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func (s *Service) GetFreezeWindows(ctx context.Context, getReq *req.GetFreezeWindowsReq) (
+	[]*resp.FreezeWindowDetail, int, error) {
+	filter := bson.M{}
+	if getReq.EnvName != "" {
+		// 未限制环境的窗口对所有环境生效
+		filter["$or"] = bson.A{
+			bson.M{"scope.env_names": getReq.EnvName},
+			bson.M{"scope.env_names": bson.M{"$in": bson.A{nil, bson.A{}}}},
+		}
+	}
+	if getReq.Active {
+		filter["end_time"] = bson.M{"$gt": time.Now()}
+	}
+
+	limit := int64(getReq.Limit)
+	skip := int64(getReq.Page-1) * limit
+
+	windows, err := s.dao.FindFreezeWindows(ctx, filter, &options.FindOptions{
+		Limit: &limit,
+		Skip:  &skip,
+		Sort:  bson.M{"start_time": -1},
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	count, err := s.dao.CountFreezeWindows(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	res := make([]*resp.FreezeWindowDetail, 0)
+	err = deepcopy.Copy(&windows).To(&res)
+	if err != nil {
+		return nil, 0, errors.Wrap(errcode.InternalError, err.Error())
+	}
+
+	return res, count, nil
+}
+
+func (s *Service) FindSingleFreezeWindowByID(ctx context.Context, id string) (*entity.FreezeWindow, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.Wrap(_errcode.InvalidHexStringError, err.Error())
+	}
+
+	return s.dao.FindSingleFreezeWindow(ctx, bson.M{"_id": objectID})
+}
+
+func (s *Service) CreateSingleFreezeWindow(ctx context.Context, createReq *req.CreateFreezeWindowReq) error {
+	now := time.Now()
+	startTime := time.Unix(createReq.StartTime, 0)
+	endTime := time.Unix(createReq.EndTime, 0)
+
+	window := &entity.FreezeWindow{
+		ID:          primitive.NewObjectID(),
+		Name:        createReq.Name,
+		Description: createReq.Description,
+		StartTime:   &startTime,
+		EndTime:     &endTime,
+		Scope:       createReq.Scope,
+		OperatorID:  createReq.OperatorID,
+		CreateTime:  &now,
+		UpdateTime:  &now,
+	}
+
+	return s.dao.CreateSingleFreezeWindow(ctx, window)
+}
+
+func (s *Service) UpdateSingleFreezeWindow(ctx context.Context, id string, updateReq *req.UpdateFreezeWindowReq) error {
+	changeMap := make(map[string]interface{})
+	changeMap["update_time"] = time.Now()
+
+	if updateReq.Name != "" {
+		changeMap["name"] = updateReq.Name
+	}
+
+	if !updateReq.Description.IsZero() {
+		changeMap["description"] = updateReq.Description.ValueOrZero()
+	}
+
+	if updateReq.StartTime != 0 {
+		changeMap["start_time"] = time.Unix(updateReq.StartTime, 0)
+	}
+
+	if updateReq.EndTime != 0 {
+		changeMap["end_time"] = time.Unix(updateReq.EndTime, 0)
+	}
+
+	if updateReq.Scope != nil {
+		changeMap["scope"] = updateReq.Scope
+	}
+
+	return s.dao.UpdateSingleFreezeWindow(ctx, id, bson.M{
+		"$set": changeMap,
+	})
+}
+
+func (s *Service) DeleteSingleFreezeWindowByID(ctx context.Context, id string) error {
+	return s.dao.DeleteSingleFreezeWindowByID(ctx, id)
+}
+
+func (s *Service) GetFreezeOverrideRecords(ctx context.Context, windowID string, getReq *req.GetFreezeOverrideRecordsReq) (
+	[]*resp.FreezeOverrideRecordDetail, error) {
+	filter := bson.M{"freeze_window_id": windowID}
+	if getReq.ProjectID != "" {
+		filter["project_id"] = getReq.ProjectID
+	}
+
+	limit := int64(getReq.Limit)
+	skip := int64(getReq.Page-1) * limit
+
+	records, err := s.dao.ListFreezeOverrideRecords(ctx, filter, &options.FindOptions{
+		Limit: &limit,
+		Skip:  &skip,
+		Sort:  dao.MongoSortByIDAsc,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*resp.FreezeOverrideRecordDetail, 0)
+	err = deepcopy.Copy(&records).To(&res)
+	if err != nil {
+		return nil, errors.Wrap(errcode.InternalError, err.Error())
+	}
+
+	return res, nil
+}
+
+// GetMatchedFreezeWindow 获取指定时间命中项目的封网窗口, 未命中时返回 nil
+func (s *Service) GetMatchedFreezeWindow(ctx context.Context, project *resp.ProjectDetailResp,
+	envName entity.AppEnvName, at time.Time) (*entity.FreezeWindow, error) {
+	windows, err := s.dao.FindFreezeWindows(ctx, bson.M{
+		"start_time": bson.M{"$lte": at},
+		"end_time":   bson.M{"$gt": at},
+	}, dao.MongoFindOptionWithSortByIDAsc)
+	if err != nil {
+		return nil, err
+	}
+
+	teamID := ""
+	if project.Team != nil {
+		teamID = project.Team.ID
+	}
+
+	for _, window := range windows {
+		if window.Covers(at) && window.Matches(envName, teamID, project.ID, project.Labels) {
+			return window, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// CheckTaskFreezeWindow 校验任务是否处于封网窗口内
+// 命中窗口时必须填写强制变更原因, 且仅项目负责人可以强制变更
+func (s *Service) CheckTaskFreezeWindow(ctx context.Context, project *resp.ProjectDetailResp,
+	createReq *req.CreateTaskReq, operatorID string) error {
+	if !entity.TaskActionFreezeList.Contains(createReq.Action) {
+		return nil
+	}
+
+	at := time.Now()
+	if createReq.DeployType == entity.ScheduledTaskDeployType && createReq.ScheduleTime > 0 {
+		at = time.Unix(createReq.ScheduleTime, 0)
+	}
+
+	window, err := s.GetMatchedFreezeWindow(ctx, project, createReq.EnvName, at)
+	if err != nil {
+		return err
+	}
+
+	if window == nil {
+		return nil
+	}
+
+	if createReq.FreezeOverrideReason == "" {
+		return errors.Wrapf(_errcode.DeployFrozenError, "freeze window(%s) ends at %s, override reason is required",
+			window.Name, window.EndTime.Format(utils.DefaultTimeFormatLayout))
+	}
+
+	isOwner := operatorID == entity.K8sSystemUserID
+	for _, owner := range project.Owners {
+		if owner.ID == operatorID {
+			isOwner = true
+			break
+		}
+	}
+	if !isOwner {
+		return errors.Wrap(_errcode.CreateTaskNoPermissionError, "no permission to override freeze window")
+	}
+
+	createReq.FreezeWindowID = window.ID.Hex()
+
+	return nil
+}
+
+// recordFreezeOverride 记录封网期间的强制变更并通知项目负责人
+func (s *Service) recordFreezeOverride(ctx context.Context, project *resp.ProjectDetailResp,
+	app *resp.AppDetailResp, task *entity.Task, windowID string) error {
+	now := time.Now()
+	_, err := s.dao.CreateFreezeOverrideRecord(ctx, &entity.FreezeOverrideRecord{
+		ID:             primitive.NewObjectID(),
+		FreezeWindowID: windowID,
+		TaskID:         task.ID.Hex(),
+		Env:            task.EnvName,
+		ProjectID:      project.ID,
+		AppID:          app.ID,
+		TaskActionType: entity.GetTaskActionDisplay(task.Action),
+		Reason:         task.FreezeOverrideReason,
+		Operator:       task.OperatorID,
+		CreateTime:     &now,
+		UpdateTime:     &now,
+	})
+	if err != nil {
+		return err
+	}
+
+	operatorName := task.OperatorID
+	operator, err := s.GetUserInfo(ctx, task.OperatorID)
+	if err != nil {
+		log.Errorc(ctx, "get freeze override operator(%s) err: %v", task.OperatorID, err)
+	} else {
+		operatorName = operator.Name
+	}
+
+	emails := make([]string, 0)
+	for _, owner := range project.Owners {
+		emails = append(emails, owner.Email)
+	}
+
+	_, err = s.SendDingCropMessage(ctx, &req.AppOpMessage{
+		Title:       fmt.Sprintf("%s 被 %s 封网期间强制变更, 原因: %s", project.Name, operatorName, task.FreezeOverrideReason),
+		ProjectName: project.Name,
+		AppName:     app.Name,
+		Env:         string(task.EnvName),
+		Action:      entity.GetTaskActionDisplay(task.Action),
+		OpTime:      now.Format(utils.DefaultTimeFormatLayout),
+		UserName:    operatorName,
+		DetailURL:   s.GetAmsFrontendProjectURL(project.ID, task.EnvName),
+	}, emails, entity.FreezeOverrideTmpName, entity.FreezeOverrideMsg)
+
+	return err
+}
+
+// HoldFrozenScheduledTasks 过滤处于封网窗口内的定时任务, 被过滤的任务执行时间延后至窗口结束
+func (s *Service) HoldFrozenScheduledTasks(ctx context.Context, tasks []*resp.TaskDetailResp) []*resp.TaskDetailResp {
+	res := make([]*resp.TaskDetailResp, 0, len(tasks))
+	for _, task := range tasks {
+		if !entity.TaskActionFreezeList.Contains(task.Action) || task.FreezeOverrideReason != "" {
+			res = append(res, task)
+			continue
+		}
+
+		window, err := s.getTaskMatchedFreezeWindow(ctx, task)
+		if err != nil {
+			// 下一轮调度会重试, 避免封网期间误执行
+			log.Errorc(ctx, "get task(%s) freeze window err: %v", task.ID, err)
+			continue
+		}
+
+		if window == nil {
+			res = append(res, task)
+			continue
+		}
+
+		err = s.dao.UpdateSingleTask(ctx, task.ID, bson.A{
+			bson.M{
+				"$set": bson.M{
+					"schedule_time": window.EndTime,
+					"update_time":   time.Now(),
+					"detail": bson.M{
+						"$concat": bson.A{
+							"$detail", s.generateTaskDetailOneLine(fmt.Sprintf("freeze window(%s) holds task until %s",
+								window.Name, window.EndTime.Format(utils.DefaultTimeFormatLayout))),
+						},
+					},
+				},
+			},
+		})
+		if err != nil {
+			log.Errorc(ctx, "hold task(%s) in freeze window err: %v", task.ID, err)
+		}
+	}
+
+	return res
+}
+
+func (s *Service) getTaskMatchedFreezeWindow(ctx context.Context, task *resp.TaskDetailResp) (*entity.FreezeWindow, error) {
+	app, err := s.GetAppDetail(ctx, task.AppID)
+	if err != nil {
+		return nil, err
+	}
+
+	project, err := s.GetProjectDetail(ctx, app.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetMatchedFreezeWindow(ctx, project, task.EnvName, time.Now())
+}
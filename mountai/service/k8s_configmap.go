@@ -168,15 +168,62 @@ func (s *Service) ApplyConfigMap(ctx context.Context,
 			return nil, e
 		}
 
+		recordTaskObjectVersion(ctx, "ConfigMap", cm)
 		return cm, nil
 	}
 	cm, err := s.PatchConfigMap(ctx, clusterName, applyCM, env)
 	if err != nil {
 		return nil, err
 	}
+	recordTaskObjectVersion(ctx, "ConfigMap", cm)
 	return cm, nil
 }
 
+// applyTaskConfigMap 从配置中心获取任务指定提交的配置, 渲染并应用 ConfigMap
+func (s *Service) applyTaskConfigMap(ctx context.Context, project *resp.ProjectDetailResp,
+	app *resp.AppDetailResp, task *resp.TaskDetailResp, team *resp.TeamDetailResp) error {
+	getReq := &req.GetConfigManagerFileReq{
+		ProjectID:   project.ID,
+		ProjectName: project.Name,
+		EnvName:     task.EnvName,
+		CommitID:    task.Param.ConfigCommitID,
+		IsDecrypt:   true,
+		FormatType:  req.ConfigManagerFormatTypeJSON,
+	}
+
+	if task.Param.ConfigRenamePrefix != "" {
+		getReq.ConfigRenamePrefix = task.Param.ConfigRenamePrefix
+		getReq.ConfigRenameMode = task.Param.ConfigRenameMode
+	}
+
+	configData, err := s.GetAppConfig(ctx, getReq)
+	if err != nil {
+		return err
+	}
+	// 渲染模版
+	data, err := s.RenderConfigMapTemplate(ctx, project, app, task, team, configData.Config)
+	if err != nil {
+		return err
+	}
+	// 生成ConfigMap
+	_, err = s.ApplyConfigMap(ctx, task.ClusterName, data, string(task.EnvName))
+	return err
+}
+
+// isTaskConfigMapCreated ConfigMap 已存在且为任务指定的配置提交
+func (s *Service) isTaskConfigMapCreated(ctx context.Context, project *resp.ProjectDetailResp,
+	app *resp.AppDetailResp, task *resp.TaskDetailResp) (bool, error) {
+	cm, err := s.GetCompatibleConfigMapDetail(ctx, project, app, task)
+	if err != nil {
+		if errcode.EqualError(_errcode.K8sResourceNotFoundError, err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return cm.GetLabels()[ConfigMapLabelCommit] == task.Param.ConfigCommitID, nil
+}
+
 // CreateConfigMap 创建 ConfigMap
 func (s *Service) CreateConfigMap(ctx context.Context,
 	clusterName entity.ClusterName, cm *v1.ConfigMap, env string) (*v1.ConfigMap, error) {
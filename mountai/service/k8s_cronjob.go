@@ -106,6 +106,15 @@ func (s *Service) initCronJobTemplate(ctx context.Context, project *resp.Project
 		BackoffLimit: task.Param.BackoffLimit,
 	}
 
+	template.InitContainers, err = s.getExtraContainerTemplates(project, app, task, team, task.Param.InitContainers)
+	if err != nil {
+		return nil, err
+	}
+
+	volumes, mounts, extraMounts := getVolumeTemplates(project, app, task)
+	template.Volumes, template.VolumeMounts = volumes, mounts
+	setExtraContainerVolumeMounts(extraMounts, template.InitContainers)
+
 	return template, nil
 }
 
@@ -161,6 +170,7 @@ func (s *Service) ApplyCronJob(ctx context.Context,
 		if err != nil {
 			return nil, e
 		}
+		recordTaskObjectVersion(ctx, "CronJob", cronJob)
 		return cronJob, nil
 	}
 
@@ -168,6 +178,7 @@ func (s *Service) ApplyCronJob(ctx context.Context,
 	if err != nil {
 		return nil, err
 	}
+	recordTaskObjectVersion(ctx, "CronJob", cronJob)
 	return cronJob, nil
 }
 
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"gitlab.shanhai.int/sre/library/net/errcode"
+	"gopkg.in/yaml.v3"
+	v1 "k8s.io/api/apps/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	"rulai/models/entity"
+	"rulai/models/req"
+	"rulai/models/resp"
+	_errcode "rulai/utils/errcode"
+)
+
+func (s *Service) initDaemonSetTemplate(ctx context.Context, project *resp.ProjectDetailResp,
+	app *resp.AppDetailResp, task *resp.TaskDetailResp, team *resp.TeamDetailResp) (*entity.DaemonSetTemplate, error) {
+	tpl, err := s.initDeploymentTemplate(ctx, project, app, task, team)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.DaemonSetTemplate{DeploymentTemplate: *tpl}, nil
+}
+
+// RenderDaemonSetTemplate 渲染DaemonSet模板
+func (s *Service) RenderDaemonSetTemplate(ctx context.Context,
+	project *resp.ProjectDetailResp, app *resp.AppDetailResp, task *resp.TaskDetailResp, team *resp.TeamDetailResp) ([]byte, error) {
+	tpl, err := s.initDaemonSetTemplate(ctx, project, app, task, team)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := s.RenderK8sTemplate(ctx, entity.DefaultTemplateFileDir, task.ClusterName, task.EnvName, tpl)
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(data), nil
+}
+
+func (s *Service) decodeDaemonSetYamlData(_ context.Context, yamlData []byte) (*v1.DaemonSet, error) {
+	obj, _, err := scheme.Codecs.UniversalDeserializer().Decode(yamlData, nil, nil)
+	if err != nil {
+		return nil, errors.Wrap(_errcode.K8sInternalError, err.Error())
+	}
+
+	daemonSet, ok := obj.(*v1.DaemonSet)
+	if !ok {
+		return nil, errors.Wrapf(_errcode.K8sInternalError, "object is not v1.DaemonSet")
+	}
+	return daemonSet, nil
+}
+
+// ApplyDaemonSet 声明式创建/更新DaemonSet
+func (s *Service) ApplyDaemonSet(ctx context.Context,
+	clusterName entity.ClusterName, yamlData []byte, env string) (*v1.DaemonSet, error) {
+	applyDaemonSet, err := s.decodeDaemonSetYamlData(ctx, yamlData)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.GetDaemonSetDetail(ctx, clusterName,
+		&req.GetWorkloadDetailReq{
+			Namespace: applyDaemonSet.GetNamespace(),
+			Name:      applyDaemonSet.GetName(),
+			Env:       env,
+		})
+	if err != nil {
+		if !errcode.EqualError(_errcode.K8sResourceNotFoundError, err) {
+			return nil, err
+		}
+
+		daemonSet, e := s.CreateDaemonSet(ctx, clusterName, applyDaemonSet, env)
+		if e != nil {
+			return nil, e
+		}
+		recordTaskObjectVersion(ctx, entity.K8sObjectKindDaemonSet, daemonSet)
+		return daemonSet, nil
+	}
+
+	daemonSet, err := s.PatchDaemonSet(ctx, clusterName, applyDaemonSet, yamlData, env)
+	if err != nil {
+		return nil, err
+	}
+	recordTaskObjectVersion(ctx, entity.K8sObjectKindDaemonSet, daemonSet)
+	return daemonSet, nil
+}
+
+// CreateDaemonSet 创建DaemonSet
+func (s *Service) CreateDaemonSet(ctx context.Context, clusterName entity.ClusterName,
+	daemonSet *v1.DaemonSet, env string) (*v1.DaemonSet, error) {
+	c, err := s.GetK8sTypedClient(clusterName, env)
+	if err != nil {
+		return nil, errors.Wrap(_errcode.K8sInternalError, err.Error())
+	}
+
+	res, err := c.AppsV1().DaemonSets(daemonSet.GetNamespace()).
+		Create(ctx, daemonSet, metav1.CreateOptions{})
+	if err != nil {
+		return nil, errors.Wrap(_errcode.K8sInternalError, err.Error())
+	}
+
+	return res, nil
+}
+
+// GetDaemonSetDetail 获取DaemonSet详情
+func (s *Service) GetDaemonSetDetail(ctx context.Context, clusterName entity.ClusterName,
+	getReq *req.GetWorkloadDetailReq) (*v1.DaemonSet, error) {
+	c, err := s.GetK8sTypedClient(clusterName, getReq.Env)
+	if err != nil {
+		return nil, errors.Wrap(_errcode.K8sInternalError, err.Error())
+	}
+
+	if getReq.Namespace == "" {
+		getReq.Namespace = getReq.Env
+	}
+
+	res, err := c.AppsV1().DaemonSets(getReq.Namespace).
+		Get(ctx, getReq.Name, metav1.GetOptions{})
+	if err != nil {
+		if k8sErrors.IsNotFound(err) {
+			return nil, errors.Wrap(_errcode.K8sResourceNotFoundError, err.Error())
+		}
+		return nil, errors.Wrap(_errcode.K8sInternalError, err.Error())
+	}
+
+	return res, nil
+}
+
+// GetDaemonSets 获取DaemonSet列表
+func (s *Service) GetDaemonSets(ctx context.Context, clusterName entity.ClusterName,
+	getReq *req.GetWorkloadsReq) ([]v1.DaemonSet, error) {
+	c, err := s.GetK8sTypedClient(clusterName, getReq.Env)
+	if err != nil {
+		return nil, errors.Wrap(_errcode.K8sInternalError, err.Error())
+	}
+
+	list, err := c.AppsV1().DaemonSets(getReq.Namespace).
+		List(ctx, metav1.ListOptions{LabelSelector: s.getWorkloadsLabelSelector(getReq)})
+	if err != nil {
+		return nil, errors.Wrap(_errcode.K8sInternalError, err.Error())
+	}
+
+	return list.Items, nil
+}
+
+// PatchDaemonSet 更新DaemonSet
+func (s *Service) PatchDaemonSet(ctx context.Context, clusterName entity.ClusterName,
+	daemonSet *v1.DaemonSet, yamlData []byte, env string) (*v1.DaemonSet, error) {
+	var jsonObj map[string]interface{}
+	err := yaml.Unmarshal(yamlData, &jsonObj)
+	if err != nil {
+		return nil, errors.Wrap(errcode.InternalError, err.Error())
+	}
+
+	patchData, err := json.Marshal(jsonObj)
+	if err != nil {
+		return nil, errors.Wrap(errcode.InternalError, err.Error())
+	}
+
+	return s.patchDaemonSet(ctx, clusterName, daemonSet.GetNamespace(), daemonSet.GetName(), patchData, env)
+}
+
+func (s *Service) patchDaemonSet(ctx context.Context, clusterName entity.ClusterName,
+	namespace, name string, patchData []byte, env string) (*v1.DaemonSet, error) {
+	c, err := s.GetK8sTypedClient(clusterName, env)
+	if err != nil {
+		return nil, errors.Wrap(_errcode.K8sInternalError, err.Error())
+	}
+
+	res, err := c.AppsV1().DaemonSets(namespace).
+		Patch(ctx, name, types.MergePatchType, patchData, metav1.PatchOptions{})
+	if err != nil {
+		if k8sErrors.IsNotFound(err) {
+			return nil, errors.Wrap(_errcode.K8sResourceNotFoundError, err.Error())
+		}
+		return nil, errors.Wrap(_errcode.K8sInternalError, err.Error())
+	}
+
+	return res, nil
+}
+
+// RestartDaemonSet 重启DaemonSet, 逐个节点滚动重建 pod
+func (s *Service) RestartDaemonSet(ctx context.Context, clusterName entity.ClusterName,
+	restartReq *req.GetWorkloadDetailReq) (*v1.DaemonSet, error) {
+	patchData := fmt.Sprintf(`{"spec":{"template":{"metadata":{"annotations":{"%s":"%s"}}}}}`,
+		K8sAnnotationRestart, time.Now().Format(RestartAnnotationLayout))
+
+	return s.patchDaemonSet(ctx, clusterName, restartReq.Namespace, restartReq.Name, []byte(patchData), restartReq.Env)
+}
+
+// DeleteDaemonSet 删除DaemonSet
+func (s *Service) DeleteDaemonSet(ctx context.Context, clusterName entity.ClusterName,
+	deleteReq *req.DeleteWorkloadReq) error {
+	c, err := s.GetK8sTypedClient(clusterName, deleteReq.Env)
+	if err != nil {
+		return errors.Wrap(_errcode.K8sInternalError, err.Error())
+	}
+
+	policy := metav1.DeletePropagationForeground
+	err = c.AppsV1().DaemonSets(deleteReq.Namespace).
+		Delete(ctx, deleteReq.Name, metav1.DeleteOptions{
+			PropagationPolicy: &policy,
+		})
+	if err != nil {
+		if k8sErrors.IsNotFound(err) {
+			return errors.Wrap(_errcode.K8sResourceNotFoundError, err.Error())
+		}
+		return errors.Wrap(_errcode.K8sInternalError, err.Error())
+	}
+
+	return nil
+}
@@ -135,6 +135,7 @@ func (s *Service) initDeploymentTemplate(ctx context.Context, project *resp.Proj
 		ImageName:                     entity.ImageName(task.Param.ImageVersion),
 		ContainerName:                 utils.GetPodContainerName(project.Name, app.Name),
 		Env:                           envVars,
+		SecretEnv:                     getSecretEnvTemplates(task),
 		CoverCommand:                  task.Param.CoverCommand,
 		PreStopCommand:                task.Param.PreStopCommand,
 		TerminationGracePeriodSeconds: task.Param.TerminationGracePeriodSeconds,
@@ -153,6 +154,7 @@ func (s *Service) initDeploymentTemplate(ctx context.Context, project *resp.Proj
 			task.Param.NodeAffinityLabelConfig,
 		),
 		DisableHighAvailability:           task.Param.DisableHighAvailability,
+		TopologySpreadConstraints:         getTopologySpreadConstraintTemplates(ctx, task),
 		LivenessProbeInitialDelaySeconds:  task.Param.LivenessProbeInitialDelaySeconds,
 		ReadinessProbeInitialDelaySeconds: task.Param.ReadinessProbeInitialDelaySeconds,
 		LocalDNS:                          cluster.localDNS,
@@ -172,6 +174,20 @@ func (s *Service) initDeploymentTemplate(ctx context.Context, project *resp.Proj
 		}
 	}
 
+	template.InitContainers, err = s.getExtraContainerTemplates(project, app, task, team, task.Param.InitContainers)
+	if err != nil {
+		return nil, err
+	}
+
+	template.Sidecars, err = s.getExtraContainerTemplates(project, app, task, team, task.Param.Sidecars)
+	if err != nil {
+		return nil, err
+	}
+
+	volumes, mounts, extraMounts := getVolumeTemplates(project, app, task)
+	template.Volumes, template.VolumeMounts = volumes, mounts
+	setExtraContainerVolumeMounts(extraMounts, template.InitContainers, template.Sidecars)
+
 	return template, nil
 }
 
@@ -739,6 +755,7 @@ func (s *Service) ApplyExtensionsV1Beta1Deployment(ctx context.Context,
 		if e != nil {
 			return nil, e
 		}
+		recordTaskObjectVersion(ctx, "Deployment", deployment)
 		return deployment, nil
 	}
 
@@ -746,6 +763,7 @@ func (s *Service) ApplyExtensionsV1Beta1Deployment(ctx context.Context,
 	if err != nil {
 		return nil, err
 	}
+	recordTaskObjectVersion(ctx, "Deployment", deployment)
 	return deployment, nil
 }
 
@@ -772,6 +790,7 @@ func (s *Service) ApplyAppsV1Deployment(ctx context.Context,
 		if e != nil {
 			return nil, e
 		}
+		recordTaskObjectVersion(ctx, "Deployment", deployment)
 		return deployment, nil
 	}
 
@@ -779,6 +798,7 @@ func (s *Service) ApplyAppsV1Deployment(ctx context.Context,
 	if err != nil {
 		return nil, err
 	}
+	recordTaskObjectVersion(ctx, "Deployment", deployment)
 	return deployment, nil
 }
 
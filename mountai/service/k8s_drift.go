@@ -0,0 +1,439 @@
+package service
+
+import (
+	"rulai/config"
+	"rulai/models/entity"
+	"rulai/models/req"
+	"rulai/models/resp"
+	"rulai/utils"
+	_errcode "rulai/utils/errcode"
+
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"gitlab.shanhai.int/sre/library/base/deepcopy.v2"
+	"gitlab.shanhai.int/sre/library/base/null"
+	"gitlab.shanhai.int/sre/library/log"
+	"gitlab.shanhai.int/sre/library/net/errcode"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	v2 "k8s.io/api/autoscaling/v2"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// DetectK8sDrifts 比较近期部署过的应用的线上对象与最近一次成功部署的渲染结果, 记录并通知配置漂移
+func (s *Service) DetectK8sDrifts(ctx context.Context) error {
+	lookback := entity.K8sDriftDefaultLookback
+	if conf := config.Conf.DriftDetection; conf != nil && conf.Lookback > 0 {
+		lookback = time.Duration(conf.Lookback)
+	}
+
+	tasks, err := s.GetTasks(ctx, &req.GetTasksReq{
+		ActionList:   entity.TaskActionFinalVersionList,
+		StatusList:   entity.TaskStatusSuccessStateList,
+		MinTimestamp: int(time.Now().Add(-lookback).Unix()),
+	})
+	if err != nil {
+		return err
+	}
+
+	apps := make(map[string]*resp.AppDetailResp)
+	projects := make(map[string]*resp.ProjectDetailResp)
+	detected := make(map[string]bool)
+	for _, task := range tasks {
+		key := fmt.Sprintf("%s-%s-%s", task.AppID, task.EnvName, task.ClusterName)
+		if detected[key] {
+			continue
+		}
+		detected[key] = true
+
+		app, ok := apps[task.AppID]
+		if !ok {
+			app, err = s.GetAppDetail(ctx, task.AppID)
+			if err != nil {
+				log.Errorc(ctx, "get app(%s) of drift detection error: %s", task.AppID, err)
+				continue
+			}
+			apps[task.AppID] = app
+		}
+
+		// 目前仅检测 Deployment 及 HPA
+		if app.Type != entity.AppTypeService && app.Type != entity.AppTypeWorker {
+			continue
+		}
+
+		project, ok := projects[app.ProjectID]
+		if !ok {
+			project, err = s.GetProjectDetail(ctx, app.ProjectID)
+			if err != nil {
+				log.Errorc(ctx, "get project(%s) of drift detection error: %s", app.ProjectID, err)
+				continue
+			}
+			projects[app.ProjectID] = project
+		}
+
+		err = s.detectK8sDrift(ctx, project, app, task.EnvName, task.ClusterName)
+		if err != nil {
+			log.Errorc(ctx, "detect k8s drift of app(%s) env(%s) cluster(%s) error: %s",
+				app.ID, task.EnvName, task.ClusterName, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) detectK8sDrift(ctx context.Context, project *resp.ProjectDetailResp, app *resp.AppDetailResp,
+	envName entity.AppEnvName, clusterName entity.ClusterName) error {
+	// 变更进行中时线上状态与期望状态不一致是正常的
+	unfinishedCount, err := s.GetTasksCount(ctx, &req.GetTasksReq{
+		AppID:             app.ID,
+		EnvName:           envName,
+		StatusInverseList: entity.TaskStatusFinalStateList,
+		Suspend:           null.BoolFrom(false),
+	})
+	if err != nil {
+		return err
+	}
+	if unfinishedCount > 0 {
+		return nil
+	}
+
+	task, err := s.GetLatestDeploySuccessTaskFinalVersion(ctx, &req.GetLatestTaskReq{
+		AppID:       app.ID,
+		EnvName:     envName,
+		ClusterName: clusterName,
+	})
+	if err != nil {
+		return err
+	}
+
+	// 已停止的应用不做检测
+	scaleTask, err := s.GetLatestSuccessTask(ctx, &req.GetLatestTaskReq{
+		AppID:       app.ID,
+		EnvName:     envName,
+		ClusterName: clusterName,
+		Version:     task.Version,
+		ActionList:  []entity.TaskAction{entity.TaskActionStop, entity.TaskActionResume},
+	})
+	if err != nil && !errcode.EqualError(_errcode.NoRequiredTaskError, err) {
+		return err
+	}
+	if err == nil && scaleTask.Action == entity.TaskActionStop && task.CreateTime < scaleTask.CreateTime {
+		return nil
+	}
+
+	// 已删除或清理的版本不做检测, 并关闭未处理的漂移记录, 避免自动修复重新创建
+	removed, err := s.isK8sDriftVersionRemoved(ctx, app, task)
+	if err != nil {
+		return err
+	}
+	if removed {
+		return s.recordK8sDrift(ctx, project, app, task, nil)
+	}
+
+	items, err := s.diffK8sDriftObjects(ctx, project, app, task)
+	if err != nil {
+		return err
+	}
+
+	return s.recordK8sDrift(ctx, project, app, task, items)
+}
+
+// isK8sDriftVersionRemoved 最终版本在部署成功后是否已被删除或清理
+func (s *Service) isK8sDriftVersionRemoved(ctx context.Context, app *resp.AppDetailResp,
+	task *resp.TaskDetailResp) (bool, error) {
+	deleteTask, err := s.GetLatestSuccessTask(ctx, &req.GetLatestTaskReq{
+		AppID:       app.ID,
+		EnvName:     task.EnvName,
+		ClusterName: task.ClusterName,
+		Version:     task.Version,
+		ActionList:  []entity.TaskAction{entity.TaskActionDelete},
+	})
+	if err != nil && !errcode.EqualError(_errcode.NoRequiredTaskError, err) {
+		return false, err
+	}
+	if err == nil && task.CreateTime < deleteTask.CreateTime {
+		return true, nil
+	}
+
+	// 清理整个应用的任务不指定版本, 应用重命名时清理的是旧应用名的资源
+	cleanTask, err := s.GetLatestSuccessTask(ctx, &req.GetLatestTaskReq{
+		AppID:       app.ID,
+		EnvName:     task.EnvName,
+		ClusterName: task.ClusterName,
+		ActionList:  []entity.TaskAction{entity.TaskActionClean},
+	})
+	if errcode.EqualError(_errcode.NoRequiredTaskError, err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if task.CreateTime >= cleanTask.CreateTime {
+		return false, nil
+	}
+
+	if cleanTask.Param.CleanedVersion != "" {
+		return cleanTask.Param.CleanedVersion == task.Version, nil
+	}
+	return cleanTask.Param.CleanedAppName == app.Name, nil
+}
+
+// diffK8sDriftObjects 渲染期望的 Deployment 及 HPA 并与线上对象比较
+func (s *Service) diffK8sDriftObjects(ctx context.Context, project *resp.ProjectDetailResp, app *resp.AppDetailResp,
+	task *resp.TaskDetailResp) ([]*entity.K8sDriftItem, error) {
+	data, err := s.RenderDeploymentTemplate(ctx, project, app, task, project.Team)
+	if err != nil {
+		return nil, err
+	}
+
+	obj, _, err := scheme.Codecs.UniversalDeserializer().Decode(data, nil, nil)
+	if err != nil {
+		return nil, errors.Wrap(_errcode.K8sInternalError, err.Error())
+	}
+
+	expected := new(resp.Deployment)
+	err = deepcopy.Copy(obj).To(expected)
+	if err != nil {
+		return nil, errors.Wrap(errcode.InternalError, err.Error())
+	}
+
+	items := make([]*entity.K8sDriftItem, 0)
+	live, err := s.GetDeploymentDetail(ctx, task.ClusterName, task.EnvName, &req.GetDeploymentDetailReq{
+		Namespace: task.Namespace,
+		Name:      task.Version,
+		Env:       string(task.EnvName),
+	})
+	if errcode.EqualError(_errcode.K8sResourceNotFoundError, err) {
+		items = append(items, &entity.K8sDriftItem{
+			Kind:     entity.K8sObjectKindDeployment,
+			Field:    "metadata.name",
+			Expected: task.Version,
+			Actual:   entity.K8sDriftMissingValue,
+		})
+	} else if err != nil {
+		return nil, err
+	} else {
+		// 副本数由 HPA 或定时 HPA 管理时不做比较
+		if !task.Param.IsAutoScale && len(task.Param.CronScaleJobGroups) == 0 {
+			items = append(items, entity.DiffK8sReplicas(entity.K8sObjectKindDeployment,
+				expected.Spec.Replicas, live.Spec.Replicas)...)
+		}
+		items = append(items, entity.DiffK8sPodTemplate(entity.K8sObjectKindDeployment,
+			&expected.Spec.Template, &live.Spec.Template)...)
+	}
+
+	var expectedHPA, liveHPA *v2.HorizontalPodAutoscaler
+	if task.Param.IsAutoScale {
+		data, err = s.RenderHPATemplate(ctx, project, app, task, project.Team)
+		if err != nil {
+			return nil, err
+		}
+		expectedHPA, err = s.decodeHPAYamlData(ctx, data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	liveHPA, err = s.GetHPADetail(ctx, task.ClusterName, &req.GetHPADetailReq{
+		Namespace: task.Namespace,
+		Name:      task.Version,
+		Env:       string(task.EnvName),
+	})
+	if err != nil && !errcode.EqualError(_errcode.K8sResourceNotFoundError, err) {
+		return nil, err
+	}
+
+	return append(items, entity.DiffK8sHPA(expectedHPA, liveHPA)...), nil
+}
+
+// recordK8sDrift 记录漂移结果, 漂移内容变化时通知, 开启自动修复时重新应用期望状态
+func (s *Service) recordK8sDrift(ctx context.Context, project *resp.ProjectDetailResp, app *resp.AppDetailResp,
+	task *resp.TaskDetailResp, items []*entity.K8sDriftItem) error {
+	now := time.Now()
+	existed, err := s.dao.FindSingleK8sDrift(ctx, bson.M{
+		"app_id":       app.ID,
+		"env_name":     task.EnvName,
+		"cluster_name": task.ClusterName,
+		"status":       entity.K8sDriftStatusDrifted,
+	})
+	if err != nil && !errcode.EqualError(errcode.NoRowsFoundError, err) {
+		return err
+	}
+
+	if len(items) == 0 {
+		if existed == nil {
+			return nil
+		}
+		return s.dao.UpdateSingleK8sDrift(ctx, existed.ID, bson.M{
+			"$set": bson.M{
+				"status":      entity.K8sDriftStatusResolved,
+				"update_time": now,
+			},
+		})
+	}
+
+	// 漂移内容未变化时仅更新检测时间, 避免重复通知
+	if existed != nil && existed.TaskID == task.ID && entity.EqualK8sDriftItems(existed.Items, items) {
+		return s.dao.UpdateSingleK8sDrift(ctx, existed.ID, bson.M{
+			"$set": bson.M{
+				"detect_time": now,
+				"update_time": now,
+			},
+		})
+	}
+
+	drift := &entity.K8sDrift{
+		ID:          primitive.NewObjectID(),
+		ProjectID:   project.ID,
+		AppID:       app.ID,
+		EnvName:     task.EnvName,
+		ClusterName: task.ClusterName,
+		Namespace:   task.Namespace,
+		TaskID:      task.ID,
+		Version:     task.Version,
+		Items:       items,
+		Status:      entity.K8sDriftStatusDrifted,
+		DetectTime:  &now,
+		CreateTime:  &now,
+		UpdateTime:  &now,
+	}
+	if existed != nil {
+		drift.ID, drift.CreateTime = existed.ID, existed.CreateTime
+	}
+
+	if config.Conf.DriftDetection.IsAutoHealEnabled(string(task.EnvName)) {
+		err = s.healK8sDrift(ctx, project, app, task)
+		if err != nil {
+			log.Errorc(ctx, "heal k8s drift of app(%s) env(%s) cluster(%s) error: %s",
+				app.ID, task.EnvName, task.ClusterName, err)
+			drift.HealError = err.Error()
+		} else {
+			drift.Status = entity.K8sDriftStatusHealed
+		}
+	}
+
+	if existed != nil {
+		err = s.dao.UpdateSingleK8sDrift(ctx, drift.ID, bson.M{"$set": drift})
+	} else {
+		err = s.dao.CreateSingleK8sDrift(ctx, drift)
+	}
+	if err != nil {
+		return err
+	}
+
+	return s.notifyK8sDrift(ctx, project, app, drift)
+}
+
+// healK8sDrift 重新应用最近一次成功部署渲染的 Deployment 及 HPA
+func (s *Service) healK8sDrift(ctx context.Context, project *resp.ProjectDetailResp, app *resp.AppDetailResp,
+	task *resp.TaskDetailResp) error {
+	data, err := s.RenderDeploymentTemplate(ctx, project, app, task, project.Team)
+	if err != nil {
+		return err
+	}
+
+	err = s.ApplyDeploymentAndIgnoreResponse(ctx, task.ClusterName, task.EnvName, data)
+	if err != nil {
+		return err
+	}
+
+	if !task.Param.IsAutoScale {
+		return nil
+	}
+
+	data, err = s.RenderHPATemplate(ctx, project, app, task, project.Team)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.ApplyHPA(ctx, task.ClusterName, data, string(task.EnvName))
+	return err
+}
+
+// notifyK8sDrift 按项目通知规则发送漂移通知
+func (s *Service) notifyK8sDrift(ctx context.Context, project *resp.ProjectDetailResp, app *resp.AppDetailResp,
+	drift *entity.K8sDrift) error {
+	rules, err := s.GetMatchedNotificationRules(ctx, project.ID, drift.EnvName, entity.NotificationEventK8sDrift)
+	if err != nil {
+		return err
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+
+	title := fmt.Sprintf("%s的应用%s在集群%s存在%d处配置漂移", project.Name, app.Name, drift.ClusterName, len(drift.Items))
+	if drift.Status == entity.K8sDriftStatusHealed {
+		title += ", 已自动修复"
+	}
+
+	msg := &req.NotificationMessage{
+		AppOpMessage: &req.AppOpMessage{
+			Title:           title,
+			ProjectName:     project.Name,
+			AppName:         app.Name,
+			UserName:        "AMS",
+			Env:             string(drift.EnvName),
+			Action:          "配置漂移",
+			OpTime:          drift.DetectTime.Format(utils.DefaultTimeFormatLayout),
+			DetailURL:       s.GetAmsFrontendProjectURL(project.ID, drift.EnvName),
+			ProjectLanguage: project.Language,
+		},
+		Event:     entity.NotificationEventK8sDrift,
+		ProjectID: project.ID,
+		AppID:     app.ID,
+		TaskID:    drift.TaskID,
+	}
+	if project.Team != nil {
+		msg.TeamName = project.Team.Name
+	}
+
+	return s.DispatchProjectNotifications(ctx, rules, msg)
+}
+
+func (s *Service) GetK8sDrifts(ctx context.Context, getReq *req.GetK8sDriftsReq) ([]*resp.K8sDriftDetail, int, error) {
+	filter := bson.M{}
+	if getReq.ProjectID != "" {
+		filter["project_id"] = getReq.ProjectID
+	}
+	if getReq.AppID != "" {
+		filter["app_id"] = getReq.AppID
+	}
+	if getReq.EnvName != "" {
+		filter["env_name"] = getReq.EnvName
+	}
+	if getReq.ClusterName != "" {
+		filter["cluster_name"] = getReq.ClusterName
+	}
+	if getReq.Status != "" {
+		filter["status"] = getReq.Status
+	}
+
+	limit := int64(getReq.Limit)
+	skip := int64(getReq.Page-1) * limit
+
+	drifts, err := s.dao.FindK8sDrifts(ctx, filter, &options.FindOptions{
+		Limit: &limit,
+		Skip:  &skip,
+		Sort:  bson.D{{Key: "detect_time", Value: -1}, {Key: "_id", Value: -1}},
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	count, err := s.dao.CountK8sDrifts(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	res := make([]*resp.K8sDriftDetail, 0)
+	err = deepcopy.Copy(&drifts).To(&res)
+	if err != nil {
+		return nil, 0, errors.Wrap(errcode.InternalError, err.Error())
+	}
+
+	return res, count, nil
+}
@@ -69,6 +69,8 @@ func (s *Service) initHPATemplate(ctx context.Context, project *resp.ProjectDeta
 		MaxReplicas: int32(task.Param.MaxPodCount),
 		CPUTarget:   DefaultHPACpuTarget,
 		MemTarget:   DefaultHPAMemTarget,
+		Metrics:     task.Param.HPAMetrics,
+		Behavior:    task.Param.HPABehavior,
 	}
 
 	return template, nil
@@ -125,6 +127,7 @@ func (s *Service) ApplyHPA(ctx context.Context, clusterName entity.ClusterName,
 		if e != nil {
 			return nil, e
 		}
+		recordTaskObjectVersion(ctx, "HorizontalPodAutoscaler", hpa)
 		return hpa, nil
 	}
 
@@ -132,6 +135,7 @@ func (s *Service) ApplyHPA(ctx context.Context, clusterName entity.ClusterName,
 	if err != nil {
 		return nil, err
 	}
+	recordTaskObjectVersion(ctx, "HorizontalPodAutoscaler", hpa)
 	return hpa, nil
 }
 
@@ -182,6 +186,14 @@ func (s *Service) PatchHPA(ctx context.Context, clusterName entity.ClusterName,
 		return nil, errors.Wrap(errcode.InternalError, err.Error())
 	}
 
+	// 合并补丁不会删除未声明的字段, 取消扩缩容行为策略时需显式置空
+	if hpa.Spec.Behavior == nil {
+		patchData, err = setHPABehaviorNull(patchData)
+		if err != nil {
+			return nil, errors.Wrap(errcode.InternalError, err.Error())
+		}
+	}
+
 	c, err := s.GetK8sTypedClient(clusterName, env)
 	if err != nil {
 		return nil, errors.Wrap(_errcode.K8sInternalError, err.Error())
@@ -200,6 +212,22 @@ func (s *Service) PatchHPA(ctx context.Context, clusterName entity.ClusterName,
 	return res, nil
 }
 
+func setHPABehaviorNull(patchData []byte) ([]byte, error) {
+	var obj map[string]interface{}
+	err := json.Unmarshal(patchData, &obj)
+	if err != nil {
+		return nil, err
+	}
+
+	spec, ok := obj["spec"].(map[string]interface{})
+	if !ok {
+		return patchData, nil
+	}
+	spec["behavior"] = nil
+
+	return json.Marshal(obj)
+}
+
 func (s *Service) getHPAsLabelSelector(getReq *req.GetHPAsReq) string {
 	labels := make([]string, 0)
 	if getReq.ProjectName != "" {
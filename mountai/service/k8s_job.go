@@ -132,6 +132,7 @@ func (s *Service) ApplyJob(ctx context.Context, clusterName entity.ClusterName,
 		if err != nil {
 			return nil, e
 		}
+		recordTaskObjectVersion(ctx, "Job", job)
 		return job, nil
 	}
 
@@ -139,6 +140,7 @@ func (s *Service) ApplyJob(ctx context.Context, clusterName entity.ClusterName,
 	if err != nil {
 		return nil, err
 	}
+	recordTaskObjectVersion(ctx, "Job", job)
 	return job, nil
 }
 
@@ -252,6 +254,15 @@ func (s *Service) initJobTemplate(ctx context.Context, project *resp.ProjectDeta
 		),
 	}
 
+	template.InitContainers, err = s.getExtraContainerTemplates(project, app, task, team, task.Param.InitContainers)
+	if err != nil {
+		return nil, err
+	}
+
+	volumes, mounts, extraMounts := getVolumeTemplates(project, app, task)
+	template.Volumes, template.VolumeMounts = volumes, mounts
+	setExtraContainerVolumeMounts(extraMounts, template.InitContainers)
+
 	return template, nil
 }
 
@@ -0,0 +1,485 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gitlab.shanhai.int/sre/library/base/deepcopy.v2"
+	"gitlab.shanhai.int/sre/library/log"
+	"gitlab.shanhai.int/sre/library/net/errcode"
+	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"rulai/config"
+	"rulai/models/entity"
+	"rulai/models/req"
+	"rulai/models/resp"
+	_errcode "rulai/utils/errcode"
+)
+
+// PodDisruptionBudget 的 k8s 资源版本
+var (
+	groupVersionPolicyV1      = &schema.GroupVersion{Group: "policy", Version: "v1"}
+	groupVersionPolicyV1beta1 = &schema.GroupVersion{Group: "policy", Version: "v1beta1"}
+)
+
+// defaultPrdAvailabilityConfig 生产环境未配置时使用的默认高可用配置
+// 实例尽量分散到不同可用区及节点, 节点排空时每次最多驱逐一个实例
+var defaultPrdAvailabilityConfig = &config.AvailabilityEnvConfig{
+	EnablePDB:         true,
+	PDBMaxUnavailable: "1",
+	PDBMinReplicas:    entity.DefaultPDBMinReplicas,
+	TopologySpread: []*config.TopologySpreadConfig{
+		{
+			TopologyKey:       entity.TopologyKeyZone,
+			MaxSkew:           1,
+			WhenUnsatisfiable: string(v1.ScheduleAnyway),
+		},
+		{
+			TopologyKey:       entity.TopologyKeyHostname,
+			MaxSkew:           1,
+			WhenUnsatisfiable: string(v1.ScheduleAnyway),
+		},
+	},
+}
+
+// getAvailabilityConfig 获取环境高可用配置, 未配置时生产环境使用默认配置, 其他环境不开启
+func getAvailabilityConfig(envName entity.AppEnvName) *config.AvailabilityEnvConfig {
+	if conf := config.Conf.Availability; conf != nil {
+		if envConf, ok := conf.Envs[string(envName)]; ok {
+			return envConf
+		}
+	}
+
+	if envName == entity.AppEnvPrd {
+		return defaultPrdAvailabilityConfig
+	}
+	return nil
+}
+
+// getTopologySpreadConstraintTemplates 拓扑分布约束渲染模板, 关闭高可用时不渲染
+func getTopologySpreadConstraintTemplates(ctx context.Context,
+	task *resp.TaskDetailResp) []*entity.TopologySpreadConstraintTemplate {
+	conf := getAvailabilityConfig(task.EnvName)
+	if conf == nil || task.Param.DisableHighAvailability {
+		return nil
+	}
+
+	res := make([]*entity.TopologySpreadConstraintTemplate, 0, len(conf.TopologySpread))
+	for _, c := range conf.TopologySpread {
+		tpl := &entity.TopologySpreadConstraintTemplate{
+			TopologyKey:       c.TopologyKey,
+			MaxSkew:           c.MaxSkew,
+			WhenUnsatisfiable: v1.UnsatisfiableConstraintAction(c.WhenUnsatisfiable),
+		}
+		// 配置有误时跳过, 避免部署失败
+		if err := tpl.Validate(); err != nil {
+			log.Warnc(ctx, "invalid topology spread config of env(%s): %s", task.EnvName, err)
+			continue
+		}
+		res = append(res, tpl)
+	}
+
+	return res
+}
+
+// needTaskPDB 任务是否需要创建 PodDisruptionBudget
+func needTaskPDB(app *resp.AppDetailResp, task *resp.TaskDetailResp) bool {
+	return needPDB(app.Type, task.EnvName, task.Param.MinPodCount, task.Param.DisableHighAvailability)
+}
+
+func needPDB(appType entity.AppType, envName entity.AppEnvName, minPodCount int, disableHighAvailability bool) bool {
+	if !entity.IsPDBSupportedAppType(appType) || disableHighAvailability {
+		return false
+	}
+
+	conf := getAvailabilityConfig(envName)
+	if conf == nil || !conf.EnablePDB {
+		return false
+	}
+
+	minReplicas := conf.PDBMinReplicas
+	if minReplicas < entity.DefaultPDBMinReplicas {
+		minReplicas = entity.DefaultPDBMinReplicas
+	}
+	return minPodCount >= minReplicas
+}
+
+// validateTaskPDB 创建部署任务时校验最大不可用数量, 避免 Deployment 发布后才创建 PodDisruptionBudget 失败
+func validateTaskPDB(app *resp.AppDetailResp, task *entity.Task) error {
+	switch task.Action {
+	case entity.TaskActionFullDeploy, entity.TaskActionFullCanaryDeploy, entity.TaskActionRollback:
+	default:
+		return nil
+	}
+
+	if task.Param == nil ||
+		!needPDB(app.Type, task.EnvName, task.Param.MinPodCount, task.Param.DisableHighAvailability) {
+		return nil
+	}
+
+	_, err := entity.ParsePDBMaxUnavailable(
+		getAvailabilityConfig(task.EnvName).PDBMaxUnavailable, task.Param.MinPodCount)
+	if err != nil {
+		return errors.Wrapf(errcode.InvalidParams,
+			"min pod count %d conflicts with pod disruption budget: %s", task.Param.MinPodCount, err)
+	}
+
+	return nil
+}
+
+// applyTaskPDB 创建任务版本的 PodDisruptionBudget, 返回下一阶段
+func (s *Service) applyTaskPDB(ctx context.Context, project *resp.ProjectDetailResp,
+	app *resp.AppDetailResp, task *resp.TaskDetailResp) (entity.TaskStatus, error) {
+	if !needTaskPDB(app, task) {
+		if !isPDBEnabled(task.EnvName) {
+			return entity.TaskStatusCreatePDBFinish, nil
+		}
+
+		// 关闭高可用或实例数不足时删除该版本已有的 PodDisruptionBudget, 避免继续限制节点排空
+		err := s.DeletePDB(ctx, task.ClusterName, &req.GetPDBDetailReq{
+			Namespace: task.Namespace,
+			Name:      task.Version,
+			Env:       string(task.EnvName),
+		})
+		if err != nil {
+			return "", err
+		}
+		return entity.TaskStatusCreatePDBFinish, nil
+	}
+
+	gv, err := s.getPDBGroupVersion(task.ClusterName, string(task.EnvName))
+	if err != nil {
+		return "", err
+	}
+	if gv == nil {
+		log.Warnc(ctx, "cluster(%s) env(%s) does not serve %s, skip creating",
+			task.ClusterName, task.EnvName, entity.K8sObjectKindPDB)
+		return entity.TaskStatusCreatePDBFinish, nil
+	}
+
+	maxUnavailable, err := entity.ParsePDBMaxUnavailable(
+		getAvailabilityConfig(task.EnvName).PDBMaxUnavailable, task.Param.MinPodCount)
+	if err != nil {
+		return "", errors.Wrap(errcode.InvalidParams, err.Error())
+	}
+
+	labels := map[string]string{
+		"project": project.Name,
+		"app":     app.Name,
+		"version": task.Version,
+	}
+	pdb := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      task.Version,
+			Namespace: task.Namespace,
+			Labels:    labels,
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			MaxUnavailable: &maxUnavailable,
+			Selector:       &metav1.LabelSelector{MatchLabels: labels},
+		},
+	}
+
+	existed, err := s.GetPDBDetail(ctx, task.ClusterName, &req.GetPDBDetailReq{
+		Namespace: task.Namespace,
+		Name:      task.Version,
+		Env:       string(task.EnvName),
+	})
+	if err != nil {
+		if !errcode.EqualError(_errcode.K8sResourceNotFoundError, err) {
+			return "", err
+		}
+
+		err = s.createPDB(ctx, task.ClusterName, string(task.EnvName), gv, pdb)
+		if err != nil {
+			return "", err
+		}
+		return entity.TaskStatusCreatePDBUnderway, nil
+	}
+
+	// 重试时已存在, 更新为当前配置
+	existed.Spec = pdb.Spec
+	err = s.updatePDB(ctx, task.ClusterName, string(task.EnvName), gv, existed)
+	if err != nil {
+		return "", err
+	}
+
+	return entity.TaskStatusCreatePDBUnderway, nil
+}
+
+// checkTaskPDBCreated 校验任务版本的 PodDisruptionBudget 是否已生效
+func (s *Service) checkTaskPDBCreated(ctx context.Context, task *resp.TaskDetailResp) (entity.TaskStatus, error) {
+	pdb, err := s.GetPDBDetail(ctx, task.ClusterName, &req.GetPDBDetailReq{
+		Namespace: task.Namespace,
+		Name:      task.Version,
+		Env:       string(task.EnvName),
+	})
+	if err != nil {
+		if errcode.EqualError(_errcode.K8sResourceNotFoundError, err) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	if pdb.Status.ObservedGeneration >= pdb.GetGeneration() {
+		return entity.TaskStatusCreatePDBFinish, nil
+	}
+	return "", nil
+}
+
+// isPDBEnabled 环境是否开启 PodDisruptionBudget, 未开启时跳过创建及清理阶段
+func isPDBEnabled(envName entity.AppEnvName) bool {
+	conf := getAvailabilityConfig(envName)
+	return conf != nil && conf.EnablePDB
+}
+
+// getPDBGroupVersion 获取集群下 PodDisruptionBudget 的 GroupVersion, 集群不支持时返回 nil
+// policy/v1 仅在 1.21 及以上版本提供, 其余版本使用 policy/v1beta1
+func (s *Service) getPDBGroupVersion(clusterName entity.ClusterName, envName string) (*schema.GroupVersion, error) {
+	clusterInfo, err := s.getClusterInfo(clusterName, envName)
+	if err != nil {
+		return nil, err
+	}
+
+	gv, ok := clusterInfo.k8sGroupVersions[entity.K8sObjectKindPDB]
+	if !ok {
+		return nil, nil
+	}
+
+	if equalK8sGroupVersion(gv, groupVersionPolicyV1) {
+		return groupVersionPolicyV1, nil
+	}
+	return groupVersionPolicyV1beta1, nil
+}
+
+// createPDB 按集群支持的版本创建 PodDisruptionBudget
+func (s *Service) createPDB(ctx context.Context, clusterName entity.ClusterName, envName string,
+	gv *schema.GroupVersion, pdb *policyv1.PodDisruptionBudget) error {
+	c, err := s.GetK8sTypedClient(clusterName, envName)
+	if err != nil {
+		return errors.Wrap(_errcode.K8sInternalError, err.Error())
+	}
+
+	if equalK8sGroupVersion(gv, groupVersionPolicyV1) {
+		_, err = c.PolicyV1().PodDisruptionBudgets(pdb.GetNamespace()).Create(ctx, pdb, metav1.CreateOptions{})
+	} else {
+		legacy := new(policyv1beta1.PodDisruptionBudget)
+		err = deepcopy.Copy(pdb).To(legacy)
+		if err != nil {
+			return errors.Wrap(errcode.InternalError, err.Error())
+		}
+		_, err = c.PolicyV1beta1().PodDisruptionBudgets(pdb.GetNamespace()).Create(ctx, legacy, metav1.CreateOptions{})
+	}
+	if err != nil {
+		return errors.Wrap(_errcode.K8sInternalError, err.Error())
+	}
+
+	return nil
+}
+
+// updatePDB 按集群支持的版本更新 PodDisruptionBudget
+func (s *Service) updatePDB(ctx context.Context, clusterName entity.ClusterName, envName string,
+	gv *schema.GroupVersion, pdb *policyv1.PodDisruptionBudget) error {
+	c, err := s.GetK8sTypedClient(clusterName, envName)
+	if err != nil {
+		return errors.Wrap(_errcode.K8sInternalError, err.Error())
+	}
+
+	if equalK8sGroupVersion(gv, groupVersionPolicyV1) {
+		_, err = c.PolicyV1().PodDisruptionBudgets(pdb.GetNamespace()).Update(ctx, pdb, metav1.UpdateOptions{})
+	} else {
+		legacy := new(policyv1beta1.PodDisruptionBudget)
+		err = deepcopy.Copy(pdb).To(legacy)
+		if err != nil {
+			return errors.Wrap(errcode.InternalError, err.Error())
+		}
+		_, err = c.PolicyV1beta1().PodDisruptionBudgets(pdb.GetNamespace()).Update(ctx, legacy, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return errors.Wrap(_errcode.K8sInternalError, err.Error())
+	}
+
+	return nil
+}
+
+// GetPDBDetail 获取 PodDisruptionBudget 详情, 集群不支持时视为不存在
+func (s *Service) GetPDBDetail(ctx context.Context, clusterName entity.ClusterName,
+	getReq *req.GetPDBDetailReq) (*policyv1.PodDisruptionBudget, error) {
+	gv, err := s.getPDBGroupVersion(clusterName, getReq.Env)
+	if err != nil {
+		return nil, err
+	}
+	if gv == nil {
+		return nil, errors.Wrapf(_errcode.K8sResourceNotFoundError,
+			"%s is not served by cluster(%s)", entity.K8sObjectKindPDB, clusterName)
+	}
+
+	c, err := s.GetK8sTypedClient(clusterName, getReq.Env)
+	if err != nil {
+		return nil, errors.Wrap(_errcode.K8sInternalError, err.Error())
+	}
+
+	var obj interface{}
+	if equalK8sGroupVersion(gv, groupVersionPolicyV1) {
+		obj, err = c.PolicyV1().PodDisruptionBudgets(getReq.Namespace).Get(ctx, getReq.Name, metav1.GetOptions{})
+	} else {
+		obj, err = c.PolicyV1beta1().PodDisruptionBudgets(getReq.Namespace).Get(ctx, getReq.Name, metav1.GetOptions{})
+	}
+	if err != nil {
+		if k8sErrors.IsNotFound(err) {
+			return nil, errors.Wrap(_errcode.K8sResourceNotFoundError, err.Error())
+		}
+		return nil, errors.Wrap(_errcode.K8sInternalError, err.Error())
+	}
+
+	if res, ok := obj.(*policyv1.PodDisruptionBudget); ok {
+		return res, nil
+	}
+
+	res := new(policyv1.PodDisruptionBudget)
+	err = deepcopy.Copy(obj).To(res)
+	if err != nil {
+		return nil, errors.Wrap(errcode.InternalError, err.Error())
+	}
+
+	return res, nil
+}
+
+// GetPDBs 获取 PodDisruptionBudget 列表, 集群不支持时返回空列表
+func (s *Service) GetPDBs(ctx context.Context, clusterName entity.ClusterName,
+	getReq *req.GetPDBsReq) ([]policyv1.PodDisruptionBudget, error) {
+	gv, err := s.getPDBGroupVersion(clusterName, getReq.Env)
+	if err != nil {
+		return nil, err
+	}
+	if gv == nil {
+		return nil, nil
+	}
+
+	labels := make([]string, 0)
+	if getReq.ProjectName != "" {
+		labels = append(labels, fmt.Sprintf("project=%s", getReq.ProjectName))
+	}
+	if getReq.AppName != "" {
+		labels = append(labels, fmt.Sprintf("app=%s", getReq.AppName))
+	}
+	if getReq.Version != "" {
+		labels = append(labels, fmt.Sprintf("version=%s", getReq.Version))
+	}
+
+	c, err := s.GetK8sTypedClient(clusterName, getReq.Env)
+	if err != nil {
+		return nil, errors.Wrap(_errcode.K8sInternalError, err.Error())
+	}
+
+	opts := metav1.ListOptions{LabelSelector: strings.Join(labels, ",")}
+	if equalK8sGroupVersion(gv, groupVersionPolicyV1) {
+		list, e := c.PolicyV1().PodDisruptionBudgets(getReq.Namespace).List(ctx, opts)
+		if e != nil {
+			return nil, errors.Wrap(_errcode.K8sInternalError, e.Error())
+		}
+		return list.Items, nil
+	}
+
+	list, err := c.PolicyV1beta1().PodDisruptionBudgets(getReq.Namespace).List(ctx, opts)
+	if err != nil {
+		return nil, errors.Wrap(_errcode.K8sInternalError, err.Error())
+	}
+
+	res := make([]policyv1.PodDisruptionBudget, 0, len(list.Items))
+	err = deepcopy.Copy(&list.Items).To(&res)
+	if err != nil {
+		return nil, errors.Wrap(errcode.InternalError, err.Error())
+	}
+
+	return res, nil
+}
+
+// DeletePDBs 批量删除 PodDisruptionBudget
+func (s *Service) DeletePDBs(ctx context.Context, clusterName entity.ClusterName,
+	deleteReq *req.DeletePDBsReq) error {
+	// 避免误删命名空间下其他应用的 PodDisruptionBudget
+	if deleteReq.ProjectName == "" || deleteReq.AppName == "" {
+		return errors.Wrap(errcode.InvalidParams, "project name and app name are required")
+	}
+
+	pdbs, err := s.GetPDBs(ctx, clusterName, &req.GetPDBsReq{
+		Namespace:   deleteReq.Namespace,
+		ProjectName: deleteReq.ProjectName,
+		AppName:     deleteReq.AppName,
+		Env:         deleteReq.Env,
+	})
+	if err != nil {
+		return err
+	}
+
+	for i := range pdbs {
+		if deleteReq.InverseVersion != "" && pdbs[i].GetName() == deleteReq.InverseVersion {
+			continue
+		}
+
+		err = s.DeletePDB(ctx, clusterName, &req.GetPDBDetailReq{
+			Namespace: pdbs[i].GetNamespace(),
+			Name:      pdbs[i].GetName(),
+			Env:       deleteReq.Env,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DeletePDB 删除 PodDisruptionBudget, 不存在或集群不支持时忽略
+func (s *Service) DeletePDB(ctx context.Context, clusterName entity.ClusterName,
+	deleteReq *req.GetPDBDetailReq) error {
+	gv, err := s.getPDBGroupVersion(clusterName, deleteReq.Env)
+	if err != nil {
+		return err
+	}
+	if gv == nil {
+		return nil
+	}
+
+	c, err := s.GetK8sTypedClient(clusterName, deleteReq.Env)
+	if err != nil {
+		return errors.Wrap(_errcode.K8sInternalError, err.Error())
+	}
+
+	if equalK8sGroupVersion(gv, groupVersionPolicyV1) {
+		err = c.PolicyV1().PodDisruptionBudgets(deleteReq.Namespace).
+			Delete(ctx, deleteReq.Name, metav1.DeleteOptions{})
+	} else {
+		err = c.PolicyV1beta1().PodDisruptionBudgets(deleteReq.Namespace).
+			Delete(ctx, deleteReq.Name, metav1.DeleteOptions{})
+	}
+	if err != nil && !k8sErrors.IsNotFound(err) {
+		return errors.Wrap(_errcode.K8sInternalError, err.Error())
+	}
+
+	return nil
+}
+
+// checkPDBsDeleted 校验 PodDisruptionBudget 是否除保留版本外均已删除
+func (s *Service) checkPDBsDeleted(ctx context.Context, clusterName entity.ClusterName,
+	getReq *req.GetPDBsReq, inverseVersion string) (bool, error) {
+	pdbs, err := s.GetPDBs(ctx, clusterName, getReq)
+	if err != nil {
+		return false, err
+	}
+
+	for i := range pdbs {
+		if inverseVersion == "" || pdbs[i].GetName() != inverseVersion {
+			return false, nil
+		}
+	}
+	return true, nil
+}
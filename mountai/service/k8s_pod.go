@@ -47,6 +47,69 @@ func (s SortPodReverseSlice) Less(i, j int) bool {
 	return s[i].GetCreationTimestamp().After(s[j].GetCreationTimestamp().Time)
 }
 
+// getPodMainContainerRestartCount 获取应用主容器的重启次数, 主容器为 spec 中的第一个容器
+func getPodMainContainerRestartCount(pod *v1.Pod) int {
+	if len(pod.Spec.Containers) == 0 {
+		return 0
+	}
+
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == pod.Spec.Containers[0].Name {
+			return int(status.RestartCount)
+		}
+	}
+	return 0
+}
+
+// getPodContainerStatuses 获取 pod 内各容器的运行状态, 依次为初始化容器, 主容器及边车容器
+func getPodContainerStatuses(pod *v1.Pod) []*resp.RunningStatusContainerResp {
+	statuses := make(map[string]v1.ContainerStatus)
+	for _, status := range pod.Status.InitContainerStatuses {
+		statuses[status.Name] = status
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		statuses[status.Name] = status
+	}
+
+	res := make([]*resp.RunningStatusContainerResp, 0, len(pod.Spec.InitContainers)+len(pod.Spec.Containers))
+	add := func(container v1.Container, tp entity.ExtraContainerType) {
+		item := &resp.RunningStatusContainerResp{
+			Name:  container.Name,
+			Type:  tp,
+			Image: container.Image,
+		}
+		res = append(res, item)
+
+		status, ok := statuses[container.Name]
+		if !ok {
+			return
+		}
+		item.Ready, item.RestartCount = status.Ready, int(status.RestartCount)
+		switch state := status.State; {
+		case state.Waiting != nil:
+			item.State, item.Reason, item.Message = "waiting", state.Waiting.Reason, state.Waiting.Message
+		case state.Running != nil:
+			item.State = "running"
+		case state.Terminated != nil:
+			item.State, item.Reason, item.Message = "terminated", state.Terminated.Reason, state.Terminated.Message
+			item.ExitCode = int(state.Terminated.ExitCode)
+		}
+	}
+
+	for _, container := range pod.Spec.InitContainers {
+		add(container, entity.ExtraContainerTypeInit)
+	}
+	for i, container := range pod.Spec.Containers {
+		tp := entity.ExtraContainerTypeSidecar
+		if i == 0 {
+			tp = entity.ExtraContainerTypeMain
+		}
+		add(container, tp)
+	}
+
+	return res
+}
+
 // GetPodDetail 获取 Pod 详情
 func (s *Service) GetPodDetail(ctx context.Context, clusterName entity.ClusterName,
 	getReq *req.GetPodDetailReq) (*v1.Pod, error) {
@@ -118,11 +181,28 @@ func (s *Service) GetPodLog(ctx context.Context, clusterName entity.ClusterName,
 		return "", errors.Wrap(_errcode.K8sInternalError, err.Error())
 	}
 
+	// 多容器 pod 未指定容器时, 默认获取应用主容器的日志
+	containerName := getReq.ContainerName
+	if containerName == "" {
+		pod, e := s.GetPodDetail(ctx, clusterName, &req.GetPodDetailReq{
+			Namespace: getReq.Namespace,
+			Name:      getReq.Name,
+			Env:       getReq.Env,
+		})
+		if e != nil {
+			return "", e
+		}
+		if len(pod.Spec.Containers) > 0 {
+			containerName = pod.Spec.Containers[0].Name
+		}
+	}
+
 	res, err := c.CoreV1().Pods(getReq.Namespace).
 		GetLogs(getReq.Name, &v1.PodLogOptions{
 			Timestamps: true,
 			TailLines:  &maxTailLines,
-			Container:  getReq.ContainerName,
+			Container:  containerName,
+			Previous:   getReq.Previous,
 		}).
 		DoRaw(ctx)
 	if err != nil {
@@ -449,7 +529,8 @@ func (s *Service) GetServiceNameFromVersion(version string) string {
 
 	index := strings.LastIndex(version, "-")
 	if index > 0 {
-		if version[index:] == entity.TaskCanaryVersionSuffix {
+		switch version[index:] {
+		case entity.TaskCanaryVersionSuffix, entity.TaskBlueVersionSuffix, entity.TaskGreenVersionSuffix:
 			return version[:index]
 		}
 
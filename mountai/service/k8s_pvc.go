@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gitlab.shanhai.int/sre/library/log"
+	"gitlab.shanhai.int/sre/library/net/errcode"
+	v1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"rulai/models/entity"
+	"rulai/models/req"
+	"rulai/models/resp"
+	"rulai/utils"
+	_errcode "rulai/utils/errcode"
+)
+
+const (
+	// PVCLabelAppVolume 标记由应用存储卷生成的 PVC, 清理时只删除带该标签的 PVC
+	PVCLabelAppVolume = "app-volume"
+)
+
+// getAppPVCName 应用持久化存储卷声明名, 同一应用的各版本共用
+func getAppPVCName(projectName, appName, volumeName string) string {
+	return fmt.Sprintf("%s-%s", utils.GetPodContainerName(projectName, appName), volumeName)
+}
+
+// getVolumeTemplates 存储卷及主容器, 附加容器的挂载渲染模板
+func getVolumeTemplates(project *resp.ProjectDetailResp, app *resp.AppDetailResp, task *resp.TaskDetailResp) (
+	[]*entity.VolumeTemplate, []*entity.VolumeMountTemplate, map[string][]*entity.VolumeMountTemplate) {
+	volumes := make([]*entity.VolumeTemplate, 0, len(task.Param.Volumes))
+	mounts := make([]*entity.VolumeMountTemplate, 0, len(task.Param.Volumes))
+	extraMounts := make(map[string][]*entity.VolumeMountTemplate)
+	for _, v := range task.Param.Volumes {
+		volume := &entity.VolumeTemplate{
+			Name:        v.Name,
+			Type:        v.Type,
+			SizeLimit:   v.SizeLimit,
+			Medium:      v.Medium,
+			SecretNames: v.SecretNames,
+		}
+		if v.Type == entity.AppVolumeTypePVC {
+			volume.ClaimName = getAppPVCName(project.Name, app.Name, v.Name)
+		}
+		volumes = append(volumes, volume)
+
+		mount := &entity.VolumeMountTemplate{
+			Name:      v.Name,
+			MountPath: v.MountPath,
+			ReadOnly:  v.ReadOnly,
+		}
+		mounts = append(mounts, mount)
+		for _, name := range v.Containers {
+			extraMounts[name] = append(extraMounts[name], mount)
+		}
+	}
+
+	return volumes, mounts, extraMounts
+}
+
+// setExtraContainerVolumeMounts 设置附加容器的存储卷挂载
+func setExtraContainerVolumeMounts(extraMounts map[string][]*entity.VolumeMountTemplate,
+	containers ...[]*entity.ExtraContainerTemplate) {
+	for _, list := range containers {
+		for _, c := range list {
+			c.VolumeMounts = extraMounts[c.Name]
+		}
+	}
+}
+
+// ApplyTaskPVCs 创建任务声明的持久化存储卷声明, 已存在时仅支持扩容
+func (s *Service) ApplyTaskPVCs(ctx context.Context, project *resp.ProjectDetailResp,
+	app *resp.AppDetailResp, task *resp.TaskDetailResp) error {
+	c, err := s.GetK8sTypedClient(task.ClusterName, string(task.EnvName))
+	if err != nil {
+		return errors.Wrap(_errcode.K8sInternalError, err.Error())
+	}
+
+	for _, v := range task.Param.Volumes {
+		if v.Type != entity.AppVolumeTypePVC {
+			continue
+		}
+
+		size, err := resource.ParseQuantity(v.Size)
+		if err != nil {
+			return errors.Wrap(errcode.InvalidParams, err.Error())
+		}
+
+		name := getAppPVCName(project.Name, app.Name, v.Name)
+		existed, err := s.GetPVCDetail(ctx, task.ClusterName, &req.GetPVCDetailReq{
+			Namespace: task.Namespace,
+			Name:      name,
+			Env:       string(task.EnvName),
+		})
+		if err != nil {
+			if !errcode.EqualError(_errcode.K8sResourceNotFoundError, err) {
+				return err
+			}
+
+			pvc := &v1.PersistentVolumeClaim{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: "v1",
+					Kind:       "PersistentVolumeClaim",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: task.Namespace,
+					Labels: map[string]string{
+						"project":         project.Name,
+						"app":             app.Name,
+						PVCLabelAppVolume: "true",
+					},
+				},
+				Spec: v1.PersistentVolumeClaimSpec{
+					AccessModes: []v1.PersistentVolumeAccessMode{v.AccessMode},
+					Resources: v1.ResourceRequirements{
+						Requests: v1.ResourceList{v1.ResourceStorage: size},
+					},
+				},
+			}
+			if v.StorageClassName != "" {
+				pvc.Spec.StorageClassName = &v.StorageClassName
+			}
+
+			_, err = c.CoreV1().PersistentVolumeClaims(task.Namespace).Create(ctx, pvc, metav1.CreateOptions{})
+			if err != nil {
+				return errors.Wrap(_errcode.K8sInternalError, err.Error())
+			}
+			continue
+		}
+
+		// 存储类及访问模式创建后不可变更, 仅在容量增大时扩容
+		current := existed.Spec.Resources.Requests[v1.ResourceStorage]
+		if size.Cmp(current) <= 0 {
+			continue
+		}
+
+		patchData, err := json.Marshal(map[string]interface{}{
+			"spec": map[string]interface{}{
+				"resources": map[string]interface{}{
+					"requests": map[string]string{string(v1.ResourceStorage): size.String()},
+				},
+			},
+		})
+		if err != nil {
+			return errors.Wrap(errcode.InternalError, err.Error())
+		}
+
+		_, err = c.CoreV1().PersistentVolumeClaims(task.Namespace).
+			Patch(ctx, name, types.MergePatchType, patchData, metav1.PatchOptions{})
+		if err != nil {
+			return errors.Wrap(_errcode.K8sInternalError, err.Error())
+		}
+	}
+
+	return nil
+}
+
+// GetPVCDetail 获取持久化存储卷声明详情
+func (s *Service) GetPVCDetail(ctx context.Context, clusterName entity.ClusterName,
+	getReq *req.GetPVCDetailReq) (*v1.PersistentVolumeClaim, error) {
+	c, err := s.GetK8sTypedClient(clusterName, getReq.Env)
+	if err != nil {
+		return nil, errors.Wrap(_errcode.K8sInternalError, err.Error())
+	}
+
+	res, err := c.CoreV1().PersistentVolumeClaims(getReq.Namespace).Get(ctx, getReq.Name, metav1.GetOptions{})
+	if err != nil {
+		if k8sErrors.IsNotFound(err) {
+			return nil, errors.Wrap(_errcode.K8sResourceNotFoundError, err.Error())
+		}
+		return nil, errors.Wrap(_errcode.K8sInternalError, err.Error())
+	}
+
+	return res, nil
+}
+
+// ListPVCs 获取应用的持久化存储卷声明列表
+func (s *Service) ListPVCs(ctx context.Context, clusterName entity.ClusterName,
+	listReq *req.ListPVCsReq) ([]v1.PersistentVolumeClaim, error) {
+	c, err := s.GetK8sTypedClient(clusterName, listReq.Env)
+	if err != nil {
+		return nil, errors.Wrap(_errcode.K8sInternalError, err.Error())
+	}
+
+	labels := []string{fmt.Sprintf("%s=true", PVCLabelAppVolume)}
+	if listReq.ProjectName != "" {
+		labels = append(labels, fmt.Sprintf("project=%s", listReq.ProjectName))
+	}
+	if listReq.AppName != "" {
+		labels = append(labels, fmt.Sprintf("app=%s", listReq.AppName))
+	}
+
+	list, err := c.CoreV1().PersistentVolumeClaims(listReq.Namespace).
+		List(ctx, metav1.ListOptions{LabelSelector: strings.Join(labels, ",")})
+	if err != nil {
+		return nil, errors.Wrap(_errcode.K8sInternalError, err.Error())
+	}
+
+	return list.Items, nil
+}
+
+// DeletePVCs 删除应用的持久化存储卷声明, 存储卷中的数据按存储类的回收策略处理
+func (s *Service) DeletePVCs(ctx context.Context, clusterName entity.ClusterName,
+	deleteReq *req.ListPVCsReq) error {
+	pvcs, err := s.ListPVCs(ctx, clusterName, deleteReq)
+	if err != nil {
+		return err
+	}
+
+	c, err := s.GetK8sTypedClient(clusterName, deleteReq.Env)
+	if err != nil {
+		return errors.Wrap(_errcode.K8sInternalError, err.Error())
+	}
+
+	for i := range pvcs {
+		log.Infoc(ctx, "delete pvc(%s/%s) of app(%s)", pvcs[i].GetNamespace(), pvcs[i].GetName(), deleteReq.AppName)
+		err = c.CoreV1().PersistentVolumeClaims(pvcs[i].GetNamespace()).
+			Delete(ctx, pvcs[i].GetName(), metav1.DeleteOptions{})
+		if err != nil && !k8sErrors.IsNotFound(err) {
+			return errors.Wrap(_errcode.K8sInternalError, err.Error())
+		}
+	}
+
+	return nil
+}
+
+// checkTaskPVCsCreated 校验任务声明的持久化存储卷声明是否均已创建
+// 存储类可能延迟到 pod 调度时才绑定, 因此只校验是否存在
+func (s *Service) checkTaskPVCsCreated(ctx context.Context, project *resp.ProjectDetailResp,
+	app *resp.AppDetailResp, task *resp.TaskDetailResp) (entity.TaskStatus, error) {
+	for _, v := range task.Param.Volumes {
+		if v.Type != entity.AppVolumeTypePVC {
+			continue
+		}
+
+		_, err := s.GetPVCDetail(ctx, task.ClusterName, &req.GetPVCDetailReq{
+			Namespace: task.Namespace,
+			Name:      getAppPVCName(project.Name, app.Name, v.Name),
+			Env:       string(task.EnvName),
+		})
+		if err != nil {
+			if errcode.EqualError(_errcode.K8sResourceNotFoundError, err) {
+				return "", nil
+			}
+			return "", err
+		}
+	}
+
+	return entity.TaskStatusCreatePVCFinish, nil
+}
+
+// hasTaskPVC 任务是否声明了持久化存储卷
+func hasTaskPVC(task *resp.TaskDetailResp) bool {
+	for _, v := range task.Param.Volumes {
+		if v.Type == entity.AppVolumeTypePVC {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gitlab.shanhai.int/sre/library/net/errcode"
+	v1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"rulai/models/entity"
+	"rulai/models/req"
+	"rulai/models/resp"
+	_errcode "rulai/utils/errcode"
+)
+
+const (
+	// SecretLabelSecretVars 标记由密钥变量生成的 Secret, 清理时只删除带该标签的 Secret
+	SecretLabelSecretVars = "secret-vars"
+)
+
+// getTaskSecretName 按版本创建的密钥变量 Secret 名
+func getTaskSecretName(version string) string {
+	return fmt.Sprintf("%s-secret-vars", version)
+}
+
+// getSecretEnvTemplates 密钥变量对应的环境变量模板
+func getSecretEnvTemplates(task *resp.TaskDetailResp) []*entity.SecretEnvTemplate {
+	res := make([]*entity.SecretEnvTemplate, len(task.Param.SecretVars))
+	for i, key := range task.Param.SecretVars {
+		res[i] = &entity.SecretEnvTemplate{
+			Name:       key,
+			SecretName: getTaskSecretName(task.Version),
+			Key:        key,
+		}
+	}
+	return res
+}
+
+// ApplyTaskSecret 解密任务引用的密钥变量并声明式创建/更新 Secret
+// 密钥不经过模板渲染, 避免明文出现在渲染日志中
+func (s *Service) ApplyTaskSecret(ctx context.Context, project *resp.ProjectDetailResp,
+	app *resp.AppDetailResp, task *resp.TaskDetailResp) (*v1.Secret, error) {
+	values, err := s.ReadSecretVariables(ctx, project.ID, task.Param.SecretVars, &entity.VariableAudit{
+		Action:     entity.VariableAuditActionDeploy,
+		OperatorID: task.OperatorID,
+		TaskID:     task.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	secret := &v1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Secret",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      getTaskSecretName(task.Version),
+			Namespace: task.Namespace,
+			Labels: map[string]string{
+				"project":             project.Name,
+				"app":                 app.Name,
+				"version":             task.Version,
+				SecretLabelSecretVars: "true",
+			},
+		},
+		Type:       v1.SecretTypeOpaque,
+		StringData: values,
+	}
+
+	c, err := s.GetK8sTypedClient(task.ClusterName, string(task.EnvName))
+	if err != nil {
+		return nil, errors.Wrap(_errcode.K8sInternalError, err.Error())
+	}
+
+	_, err = s.GetSecretDetail(ctx, task.ClusterName, &req.GetSecretDetailReq{
+		Namespace: secret.GetNamespace(),
+		Name:      secret.GetName(),
+		Env:       string(task.EnvName),
+	})
+	if err != nil {
+		if !errcode.EqualError(_errcode.K8sResourceNotFoundError, err) {
+			return nil, err
+		}
+
+		res, e := c.CoreV1().Secrets(secret.GetNamespace()).Create(ctx, secret, metav1.CreateOptions{})
+		if e != nil {
+			return nil, errors.Wrap(_errcode.K8sInternalError, e.Error())
+		}
+		return res, nil
+	}
+
+	// 使用 data 整体替换, 移除不再引用的密钥变量
+	patchData, err := json.Marshal(map[string]interface{}{
+		"metadata":   map[string]interface{}{"labels": secret.GetLabels()},
+		"data":       nil,
+		"stringData": values,
+	})
+	if err != nil {
+		return nil, errors.Wrap(errcode.InternalError, err.Error())
+	}
+
+	res, err := c.CoreV1().Secrets(secret.GetNamespace()).
+		Patch(ctx, secret.GetName(), types.MergePatchType, patchData, metav1.PatchOptions{})
+	if err != nil {
+		return nil, errors.Wrap(_errcode.K8sInternalError, err.Error())
+	}
+
+	return res, nil
+}
+
+// GetSecretDetail 获取 Secret 详情
+func (s *Service) GetSecretDetail(ctx context.Context, clusterName entity.ClusterName,
+	getReq *req.GetSecretDetailReq) (*v1.Secret, error) {
+	c, err := s.GetK8sTypedClient(clusterName, getReq.Env)
+	if err != nil {
+		return nil, errors.Wrap(_errcode.K8sInternalError, err.Error())
+	}
+
+	res, err := c.CoreV1().Secrets(getReq.Namespace).Get(ctx, getReq.Name, metav1.GetOptions{})
+	if err != nil {
+		if k8sErrors.IsNotFound(err) {
+			return nil, errors.Wrap(_errcode.K8sResourceNotFoundError, err.Error())
+		}
+		return nil, errors.Wrap(_errcode.K8sInternalError, err.Error())
+	}
+
+	return res, nil
+}
+
+// ListSecrets 获取应用的密钥变量 Secret 列表
+func (s *Service) ListSecrets(ctx context.Context, clusterName entity.ClusterName,
+	listReq *req.ListSecretsReq) ([]v1.Secret, error) {
+	c, err := s.GetK8sTypedClient(clusterName, listReq.Env)
+	if err != nil {
+		return nil, errors.Wrap(_errcode.K8sInternalError, err.Error())
+	}
+
+	labels := []string{fmt.Sprintf("%s=true", SecretLabelSecretVars)}
+	if listReq.ProjectName != "" {
+		labels = append(labels, fmt.Sprintf("project=%s", listReq.ProjectName))
+	}
+	if listReq.AppName != "" {
+		labels = append(labels, fmt.Sprintf("app=%s", listReq.AppName))
+	}
+
+	list, err := c.CoreV1().Secrets(listReq.Namespace).
+		List(ctx, metav1.ListOptions{LabelSelector: strings.Join(labels, ",")})
+	if err != nil {
+		return nil, errors.Wrap(_errcode.K8sInternalError, err.Error())
+	}
+
+	return list.Items, nil
+}
+
+// DeleteSecrets 删除应用的密钥变量 Secret
+func (s *Service) DeleteSecrets(ctx context.Context, clusterName entity.ClusterName,
+	deleteReq *req.ListSecretsReq) error {
+	secrets, err := s.ListSecrets(ctx, clusterName, deleteReq)
+	if err != nil {
+		return err
+	}
+
+	c, err := s.GetK8sTypedClient(clusterName, deleteReq.Env)
+	if err != nil {
+		return errors.Wrap(_errcode.K8sInternalError, err.Error())
+	}
+
+	for i := range secrets {
+		err = c.CoreV1().Secrets(secrets[i].GetNamespace()).
+			Delete(ctx, secrets[i].GetName(), metav1.DeleteOptions{})
+		if err != nil && !k8sErrors.IsNotFound(err) {
+			return errors.Wrap(_errcode.K8sInternalError, err.Error())
+		}
+	}
+
+	return nil
+}
+
+// checkTaskSecretCreated 校验任务的密钥变量 Secret 是否已创建
+func (s *Service) checkTaskSecretCreated(ctx context.Context, task *resp.TaskDetailResp) (entity.TaskStatus, error) {
+	_, err := s.GetSecretDetail(ctx, task.ClusterName, &req.GetSecretDetailReq{
+		Namespace: task.Namespace,
+		Name:      getTaskSecretName(task.Version),
+		Env:       string(task.EnvName),
+	})
+	if err != nil {
+		if errcode.EqualError(_errcode.K8sResourceNotFoundError, err) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	return entity.TaskStatusCreateSecretFinish, nil
+}
@@ -190,6 +190,7 @@ func (s *Service) ApplyService(ctx context.Context, clusterName entity.ClusterNa
 		if e != nil {
 			return nil, e
 		}
+		recordTaskObjectVersion(ctx, "Service", service)
 		return service, nil
 	}
 
@@ -224,9 +225,55 @@ func (s *Service) ApplyService(ctx context.Context, clusterName entity.ClusterNa
 	if err != nil {
 		return nil, err
 	}
+
+	// 蓝绿部署会在 selector 中限定版本, 合并更新无法移除, 常规部署需要恢复为选择应用的所有版本
+	if _, ok := oldService.Spec.Selector[entity.K8sLabelVersion]; ok {
+		if _, ok = applyService.Spec.Selector[entity.K8sLabelVersion]; !ok {
+			return s.PatchServiceSelectorVersion(ctx, clusterName, env,
+				applyService.GetNamespace(), applyService.GetName(), "")
+		}
+	}
+	recordTaskObjectVersion(ctx, "Service", service)
 	return service, nil
 }
 
+// PatchServiceSelectorVersion 更新服务 selector 中的版本, 版本为空时移除版本限制
+func (s *Service) PatchServiceSelectorVersion(ctx context.Context, clusterName entity.ClusterName,
+	envName, namespace, name, version string) (*v1.Service, error) {
+	var selectorVersion interface{}
+	if version != "" {
+		selectorVersion = version
+	}
+
+	patchData, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"selector": map[string]interface{}{
+				entity.K8sLabelVersion: selectorVersion,
+			},
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrap(errcode.InternalError, err.Error())
+	}
+
+	c, err := s.GetK8sTypedClient(clusterName, envName)
+	if err != nil {
+		return nil, errors.Wrap(_errcode.K8sInternalError, err.Error())
+	}
+
+	res, err := c.CoreV1().Services(namespace).
+		Patch(ctx, name, types.MergePatchType, patchData, metav1.PatchOptions{})
+	if err != nil {
+		if k8sErrors.IsNotFound(err) {
+			return nil, errors.Wrap(_errcode.K8sResourceNotFoundError, err.Error())
+		}
+		return nil, errors.Wrap(_errcode.K8sInternalError, err.Error())
+	}
+
+	recordTaskObjectVersion(ctx, "Service", res)
+	return res, nil
+}
+
 // CreateService 创建服务
 func (s *Service) CreateService(ctx context.Context, clusterName entity.ClusterName,
 	service *v1.Service, envName string) (*v1.Service, error) {
@@ -0,0 +1,257 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"gitlab.shanhai.int/sre/library/net/errcode"
+	"gopkg.in/yaml.v3"
+	v1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	"rulai/models/entity"
+	"rulai/models/req"
+	"rulai/models/resp"
+	_errcode "rulai/utils/errcode"
+)
+
+func (s *Service) initStatefulSetTemplate(ctx context.Context, project *resp.ProjectDetailResp,
+	app *resp.AppDetailResp, task *resp.TaskDetailResp, team *resp.TeamDetailResp) (*entity.StatefulSetTemplate, error) {
+	tpl, err := s.initDeploymentTemplate(ctx, project, app, task, team)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.StatefulSetTemplate{
+		DeploymentTemplate: *tpl,
+		// headless Service 由中间件自行维护, 与 StatefulSet 同名
+		ServiceName: task.Version,
+	}, nil
+}
+
+// RenderStatefulSetTemplate 渲染StatefulSet模板
+func (s *Service) RenderStatefulSetTemplate(ctx context.Context,
+	project *resp.ProjectDetailResp, app *resp.AppDetailResp, task *resp.TaskDetailResp, team *resp.TeamDetailResp) ([]byte, error) {
+	tpl, err := s.initStatefulSetTemplate(ctx, project, app, task, team)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := s.RenderK8sTemplate(ctx, entity.DefaultTemplateFileDir, task.ClusterName, task.EnvName, tpl)
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(data), nil
+}
+
+func (s *Service) decodeStatefulSetYamlData(_ context.Context, yamlData []byte) (*v1.StatefulSet, error) {
+	obj, _, err := scheme.Codecs.UniversalDeserializer().Decode(yamlData, nil, nil)
+	if err != nil {
+		return nil, errors.Wrap(_errcode.K8sInternalError, err.Error())
+	}
+
+	statefulSet, ok := obj.(*v1.StatefulSet)
+	if !ok {
+		return nil, errors.Wrapf(_errcode.K8sInternalError, "object is not v1.StatefulSet")
+	}
+	return statefulSet, nil
+}
+
+// ApplyStatefulSet 声明式创建/更新StatefulSet
+func (s *Service) ApplyStatefulSet(ctx context.Context,
+	clusterName entity.ClusterName, yamlData []byte, env string) (*v1.StatefulSet, error) {
+	applyStatefulSet, err := s.decodeStatefulSetYamlData(ctx, yamlData)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.GetStatefulSetDetail(ctx, clusterName,
+		&req.GetWorkloadDetailReq{
+			Namespace: applyStatefulSet.GetNamespace(),
+			Name:      applyStatefulSet.GetName(),
+			Env:       env,
+		})
+	if err != nil {
+		if !errcode.EqualError(_errcode.K8sResourceNotFoundError, err) {
+			return nil, err
+		}
+
+		statefulSet, e := s.CreateStatefulSet(ctx, clusterName, applyStatefulSet, env)
+		if e != nil {
+			return nil, e
+		}
+		recordTaskObjectVersion(ctx, entity.K8sObjectKindStatefulSet, statefulSet)
+		return statefulSet, nil
+	}
+
+	statefulSet, err := s.PatchStatefulSet(ctx, clusterName, applyStatefulSet, yamlData, env)
+	if err != nil {
+		return nil, err
+	}
+	recordTaskObjectVersion(ctx, entity.K8sObjectKindStatefulSet, statefulSet)
+	return statefulSet, nil
+}
+
+// CreateStatefulSet 创建StatefulSet
+func (s *Service) CreateStatefulSet(ctx context.Context, clusterName entity.ClusterName,
+	statefulSet *v1.StatefulSet, env string) (*v1.StatefulSet, error) {
+	c, err := s.GetK8sTypedClient(clusterName, env)
+	if err != nil {
+		return nil, errors.Wrap(_errcode.K8sInternalError, err.Error())
+	}
+
+	res, err := c.AppsV1().StatefulSets(statefulSet.GetNamespace()).
+		Create(ctx, statefulSet, metav1.CreateOptions{})
+	if err != nil {
+		return nil, errors.Wrap(_errcode.K8sInternalError, err.Error())
+	}
+
+	return res, nil
+}
+
+// GetStatefulSetDetail 获取StatefulSet详情
+func (s *Service) GetStatefulSetDetail(ctx context.Context, clusterName entity.ClusterName,
+	getReq *req.GetWorkloadDetailReq) (*v1.StatefulSet, error) {
+	c, err := s.GetK8sTypedClient(clusterName, getReq.Env)
+	if err != nil {
+		return nil, errors.Wrap(_errcode.K8sInternalError, err.Error())
+	}
+
+	if getReq.Namespace == "" {
+		getReq.Namespace = getReq.Env
+	}
+
+	res, err := c.AppsV1().StatefulSets(getReq.Namespace).
+		Get(ctx, getReq.Name, metav1.GetOptions{})
+	if err != nil {
+		if k8sErrors.IsNotFound(err) {
+			return nil, errors.Wrap(_errcode.K8sResourceNotFoundError, err.Error())
+		}
+		return nil, errors.Wrap(_errcode.K8sInternalError, err.Error())
+	}
+
+	return res, nil
+}
+
+// GetStatefulSets 获取StatefulSet列表
+func (s *Service) GetStatefulSets(ctx context.Context, clusterName entity.ClusterName,
+	getReq *req.GetWorkloadsReq) ([]v1.StatefulSet, error) {
+	c, err := s.GetK8sTypedClient(clusterName, getReq.Env)
+	if err != nil {
+		return nil, errors.Wrap(_errcode.K8sInternalError, err.Error())
+	}
+
+	list, err := c.AppsV1().StatefulSets(getReq.Namespace).
+		List(ctx, metav1.ListOptions{LabelSelector: s.getWorkloadsLabelSelector(getReq)})
+	if err != nil {
+		return nil, errors.Wrap(_errcode.K8sInternalError, err.Error())
+	}
+
+	return list.Items, nil
+}
+
+// PatchStatefulSet 更新StatefulSet
+func (s *Service) PatchStatefulSet(ctx context.Context, clusterName entity.ClusterName,
+	statefulSet *v1.StatefulSet, yamlData []byte, env string) (*v1.StatefulSet, error) {
+	var jsonObj map[string]interface{}
+	err := yaml.Unmarshal(yamlData, &jsonObj)
+	if err != nil {
+		return nil, errors.Wrap(errcode.InternalError, err.Error())
+	}
+
+	patchData, err := json.Marshal(jsonObj)
+	if err != nil {
+		return nil, errors.Wrap(errcode.InternalError, err.Error())
+	}
+
+	return s.patchStatefulSet(ctx, clusterName, statefulSet.GetNamespace(), statefulSet.GetName(), patchData, env)
+}
+
+func (s *Service) patchStatefulSet(ctx context.Context, clusterName entity.ClusterName,
+	namespace, name string, patchData []byte, env string) (*v1.StatefulSet, error) {
+	c, err := s.GetK8sTypedClient(clusterName, env)
+	if err != nil {
+		return nil, errors.Wrap(_errcode.K8sInternalError, err.Error())
+	}
+
+	res, err := c.AppsV1().StatefulSets(namespace).
+		Patch(ctx, name, types.MergePatchType, patchData, metav1.PatchOptions{})
+	if err != nil {
+		if k8sErrors.IsNotFound(err) {
+			return nil, errors.Wrap(_errcode.K8sResourceNotFoundError, err.Error())
+		}
+		return nil, errors.Wrap(_errcode.K8sInternalError, err.Error())
+	}
+
+	return res, nil
+}
+
+// RestartStatefulSet 重启StatefulSet, 按序号逆序滚动重建 pod
+func (s *Service) RestartStatefulSet(ctx context.Context, clusterName entity.ClusterName,
+	restartReq *req.GetWorkloadDetailReq) (*v1.StatefulSet, error) {
+	patchData := fmt.Sprintf(`{"spec":{"template":{"metadata":{"annotations":{"%s":"%s"}}}}}`,
+		K8sAnnotationRestart, time.Now().Format(RestartAnnotationLayout))
+
+	return s.patchStatefulSet(ctx, clusterName, restartReq.Namespace, restartReq.Name, []byte(patchData), restartReq.Env)
+}
+
+// UpdateStatefulSetScale 伸缩StatefulSet
+func (s *Service) UpdateStatefulSetScale(ctx context.Context, clusterName entity.ClusterName,
+	scaleReq *req.GetWorkloadDetailReq, scaleCount int) (*autoscalingv1.Scale, error) {
+	scale := &autoscalingv1.Scale{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      scaleReq.Name,
+			Namespace: scaleReq.Namespace,
+		},
+		Spec: autoscalingv1.ScaleSpec{
+			Replicas: int32(scaleCount),
+		},
+	}
+
+	c, err := s.GetK8sTypedClient(clusterName, scaleReq.Env)
+	if err != nil {
+		return nil, errors.Wrap(_errcode.K8sInternalError, err.Error())
+	}
+
+	res, err := c.AppsV1().StatefulSets(scaleReq.Namespace).
+		UpdateScale(ctx, scaleReq.Name, scale, metav1.UpdateOptions{})
+	if err != nil {
+		if k8sErrors.IsNotFound(err) {
+			return nil, errors.Wrap(_errcode.K8sResourceNotFoundError, err.Error())
+		}
+		return nil, errors.Wrap(_errcode.K8sInternalError, err.Error())
+	}
+
+	return res, nil
+}
+
+// DeleteStatefulSet 删除StatefulSet, 不会删除 pod 挂载的 PVC
+func (s *Service) DeleteStatefulSet(ctx context.Context, clusterName entity.ClusterName,
+	deleteReq *req.DeleteWorkloadReq) error {
+	c, err := s.GetK8sTypedClient(clusterName, deleteReq.Env)
+	if err != nil {
+		return errors.Wrap(_errcode.K8sInternalError, err.Error())
+	}
+
+	policy := metav1.DeletePropagationForeground
+	err = c.AppsV1().StatefulSets(deleteReq.Namespace).
+		Delete(ctx, deleteReq.Name, metav1.DeleteOptions{
+			PropagationPolicy: &policy,
+		})
+	if err != nil {
+		if k8sErrors.IsNotFound(err) {
+			return errors.Wrap(_errcode.K8sResourceNotFoundError, err.Error())
+		}
+		return errors.Wrap(_errcode.K8sInternalError, err.Error())
+	}
+
+	return nil
+}
@@ -11,6 +11,7 @@ import (
 	"rulai/config"
 	"rulai/models/entity"
 	"rulai/models/resp"
+	"rulai/utils"
 	_errcode "rulai/utils/errcode"
 )
 
@@ -169,3 +170,35 @@ func (s *Service) GetNamespaceBase(enableIstio bool, envName entity.AppEnvName)
 	}
 	return string(envName)
 }
+
+// getExtraContainerTemplates 附加容器渲染模板, 环境变量为通用系统环境变量及容器自身变量
+func (s *Service) getExtraContainerTemplates(project *resp.ProjectDetailResp, app *resp.AppDetailResp,
+	task *resp.TaskDetailResp, team *resp.TeamDetailResp, containers []*entity.ExtraContainer) ([]*entity.ExtraContainerTemplate, error) {
+	res := make([]*entity.ExtraContainerTemplate, 0, len(containers))
+	for _, c := range containers {
+		envVars, err := s.getSystemEnv(project, app, task, team)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, key := range utils.SortedMapKeys(c.Vars) {
+			envVars = append(envVars, &entity.EnvTemplate{
+				Name:  key,
+				Value: c.Vars[key],
+			})
+		}
+
+		res = append(res, &entity.ExtraContainerTemplate{
+			Name:          c.Name,
+			ImageName:     entity.ImageName(c.Image),
+			Command:       c.Command,
+			Env:           envVars,
+			CPULimit:      c.CPULimit,
+			MemoryLimit:   c.MemLimit,
+			CPURequest:    c.CPURequest,
+			MemoryRequest: c.MemRequest,
+		})
+	}
+
+	return res, nil
+}
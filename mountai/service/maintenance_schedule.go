@@ -0,0 +1,448 @@
+package service
+
+import (
+	"rulai/dao"
+	"rulai/models/entity"
+	"rulai/models/req"
+	"rulai/models/resp"
+	"rulai/utils"
+	_errcode "rulai/utils/errcode"
+
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"gitlab.shanhai.int/sre/library/base/deepcopy.v2"
+	"gitlab.shanhai.int/sre/library/base/null"
+	"gitlab.shanhai.int/sre/library/log"
+	"gitlab.shanhai.int/sre/library/net/errcode"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func (s *Service) GetMaintenanceSchedules(ctx context.Context,
+	getReq *req.GetMaintenanceSchedulesReq) ([]*resp.MaintenanceScheduleDetail, int, error) {
+	filter := bson.M{}
+	if getReq.ProjectID != "" {
+		filter["project_id"] = getReq.ProjectID
+	}
+	if getReq.AppID != "" {
+		filter["app_id"] = getReq.AppID
+	}
+	if getReq.EnvName != "" {
+		filter["env_name"] = getReq.EnvName
+	}
+
+	limit := int64(getReq.Limit)
+	skip := int64(getReq.Page-1) * limit
+
+	schedules, err := s.dao.FindMaintenanceSchedules(ctx, filter, &options.FindOptions{
+		Limit: &limit,
+		Skip:  &skip,
+		Sort:  dao.MongoSortByIDAsc,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	count, err := s.dao.CountMaintenanceSchedules(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	res := make([]*resp.MaintenanceScheduleDetail, 0)
+	err = deepcopy.Copy(&schedules).To(&res)
+	if err != nil {
+		return nil, 0, errors.Wrap(errcode.InternalError, err.Error())
+	}
+
+	return res, count, nil
+}
+
+func (s *Service) FindSingleMaintenanceScheduleByID(ctx context.Context, id string) (*entity.MaintenanceSchedule, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.Wrap(_errcode.InvalidHexStringError, err.Error())
+	}
+
+	return s.dao.FindSingleMaintenanceSchedule(ctx, bson.M{"_id": objectID})
+}
+
+func (s *Service) GetMaintenanceScheduleDetail(ctx context.Context, id string) (*resp.MaintenanceScheduleDetail, error) {
+	schedule, err := s.FindSingleMaintenanceScheduleByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	res := new(resp.MaintenanceScheduleDetail)
+	err = deepcopy.Copy(schedule).To(res)
+	if err != nil {
+		return nil, errors.Wrap(errcode.InternalError, err.Error())
+	}
+
+	return res, nil
+}
+
+func (s *Service) CreateSingleMaintenanceSchedule(ctx context.Context,
+	createReq *req.CreateMaintenanceScheduleReq) (*resp.MaintenanceScheduleDetail, error) {
+	now := time.Now()
+
+	schedule := &entity.MaintenanceSchedule{
+		ID:          primitive.NewObjectID(),
+		Name:        createReq.Name,
+		ProjectID:   createReq.ProjectID,
+		AppID:       createReq.AppID,
+		EnvName:     createReq.EnvName,
+		ClusterName: createReq.ClusterName,
+		Action:      createReq.Action,
+		Cron:        createReq.Cron,
+		MinPodCount: createReq.MinPodCount,
+		MaxPodCount: createReq.MaxPodCount,
+		OwnerID:     createReq.OwnerID,
+		CreateTime:  &now,
+		UpdateTime:  &now,
+	}
+	if createReq.EndTime > 0 {
+		endTime := time.Unix(createReq.EndTime, 0)
+		schedule.EndTime = &endTime
+	}
+
+	nextRunTime, err := schedule.GetNextRunTime(now)
+	if err != nil {
+		return nil, errors.Wrap(errcode.InvalidParams, err.Error())
+	}
+	schedule.NextRunTime = nextRunTime
+
+	err = s.dao.CreateSingleMaintenanceSchedule(ctx, schedule)
+	if err != nil {
+		return nil, err
+	}
+
+	res := new(resp.MaintenanceScheduleDetail)
+	err = deepcopy.Copy(schedule).To(res)
+	if err != nil {
+		return nil, errors.Wrap(errcode.InternalError, err.Error())
+	}
+
+	return res, nil
+}
+
+// UpdateSingleMaintenanceSchedule 更新定时运维计划, 并重新计算下次执行时间
+func (s *Service) UpdateSingleMaintenanceSchedule(ctx context.Context, schedule *entity.MaintenanceSchedule,
+	updateReq *req.UpdateMaintenanceScheduleReq) error {
+	now := time.Now()
+	changeMap := make(map[string]interface{})
+	changeMap["update_time"] = now
+
+	if updateReq.Name != "" {
+		changeMap["name"] = updateReq.Name
+	}
+
+	if updateReq.Cron != "" {
+		schedule.Cron = updateReq.Cron
+		changeMap["cron"] = updateReq.Cron
+	}
+
+	if updateReq.MinPodCount > 0 {
+		changeMap["min_pod_count"] = updateReq.MinPodCount
+	}
+
+	if updateReq.MaxPodCount > 0 {
+		changeMap["max_pod_count"] = updateReq.MaxPodCount
+	}
+
+	if !updateReq.EndTime.IsZero() {
+		schedule.EndTime = nil
+		if updateReq.EndTime.ValueOrZero() > 0 {
+			endTime := time.Unix(updateReq.EndTime.ValueOrZero(), 0)
+			schedule.EndTime = &endTime
+		}
+		changeMap["end_time"] = schedule.EndTime
+	}
+
+	if !updateReq.Disabled.IsZero() {
+		changeMap["disabled"] = updateReq.Disabled.ValueOrZero()
+	}
+
+	nextRunTime, err := schedule.GetNextRunTime(now)
+	if err != nil {
+		return errors.Wrap(errcode.InvalidParams, err.Error())
+	}
+	changeMap["next_run_time"] = nextRunTime
+
+	return s.dao.UpdateSingleMaintenanceSchedule(ctx, schedule.ID.Hex(), bson.M{
+		"$set": changeMap,
+	})
+}
+
+func (s *Service) DeleteSingleMaintenanceScheduleByID(ctx context.Context, id string) error {
+	return s.dao.DeleteSingleMaintenanceScheduleByID(ctx, id)
+}
+
+func (s *Service) GetMaintenanceScheduleExecutions(ctx context.Context, scheduleID string,
+	getReq *req.GetMaintenanceScheduleExecutionsReq) ([]*resp.MaintenanceScheduleExecutionDetail, int, error) {
+	filter := bson.M{
+		"schedule_id": scheduleID,
+	}
+	if getReq.Status != "" {
+		filter["status"] = getReq.Status
+	}
+
+	limit := int64(getReq.Limit)
+	skip := int64(getReq.Page-1) * limit
+
+	executions, err := s.dao.FindMaintenanceScheduleExecutions(ctx, filter, &options.FindOptions{
+		Limit: &limit,
+		Skip:  &skip,
+		Sort:  dao.MongoSortByCreateTimeDesc,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	count, err := s.dao.CountMaintenanceScheduleExecutions(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	res := make([]*resp.MaintenanceScheduleExecutionDetail, 0)
+	err = deepcopy.Copy(&executions).To(&res)
+	if err != nil {
+		return nil, 0, errors.Wrap(errcode.InternalError, err.Error())
+	}
+
+	return res, count, nil
+}
+
+// ValidateMaintenanceSchedulePermission 校验负责人是否有权限执行定时运维计划
+// 与手动创建任务一致: 校验 git 权限, P0 项目 prd 环境免审批任务仅允许项目负责人创建
+func (s *Service) ValidateMaintenanceSchedulePermission(ctx context.Context, project *resp.ProjectDetailResp,
+	app *resp.AppDetailResp, envName entity.AppEnvName, action entity.TaskAction, ownerID string) error {
+	err := s.ValidateHasPermission(ctx, &req.ValidateHasPermissionReq{
+		OperateType:       entity.OperateTypeCreateTask,
+		CreateTaskEnvName: envName,
+		CreateTaskAction:  action,
+		ProjectID:         project.ID,
+		OperatorID:        ownerID,
+	})
+	if err != nil {
+		return err
+	}
+
+	// 可插拔工作负载仅支持其注册的行为
+	if wt, ok := entity.GetWorkloadType(app.Type); ok && !wt.SupportsAction(action) {
+		return errors.Wrapf(errcode.InvalidParams, "action %s is not supported by %s", action, app.Type)
+	}
+
+	if action == entity.TaskActionUpdateHPA && app.Type != entity.AppTypeService && app.Type != entity.AppTypeWorker {
+		return errors.Wrap(errcode.InvalidParams, "action is not supported")
+	}
+
+	if action == entity.TaskActionStop && app.Type == entity.AppTypeOneTimeJob {
+		return errors.Wrap(errcode.InvalidParams, "action is unsupported for one time job")
+	}
+
+	if !s.IsPrdP0LevelApp(envName, project) || ownerID == entity.K8sSystemUserID {
+		return nil
+	}
+
+	for _, owner := range project.Owners {
+		if owner.ID == ownerID {
+			return nil
+		}
+	}
+
+	return errors.Wrap(_errcode.CreateTaskNoPermissionError, "no permission to create urgently deploy task")
+}
+
+// RunDueMaintenanceSchedules 执行所有到期的定时运维计划
+func (s *Service) RunDueMaintenanceSchedules(ctx context.Context) error {
+	schedules, err := s.dao.FindMaintenanceSchedules(ctx, bson.M{
+		"disabled":      false,
+		"next_run_time": bson.M{"$lte": time.Now()},
+	}, dao.MongoFindOptionWithSortByIDAsc)
+	if err != nil {
+		return err
+	}
+
+	for _, schedule := range schedules {
+		err = s.runMaintenanceScheduleWithLock(ctx, schedule.ID)
+		if err != nil {
+			log.Errorc(ctx, "run maintenance schedule(%s) err: %+v", schedule.ID.Hex(), err)
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) runMaintenanceScheduleWithLock(ctx context.Context, id primitive.ObjectID) (err error) {
+	mutex := s.dao.GetMaintenanceScheduleWorkerLock(ctx, id.Hex())
+	err = mutex.Lock(ctx)
+	if err != nil {
+		return errors.Wrapf(errcode.RedLockLockError, "%s", err)
+	}
+
+	defer func() {
+		result := mutex.Unlock(ctx)
+		if !result {
+			err = errors.WithStack(errcode.RedLockUnLockError)
+		}
+	}()
+
+	// 加锁后重新获取, 避免同一次计划被重复执行
+	schedule, err := s.dao.FindSingleMaintenanceSchedule(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if schedule.Disabled || schedule.NextRunTime == nil || schedule.NextRunTime.After(now) {
+		return nil
+	}
+
+	execution := &entity.MaintenanceScheduleExecution{
+		ID:            primitive.NewObjectID(),
+		ScheduleID:    schedule.ID.Hex(),
+		AppID:         schedule.AppID,
+		ScheduledTime: schedule.NextRunTime,
+		CreateTime:    &now,
+	}
+
+	// 服务停止等原因错过执行时间过久时不再补执行, 避免在非预期时间变更
+	if now.Sub(*schedule.NextRunTime) > entity.MaintenanceScheduleMisfireTolerance {
+		execution.Status = entity.MaintenanceScheduleExecutionStatusSkipped
+		execution.Message = fmt.Sprintf("missed the scheduled time by more than %s",
+			entity.MaintenanceScheduleMisfireTolerance)
+	} else {
+		e := s.runMaintenanceSchedule(ctx, schedule, execution)
+		if e != nil {
+			execution.Status = entity.MaintenanceScheduleExecutionStatusFail
+			execution.Message = e.Error()
+		}
+	}
+
+	err = s.dao.CreateSingleMaintenanceScheduleExecution(ctx, execution)
+	if err != nil {
+		return err
+	}
+
+	nextRunTime, err := schedule.GetNextRunTime(now)
+	if err != nil {
+		return err
+	}
+
+	return s.dao.UpdateSingleMaintenanceSchedule(ctx, schedule.ID.Hex(), bson.M{
+		"$set": bson.M{
+			"next_run_time": nextRunTime,
+			"last_run_time": schedule.NextRunTime,
+			"update_time":   now,
+		},
+	})
+}
+
+// runMaintenanceSchedule 以负责人身份创建定时运维任务, 校验不通过时返回错误, 需要等待时标记为跳过
+func (s *Service) runMaintenanceSchedule(ctx context.Context, schedule *entity.MaintenanceSchedule,
+	execution *entity.MaintenanceScheduleExecution) error {
+	app, err := s.GetAppDetail(ctx, schedule.AppID)
+	if err != nil {
+		return err
+	}
+
+	project, err := s.GetProjectDetail(ctx, app.ProjectID)
+	if err != nil {
+		return err
+	}
+
+	// 负责人权限可能在计划创建后变更, 每次执行时重新校验
+	err = s.ValidateMaintenanceSchedulePermission(ctx, project, app, schedule.EnvName, schedule.Action, schedule.OwnerID)
+	if err != nil {
+		return err
+	}
+
+	// 所有集群同时只能有一个任务在执行
+	unfinishedCount, err := s.GetTasksCount(ctx, &req.GetTasksReq{
+		AppID:             app.ID,
+		EnvName:           schedule.EnvName,
+		StatusInverseList: entity.TaskStatusFinalStateList,
+		Suspend:           null.BoolFrom(false),
+	})
+	if err != nil {
+		return err
+	}
+	if unfinishedCount > 0 {
+		execution.Status = entity.MaintenanceScheduleExecutionStatusSkipped
+		execution.Message = "other unfinished tasks of the app exist"
+		return nil
+	}
+
+	window, err := s.GetMatchedFreezeWindow(ctx, project, schedule.EnvName, time.Now())
+	if err != nil {
+		return err
+	}
+	if window != nil {
+		execution.Status = entity.MaintenanceScheduleExecutionStatusSkipped
+		execution.Message = fmt.Sprintf("frozen by %s until %s", window.Name,
+			window.EndTime.Format(utils.DefaultTimeFormatLayout))
+		return nil
+	}
+
+	latestTask, err := s.GetLatestDeploySuccessTaskFinalVersion(ctx, &req.GetLatestTaskReq{
+		AppID:       app.ID,
+		EnvName:     schedule.EnvName,
+		ClusterName: schedule.ClusterName,
+	})
+	if errcode.EqualError(_errcode.NoRequiredTaskError, err) {
+		return errors.New("no successful deployment to inherit params from")
+	}
+	if err != nil {
+		return err
+	}
+
+	// 以上次成功任务作为基本参数, 作用于当前运行的版本
+	createReq := new(req.CreateTaskReq)
+	err = deepcopy.Copy(latestTask).To(createReq)
+	if err != nil {
+		return errors.Wrap(errcode.InternalError, err.Error())
+	}
+
+	createReq.Action = schedule.Action
+	createReq.OperatorID = schedule.OwnerID
+	createReq.Description = fmt.Sprintf("定时运维计划(%s): %s", schedule.ID.Hex(), schedule.Name)
+	createReq.RelatedTaskID = ""
+	createReq.FreezeOverrideReason, createReq.ErrorBudgetOverrideReason = "", ""
+	createReq.DeployType, createReq.ScheduleTime = entity.ImmediateTaskDeployType, 0
+	createReq.Approval = &req.ApprovalReq{Type: entity.SkipTaskApprovalType}
+	createReq.Namespace = s.GetNamespaceBase(s.GetApplicationIstioState(ctx, schedule.EnvName,
+		schedule.ClusterName, app), schedule.EnvName)
+
+	switch schedule.Action {
+	case entity.TaskActionStop:
+		if app.Type != entity.AppTypeCronJob {
+			createReq.Param.MinPodCount = 0
+		}
+	case entity.TaskActionUpdateHPA:
+		createReq.Param.IsAutoScale = true
+		createReq.Param.MinPodCount = schedule.MinPodCount
+		createReq.Param.MaxPodCount = schedule.MaxPodCount
+	}
+
+	exist, err := s.IsAppK8sPrimaryResourceExist(ctx, createReq.ClusterName,
+		createReq.EnvName, app.Type, createReq.Version, createReq.Namespace)
+	if err != nil {
+		return err
+	}
+	if !exist {
+		return errors.Wrapf(_errcode.TaskPrimaryResourceNotExistsError, "can't %s task", schedule.Action)
+	}
+
+	task, err := s.CreateTask(ctx, project, app, createReq, schedule.OwnerID)
+	if err != nil {
+		return err
+	}
+
+	execution.TaskID = task.ID
+	execution.Status = entity.MaintenanceScheduleExecutionStatusSuccess
+
+	return nil
+}
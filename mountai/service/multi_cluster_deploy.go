@@ -0,0 +1,430 @@
+package service
+
+import (
+	"rulai/dao"
+	"rulai/models/entity"
+	"rulai/models/req"
+	"rulai/models/resp"
+	_errcode "rulai/utils/errcode"
+
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"gitlab.shanhai.int/sre/library/base/deepcopy.v2"
+	"gitlab.shanhai.int/sre/library/base/null"
+	"gitlab.shanhai.int/sre/library/log"
+	"gitlab.shanhai.int/sre/library/net/errcode"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetMultiClusterDeployWaves 校验并补全部署批次
+// 未指定批次时默认集群先行, 其余集群随后; 指定批次时未列出的支持集群追加为最后一个批次
+func (s *Service) GetMultiClusterDeployWaves(ctx context.Context, projectID string,
+	envName entity.AppEnvName, waves [][]entity.ClusterName) ([][]entity.ClusterName, error) {
+	clusters, err := s.GetProjectSupportedClusters(ctx, projectID, envName)
+	if err != nil {
+		return nil, err
+	}
+
+	supported := make(map[entity.ClusterName]bool)
+	supportedNames := make([]entity.ClusterName, 0, len(clusters))
+	for _, cluster := range clusters {
+		if cluster.Env != envName {
+			continue
+		}
+		supported[cluster.Name] = true
+		supportedNames = append(supportedNames, cluster.Name)
+	}
+
+	if len(supportedNames) == 0 {
+		return nil, errors.Wrapf(errcode.InvalidParams, "no supported cluster in env(%s)", envName)
+	}
+
+	if len(waves) == 0 && supported[entity.DefaultClusterName] {
+		waves = [][]entity.ClusterName{{entity.DefaultClusterName}}
+	}
+
+	listed := make(map[entity.ClusterName]bool)
+	res := make([][]entity.ClusterName, 0, len(waves)+1)
+	for i, wave := range waves {
+		if len(wave) == 0 {
+			return nil, errors.Wrapf(errcode.InvalidParams, "wave %d is empty", i)
+		}
+
+		for _, clusterName := range wave {
+			if !supported[clusterName] {
+				return nil, errors.Wrapf(errcode.InvalidParams, "cluster(%s) is not supported in env(%s)", clusterName, envName)
+			}
+			if listed[clusterName] {
+				return nil, errors.Wrapf(errcode.InvalidParams, "cluster(%s) is listed in more than one wave", clusterName)
+			}
+			listed[clusterName] = true
+		}
+
+		res = append(res, wave)
+	}
+
+	rest := make([]entity.ClusterName, 0)
+	for _, clusterName := range supportedNames {
+		if !listed[clusterName] {
+			rest = append(rest, clusterName)
+		}
+	}
+	if len(rest) > 0 {
+		res = append(res, rest)
+	}
+
+	return res, nil
+}
+
+// CreateMultiClusterDeploy 创建多集群部署, 一次性创建各集群任务, 首个批次之后的任务暂停至前序批次成功
+func (s *Service) CreateMultiClusterDeploy(ctx context.Context, project *resp.ProjectDetailResp, app *resp.AppDetailResp,
+	createReq *req.CreateMultiClusterDeployReq, waveReqs [][]*req.CreateTaskReq, operatorID string) (
+	*resp.MultiClusterDeployDetail, error) {
+	now := time.Now()
+	deploy := &entity.MultiClusterDeploy{
+		ID:          primitive.NewObjectID(),
+		ProjectID:   project.ID,
+		AppID:       app.ID,
+		EnvName:     createReq.EnvName,
+		Action:      createReq.Action,
+		Description: createReq.Description,
+		Status:      entity.MultiClusterDeployStatusRunning,
+		Waves:       make([]*entity.MultiClusterDeployWave, 0, len(waveReqs)),
+		OperatorID:  operatorID,
+		CreateTime:  &now,
+		UpdateTime:  &now,
+	}
+
+	for i, taskReqs := range waveReqs {
+		wave := &entity.MultiClusterDeployWave{
+			Tasks:  make([]*entity.MultiClusterDeployTask, 0, len(taskReqs)),
+			Status: entity.MultiClusterDeployWaveStatusPending,
+		}
+		if i == 0 {
+			wave.Status = entity.MultiClusterDeployWaveStatusRunning
+			wave.StartTime = &now
+		}
+
+		deploy.Waves = append(deploy.Waves, wave)
+	}
+
+	// 创建任务期间持有锁, 避免worker推进尚未创建完任务的批次
+	mutex := s.dao.GetMultiClusterDeployWorkerLock(ctx, deploy.ID.Hex())
+	err := mutex.Lock(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(errcode.RedLockLockError, "%s", err)
+	}
+
+	defer func() {
+		if !mutex.Unlock(ctx) {
+			log.Errorc(ctx, "unlock multi cluster deploy(%s) err", deploy.ID.Hex())
+		}
+	}()
+
+	// 先落库再创建任务, 保证已创建的任务都能通过部署记录追踪
+	err = s.dao.CreateSingleMultiClusterDeploy(ctx, deploy)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, taskReqs := range waveReqs {
+		wave := deploy.Waves[i]
+
+		for _, taskReq := range taskReqs {
+			taskReq.Suspend = i > 0
+
+			task, err := s.CreateTask(ctx, project, app, taskReq, operatorID)
+			if err != nil {
+				// 所有批次中已创建的任务均不再执行
+				s.haltMultiClusterDeployWaves(ctx, project, app, deploy, 0)
+				deploy.Status = entity.MultiClusterDeployStatusFail
+				deploy.Message = err.Error()
+				if saveErr := s.saveMultiClusterDeploy(ctx, deploy); saveErr != nil {
+					log.Errorc(ctx, "save multi cluster deploy(%s) err: %+v", deploy.ID.Hex(), saveErr)
+				}
+				return nil, err
+			}
+
+			wave.Tasks = append(wave.Tasks, &entity.MultiClusterDeployTask{
+				ClusterName: taskReq.ClusterName,
+				TaskID:      task.ID,
+			})
+		}
+	}
+
+	err = s.saveMultiClusterDeploy(ctx, deploy)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getMultiClusterDeployDetail(ctx, deploy)
+}
+
+func (s *Service) GetMultiClusterDeploys(ctx context.Context, getReq *req.GetMultiClusterDeploysReq) (
+	[]*resp.MultiClusterDeployDetail, int, error) {
+	filter := bson.M{}
+	if getReq.AppID != "" {
+		filter["app_id"] = getReq.AppID
+	}
+	if getReq.EnvName != "" {
+		filter["env_name"] = getReq.EnvName
+	}
+	if getReq.Status != "" {
+		filter["status"] = getReq.Status
+	}
+
+	limit := int64(getReq.Limit)
+	skip := int64(getReq.Page-1) * limit
+
+	deploys, err := s.dao.FindMultiClusterDeploys(ctx, filter, &options.FindOptions{
+		Limit: &limit,
+		Skip:  &skip,
+		Sort:  bson.M{"_id": -1},
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	count, err := s.dao.CountMultiClusterDeploys(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	res := make([]*resp.MultiClusterDeployDetail, 0)
+	err = deepcopy.Copy(&deploys).To(&res)
+	if err != nil {
+		return nil, 0, errors.Wrap(errcode.InternalError, err.Error())
+	}
+
+	return res, count, nil
+}
+
+func (s *Service) FindSingleMultiClusterDeployByID(ctx context.Context, id string) (*entity.MultiClusterDeploy, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.Wrap(_errcode.InvalidHexStringError, err.Error())
+	}
+
+	return s.dao.FindSingleMultiClusterDeploy(ctx, bson.M{"_id": objectID})
+}
+
+// GetMultiClusterDeployDetail 获取多集群部署详情, 包含各集群任务的当前状态
+func (s *Service) GetMultiClusterDeployDetail(ctx context.Context, id string) (*resp.MultiClusterDeployDetail, error) {
+	deploy, err := s.FindSingleMultiClusterDeployByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getMultiClusterDeployDetail(ctx, deploy)
+}
+
+func (s *Service) getMultiClusterDeployDetail(ctx context.Context,
+	deploy *entity.MultiClusterDeploy) (*resp.MultiClusterDeployDetail, error) {
+	res := new(resp.MultiClusterDeployDetail)
+	err := deepcopy.Copy(deploy).To(res)
+	if err != nil {
+		return nil, errors.Wrap(errcode.InternalError, err.Error())
+	}
+
+	for _, wave := range res.Waves {
+		for _, task := range wave.Tasks {
+			detail, e := s.GetTaskDetail(ctx, task.TaskID)
+			if errcode.EqualError(errcode.NoRowsFoundError, e) {
+				continue
+			}
+			if e != nil {
+				return nil, e
+			}
+
+			task.Status = detail.Status
+			task.StatusDisplay = detail.StatusDisplay
+		}
+	}
+
+	return res, nil
+}
+
+// AdvanceMultiClusterDeploys 推进所有执行中的多集群部署
+func (s *Service) AdvanceMultiClusterDeploys(ctx context.Context) error {
+	deploys, err := s.dao.FindMultiClusterDeploys(ctx, bson.M{
+		"status": entity.MultiClusterDeployStatusRunning,
+	}, dao.MongoFindOptionWithSortByIDAsc)
+	if err != nil {
+		return err
+	}
+
+	for _, deploy := range deploys {
+		err = s.advanceMultiClusterDeployWithLock(ctx, deploy.ID)
+		if err != nil {
+			log.Errorc(ctx, "advance multi cluster deploy(%s) err: %+v", deploy.ID.Hex(), err)
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) advanceMultiClusterDeployWithLock(ctx context.Context, id primitive.ObjectID) (err error) {
+	mutex := s.dao.GetMultiClusterDeployWorkerLock(ctx, id.Hex())
+	err = mutex.Lock(ctx)
+	if err != nil {
+		return errors.Wrapf(errcode.RedLockLockError, "%s", err)
+	}
+
+	defer func() {
+		result := mutex.Unlock(ctx)
+		if !result {
+			err = errors.WithStack(errcode.RedLockUnLockError)
+		}
+	}()
+
+	deploy, err := s.dao.FindSingleMultiClusterDeploy(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if deploy.Status != entity.MultiClusterDeployStatusRunning {
+		return nil
+	}
+
+	app, err := s.GetAppDetail(ctx, deploy.AppID)
+	if err != nil {
+		return err
+	}
+
+	project, err := s.GetProjectDetail(ctx, deploy.ProjectID)
+	if err != nil {
+		return err
+	}
+
+	err = s.advanceMultiClusterDeploy(ctx, project, app, deploy)
+	if err != nil {
+		return err
+	}
+
+	return s.saveMultiClusterDeploy(ctx, deploy)
+}
+
+// saveMultiClusterDeploy 保存多集群部署的状态及批次
+func (s *Service) saveMultiClusterDeploy(ctx context.Context, deploy *entity.MultiClusterDeploy) error {
+	now := time.Now()
+	deploy.UpdateTime = &now
+	return s.dao.UpdateSingleMultiClusterDeploy(ctx, deploy.ID, bson.M{
+		"$set": bson.M{
+			"status":       deploy.Status,
+			"current_wave": deploy.CurrentWave,
+			"waves":        deploy.Waves,
+			"message":      deploy.Message,
+			"update_time":  deploy.UpdateTime,
+		},
+	})
+}
+
+// advanceMultiClusterDeploy 推进当前批次: 恢复暂停的任务 -> 等待全部成功 -> 进入下一批次, 任一任务失败则中止
+func (s *Service) advanceMultiClusterDeploy(ctx context.Context, project *resp.ProjectDetailResp,
+	app *resp.AppDetailResp, deploy *entity.MultiClusterDeploy) error {
+	wave := deploy.GetCurrentWave()
+	if wave == nil {
+		deploy.Status = entity.MultiClusterDeployStatusSuccess
+		return nil
+	}
+
+	now := time.Now()
+	switch wave.Status {
+	case entity.MultiClusterDeployWaveStatusPending:
+		for _, waveTask := range wave.Tasks {
+			task, err := s.GetTaskDetail(ctx, waveTask.TaskID)
+			if err != nil {
+				return err
+			}
+
+			err = s.UpdateTask(ctx, project, app, task, &req.UpdateTaskReq{
+				Suspend:    null.BoolFrom(false),
+				OperatorID: entity.K8sSystemUserID,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		wave.Status = entity.MultiClusterDeployWaveStatusRunning
+		wave.StartTime = &now
+	case entity.MultiClusterDeployWaveStatusRunning:
+		finished := true
+		for _, waveTask := range wave.Tasks {
+			task, err := s.GetTaskDetail(ctx, waveTask.TaskID)
+			if errcode.EqualError(errcode.NoRowsFoundError, err) {
+				deploy.Message = fmt.Sprintf("task(%s) of cluster(%s) is deleted", waveTask.TaskID, waveTask.ClusterName)
+				s.failMultiClusterDeploy(ctx, project, app, deploy)
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+
+			switch task.Status {
+			case entity.TaskStatusSuccess:
+			case entity.TaskStatusFail:
+				deploy.Message = fmt.Sprintf("task(%s) of cluster(%s) failed", task.ID, waveTask.ClusterName)
+				s.failMultiClusterDeploy(ctx, project, app, deploy)
+				return nil
+			default:
+				finished = false
+			}
+		}
+
+		if !finished {
+			return nil
+		}
+
+		wave.Status = entity.MultiClusterDeployWaveStatusSuccess
+		wave.FinishTime = &now
+		deploy.CurrentWave++
+		if deploy.GetCurrentWave() == nil {
+			deploy.Status = entity.MultiClusterDeployStatusSuccess
+		}
+	}
+
+	return nil
+}
+
+// failMultiClusterDeploy 当前批次失败, 中止后续所有批次
+func (s *Service) failMultiClusterDeploy(ctx context.Context, project *resp.ProjectDetailResp,
+	app *resp.AppDetailResp, deploy *entity.MultiClusterDeploy) {
+	now := time.Now()
+	wave := deploy.GetCurrentWave()
+	wave.Status = entity.MultiClusterDeployWaveStatusFail
+	wave.FinishTime = &now
+	deploy.Status = entity.MultiClusterDeployStatusFail
+
+	s.haltMultiClusterDeployWaves(ctx, project, app, deploy, deploy.CurrentWave+1)
+}
+
+// haltMultiClusterDeployWaves 将指定批次及之后批次的暂停任务置为失败, 避免阻塞应用后续的任务
+func (s *Service) haltMultiClusterDeployWaves(ctx context.Context, project *resp.ProjectDetailResp,
+	app *resp.AppDetailResp, deploy *entity.MultiClusterDeploy, from int) {
+	for i := from; i < len(deploy.Waves); i++ {
+		wave := deploy.Waves[i]
+		wave.Status = entity.MultiClusterDeployWaveStatusHalted
+
+		for _, waveTask := range wave.Tasks {
+			task, err := s.GetTaskDetail(ctx, waveTask.TaskID)
+			if err != nil {
+				log.Errorc(ctx, "get halted task(%s) err: %v", waveTask.TaskID, err)
+				continue
+			}
+
+			err = s.UpdateTask(ctx, project, app, task, &req.UpdateTaskReq{
+				Suspend:    null.BoolFrom(false),
+				Status:     entity.TaskStatusFail,
+				OperatorID: entity.K8sSystemUserID,
+			})
+			if err != nil {
+				log.Errorc(ctx, "halt task(%s) err: %v", waveTask.TaskID, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,263 @@
+package service
+
+import (
+	"rulai/dao"
+	"rulai/models/entity"
+	"rulai/models/req"
+	"rulai/models/resp"
+	_errcode "rulai/utils/errcode"
+
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"gitlab.shanhai.int/sre/library/base/deepcopy.v2"
+	"gitlab.shanhai.int/sre/library/log"
+	"gitlab.shanhai.int/sre/library/net/errcode"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func (s *Service) GetNotificationChannels(ctx context.Context, projectID string) ([]*resp.NotificationChannelDetail, error) {
+	channels, err := s.dao.FindNotificationChannels(ctx, bson.M{"project_id": projectID}, dao.MongoFindOptionWithSortByIDAsc)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*resp.NotificationChannelDetail, 0)
+	err = deepcopy.Copy(&channels).To(&res)
+	if err != nil {
+		return nil, errors.Wrap(errcode.InternalError, err.Error())
+	}
+
+	return res, nil
+}
+
+func (s *Service) FindSingleNotificationChannelByID(ctx context.Context, id string) (*entity.NotificationChannel, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.Wrap(_errcode.InvalidHexStringError, err.Error())
+	}
+
+	return s.dao.FindSingleNotificationChannel(ctx, bson.M{"_id": objectID})
+}
+
+func (s *Service) CreateNotificationChannel(ctx context.Context, createReq *req.CreateNotificationChannelReq) error {
+	now := time.Now()
+	channel := &entity.NotificationChannel{
+		ID:         primitive.NewObjectID(),
+		ProjectID:  createReq.ProjectID,
+		Name:       createReq.Name,
+		Type:       createReq.Type,
+		URL:        createReq.URL,
+		Secret:     createReq.Secret,
+		Emails:     createReq.Emails,
+		OperatorID: createReq.OperatorID,
+		CreateTime: &now,
+		UpdateTime: &now,
+	}
+
+	return s.dao.CreateSingleNotificationChannel(ctx, channel)
+}
+
+func (s *Service) UpdateNotificationChannel(ctx context.Context, id string, updateReq *req.UpdateNotificationChannelReq) error {
+	changeMap := make(map[string]interface{})
+	changeMap["update_time"] = time.Now()
+
+	if updateReq.Name != "" {
+		changeMap["name"] = updateReq.Name
+	}
+
+	if updateReq.URL != "" {
+		changeMap["url"] = updateReq.URL
+	}
+
+	if !updateReq.Secret.IsZero() {
+		changeMap["secret"] = updateReq.Secret.ValueOrZero()
+	}
+
+	if len(updateReq.Emails) > 0 {
+		changeMap["emails"] = updateReq.Emails
+	}
+
+	return s.dao.UpdateSingleNotificationChannel(ctx, id, bson.M{
+		"$set": changeMap,
+	})
+}
+
+// DeleteNotificationChannel 删除通知渠道, 同时删除指向该渠道的规则
+func (s *Service) DeleteNotificationChannel(ctx context.Context, id string) error {
+	err := s.dao.DeleteNotificationRules(ctx, bson.M{"channel_id": id})
+	if err != nil {
+		return err
+	}
+
+	return s.dao.DeleteSingleNotificationChannelByID(ctx, id)
+}
+
+func (s *Service) GetNotificationRules(ctx context.Context, projectID string) ([]*resp.NotificationRuleDetail, error) {
+	rules, err := s.dao.FindNotificationRules(ctx, bson.M{"project_id": projectID}, dao.MongoFindOptionWithSortByIDAsc)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*resp.NotificationRuleDetail, 0)
+	err = deepcopy.Copy(&rules).To(&res)
+	if err != nil {
+		return nil, errors.Wrap(errcode.InternalError, err.Error())
+	}
+
+	return res, nil
+}
+
+func (s *Service) FindSingleNotificationRuleByID(ctx context.Context, id string) (*entity.NotificationRule, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.Wrap(_errcode.InvalidHexStringError, err.Error())
+	}
+
+	return s.dao.FindSingleNotificationRule(ctx, bson.M{"_id": objectID})
+}
+
+func (s *Service) CreateNotificationRule(ctx context.Context, createReq *req.CreateNotificationRuleReq) error {
+	now := time.Now()
+	rule := &entity.NotificationRule{
+		ID:         primitive.NewObjectID(),
+		ProjectID:  createReq.ProjectID,
+		ChannelID:  createReq.ChannelID,
+		EnvNames:   createReq.EnvNames,
+		Events:     createReq.Events,
+		OperatorID: createReq.OperatorID,
+		CreateTime: &now,
+		UpdateTime: &now,
+	}
+
+	return s.dao.CreateSingleNotificationRule(ctx, rule)
+}
+
+func (s *Service) UpdateNotificationRule(ctx context.Context, id string, updateReq *req.UpdateNotificationRuleReq) error {
+	changeMap := make(map[string]interface{})
+	changeMap["update_time"] = time.Now()
+
+	if updateReq.ChannelID != "" {
+		changeMap["channel_id"] = updateReq.ChannelID
+	}
+
+	// 环境允许更新为空, 表示所有环境
+	if updateReq.EnvNames != nil {
+		changeMap["env_names"] = updateReq.EnvNames
+	}
+
+	if len(updateReq.Events) > 0 {
+		changeMap["events"] = updateReq.Events
+	}
+
+	return s.dao.UpdateSingleNotificationRule(ctx, id, bson.M{
+		"$set": changeMap,
+	})
+}
+
+func (s *Service) DeleteNotificationRuleByID(ctx context.Context, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.Wrap(_errcode.InvalidHexStringError, err.Error())
+	}
+
+	return s.dao.DeleteNotificationRules(ctx, bson.M{"_id": objectID})
+}
+
+func (s *Service) GetNotificationDeliveries(ctx context.Context, projectID string,
+	getReq *req.GetNotificationDeliveriesReq) ([]*resp.NotificationDeliveryDetail, int, error) {
+	filter := bson.M{"project_id": projectID}
+	if getReq.ChannelID != "" {
+		filter["channel_id"] = getReq.ChannelID
+	}
+	if getReq.Event != "" {
+		filter["event"] = getReq.Event
+	}
+	if getReq.Status != "" {
+		filter["status"] = getReq.Status
+	}
+
+	limit := int64(getReq.Limit)
+	skip := int64(getReq.Page-1) * limit
+
+	deliveries, err := s.dao.FindNotificationDeliveries(ctx, filter, &options.FindOptions{
+		Limit: &limit,
+		Skip:  &skip,
+		Sort:  dao.MongoSortByCreateTimeDesc,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	count, err := s.dao.CountNotificationDeliveries(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	res := make([]*resp.NotificationDeliveryDetail, 0)
+	err = deepcopy.Copy(&deliveries).To(&res)
+	if err != nil {
+		return nil, 0, errors.Wrap(errcode.InternalError, err.Error())
+	}
+
+	return res, count, nil
+}
+
+// GetMatchedNotificationRules 获取项目中命中事件的通知规则
+func (s *Service) GetMatchedNotificationRules(ctx context.Context, projectID string, envName entity.AppEnvName,
+	event entity.NotificationEvent) ([]*entity.NotificationRule, error) {
+	rules, err := s.dao.FindNotificationRules(ctx, bson.M{
+		"project_id": projectID,
+		"events":     event,
+	}, dao.MongoFindOptionWithSortByIDAsc)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*entity.NotificationRule, 0)
+	for _, rule := range rules {
+		if rule.Matches(envName, event) {
+			res = append(res, rule)
+		}
+	}
+
+	return res, nil
+}
+
+// DispatchProjectNotifications 按项目通知规则将消息发送至对应渠道
+// 多条规则指向同一渠道时只发送一次, 单个渠道发送失败不影响其他渠道
+func (s *Service) DispatchProjectNotifications(ctx context.Context, rules []*entity.NotificationRule,
+	msg *req.NotificationMessage) error {
+	var lastErr error
+	sent := make(map[string]bool)
+	for _, rule := range rules {
+		if sent[rule.ChannelID] {
+			continue
+		}
+		sent[rule.ChannelID] = true
+
+		channel, err := s.FindSingleNotificationChannelByID(ctx, rule.ChannelID)
+		if err != nil {
+			log.Errorc(ctx, "find notification channel(%s) of rule(%s) error: %s", rule.ChannelID, rule.ID.Hex(), err)
+			lastErr = err
+			continue
+		}
+
+		notifier, err := s.getChannelNotifier(channel)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		err = s.deliverNotification(ctx, notifier, msg, rule.ChannelID, rule.ID.Hex())
+		if err != nil {
+			log.Errorc(ctx, "dispatch notification to channel(%s) error: %s", rule.ChannelID, err)
+			lastErr = err
+		}
+	}
+
+	return lastErr
+}
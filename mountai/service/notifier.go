@@ -0,0 +1,313 @@
+package service
+
+import (
+	"rulai/config"
+	"rulai/models/entity"
+	"rulai/models/req"
+	"rulai/models/resp"
+	"rulai/utils"
+	_errcode "rulai/utils/errcode"
+
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gitlab.shanhai.int/sre/library/log"
+	"gitlab.shanhai.int/sre/library/net/errcode"
+	"gitlab.shanhai.int/sre/library/net/httpclient"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// webhook 请求头
+	webhookHeaderEvent     = "X-AMS-Event"
+	webhookHeaderTimestamp = "X-AMS-Timestamp"
+	webhookHeaderSignature = "X-AMS-Signature"
+
+	defaultNotificationMaxAttempts   = 3
+	defaultNotificationRetryInterval = 2 * time.Second
+
+	slackAppOpTmpName = "slackAppOpMsg"
+	slackAppOpMsg     = `*{{.Title}}*
+• 项目名: {{.ProjectName}}
+• 应用名: {{.AppName}}
+• 环境名: {{.Env}}
+• 操作人: {{.UserName}}
+• 操作: {{.Action}}
+• 时间: {{.OpTime}}
+<{{.DetailURL}}|进入AMS, 查看详情>`
+
+	emailAppOpTmpName = "emailAppOpMsg"
+	emailAppOpMsg     = `{{.Title}}
+
+项目名: {{.ProjectName}}
+应用名: {{.AppName}}
+环境名: {{.Env}}
+操作人: {{.UserName}}
+操作: {{.Action}}
+时间: {{.OpTime}}
+
+进入AMS, 查看详情: {{.DetailURL}}
+`
+)
+
+// 外部 webhook 视为成功的响应状态码
+var notifierAccessStatusCodes = []int{http.StatusOK, http.StatusCreated, http.StatusAccepted, http.StatusNoContent}
+
+// Notifier 通知渠道
+// 新增渠道类型时实现该接口, 项目可配置的渠道需在 notifierFactories 中注册
+type Notifier interface {
+	// Type 渠道类型
+	Type() entity.NotificationChannelType
+	// Notify 发送通知
+	Notify(ctx context.Context, msg *req.NotificationMessage) error
+}
+
+// notifierFactories 项目可配置渠道类型对应的 Notifier 实现
+var notifierFactories = map[entity.NotificationChannelType]func(s *Service, channel *entity.NotificationChannel) Notifier{
+	entity.NotificationChannelTypeWebhook: func(s *Service, channel *entity.NotificationChannel) Notifier {
+		return &webhookNotifier{s: s, channel: channel}
+	},
+	entity.NotificationChannelTypeSlack: func(s *Service, channel *entity.NotificationChannel) Notifier {
+		return &slackNotifier{s: s, channel: channel}
+	},
+	entity.NotificationChannelTypeEmail: func(s *Service, channel *entity.NotificationChannel) Notifier {
+		return &emailNotifier{s: s, channel: channel}
+	},
+}
+
+// getChannelNotifier 获取项目通知渠道对应的 Notifier
+func (s *Service) getChannelNotifier(channel *entity.NotificationChannel) (Notifier, error) {
+	factory, ok := notifierFactories[channel.Type]
+	if !ok {
+		return nil, errors.Wrapf(errcode.InvalidParams, "unsupported notification channel type: %s", channel.Type)
+	}
+
+	return factory(s, channel), nil
+}
+
+// webhookNotifier 通用 HTTP webhook, 配置密钥时请求头携带 HMAC-SHA256 签名
+type webhookNotifier struct {
+	s       *Service
+	channel *entity.NotificationChannel
+}
+
+func (n *webhookNotifier) Type() entity.NotificationChannelType {
+	return entity.NotificationChannelTypeWebhook
+}
+
+func (n *webhookNotifier) Notify(ctx context.Context, msg *req.NotificationMessage) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return errors.Wrap(errcode.InternalError, err.Error())
+	}
+
+	timestamp := time.Now().Unix()
+	headers := httpclient.NewJsonHeader().
+		Set(webhookHeaderEvent, string(msg.Event)).
+		Set(webhookHeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	if n.channel.Secret != "" {
+		headers.Set(webhookHeaderSignature, utils.SignWebhookPayload(n.channel.Secret, timestamp, body))
+	}
+
+	err = n.s.httpClient.Builder().
+		URL(n.channel.URL).
+		Method(http.MethodPost).
+		Headers(headers).
+		Body(body).
+		// 外部地址不走熔断降级, 避免降级被误判为发送成功
+		DisableBreaker(true).
+		AccessStatusCode(notifierAccessStatusCodes...).
+		Fetch(ctx).
+		Error()
+	if err != nil {
+		return errors.Wrap(_errcode.NotificationDeliveryError, err.Error())
+	}
+
+	return nil
+}
+
+// slackNotifier Slack incoming webhook
+type slackNotifier struct {
+	s       *Service
+	channel *entity.NotificationChannel
+}
+
+func (n *slackNotifier) Type() entity.NotificationChannelType {
+	return entity.NotificationChannelTypeSlack
+}
+
+func (n *slackNotifier) Notify(ctx context.Context, msg *req.NotificationMessage) error {
+	text, err := n.s.RenderTemplateFromText(msg.AppOpMessage, slackAppOpTmpName, slackAppOpMsg)
+	if err != nil {
+		return err
+	}
+
+	err = n.s.httpClient.Builder().
+		URL(n.channel.URL).
+		Method(http.MethodPost).
+		JsonBody(map[string]string{"text": text}).
+		DisableBreaker(true).
+		AccessStatusCode(notifierAccessStatusCodes...).
+		Fetch(ctx).
+		Error()
+	if err != nil {
+		return errors.Wrap(_errcode.NotificationDeliveryError, err.Error())
+	}
+
+	return nil
+}
+
+// emailNotifier SMTP 邮件
+type emailNotifier struct {
+	s       *Service
+	channel *entity.NotificationChannel
+}
+
+func (n *emailNotifier) Type() entity.NotificationChannelType {
+	return entity.NotificationChannelTypeEmail
+}
+
+func (n *emailNotifier) Notify(_ context.Context, msg *req.NotificationMessage) error {
+	if config.Conf.Notification == nil || config.Conf.Notification.SMTP == nil {
+		return errors.Wrap(_errcode.NotificationDeliveryError, "smtp is not configured")
+	}
+	smtpConf := config.Conf.Notification.SMTP
+
+	text, err := n.s.RenderTemplateFromText(msg.AppOpMessage, emailAppOpTmpName, emailAppOpMsg)
+	if err != nil {
+		return err
+	}
+
+	body := new(bytes.Buffer)
+	fmt.Fprintf(body, "From: %s\r\n", smtpConf.From)
+	fmt.Fprintf(body, "To: %s\r\n", strings.Join(n.channel.Emails, ", "))
+	fmt.Fprintf(body, "Subject: %s\r\n", mime.BEncoding.Encode("UTF-8", msg.Title))
+	body.WriteString("MIME-Version: 1.0\r\n")
+	body.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	body.WriteString(strings.ReplaceAll(text, "\n", "\r\n"))
+
+	var auth smtp.Auth
+	if smtpConf.Username != "" {
+		auth = smtp.PlainAuth("", smtpConf.Username, smtpConf.Password, smtpConf.Host)
+	}
+
+	addr := net.JoinHostPort(smtpConf.Host, strconv.Itoa(smtpConf.Port))
+	err = smtp.SendMail(addr, auth, smtpConf.From, n.channel.Emails, body.Bytes())
+	if err != nil {
+		return errors.Wrap(_errcode.NotificationDeliveryError, err.Error())
+	}
+
+	return nil
+}
+
+// feishuNotifier 飞书部署通知群机器人, 内置渠道
+type feishuNotifier struct {
+	s *Service
+}
+
+func (n *feishuNotifier) Type() entity.NotificationChannelType {
+	return entity.NotificationChannelTypeFeishu
+}
+
+func (n *feishuNotifier) Notify(ctx context.Context, msg *req.NotificationMessage) error {
+	msgReq, err := n.s.generateRobotMessageReq(msg.AppOpMessage)
+	if err != nil {
+		return err
+	}
+
+	return n.s.SendRobotMsgToFeishu(ctx, msgReq)
+}
+
+// dingTalkNotifier 钉钉工作通知, 内置渠道, 发送成功后 result 中保存钉钉任务信息
+type dingTalkNotifier struct {
+	s      *Service
+	emails []string
+	result *resp.DingTalkCropMessageData
+}
+
+func (n *dingTalkNotifier) Type() entity.NotificationChannelType {
+	return entity.NotificationChannelTypeDingTalk
+}
+
+func (n *dingTalkNotifier) Notify(ctx context.Context, msg *req.NotificationMessage) error {
+	res, err := n.s.SendDingCropMessage(ctx, msg.AppOpMessage, n.emails, defaultTmpName, defaultAppOpMsg)
+	if err != nil {
+		return err
+	}
+
+	n.result = res
+	return nil
+}
+
+// deliverNotification 通过渠道发送通知, 失败时按配置重试, 并记录投递日志
+// 投递日志写入失败不影响发送结果
+func (s *Service) deliverNotification(ctx context.Context, notifier Notifier, msg *req.NotificationMessage,
+	channelID, ruleID string) error {
+	maxAttempts, retryInterval := defaultNotificationMaxAttempts, defaultNotificationRetryInterval
+	if conf := config.Conf.Notification; conf != nil {
+		if conf.MaxAttempts > 0 {
+			maxAttempts = conf.MaxAttempts
+		}
+		if conf.RetryInterval > 0 {
+			retryInterval = time.Duration(conf.RetryInterval)
+		}
+	}
+
+	var err error
+	attempts := 0
+	for attempts < maxAttempts {
+		if attempts > 0 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(retryInterval * time.Duration(attempts)):
+			}
+			if ctx.Err() != nil {
+				err = errors.Wrap(_errcode.NotificationDeliveryError, ctx.Err().Error())
+				break
+			}
+		}
+
+		attempts++
+		err = notifier.Notify(ctx, msg)
+		if err == nil {
+			break
+		}
+		log.Warnc(ctx, "notify %s failed, attempt: %d, task id: %s, err: %s", notifier.Type(), attempts, msg.TaskID, err)
+	}
+
+	now := time.Now()
+	delivery := &entity.NotificationDelivery{
+		ID:          primitive.NewObjectID(),
+		ProjectID:   msg.ProjectID,
+		ChannelID:   channelID,
+		ChannelType: notifier.Type(),
+		RuleID:      ruleID,
+		Event:       msg.Event,
+		AppID:       msg.AppID,
+		Env:         entity.AppEnvName(msg.Env),
+		TaskID:      msg.TaskID,
+		Status:      entity.NotificationDeliveryStatusSuccess,
+		Attempts:    attempts,
+		CreateTime:  &now,
+	}
+	if err != nil {
+		delivery.Status = entity.NotificationDeliveryStatusFail
+		delivery.Error = err.Error()
+	}
+
+	if e := s.dao.CreateNotificationDelivery(ctx, delivery); e != nil {
+		log.Errorc(ctx, "create notification delivery error: %s", e)
+	}
+
+	return err
+}
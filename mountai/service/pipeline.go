@@ -0,0 +1,690 @@
+package service
+
+import (
+	"rulai/dao"
+	"rulai/models/entity"
+	"rulai/models/req"
+	"rulai/models/resp"
+	"rulai/utils"
+	_errcode "rulai/utils/errcode"
+
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"gitlab.shanhai.int/sre/library/base/deepcopy.v2"
+	"gitlab.shanhai.int/sre/library/base/null"
+	"gitlab.shanhai.int/sre/library/log"
+	"gitlab.shanhai.int/sre/library/net/errcode"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func (s *Service) GetPipelines(ctx context.Context, getReq *req.GetPipelinesReq) ([]*resp.PipelineDetail, int, error) {
+	filter := bson.M{}
+	if getReq.ProjectID != "" {
+		filter["project_id"] = getReq.ProjectID
+	}
+	if getReq.AppID != "" {
+		filter["app_id"] = getReq.AppID
+	}
+
+	limit := int64(getReq.Limit)
+	skip := int64(getReq.Page-1) * limit
+
+	pipelines, err := s.dao.FindPipelines(ctx, filter, &options.FindOptions{
+		Limit: &limit,
+		Skip:  &skip,
+		Sort:  dao.MongoSortByIDAsc,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	count, err := s.dao.CountPipelines(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	res := make([]*resp.PipelineDetail, 0)
+	err = deepcopy.Copy(&pipelines).To(&res)
+	if err != nil {
+		return nil, 0, errors.Wrap(errcode.InternalError, err.Error())
+	}
+
+	return res, count, nil
+}
+
+func (s *Service) FindSinglePipelineByID(ctx context.Context, id string) (*entity.Pipeline, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.Wrap(_errcode.InvalidHexStringError, err.Error())
+	}
+
+	return s.dao.FindSinglePipeline(ctx, bson.M{"_id": objectID})
+}
+
+func (s *Service) GetPipelineDetail(ctx context.Context, id string) (*resp.PipelineDetail, error) {
+	pipeline, err := s.FindSinglePipelineByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	res := new(resp.PipelineDetail)
+	err = deepcopy.Copy(pipeline).To(res)
+	if err != nil {
+		return nil, errors.Wrap(errcode.InternalError, err.Error())
+	}
+
+	return res, nil
+}
+
+// ValidatePipelineStages 校验流水线阶段配置
+func (s *Service) ValidatePipelineStages(ctx context.Context, stages []*entity.PipelineEnvStage) error {
+	for i, stage := range stages {
+		if stage == nil || stage.EnvName == "" {
+			return errors.Wrapf(errcode.InvalidParams, "stage %d: env_name is required", i)
+		}
+
+		clusterName, err := s.CheckAndUnifyClusterName(ctx, stage.ClusterName, stage.EnvName)
+		if err != nil {
+			return err
+		}
+		stage.ClusterName = clusterName
+
+		// prd 环境必须经过人工确认或钉钉审批
+		if stage.EnvName == entity.AppEnvPrd && (stage.Gate == nil ||
+			(stage.Gate.Type != entity.PipelineGateTypeManual && stage.Gate.Type != entity.PipelineGateTypeDingApproval)) {
+			return errors.Wrapf(errcode.InvalidParams, "stage %d: prd stage requires manual or ding_approval gate", i)
+		}
+
+		if stage.Gate == nil {
+			continue
+		}
+
+		switch stage.Gate.Type {
+		case entity.PipelineGateTypeManual, entity.PipelineGateTypeDingApproval:
+		case entity.PipelineGateTypeSoak:
+			if stage.Gate.SoakSeconds <= 0 {
+				return errors.Wrapf(errcode.InvalidParams, "stage %d: soak_seconds must be positive", i)
+			}
+		case entity.PipelineGateTypeHealthCheck:
+			if i == 0 {
+				return errors.Wrap(errcode.InvalidParams, "stage 0: health_check gate requires a previous stage")
+			}
+		default:
+			return errors.Wrapf(errcode.InvalidParams, "stage %d: invalid gate type %s", i, stage.Gate.Type)
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) CreateSinglePipeline(ctx context.Context, createReq *req.CreatePipelineReq) error {
+	now := time.Now()
+
+	pipeline := &entity.Pipeline{
+		ID:          primitive.NewObjectID(),
+		Name:        createReq.Name,
+		Description: createReq.Description,
+		ProjectID:   createReq.ProjectID,
+		AppID:       createReq.AppID,
+		Stages:      createReq.Stages,
+		OperatorID:  createReq.OperatorID,
+		CreateTime:  &now,
+		UpdateTime:  &now,
+	}
+
+	return s.dao.CreateSinglePipeline(ctx, pipeline)
+}
+
+func (s *Service) UpdateSinglePipeline(ctx context.Context, id string, updateReq *req.UpdatePipelineReq) error {
+	changeMap := make(map[string]interface{})
+	changeMap["update_time"] = time.Now()
+
+	if updateReq.Name != "" {
+		changeMap["name"] = updateReq.Name
+	}
+
+	if !updateReq.Description.IsZero() {
+		changeMap["description"] = updateReq.Description.ValueOrZero()
+	}
+
+	if len(updateReq.Stages) > 0 {
+		changeMap["stages"] = updateReq.Stages
+	}
+
+	return s.dao.UpdateSinglePipeline(ctx, id, bson.M{
+		"$set": changeMap,
+	})
+}
+
+func (s *Service) DeleteSinglePipelineByID(ctx context.Context, id string) error {
+	return s.dao.DeleteSinglePipelineByID(ctx, id)
+}
+
+// HasRunningPipelineRun 流水线是否存在运行中的记录
+func (s *Service) HasRunningPipelineRun(ctx context.Context, pipelineID string) (bool, error) {
+	count, err := s.dao.CountPipelineRuns(ctx, bson.M{
+		"pipeline_id": pipelineID,
+		"status":      entity.PipelineRunStatusRunning,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// CreatePipelineRun 运行流水线, 由 pipeline worker 依次推进各阶段
+func (s *Service) CreatePipelineRun(ctx context.Context, pipeline *entity.Pipeline,
+	createReq *req.CreatePipelineRunReq) (*resp.PipelineRunDetail, error) {
+	running, err := s.HasRunningPipelineRun(ctx, pipeline.ID.Hex())
+	if err != nil {
+		return nil, err
+	}
+	if running {
+		return nil, errors.Wrap(errcode.InvalidParams, "pipeline has a running run")
+	}
+
+	now := time.Now()
+	stages := make([]*entity.PipelineRunStage, len(pipeline.Stages))
+	for i, stage := range pipeline.Stages {
+		stages[i] = &entity.PipelineRunStage{
+			EnvName:     stage.EnvName,
+			ClusterName: stage.ClusterName,
+			Gate:        stage.Gate,
+			Status:      entity.PipelineStageStatusPending,
+		}
+	}
+
+	run := &entity.PipelineRun{
+		ID:             primitive.NewObjectID(),
+		PipelineID:     pipeline.ID.Hex(),
+		ProjectID:      pipeline.ProjectID,
+		AppID:          pipeline.AppID,
+		ImageVersion:   createReq.ImageVersion,
+		ConfigCommitID: createReq.ConfigCommitID,
+		Description:    createReq.Description,
+		Status:         entity.PipelineRunStatusRunning,
+		Stages:         stages,
+		OperatorID:     createReq.OperatorID,
+		CreateTime:     &now,
+		UpdateTime:     &now,
+	}
+
+	err = s.dao.CreateSinglePipelineRun(ctx, run)
+	if err != nil {
+		return nil, err
+	}
+
+	res := new(resp.PipelineRunDetail)
+	err = deepcopy.Copy(run).To(res)
+	if err != nil {
+		return nil, errors.Wrap(errcode.InternalError, err.Error())
+	}
+
+	return res, nil
+}
+
+func (s *Service) GetPipelineRuns(ctx context.Context, pipelineID string, getReq *req.GetPipelineRunsReq) (
+	[]*resp.PipelineRunDetail, int, error) {
+	filter := bson.M{"pipeline_id": pipelineID}
+	if getReq.Status != "" {
+		filter["status"] = getReq.Status
+	}
+
+	limit := int64(getReq.Limit)
+	skip := int64(getReq.Page-1) * limit
+
+	runs, err := s.dao.FindPipelineRuns(ctx, filter, &options.FindOptions{
+		Limit: &limit,
+		Skip:  &skip,
+		Sort:  bson.M{"_id": -1},
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	count, err := s.dao.CountPipelineRuns(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	res := make([]*resp.PipelineRunDetail, 0)
+	err = deepcopy.Copy(&runs).To(&res)
+	if err != nil {
+		return nil, 0, errors.Wrap(errcode.InternalError, err.Error())
+	}
+
+	return res, count, nil
+}
+
+func (s *Service) FindSinglePipelineRunByID(ctx context.Context, id string) (*entity.PipelineRun, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.Wrap(_errcode.InvalidHexStringError, err.Error())
+	}
+
+	return s.dao.FindSinglePipelineRun(ctx, bson.M{"_id": objectID})
+}
+
+func (s *Service) GetPipelineRunDetail(ctx context.Context, id string) (*resp.PipelineRunDetail, error) {
+	run, err := s.FindSinglePipelineRunByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	res := new(resp.PipelineRunDetail)
+	err = deepcopy.Copy(run).To(res)
+	if err != nil {
+		return nil, errors.Wrap(errcode.InternalError, err.Error())
+	}
+
+	return res, nil
+}
+
+// ApprovePipelineRunGate 放行当前阶段的人工卡点
+func (s *Service) ApprovePipelineRunGate(ctx context.Context, run *entity.PipelineRun, operatorID string) error {
+	stage := run.GetCurrentStage()
+	if run.Status != entity.PipelineRunStatusRunning || stage == nil ||
+		stage.Status != entity.PipelineStageStatusGating || stage.Gate == nil ||
+		stage.Gate.Type != entity.PipelineGateTypeManual {
+		return errors.Wrap(errcode.InvalidParams, "current stage is not waiting for manual approval")
+	}
+
+	return s.dao.UpdateSinglePipelineRun(ctx, run.ID, bson.M{
+		"$set": bson.M{
+			fmt.Sprintf("stages.%d.approved_by", run.CurrentStage): operatorID,
+			"update_time": time.Now(),
+		},
+	})
+}
+
+// CancelPipelineRun 取消流水线运行, 已创建的任务不受影响
+func (s *Service) CancelPipelineRun(ctx context.Context, run *entity.PipelineRun, operatorID string) error {
+	if run.Status != entity.PipelineRunStatusRunning {
+		return errors.Wrap(errcode.InvalidParams, "pipeline run is not running")
+	}
+
+	change := bson.M{
+		"status":      entity.PipelineRunStatusCanceled,
+		"update_time": time.Now(),
+	}
+	if run.GetCurrentStage() != nil {
+		change[fmt.Sprintf("stages.%d.status", run.CurrentStage)] = entity.PipelineStageStatusCanceled
+		change[fmt.Sprintf("stages.%d.message", run.CurrentStage)] = fmt.Sprintf("canceled by %s", operatorID)
+	}
+
+	return s.dao.UpdateSinglePipelineRun(ctx, run.ID, bson.M{"$set": change})
+}
+
+// AdvancePipelineRuns 推进所有运行中的流水线
+func (s *Service) AdvancePipelineRuns(ctx context.Context) error {
+	runs, err := s.dao.FindPipelineRuns(ctx, bson.M{
+		"status": entity.PipelineRunStatusRunning,
+	}, dao.MongoFindOptionWithSortByIDAsc)
+	if err != nil {
+		return err
+	}
+
+	for _, run := range runs {
+		err = s.advancePipelineRunWithLock(ctx, run.ID)
+		if err != nil {
+			log.Errorc(ctx, "advance pipeline run(%s) err: %+v", run.ID.Hex(), err)
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) advancePipelineRunWithLock(ctx context.Context, id primitive.ObjectID) (err error) {
+	mutex := s.dao.GetPipelineRunWorkerLock(ctx, id.Hex())
+	err = mutex.Lock(ctx)
+	if err != nil {
+		return errors.Wrapf(errcode.RedLockLockError, "%s", err)
+	}
+
+	defer func() {
+		result := mutex.Unlock(ctx)
+		if !result {
+			err = errors.WithStack(errcode.RedLockUnLockError)
+		}
+	}()
+
+	// 加锁后重新获取, 避免并发放行或取消被覆盖
+	run, err := s.dao.FindSinglePipelineRun(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if run.Status != entity.PipelineRunStatusRunning {
+		return nil
+	}
+
+	err = s.advancePipelineRun(ctx, run)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	run.UpdateTime = &now
+	return s.dao.UpdateSinglePipelineRun(ctx, run.ID, bson.M{
+		"$set": bson.M{
+			"status":        run.Status,
+			"current_stage": run.CurrentStage,
+			"stages":        run.Stages,
+			"update_time":   run.UpdateTime,
+		},
+	})
+}
+
+// advancePipelineRun 推进流水线当前阶段: 等待 -> 卡点 -> 部署 -> 成功/失败
+func (s *Service) advancePipelineRun(ctx context.Context, run *entity.PipelineRun) error {
+	stage := run.GetCurrentStage()
+	if stage == nil {
+		run.Status = entity.PipelineRunStatusSuccess
+		return nil
+	}
+
+	now := time.Now()
+	switch stage.Status {
+	case entity.PipelineStageStatusPending:
+		if !run.StartCurrentStage(now) {
+			return nil
+		}
+
+		return s.startPipelineStage(ctx, run, stage)
+	case entity.PipelineStageStatusGating:
+		passed, msg, err := s.checkPipelineGate(ctx, run, stage)
+		if err != nil {
+			return err
+		}
+		if !passed {
+			stage.Message = msg
+			return nil
+		}
+
+		return s.startPipelineStage(ctx, run, stage)
+	case entity.PipelineStageStatusDeploying:
+		task, err := s.GetTaskDetail(ctx, stage.TaskID)
+		if err != nil {
+			return err
+		}
+
+		run.UpdateCurrentStageByTask(task.Status,
+			task.Approval != nil && task.Approval.Status == entity.RefusedTaskApprovalStatus, now)
+	}
+
+	return nil
+}
+
+// checkPipelineGate 校验当前阶段的卡点是否通过, 未通过时返回等待原因
+func (s *Service) checkPipelineGate(ctx context.Context, run *entity.PipelineRun,
+	stage *entity.PipelineRunStage) (bool, string, error) {
+	previous := run.GetPreviousStage()
+
+	switch stage.Gate.Type {
+	case entity.PipelineGateTypeManual:
+		if stage.ApprovedBy != "" {
+			return true, "", nil
+		}
+		return false, "waiting for manual approval", nil
+	case entity.PipelineGateTypeSoak:
+		since := stage.StartTime
+		if previous != nil && previous.FinishTime != nil {
+			since = previous.FinishTime
+		}
+		remain := time.Duration(stage.Gate.SoakSeconds)*time.Second - time.Since(*since)
+		if remain <= 0 {
+			return true, "", nil
+		}
+		return false, fmt.Sprintf("soaking, %s remaining", remain.Truncate(time.Second)), nil
+	case entity.PipelineGateTypeHealthCheck:
+		if previous == nil {
+			return true, "", nil
+		}
+
+		task, err := s.GetTaskDetail(ctx, previous.TaskID)
+		if err != nil {
+			return false, "", err
+		}
+
+		status, err := s.GetDeploymentStatus(ctx, previous.ClusterName, previous.EnvName, &req.GetDeploymentDetailReq{
+			Namespace: task.Namespace,
+			Name:      task.Version,
+			Env:       string(previous.EnvName),
+		})
+		if err != nil {
+			return false, "", err
+		}
+
+		if status.Replicas > 0 && status.ReadyReplicas == status.Replicas &&
+			status.UpdatedReplicas == status.Replicas && status.UnavailableReplicas == 0 {
+			return true, "", nil
+		}
+		return false, fmt.Sprintf("waiting for %s to be healthy, ready %d/%d",
+			task.Version, status.ReadyReplicas, status.Replicas), nil
+	}
+
+	return true, "", nil
+}
+
+// startPipelineStage 以该环境上一次成功的部署参数为基础, 使用流水线的镜像及配置创建全量部署任务
+func (s *Service) startPipelineStage(ctx context.Context, run *entity.PipelineRun, stage *entity.PipelineRunStage) error {
+	app, err := s.GetAppDetail(ctx, run.AppID)
+	if err != nil {
+		return err
+	}
+
+	project, err := s.GetProjectDetail(ctx, app.ProjectID)
+	if err != nil {
+		return err
+	}
+
+	// 应用在该环境存在未完成的任务时等待
+	unfinishedCount, err := s.GetTasksCount(ctx, &req.GetTasksReq{
+		AppID:             app.ID,
+		EnvName:           stage.EnvName,
+		ClusterName:       stage.ClusterName,
+		StatusInverseList: entity.TaskStatusFinalStateList,
+		Suspend:           null.BoolFrom(false),
+	})
+	if err != nil {
+		return err
+	}
+	if unfinishedCount > 0 {
+		stage.Message = "waiting for unfinished tasks of the app"
+		return nil
+	}
+
+	window, err := s.GetMatchedFreezeWindow(ctx, project, stage.EnvName, time.Now())
+	if err != nil {
+		return err
+	}
+	if window != nil {
+		stage.Message = fmt.Sprintf("frozen by %s until %s", window.Name,
+			window.EndTime.Format(utils.DefaultTimeFormatLayout))
+		return nil
+	}
+
+	createReq, err := s.BuildPipelineStageTaskReq(ctx, app, stage.EnvName, stage.ClusterName,
+		run.ImageVersion, run.ConfigCommitID)
+	if errcode.EqualError(_errcode.NoRequiredTaskError, err) {
+		run.FailCurrentStage("no successful deployment to inherit params from", time.Now())
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	createReq.Description = fmt.Sprintf("部署流水线运行(%s)第%d阶段", run.ID.Hex(), run.CurrentStage+1)
+
+	// 阶段任务跳过了创建任务接口的校验, 创建前重新校验发起人的权限及参数
+	err = s.ValidatePipelineStageTask(ctx, project, createReq, run.OperatorID)
+	if isPipelineStageRejectedError(err) {
+		run.FailCurrentStage(err.Error(), time.Now())
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// 与创建任务接口一致, 生产环境全量部署需检查错误预算, 预算耗尽时等待恢复
+	err = s.CheckTaskErrorBudget(ctx, project, app, createReq, run.OperatorID)
+	if errcode.EqualError(_errcode.ErrorBudgetExhaustedError, err) {
+		stage.Message = err.Error()
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if stage.Gate != nil && stage.Gate.Type == entity.PipelineGateTypeDingApproval {
+		createReq.Approval.Type = entity.DefaultTaskApprovalType
+		ctx, err = s.withPipelineOperator(ctx, run.OperatorID)
+		if err != nil {
+			return err
+		}
+	}
+
+	task, err := s.CreateTask(ctx, project, app, createReq, run.OperatorID)
+	if err != nil {
+		return err
+	}
+
+	run.DeployCurrentStage(task.ID)
+
+	return nil
+}
+
+// BuildPipelineStageTaskReq 以该环境上一次成功的部署参数为基础, 使用流水线的镜像及配置生成全量部署任务请求
+func (s *Service) BuildPipelineStageTaskReq(ctx context.Context, app *resp.AppDetailResp, envName entity.AppEnvName,
+	clusterName entity.ClusterName, imageVersion, configCommitID string) (*req.CreateTaskReq, error) {
+	latestTask, err := s.GetLatestDeploySuccessTaskFinalVersion(ctx, &req.GetLatestTaskReq{
+		AppID:       app.ID,
+		EnvName:     envName,
+		ClusterName: clusterName,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	createReq := new(req.CreateTaskReq)
+	err = deepcopy.Copy(latestTask).To(createReq)
+	if err != nil {
+		return nil, errors.Wrap(errcode.InternalError, err.Error())
+	}
+
+	createReq.Version = ""
+	createReq.Action = entity.TaskActionFullDeploy
+	createReq.RelatedTaskID = ""
+	createReq.FreezeOverrideReason, createReq.ErrorBudgetOverrideReason = "", ""
+	createReq.DeployType, createReq.ScheduleTime = entity.ImmediateTaskDeployType, 0
+	createReq.Approval = &req.ApprovalReq{Type: entity.SkipTaskApprovalType}
+	createReq.Param.ImageVersion = imageVersion
+	if configCommitID != "" {
+		createReq.Param.ConfigCommitID = configCommitID
+	}
+
+	return createReq, nil
+}
+
+// ValidatePipelineStagesPermission 校验操作人是否可以在流水线各阶段的环境及集群创建部署任务
+func (s *Service) ValidatePipelineStagesPermission(ctx context.Context, project *resp.ProjectDetailResp,
+	stages []*entity.PipelineEnvStage, operatorID string) error {
+	for _, stage := range stages {
+		err := s.validatePipelineStagePermission(ctx, project, stage.EnvName, stage.ClusterName, operatorID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validatePipelineStagePermission 与创建任务接口一致, 校验集群及创建全量部署任务的权限
+func (s *Service) validatePipelineStagePermission(ctx context.Context, project *resp.ProjectDetailResp,
+	envName entity.AppEnvName, clusterName entity.ClusterName, operatorID string) error {
+	clusters, err := s.GetProjectSupportedClusters(ctx, project.ID, envName)
+	if err != nil {
+		return err
+	}
+
+	supported := false
+	for _, cluster := range clusters {
+		if cluster.Env == envName && cluster.Name == clusterName {
+			supported = true
+			break
+		}
+	}
+	if !supported {
+		return errors.Wrapf(errcode.InvalidParams, "%s is not support", clusterName)
+	}
+
+	return s.ValidateHasPermission(ctx, &req.ValidateHasPermissionReq{
+		OperateType:       entity.OperateTypeCreateTask,
+		CreateTaskEnvName: envName,
+		CreateTaskAction:  entity.TaskActionFullDeploy,
+		ProjectID:         project.ID,
+		OperatorID:        operatorID,
+	})
+}
+
+// ValidatePipelineStageTask 校验流水线阶段任务, 包括发起人的权限及镜像参数
+// 其余参数继承自该环境上一次成功的部署, 已在创建时校验
+func (s *Service) ValidatePipelineStageTask(ctx context.Context, project *resp.ProjectDetailResp,
+	createReq *req.CreateTaskReq, operatorID string) error {
+	err := s.validatePipelineStagePermission(ctx, project, createReq.EnvName, createReq.ClusterName, operatorID)
+	if err != nil {
+		return err
+	}
+
+	if createReq.Param.ImageVersion == "" {
+		return errors.Wrap(errcode.InvalidParams, "image version is empty")
+	}
+
+	// 与创建任务接口一致, 镜像分支需与该环境上一次部署的分支一致
+	lastTaskBranch, err := s.GetLatestDeploySuccessTaskBranch(ctx, createReq)
+	if err != nil {
+		return err
+	}
+
+	_, _, currTaskBranch, err := s.ExtraInfoFromImageVersion(createReq.Param.ImageVersion)
+	if err != nil {
+		return err
+	}
+
+	if lastTaskBranch != "" && lastTaskBranch != currTaskBranch {
+		return errors.Wrapf(_errcode.CurrBranchNotMatchExpectBranchError,
+			"%s is not the expected branch %s of %s", currTaskBranch, lastTaskBranch, createReq.EnvName)
+	}
+
+	return nil
+}
+
+// isPipelineStageRejectedError 阶段任务校验未通过, 重试也无法通过
+func isPipelineStageRejectedError(err error) bool {
+	return errcode.EqualError(errcode.InvalidParams, err) ||
+		errcode.EqualError(_errcode.GitlabUserNoPermissionError, err) ||
+		errcode.EqualError(_errcode.CreateTaskNoPermissionError, err) ||
+		errcode.EqualError(_errcode.InvalidImageVersionError, err) ||
+		errcode.EqualError(_errcode.CurrBranchNotMatchExpectBranchError, err)
+}
+
+// withPipelineOperator 钉钉审批需要发起人的内部用户信息
+func (s *Service) withPipelineOperator(ctx context.Context, operatorID string) (context.Context, error) {
+	operator, err := s.GetUserInfo(ctx, operatorID)
+	if err != nil {
+		return nil, err
+	}
+
+	internalUser, err := s.GetInternalSingleUser(ctx, &req.GetInternalUsersReq{
+		Email: operator.Email,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return context.WithValue(ctx, utils.ContextInternalUserKey, *internalUser), nil
+}
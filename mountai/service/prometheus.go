@@ -33,9 +33,14 @@ func (s *Service) renderAndGetPrometheusData(ctx context.Context, envName entity
 		return 0, err
 	}
 
+	return s.getPrometheusScalar(ctx, envName, tpl)
+}
+
+// 查询并解析单值的prometheus数据
+func (s *Service) getPrometheusScalar(ctx context.Context, envName entity.AppEnvName, sql string) (float64, error) {
 	data, err := s.GetPrometheusData(ctx, &req.QueryPrometheusReq{
 		EnvName: envName,
-		SQL:     tpl,
+		SQL:     sql,
 	})
 	if err != nil {
 		return 0, err
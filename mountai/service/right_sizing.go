@@ -0,0 +1,296 @@
+package service
+
+import (
+	"rulai/config"
+	"rulai/models/entity"
+	"rulai/models/req"
+	"rulai/models/resp"
+	"rulai/utils"
+	_errcode "rulai/utils/errcode"
+
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/pkg/errors"
+	"gitlab.shanhai.int/sre/library/base/deepcopy.v2"
+	"gitlab.shanhai.int/sre/library/log"
+	"gitlab.shanhai.int/sre/library/net/errcode"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	rightSizingCPUUsageTplPath = "./template/prometheus/sql/RightSizingCpuUsage.sql"
+	rightSizingMemUsageTplPath = "./template/prometheus/sql/RightSizingMemUsage.sql"
+)
+
+// GenerateRightSizingRecommendations 为近期部署过的应用环境集群生成资源规格推荐
+func (s *Service) GenerateRightSizingRecommendations(ctx context.Context) error {
+	lookback := entity.RightSizingDefaultLookback
+	if conf := config.Conf.RightSizing; conf != nil && conf.Lookback > 0 {
+		lookback = time.Duration(conf.Lookback)
+	}
+
+	tasks, err := s.GetTasks(ctx, &req.GetTasksReq{
+		ActionList:   entity.TaskActionFinalVersionList,
+		StatusList:   entity.TaskStatusSuccessStateList,
+		MinTimestamp: int(time.Now().Add(-lookback).Unix()),
+	})
+	if err != nil {
+		return err
+	}
+
+	apps := make(map[string]*resp.AppDetailResp)
+	projects := make(map[string]*resp.ProjectDetailResp)
+	generated := make(map[string]bool)
+	for _, task := range tasks {
+		key := fmt.Sprintf("%s-%s-%s", task.AppID, task.EnvName, task.ClusterName)
+		if generated[key] {
+			continue
+		}
+		generated[key] = true
+
+		app, ok := apps[task.AppID]
+		if !ok {
+			app, err = s.GetAppDetail(ctx, task.AppID)
+			if err != nil {
+				log.Errorc(ctx, "get app(%s) of right sizing error: %s", task.AppID, err)
+				continue
+			}
+			apps[task.AppID] = app
+		}
+
+		// 仅常驻运行的应用需要推荐规格
+		if app.Type != entity.AppTypeService && app.Type != entity.AppTypeWorker {
+			continue
+		}
+
+		project, ok := projects[app.ProjectID]
+		if !ok {
+			project, err = s.GetProjectDetail(ctx, app.ProjectID)
+			if err != nil {
+				log.Errorc(ctx, "get project(%s) of right sizing error: %s", app.ProjectID, err)
+				continue
+			}
+			projects[app.ProjectID] = project
+		}
+
+		err = s.generateRightSizingRecommendation(ctx, project, app, task.EnvName, task.ClusterName)
+		if err != nil {
+			log.Errorc(ctx, "generate right sizing recommendation of app(%s) env(%s) cluster(%s) error: %s",
+				app.ID, task.EnvName, task.ClusterName, err)
+		}
+	}
+
+	return nil
+}
+
+// generateRightSizingRecommendation 根据最新部署参数及资源使用量生成推荐, 无使用量数据时跳过
+func (s *Service) generateRightSizingRecommendation(ctx context.Context, project *resp.ProjectDetailResp,
+	app *resp.AppDetailResp, envName entity.AppEnvName, clusterName entity.ClusterName) error {
+	spec, ok := project.ResourceSpec[envName]
+	if !ok {
+		return errors.Wrapf(errcode.InvalidParams, "resource spec of env(%s) is not found", envName)
+	}
+
+	task, err := s.GetLatestDeploySuccessTaskFinalVersion(ctx, &req.GetLatestTaskReq{
+		AppID:       app.ID,
+		EnvName:     envName,
+		ClusterName: clusterName,
+	})
+	if err != nil {
+		return err
+	}
+
+	countTime, headroom := entity.RightSizingDefaultCountTime, entity.RightSizingDefaultHeadroom
+	if conf := config.Conf.RightSizing; conf != nil {
+		if conf.CountTime != "" {
+			countTime = conf.CountTime
+		}
+		if conf.Headroom > 0 {
+			headroom = conf.Headroom
+		}
+	}
+
+	labelName := config.Conf.Prometheus.StgContainerLabelName
+	if envName == entity.AppEnvPrd || envName == entity.AppEnvPre {
+		labelName = config.Conf.Prometheus.PrdContainerLabelName
+	}
+	tplReq := &entity.RightSizingUsageTemplate{
+		Namespace:          fmt.Sprintf("%s|%s%s", envName, entity.IstioNamespacePrefix, envName),
+		ContainerLabelName: labelName,
+		ContainerName:      utils.GetPodContainerName(project.Name, app.Name),
+		CountTime:          countTime,
+	}
+
+	recommendation := &entity.RightSizingRecommendation{
+		ProjectID:   project.ID,
+		AppID:       app.ID,
+		EnvName:     envName,
+		ClusterName: clusterName,
+		TaskID:      task.ID,
+		PodCount:    task.Param.MinPodCount,
+		CountTime:   countTime,
+		CPURequest:  task.Param.CPURequest,
+		CPULimit:    task.Param.CPULimit,
+		MemRequest:  task.Param.MemRequest,
+		MemLimit:    task.Param.MemLimit,
+	}
+
+	usages := []struct {
+		tplPath  string
+		quantile float64
+		value    *float64
+	}{
+		{rightSizingCPUUsageTplPath, entity.RightSizingUsageQuantile, &recommendation.CPUUsageP95},
+		{rightSizingCPUUsageTplPath, 1, &recommendation.CPUUsageMax},
+		{rightSizingMemUsageTplPath, entity.RightSizingUsageQuantile, &recommendation.MemUsageP95},
+		{rightSizingMemUsageTplPath, 1, &recommendation.MemUsageMax},
+	}
+	for _, usage := range usages {
+		tplReq.Quantile = usage.quantile
+		res, e := s.renderAndGetPrometheusData(ctx, envName, usage.tplPath, tplReq)
+		// 没有使用量数据时不做推荐
+		if errcode.EqualError(_errcode.PrometheusQueryEmptyError, e) {
+			log.Infoc(ctx, "no usage data of app(%s) env(%s), skip right sizing", app.ID, envName)
+			return nil
+		}
+		if e != nil {
+			return e
+		}
+		if math.IsNaN(res) || math.IsInf(res, 0) {
+			return nil
+		}
+		*usage.value = res
+	}
+
+	cpuRequest, err := recommendation.CPURequest.Cores()
+	if err != nil {
+		return err
+	}
+	cpuLimit, err := recommendation.CPULimit.Cores()
+	if err != nil {
+		return err
+	}
+	memRequest, err := recommendation.MemRequest.Bytes()
+	if err != nil {
+		return err
+	}
+	memLimit, err := recommendation.MemLimit.Bytes()
+	if err != nil {
+		return err
+	}
+	recommendation.CPUP95RequestRate = recommendation.CPUUsageP95 / cpuRequest
+	recommendation.CPUMaxLimitRate = recommendation.CPUUsageMax / cpuLimit
+	recommendation.MemP95RequestRate = recommendation.MemUsageP95 / memRequest
+	recommendation.MemMaxLimitRate = recommendation.MemUsageMax / memLimit
+
+	// cpu 可压缩, 按 p95 推荐; 内存不可压缩, 按最大使用量推荐
+	recommendation.RecommendedCPURequest, err = entity.RecommendCPURequest(spec.CPURequestList,
+		recommendation.CPUUsageP95, headroom, recommendation.CPULimit)
+	if err != nil {
+		return err
+	}
+	recommendation.RecommendedMemRequest, err = entity.RecommendMemRequest(spec.MemRequestList,
+		recommendation.MemUsageMax, headroom, recommendation.MemLimit)
+	if err != nil {
+		return err
+	}
+
+	recommendedCPU, err := recommendation.RecommendedCPURequest.Cores()
+	if err != nil {
+		return err
+	}
+	recommendedMem, err := recommendation.RecommendedMemRequest.Bytes()
+	if err != nil {
+		return err
+	}
+	if conf := config.Conf.RightSizing; conf != nil {
+		if price, ok := conf.Prices[string(envName)]; ok {
+			recommendation.MonthlyCostDelta = entity.EstimateMonthlyCostDelta(recommendedCPU-cpuRequest,
+				recommendedMem-memRequest, recommendation.PodCount, price.CPUCoreMonthly, price.MemGiBMonthly)
+		}
+	}
+
+	now := time.Now()
+	recommendation.ID, recommendation.CreateTime, recommendation.UpdateTime = primitive.NewObjectID(), &now, &now
+	existed, err := s.dao.FindSingleRightSizingRecommendation(ctx, bson.M{
+		"app_id":       app.ID,
+		"env_name":     envName,
+		"cluster_name": clusterName,
+	})
+	if err == nil {
+		recommendation.ID, recommendation.CreateTime = existed.ID, existed.CreateTime
+	} else if !errcode.EqualError(errcode.NoRowsFoundError, err) {
+		return err
+	}
+
+	return s.dao.UpsertRightSizingRecommendation(ctx, recommendation)
+}
+
+// GetRightSizingReport 获取资源规格推荐报告, 按每月节省费用排序
+func (s *Service) GetRightSizingReport(ctx context.Context,
+	getReq *req.GetRightSizingReportReq) ([]*resp.RightSizingRecommendationDetail, int, error) {
+	filter := bson.M{}
+	if getReq.ProjectID != "" {
+		filter["project_id"] = getReq.ProjectID
+	}
+	if getReq.AppID != "" {
+		filter["app_id"] = getReq.AppID
+	}
+	if getReq.EnvName != "" {
+		filter["env_name"] = getReq.EnvName
+	}
+	if getReq.ClusterName != "" {
+		filter["cluster_name"] = getReq.ClusterName
+	}
+	if getReq.OnlyResize {
+		filter["$expr"] = bson.M{
+			"$or": bson.A{
+				bson.M{"$ne": bson.A{"$recommended_cpu_request", "$cpu_request"}},
+				bson.M{"$ne": bson.A{"$recommended_mem_request", "$mem_request"}},
+			},
+		}
+	}
+
+	limit := int64(getReq.Limit)
+	skip := int64(getReq.Page-1) * limit
+
+	recommendations, err := s.dao.FindRightSizingRecommendations(ctx, filter, &options.FindOptions{
+		Limit: &limit,
+		Skip:  &skip,
+		Sort:  bson.D{{Key: "monthly_cost_delta", Value: 1}, {Key: "_id", Value: 1}},
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	count, err := s.dao.CountRightSizingRecommendations(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	res := make([]*resp.RightSizingRecommendationDetail, len(recommendations))
+	for i, recommendation := range recommendations {
+		res[i] = new(resp.RightSizingRecommendationDetail)
+		err = deepcopy.Copy(recommendation).To(res[i])
+		if err != nil {
+			return nil, 0, errors.Wrap(errcode.InternalError, err.Error())
+		}
+		res[i].NeedResize = recommendation.NeedResize()
+	}
+
+	return res, count, nil
+}
+
+func (s *Service) FindSingleRightSizingRecommendationByID(ctx context.Context, id string) (*entity.RightSizingRecommendation, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.Wrap(_errcode.InvalidHexStringError, err.Error())
+	}
+
+	return s.dao.FindSingleRightSizingRecommendation(ctx, bson.M{"_id": objectID})
+}
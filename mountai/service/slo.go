@@ -0,0 +1,264 @@
+package service
+
+import (
+	"rulai/dao"
+	"rulai/models/entity"
+	"rulai/models/req"
+	"rulai/models/resp"
+	_errcode "rulai/utils/errcode"
+
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/pkg/errors"
+	"gitlab.shanhai.int/sre/library/base/deepcopy.v2"
+	"gitlab.shanhai.int/sre/library/log"
+	"gitlab.shanhai.int/sre/library/net/errcode"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	sloAvailabilityTplPath = "./template/prometheus/sql/SLOAvailability.sql"
+	sloLatencyTplPath      = "./template/prometheus/sql/SLOLatency.sql"
+	sloCustomQueryTmpName  = "sloErrorRatioQuery"
+)
+
+func (s *Service) GetAppSLOs(ctx context.Context, appID string, envName entity.AppEnvName) ([]*entity.SLO, error) {
+	return s.dao.FindSLOs(ctx, bson.M{
+		"app_id":   appID,
+		"env_name": envName,
+	}, dao.MongoFindOptionWithSortByIDAsc)
+}
+
+func (s *Service) CountAppSLOs(ctx context.Context, appID string, envName entity.AppEnvName) (int, error) {
+	return s.dao.CountSLOs(ctx, bson.M{
+		"app_id":   appID,
+		"env_name": envName,
+	})
+}
+
+func (s *Service) FindSingleSLOByID(ctx context.Context, id string) (*entity.SLO, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.Wrap(_errcode.InvalidHexStringError, err.Error())
+	}
+
+	return s.dao.FindSingleSLO(ctx, bson.M{"_id": objectID})
+}
+
+func (s *Service) CreateSLO(ctx context.Context, createReq *req.CreateSLOReq) error {
+	now := time.Now()
+	slo := &entity.SLO{
+		ID:                 primitive.NewObjectID(),
+		AppID:              createReq.AppID,
+		EnvName:            createReq.EnvName,
+		Name:               createReq.Name,
+		Type:               createReq.Type,
+		Objective:          createReq.Objective,
+		WindowDays:         createReq.WindowDays,
+		LatencyThresholdMs: createReq.LatencyThresholdMs,
+		ErrorRatioQuery:    createReq.ErrorRatioQuery,
+		OperatorID:         createReq.OperatorID,
+		CreateTime:         &now,
+		UpdateTime:         &now,
+	}
+	if slo.WindowDays == 0 {
+		slo.WindowDays = entity.SLODefaultWindowDays
+	}
+
+	return s.dao.CreateSingleSLO(ctx, slo)
+}
+
+func (s *Service) UpdateSLO(ctx context.Context, id string, updateReq *req.UpdateSLOReq) error {
+	changeMap := make(map[string]interface{})
+	changeMap["update_time"] = time.Now()
+
+	if updateReq.Name != "" {
+		changeMap["name"] = updateReq.Name
+	}
+
+	if updateReq.Objective != 0 {
+		changeMap["objective"] = updateReq.Objective
+	}
+
+	if updateReq.WindowDays != 0 {
+		changeMap["window_days"] = updateReq.WindowDays
+	}
+
+	if updateReq.LatencyThresholdMs != 0 {
+		changeMap["latency_threshold_ms"] = updateReq.LatencyThresholdMs
+	}
+
+	// 允许更新为空, 表示改回内置查询
+	if updateReq.ErrorRatioQuery.Valid {
+		changeMap["error_ratio_query"] = updateReq.ErrorRatioQuery.ValueOrZero()
+	}
+
+	return s.dao.UpdateSingleSLO(ctx, id, bson.M{
+		"$set": changeMap,
+	})
+}
+
+func (s *Service) DeleteSLOByID(ctx context.Context, id string) error {
+	return s.dao.DeleteSingleSLOByID(ctx, id)
+}
+
+// getSLOErrorRatio 查询 SLO 在指定窗口内的错误率, 无流量时视为0
+func (s *Service) getSLOErrorRatio(ctx context.Context, slo *entity.SLO, project *resp.ProjectDetailResp,
+	app *resp.AppDetailResp, window time.Duration) (float64, error) {
+	podName := fmt.Sprintf("%s-%s", project.Name, app.Name)
+	tplReq := &entity.SLOMetricTemplate{
+		// 同时统计开启与未开启 istio 的命名空间
+		Namespace: fmt.Sprintf("%s|%s%s", slo.EnvName, entity.IstioNamespacePrefix, slo.EnvName),
+		// 包含金丝雀版本的 pod
+		PodRegex:           fmt.Sprintf("%s(%s)?-[a-z0-9]+-[a-z0-9]+", podName, entity.TaskCanaryVersionSuffix),
+		Window:             entity.FormatPromDuration(window),
+		LatencyThresholdMs: slo.LatencyThresholdMs,
+	}
+
+	var (
+		res float64
+		err error
+	)
+	switch {
+	case slo.ErrorRatioQuery != "":
+		tpl, e := s.RenderTemplateFromText(tplReq, sloCustomQueryTmpName, slo.ErrorRatioQuery)
+		if e != nil {
+			return 0, e
+		}
+		res, err = s.getPrometheusScalar(ctx, slo.EnvName, tpl)
+	case slo.Type == entity.SLOTypeLatency:
+		res, err = s.renderAndGetPrometheusData(ctx, slo.EnvName, sloLatencyTplPath, tplReq)
+	default:
+		res, err = s.renderAndGetPrometheusData(ctx, slo.EnvName, sloAvailabilityTplPath, tplReq)
+	}
+	// 查不到则认为没有流量
+	if errcode.EqualError(_errcode.PrometheusQueryEmptyError, err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	// 无请求时错误率为 NaN
+	if math.IsNaN(res) || math.IsInf(res, 0) {
+		return 0, nil
+	}
+	return res, nil
+}
+
+// evaluateSLO 计算单个 SLO 的错误预算状态, 查询失败时记录原因且不视为耗尽
+func (s *Service) evaluateSLO(ctx context.Context, slo *entity.SLO, project *resp.ProjectDetailResp,
+	app *resp.AppDetailResp) (*resp.SLOStatusResp, error) {
+	res := new(resp.SLOStatusResp)
+	err := deepcopy.Copy(slo).To(res)
+	if err != nil {
+		return nil, errors.Wrap(errcode.InternalError, err.Error())
+	}
+
+	errorRatio, err := s.getSLOErrorRatio(ctx, slo, project, app, slo.Window())
+	if err != nil {
+		log.Errorc(ctx, "get slo(%s) error ratio of app(%s) error: %s", slo.ID.Hex(), app.ID, err)
+		res.Error = err.Error()
+		return res, nil
+	}
+
+	res.ErrorRatio = errorRatio
+	res.RemainingBudget = slo.RemainingBudget(errorRatio)
+	res.Exhausted = res.RemainingBudget <= 0
+
+	res.BurnRates = make([]*resp.SLOBurnRateResp, 0, len(entity.SLOBurnRateWindows))
+	for _, window := range entity.SLOBurnRateWindows {
+		if window >= slo.Window() {
+			continue
+		}
+
+		ratio, e := s.getSLOErrorRatio(ctx, slo, project, app, window)
+		if e != nil {
+			log.Errorc(ctx, "get slo(%s) burn rate of app(%s) error: %s", slo.ID.Hex(), app.ID, e)
+			res.Error = e.Error()
+			continue
+		}
+
+		res.BurnRates = append(res.BurnRates, &resp.SLOBurnRateResp{
+			Window:     entity.FormatPromDuration(window),
+			ErrorRatio: ratio,
+			BurnRate:   slo.BurnRate(ratio),
+		})
+	}
+
+	return res, nil
+}
+
+// GetAppSLOStatus 获取应用在指定环境的 SLO 及错误预算状态
+func (s *Service) GetAppSLOStatus(ctx context.Context, project *resp.ProjectDetailResp,
+	app *resp.AppDetailResp, envName entity.AppEnvName) (*resp.AppSLOResp, error) {
+	slos, err := s.GetAppSLOs(ctx, app.ID, envName)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &resp.AppSLOResp{
+		AppID:   app.ID,
+		EnvName: envName,
+		SLOs:    make([]*resp.SLOStatusResp, 0, len(slos)),
+	}
+	for _, slo := range slos {
+		status, e := s.evaluateSLO(ctx, slo, project, app)
+		if e != nil {
+			return nil, e
+		}
+
+		if status.Exhausted {
+			res.BudgetExhausted = true
+		}
+		res.SLOs = append(res.SLOs, status)
+	}
+
+	return res, nil
+}
+
+// CheckTaskErrorBudget 检查生产环境全量部署时应用的错误预算
+// 错误预算耗尽时需要项目负责人填写原因强制部署, 指标查询失败时不拦截
+func (s *Service) CheckTaskErrorBudget(ctx context.Context, project *resp.ProjectDetailResp, app *resp.AppDetailResp,
+	createReq *req.CreateTaskReq, operatorID string) error {
+	if createReq.Action != entity.TaskActionFullDeploy || createReq.EnvName != entity.AppEnvPrd {
+		return nil
+	}
+
+	status, err := s.GetAppSLOStatus(ctx, project, app, createReq.EnvName)
+	if err != nil {
+		return err
+	}
+
+	if !status.BudgetExhausted {
+		return nil
+	}
+
+	if createReq.ErrorBudgetOverrideReason == "" {
+		for _, slo := range status.SLOs {
+			if slo.Exhausted {
+				return errors.Wrapf(_errcode.ErrorBudgetExhaustedError,
+					"slo(%s) error budget is exhausted, override reason is required", slo.Name)
+			}
+		}
+	}
+
+	isOwner := operatorID == entity.K8sSystemUserID
+	for _, owner := range project.Owners {
+		if owner.ID == operatorID {
+			isOwner = true
+			break
+		}
+	}
+	if !isOwner {
+		return errors.Wrap(_errcode.CreateTaskNoPermissionError, "no permission to override exhausted error budget")
+	}
+
+	log.Warnc(ctx, "app(%s) deploys with exhausted error budget, operator: %s, reason: %s",
+		app.ID, operatorID, createReq.ErrorBudgetOverrideReason)
+
+	return nil
+}
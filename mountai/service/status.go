@@ -55,6 +55,8 @@ func (s *Service) getPodMonitorURL(namespace string, appType entity.AppType, ver
 		workerType = "cronjob"
 	} else if appType == entity.AppTypeOneTimeJob {
 		workerType = "job"
+	} else if wt, ok := entity.GetWorkloadType(appType); ok {
+		workerType = wt.MonitorType
 	}
 
 	dataSource := config.Conf.K8s.PrdContextName
@@ -513,6 +515,10 @@ func (s *Service) GetRunningStatusJobList(ctx context.Context,
 func (s *Service) GetRunningStatusList(ctx context.Context,
 	clusterName entity.ClusterName, getReq *req.GetRunningStatusListReq,
 	project *resp.ProjectDetailResp, app *resp.AppDetailResp) ([]*resp.RunningStatusListResp, error) {
+	if w, _, ok := s.getWorkload(app.Type); ok {
+		return s.GetRunningStatusWorkloadList(ctx, clusterName, getReq, project, app, w)
+	}
+
 	if app.Type == entity.AppTypeWorker || app.Type == entity.AppTypeService {
 		return s.GetRunningStatusDeploymentList(ctx, clusterName, getReq, project, app)
 	} else if app.Type == entity.AppTypeCronJob {
@@ -587,10 +593,7 @@ func (s *Service) GetRunningStatusDeploymentDetail(ctx context.Context, getReq *
 	for i := range pods {
 		pod := pods[i]
 
-		restart := 0
-		if len(pod.Status.ContainerStatuses) > 0 {
-			restart = int(pod.Status.ContainerStatuses[0].RestartCount)
-		}
+		restart := getPodMainContainerRestartCount(&pod)
 
 		podResp := &resp.RunningStatusPodDetailResp{
 			Name:         pod.GetName(),
@@ -601,6 +604,7 @@ func (s *Service) GetRunningStatusDeploymentDetail(ctx context.Context, getReq *
 			NodeIP:       pod.Status.HostIP,
 			PodIP:        pod.Status.PodIP,
 			Namespace:    pod.GetNamespace(),
+			Containers:   getPodContainerStatuses(&pod),
 		}
 		if pod.Status.StartTime != nil {
 			podResp.Age = time.Since(pod.Status.StartTime.Time).Round(time.Second).String()
@@ -625,6 +629,218 @@ func (s *Service) GetRunningStatusDeploymentDetail(ctx context.Context, getReq *
 	return res, nil
 }
 
+// GetRunningStatusWorkloadList 获取部署过程中的可插拔工作负载(StatefulSet/DaemonSet)列表
+func (s *Service) GetRunningStatusWorkloadList(ctx context.Context,
+	clusterName entity.ClusterName, getReq *req.GetRunningStatusListReq,
+	project *resp.ProjectDetailResp, app *resp.AppDetailResp, w workload) ([]*resp.RunningStatusListResp, error) {
+	// 获取所有尚未完成的部署任务
+	deployTasks, err := s.GetTasks(ctx, &req.GetTasksReq{
+		AppID:             app.ID,
+		EnvName:           getReq.EnvName,
+		ClusterName:       clusterName,
+		ActionList:        entity.TaskActionInitDeployList,
+		StatusInverseList: entity.TaskStatusFinalStateList,
+		Suspend:           null.BoolFrom(false),
+	})
+	if err != nil {
+		return nil, err
+	}
+	// 默认每个任务未创建工作负载
+	taskCheckMap := make(map[string]bool)
+	for _, task := range deployTasks {
+		taskCheckMap[task.Version] = false
+	}
+
+	// 获取已经创建的工作负载
+	workloads, err := w.List(ctx, clusterName, &req.GetWorkloadsReq{
+		Namespace:   getReq.Namespace,
+		ProjectName: project.Name,
+		AppName:     app.Name,
+		Env:         string(getReq.EnvName),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	createdList := make([]*resp.RunningStatusListResp, len(workloads))
+	for i, status := range workloads {
+		// 获取每个版本的上一次任务
+		task, e := s.GetSingleTask(ctx, &req.GetTasksReq{
+			AppID:             app.ID,
+			EnvName:           getReq.EnvName,
+			ClusterName:       clusterName,
+			Version:           status.Name,
+			ActionInverseList: entity.TaskActionSystemList,
+		})
+		if e != nil {
+			return nil, e
+		}
+		// 获取上次部署的任务
+		lastDeployTask, e := s.GetLatestDeploySuccessTaskFinalVersion(ctx, &req.GetLatestTaskReq{
+			AppID:        getReq.AppID,
+			EnvName:      getReq.EnvName,
+			ClusterName:  clusterName,
+			Version:      status.Name,
+			IgnoreStatus: true,
+		})
+		if e != nil {
+			return nil, e
+		}
+
+		monitorURL, e := s.getPodMonitorURL(status.Namespace, app.Type, status.Name, clusterName)
+		if e != nil {
+			return nil, e
+		}
+
+		createdList[i] = &resp.RunningStatusListResp{
+			Version:           status.Name,
+			CreateTime:        lastDeployTask.CreateTime,
+			TaskID:            task.ID,
+			TaskStatus:        string(task.Status),
+			TaskStatusDisplay: task.StatusDisplay,
+			TaskDisplayIcon:   task.DisplayIcon,
+			TaskRetryCount:    task.RetryCount,
+			TaskSuspend:       task.Suspend,
+			PodMonitorURL:     monitorURL,
+			ConfigURL:         lastDeployTask.Param.ConfigURL,
+			ReadyPodCount:     status.ReadyReplicas,
+			TotalPodCount:     status.DesiredReplicas,
+			ImageVersion:      status.Image,
+			Namespace:         status.Namespace,
+		}
+		// 修改校验map
+		taskCheckMap[status.Name] = true
+	}
+
+	// 仍未创建工作负载的部署任务，添加至结果集
+	res := make([]*resp.RunningStatusListResp, 0)
+	for _, lastDeployTask := range deployTasks {
+		if taskCheckMap[lastDeployTask.Version] {
+			continue
+		}
+
+		// 获取每个版本的上一次任务
+		task, e := s.GetSingleTask(ctx, &req.GetTasksReq{
+			AppID:             app.ID,
+			EnvName:           getReq.EnvName,
+			ClusterName:       clusterName,
+			Version:           lastDeployTask.Version,
+			ActionInverseList: entity.TaskActionSystemList,
+		})
+		if e != nil {
+			return nil, e
+		}
+
+		res = append(res, &resp.RunningStatusListResp{
+			Version:           lastDeployTask.Version,
+			CreateTime:        lastDeployTask.CreateTime,
+			TaskID:            task.ID,
+			TaskStatus:        string(task.Status),
+			TaskStatusDisplay: task.StatusDisplay,
+			TaskDisplayIcon:   task.DisplayIcon,
+			TaskRetryCount:    task.RetryCount,
+			TaskSuspend:       task.Suspend,
+			ConfigURL:         lastDeployTask.Param.ConfigURL,
+			ReadyPodCount:     0,
+			TotalPodCount:     lastDeployTask.Param.MinPodCount,
+			ImageVersion:      lastDeployTask.Param.ImageVersion,
+			Namespace:         task.Namespace,
+		})
+	}
+	res = append(res, createdList...)
+
+	failedStatus, err := s.getLatestFailedRunningStatus(ctx, clusterName, getReq.EnvName, app.ID, app.Type, string(getReq.EnvName))
+	if err != nil {
+		return nil, err
+	}
+	if failedStatus != nil {
+		res = append([]*resp.RunningStatusListResp{failedStatus}, res...)
+	}
+
+	return res, nil
+}
+
+// GetRunningStatusWorkloadDetail 获取部署过程中的可插拔工作负载(StatefulSet/DaemonSet)详情
+func (s *Service) GetRunningStatusWorkloadDetail(ctx context.Context, getReq *req.GetRunningStatusDetailReq,
+	app *resp.AppDetailResp, lastTask, lastDeployTask *resp.TaskDetailResp, w workload) (*resp.RunningStatusDetailResp, error) {
+	res := &resp.RunningStatusDetailResp{
+		TaskID:            lastTask.ID,
+		TaskStatus:        string(lastTask.Status),
+		TaskStatusDisplay: lastTask.StatusDisplay,
+		TaskDisplayIcon:   lastTask.DisplayIcon,
+		TaskDetail:        lastTask.Detail,
+		TaskRetryCount:    lastTask.RetryCount,
+		TaskSuspend:       lastTask.Suspend,
+		ConfigURL:         lastDeployTask.Param.ConfigURL,
+		DeploymentPods:    make([]*resp.RunningStatusPodDetailResp, 0),
+		DeployType:        lastTask.DeployType,
+		ScheduleTime:      lastTask.ScheduleTime,
+		Approval:          lastTask.Approval,
+	}
+
+	status, err := w.GetStatus(ctx, getReq.ClusterName, &req.GetWorkloadDetailReq{
+		Env:       string(getReq.EnvName),
+		Name:      getReq.Version,
+		Namespace: getReq.Namespace,
+	})
+	if err != nil {
+		// 仍未创建工作负载时，使用部署任务参数
+		if errcode.EqualError(_errcode.K8sResourceNotFoundError, err) &&
+			lastDeployTask.Status != entity.TaskStatusSuccess &&
+			lastDeployTask.Status != entity.TaskStatusFail {
+			res.Version = lastDeployTask.Version
+			res.CreateTime = lastDeployTask.CreateTime
+			res.ReadyPodCount = 0
+			res.TotalPodCount = lastDeployTask.Param.MinPodCount
+			return res, nil
+		}
+		return nil, err
+	}
+
+	res.Version = status.Name
+	res.CreateTime = status.CreateTime.Format(utils.DefaultTimeFormatLayout)
+	res.PodMonitorURL, err = s.getPodMonitorURL(status.Namespace, app.Type, status.Name, getReq.ClusterName)
+	if err != nil {
+		return nil, err
+	}
+	res.ReadyPodCount = status.ReadyReplicas
+	res.TotalPodCount = status.DesiredReplicas
+	res.ImageVersion = status.Image
+
+	pods, err := s.GetPods(ctx, getReq.ClusterName,
+		&req.GetPodsReq{
+			Namespace: status.Namespace,
+			Env:       string(getReq.EnvName),
+			Version:   getReq.Version,
+		})
+	if err != nil {
+		return nil, err
+	}
+	for i := range pods {
+		pod := pods[i]
+
+		restart := getPodMainContainerRestartCount(&pod)
+
+		podResp := &resp.RunningStatusPodDetailResp{
+			Name:         pod.GetName(),
+			CreateTime:   pod.GetCreationTimestamp().Format(utils.DefaultTimeFormatLayout),
+			RestartCount: restart,
+			ShellURL:     s.getPodShellURL(pod.GetNamespace(), pod.GetName()),
+			Phase:        pod.Status.Phase,
+			NodeIP:       pod.Status.HostIP,
+			PodIP:        pod.Status.PodIP,
+			Namespace:    pod.GetNamespace(),
+			Containers:   getPodContainerStatuses(&pod),
+		}
+		if pod.Status.StartTime != nil {
+			podResp.Age = time.Since(pod.Status.StartTime.Time).Round(time.Second).String()
+		}
+		res.DeploymentPods = append(res.DeploymentPods, podResp)
+	}
+
+	return res, nil
+}
+
 // GetVersionAllowedActions 用于返回给前端某个具体 version 有哪些允许的操作，三个部署操作是否允许暂不考虑
 func (s *Service) GetVersionAllowedActions(ctx context.Context, app *resp.AppDetailResp, lastTask *resp.TaskDetailResp) (
 	map[entity.TaskAction]bool, error) {
@@ -659,6 +875,12 @@ func (s *Service) GetVersionAllowedActions(ctx context.Context, app *resp.AppDet
 		allowedActions[entity.TaskActionDelete] = true
 		allowedActions[entity.TaskActionUpdateHPA] = true
 		allowedActions[entity.TaskActionReloadConfig] = true
+		allowedActions[entity.TaskActionRollback] = true
+		// 蓝绿部署后旧版本保留期内允许切回
+		if app.Type == entity.AppTypeService && lastTask.PreviousVersion != "" &&
+			entity.TaskActionBlueGreenList.Contains(lastTask.Action) {
+			allowedActions[entity.TaskActionBlueGreenSwitchBack] = true
+		}
 	case entity.AppTypeCronJob:
 		if lastTask.Action == entity.TaskActionStop {
 			allowedActions[entity.TaskActionResume] = true
@@ -669,13 +891,27 @@ func (s *Service) GetVersionAllowedActions(ctx context.Context, app *resp.AppDet
 		allowedActions[entity.TaskActionManualLaunch] = true
 	case entity.AppTypeOneTimeJob:
 		allowedActions[entity.TaskActionDelete] = true
+	default:
+		// 可插拔工作负载按其支持的行为允许操作
+		if wt, ok := entity.GetWorkloadType(app.Type); ok {
+			if wt.SupportsAction(entity.TaskActionStop) {
+				if lastTask.Action == entity.TaskActionStop {
+					allowedActions[entity.TaskActionResume] = true
+				} else {
+					allowedActions[entity.TaskActionStop] = true
+				}
+			}
+			allowedActions[entity.TaskActionRestart] = wt.SupportsAction(entity.TaskActionRestart)
+			allowedActions[entity.TaskActionDelete] = wt.SupportsAction(entity.TaskActionDelete)
+		}
 	}
 
 	// 根据当前的 task 状态，不允许一些操作
 	switch lastTask.Action {
 	case entity.TaskActionFullDeploy, entity.TaskActionCanaryDeploy, entity.TaskActionFullCanaryDeploy,
 		entity.TaskActionRestart, entity.TaskActionResume, entity.TaskActionManualLaunch, entity.TaskActionUpdateHPA,
-		entity.TaskActionReloadConfig:
+		entity.TaskActionReloadConfig, entity.TaskActionRollback, entity.TaskActionBlueGreenDeploy,
+		entity.TaskActionBlueGreenSwitchBack:
 		if lastTask.Suspend {
 			allowedActions[entity.TaskActionStop] = false
 			allowedActions[entity.TaskActionRestart] = false
@@ -683,11 +919,15 @@ func (s *Service) GetVersionAllowedActions(ctx context.Context, app *resp.AppDet
 			allowedActions[entity.TaskActionManualLaunch] = false
 			allowedActions[entity.TaskActionUpdateHPA] = false
 			allowedActions[entity.TaskActionReloadConfig] = false
+			allowedActions[entity.TaskActionRollback] = false
+			allowedActions[entity.TaskActionBlueGreenSwitchBack] = false
 		}
 	case entity.TaskActionStop:
 		allowedActions[entity.TaskActionStop] = false
 		allowedActions[entity.TaskActionUpdateHPA] = false
 		allowedActions[entity.TaskActionReloadConfig] = false
+		allowedActions[entity.TaskActionRollback] = false
+		allowedActions[entity.TaskActionBlueGreenSwitchBack] = false
 
 	case entity.TaskActionDelete:
 		allowedActions = s.getDefaultAllowedActions(ctx)
@@ -809,10 +1049,7 @@ func (s *Service) GetRunningStatusCronJobDetail(ctx context.Context, getReq *req
 		for j := range pods {
 			pod := pods[j]
 
-			restart := 0
-			if len(pod.Status.ContainerStatuses) > 0 {
-				restart = int(pod.Status.ContainerStatuses[0].RestartCount)
-			}
+			restart := getPodMainContainerRestartCount(&pod)
 
 			curResp := resp.RunningStatusPodDetailResp{
 				Name:         pod.GetName(),
@@ -823,6 +1060,7 @@ func (s *Service) GetRunningStatusCronJobDetail(ctx context.Context, getReq *req
 				NodeIP:       pod.Status.HostIP,
 				PodIP:        pod.Status.PodIP,
 				Namespace:    pod.GetNamespace(),
+				Containers:   getPodContainerStatuses(&pod),
 			}
 			if pod.Status.StartTime != nil {
 				curResp.Age = time.Since(pod.Status.StartTime.Time).Round(time.Second).String()
@@ -911,10 +1149,7 @@ func (s *Service) GetRunningStatusJobDetail(ctx context.Context, getReq *req.Get
 	for j := range pods {
 		pod := pods[j]
 
-		restart := 0
-		if len(pod.Status.ContainerStatuses) > 0 {
-			restart = int(pod.Status.ContainerStatuses[0].RestartCount)
-		}
+		restart := getPodMainContainerRestartCount(&pod)
 
 		curResp := resp.RunningStatusPodDetailResp{
 			Name:         pod.GetName(),
@@ -925,6 +1160,7 @@ func (s *Service) GetRunningStatusJobDetail(ctx context.Context, getReq *req.Get
 			NodeIP:       pod.Status.HostIP,
 			PodIP:        pod.Status.PodIP,
 			Namespace:    pod.GetNamespace(),
+			Containers:   getPodContainerStatuses(&pod),
 		}
 		if pod.Status.StartTime != nil {
 			curResp.Age = time.Since(pod.Status.StartTime.Time).Round(time.Second).String()
@@ -1007,7 +1243,9 @@ func (s *Service) GetRunningStatusDetail(ctx context.Context, getReq *req.GetRun
 		return nil, errors.Wrap(errcode.InvalidParams, "retrieved lastTask or lastDeployTask is empty")
 	}
 
-	if app.Type == entity.AppTypeService || app.Type == entity.AppTypeWorker {
+	if w, _, ok := s.getWorkload(app.Type); ok {
+		runningStatusRes, err = s.GetRunningStatusWorkloadDetail(ctx, getReq, app, lastTask, lastDeployTask, w)
+	} else if app.Type == entity.AppTypeService || app.Type == entity.AppTypeWorker {
 		runningStatusRes, err = s.GetRunningStatusDeploymentDetail(ctx, getReq, app, lastTask, lastDeployTask)
 	} else if app.Type == entity.AppTypeCronJob {
 		runningStatusRes, err = s.GetRunningStatusCronJobDetail(ctx, getReq, app, lastTask, lastDeployTask)
@@ -1082,6 +1320,20 @@ func (s *Service) CheckRunningStatusExists(ctx context.Context, cluster *resp.Cl
 			if len(jobs) > 0 {
 				return errors.Wrap(errcode.InvalidParams, "存在未删除的任务")
 			}
+		} else if w, _, ok := s.getWorkload(app.Type); ok {
+			list, err := w.List(ctx, cluster.Name,
+				&req.GetWorkloadsReq{
+					Namespace:   string(envName),
+					ProjectName: project.Name,
+					AppName:     app.Name,
+					Env:         string(envName),
+				})
+			if err != nil {
+				return err
+			}
+			if len(list) > 0 {
+				return errors.Wrap(errcode.InvalidParams, "存在未删除的部署")
+			}
 		} else {
 			exists, err := s.CheckDeploymentsExistance(ctx, cluster.Name, envName, &req.GetDeploymentsReq{
 				Namespace:   string(envName),
@@ -1109,6 +1361,9 @@ func (s *Service) getDefaultAllowedActions(_ context.Context) map[entity.TaskAct
 		entity.TaskActionManualLaunch: false,
 		entity.TaskActionUpdateHPA:    false,
 		entity.TaskActionReloadConfig: false,
+		entity.TaskActionRollback:     false,
+
+		entity.TaskActionBlueGreenSwitchBack: false,
 	}
 
 	return defaultAllowedActions
@@ -1229,6 +1484,14 @@ func (s *Service) IsAppK8sPrimaryResourceExist(ctx context.Context, clusterName
 			Name:      version,
 			Env:       string(envName),
 		})
+	default:
+		if w, _, ok := s.getWorkload(appType); ok {
+			_, err = w.GetStatus(ctx, clusterName, &req.GetWorkloadDetailReq{
+				Namespace: namespace,
+				Name:      version,
+				Env:       string(envName),
+			})
+		}
 	}
 	if err != nil {
 		if errcode.EqualError(_errcode.K8sResourceNotFoundError, err) {
@@ -40,14 +40,23 @@ func (s *Service) TransformTaskStatus(ctx context.Context, task *resp.TaskDetail
 		}
 	}()
 
+	// 收集本次流转 apply 的 K8s 对象版本, 用于记录任务事件
+	ctx = withTaskObjectVersions(ctx)
+
 	project, app := new(resp.ProjectDetailResp), new(resp.AppDetailResp)
 	// 更新状态
 	var nextStatus entity.TaskStatus
 	defer func() {
 		if err == nil && nextStatus != "" && nextStatus != task.Status {
-			curError := s.UpdateTask(ctx, project, app, task, &req.UpdateTaskReq{
+			updateReq := &req.UpdateTaskReq{
 				Status: nextStatus,
-			})
+			}
+			// 渐进式金丝雀观察结束时的判定结果与状态一同更新
+			if task.Status == entity.TaskStatusCanaryStepBakeUnderway {
+				updateReq.CanaryVerdicts = task.CanaryVerdicts
+			}
+
+			curError := s.UpdateTask(ctx, project, app, task, updateReq)
 			if curError != nil {
 				err = curError
 				log.Errorc(ctx, "An error occurred during updating task %s: %s.", task.ID, curError.Error())
@@ -73,6 +82,19 @@ func (s *Service) TransformTaskStatus(ctx context.Context, task *resp.TaskDetail
 						" to message queue: %s.", actionType, task.ID, pubErr.Error())
 				}
 			}
+
+			// 全量部署失败时, 按应用环境配置自动回滚至上一个成功版本
+			if curError == nil && nextStatus == entity.TaskStatusFail {
+				rollbackErr := s.AutoRollbackTask(ctx, task)
+				if rollbackErr != nil {
+					log.Errorc(ctx, "An error occurred during auto rollback of task %s: %s.", task.ID, rollbackErr.Error())
+				}
+			}
+		}
+
+		// 记录状态变化及出错事件
+		if err != nil || (nextStatus != "" && nextStatus != task.Status) {
+			s.recordTaskEvent(ctx, project.ID, task, nextStatus, err)
 		}
 		log.Infoc(ctx, "Successfully updated task %s.", task.ID)
 	}()
@@ -129,6 +151,15 @@ func (s *Service) TransformTaskStatus(ctx context.Context, task *resp.TaskDetail
 		task.Namespace = string(task.EnvName)
 	}
 
+	// 可插拔工作负载由统一的状态机处理
+	if w, wt, ok := s.getWorkload(app.Type); ok {
+		nextStatus, err = s.transformWorkloadTaskStatus(ctx, project, app, task, project.Team, w, wt)
+		if err != nil {
+			return err
+		}
+		return nil
+	}
+
 	switch task.Action {
 	case entity.TaskActionManualLaunch:
 		nextStatus, err = s.transformManualLaunchTaskStatus(ctx, project, app, task, project.Team)
@@ -180,6 +211,16 @@ func (s *Service) TransformTaskStatus(ctx context.Context, task *resp.TaskDetail
 		if err != nil {
 			return err
 		}
+	case entity.TaskActionRollback:
+		nextStatus, err = s.transformRollbackTaskStatus(ctx, project, app, task, project.Team)
+		if err != nil {
+			return err
+		}
+	case entity.TaskActionBlueGreenDeploy, entity.TaskActionBlueGreenSwitchBack:
+		nextStatus, err = s.transformBlueGreenTaskStatus(ctx, project, app, task, project.Team)
+		if err != nil {
+			return err
+		}
 	case entity.TaskActionDisableInClusterDNS:
 		nextStatus, err = s.transformInClusterDNSStatus(ctx, project, app, task, project.Team)
 		if err != nil {
@@ -564,7 +605,25 @@ func (s *Service) transformFullDeployTaskStatus(ctx context.Context, project *re
 
 		return entity.TaskStatusSyncColdStorageDeliverTaskFinish, nil
 	// 投递任务同步成功
-	case entity.TaskStatusCreateAliLogConfigFinish:
+	case entity.TaskStatusCreateAliLogConfigFinish, entity.TaskStatusCreateSecretFinish, entity.TaskStatusCreatePVCFinish:
+		// 引用了密钥变量时先创建 Secret
+		if task.Status == entity.TaskStatusCreateAliLogConfigFinish && len(task.Param.SecretVars) > 0 {
+			_, err := s.ApplyTaskSecret(ctx, project, app, task)
+			if err != nil {
+				return "", err
+			}
+			return entity.TaskStatusCreateSecretUnderway, nil
+		}
+
+		// 声明了持久化存储卷时先创建 PVC
+		if task.Status != entity.TaskStatusCreatePVCFinish && hasTaskPVC(task) {
+			err := s.ApplyTaskPVCs(ctx, project, app, task)
+			if err != nil {
+				return "", err
+			}
+			return entity.TaskStatusCreatePVCUnderway, nil
+		}
+
 		if app.Type == entity.AppTypeCronJob {
 			// 渲染模版
 			data, err := s.RenderCronJobTemplate(ctx, project, app, task, team)
@@ -603,6 +662,12 @@ func (s *Service) transformFullDeployTaskStatus(ctx context.Context, project *re
 			}
 			return entity.TaskStatusCreateFullDeploymentUnderway, nil
 		}
+	// 密钥变量 Secret 创建中
+	case entity.TaskStatusCreateSecretUnderway:
+		return s.checkTaskSecretCreated(ctx, task)
+	// 持久化存储卷声明创建中
+	case entity.TaskStatusCreatePVCUnderway:
+		return s.checkTaskPVCsCreated(ctx, project, app, task)
 	// K8s CronJob 创建中
 	case entity.TaskStatusCreateFullCronJobUnderway:
 		_, err := s.GetCronJobDetail(ctx, task.ClusterName,
@@ -650,6 +715,12 @@ func (s *Service) transformFullDeployTaskStatus(ctx context.Context, project *re
 		}
 	// K8s Deployment 创建完成
 	case entity.TaskStatusCreateFullDeploymentFinish:
+		return s.applyTaskPDB(ctx, project, app, task)
+	// K8s PodDisruptionBudget 创建中
+	case entity.TaskStatusCreatePDBUnderway:
+		return s.checkTaskPDBCreated(ctx, task)
+	// K8s PodDisruptionBudget 创建完成
+	case entity.TaskStatusCreatePDBFinish:
 		// todo:: 暂时不移除 K8sAnnotationHPASkipped 注解
 		//err := s.EnableDeploymentHPAAndIgnoreResponse(ctx, task.ClusterName, task.EnvName, &req.EnableDeploymentHPAReq{
 		//	Env:       string(task.EnvName),
@@ -984,6 +1055,38 @@ func (s *Service) transformFullDeployTaskStatus(ctx context.Context, project *re
 		}
 	// HPA 清理完成
 	case entity.TaskStatusCleanHPAFinish:
+		// 未开启 PodDisruptionBudget 时跳过清理阶段
+		if !isPDBEnabled(task.EnvName) {
+			return entity.TaskStatusCleanPDBFinish, nil
+		}
+		// 删除除当前版本以外的所有 PodDisruptionBudget
+		err := s.DeletePDBs(ctx, task.ClusterName, &req.DeletePDBsReq{
+			Namespace:      task.Namespace,
+			ProjectName:    project.Name,
+			AppName:        app.Name,
+			InverseVersion: task.Version,
+			Env:            string(task.EnvName),
+		})
+		if err != nil {
+			return "", err
+		}
+		return entity.TaskStatusCleanPDBUnderway, nil
+	// PodDisruptionBudget 清理中
+	case entity.TaskStatusCleanPDBUnderway:
+		isDeleted, err := s.checkPDBsDeleted(ctx, task.ClusterName, &req.GetPDBsReq{
+			Namespace:   task.Namespace,
+			ProjectName: project.Name,
+			AppName:     app.Name,
+			Env:         string(task.EnvName),
+		}, task.Version)
+		if err != nil {
+			return "", err
+		}
+		if isDeleted {
+			return entity.TaskStatusCleanPDBFinish, nil
+		}
+	// PodDisruptionBudget 清理完成
+	case entity.TaskStatusCleanPDBFinish:
 		// 删除除当前版本以外的所有deployment
 		err := s.DeleteDeployments(ctx, task.ClusterName, task.EnvName, &req.DeleteDeploymentsReq{
 			Namespace:      task.Namespace,
@@ -1186,7 +1289,25 @@ func (s *Service) transformCanaryDeployTaskStatus(ctx context.Context, project *
 
 		return entity.TaskStatusSyncColdStorageDeliverTaskFinish, nil
 	// 日志冷投创建完成
-	case entity.TaskStatusCreateAliLogConfigFinish:
+	case entity.TaskStatusCreateAliLogConfigFinish, entity.TaskStatusCreateSecretFinish, entity.TaskStatusCreatePVCFinish:
+		// 引用了密钥变量时先创建 Secret
+		if task.Status == entity.TaskStatusCreateAliLogConfigFinish && len(task.Param.SecretVars) > 0 {
+			_, err := s.ApplyTaskSecret(ctx, project, app, task)
+			if err != nil {
+				return "", err
+			}
+			return entity.TaskStatusCreateSecretUnderway, nil
+		}
+
+		// 声明了持久化存储卷时先创建 PVC
+		if task.Status != entity.TaskStatusCreatePVCFinish && hasTaskPVC(task) {
+			err := s.ApplyTaskPVCs(ctx, project, app, task)
+			if err != nil {
+				return "", err
+			}
+			return entity.TaskStatusCreatePVCUnderway, nil
+		}
+
 		// 金丝雀发布只发布一个
 		task.Param.MinPodCount = 1
 		// 渲染模版
@@ -1200,6 +1321,12 @@ func (s *Service) transformCanaryDeployTaskStatus(ctx context.Context, project *
 			return "", err
 		}
 		return entity.TaskStatusCreateCanaryDeploymentUnderway, nil
+	// 密钥变量 Secret 创建中
+	case entity.TaskStatusCreateSecretUnderway:
+		return s.checkTaskSecretCreated(ctx, task)
+	// 持久化存储卷声明创建中
+	case entity.TaskStatusCreatePVCUnderway:
+		return s.checkTaskPVCsCreated(ctx, project, app, task)
 	// 灰度发布
 	case entity.TaskStatusCreateCanaryDeploymentUnderway:
 		status, err := s.GetDeploymentStatus(ctx, task.ClusterName, task.EnvName, &req.GetDeploymentDetailReq{
@@ -1332,7 +1459,101 @@ func (s *Service) transformCanaryDeployTaskStatus(ctx context.Context, project *
 		return entity.TaskStatusAllCreationPhasesFinish, nil
 	// 服务相关创建完成
 	case entity.TaskStatusAllCreationPhasesFinish:
+		if task.Param.ProgressiveCanary == nil {
+			return entity.TaskStatusSuccess, nil
+		}
+
+		// 渐进式金丝雀扩容至第一步实例数
+		err := s.UpdateDeploymentScaleAndIgnoreResponse(ctx, task.ClusterName, task.EnvName,
+			task.Namespace, task.Version, task.Param.ProgressiveCanary.GetStepPodCount(1))
+		if err != nil {
+			return "", err
+		}
+		return entity.TaskStatusCanaryStepScaleUnderway, nil
+	// 渐进式金丝雀扩容中
+	case entity.TaskStatusCanaryStepScaleUnderway:
+		step := task.Param.ProgressiveCanary.GetCurrentStep(task.CanaryVerdicts)
+		status, err := s.GetDeploymentStatus(ctx, task.ClusterName, task.EnvName, &req.GetDeploymentDetailReq{
+			Namespace: task.Namespace,
+			Name:      task.Version,
+			Env:       string(task.EnvName),
+		})
+		if err != nil {
+			return "", err
+		}
+		if int(status.ReadyReplicas) >= task.Param.ProgressiveCanary.GetStepPodCount(step) &&
+			int(status.UnavailableReplicas) == 0 {
+			return entity.TaskStatusCanaryStepBakeUnderway, nil
+		}
+	// 渐进式金丝雀观察中
+	case entity.TaskStatusCanaryStepBakeUnderway:
+		// 进入观察阶段时会更新任务时间, 以此作为本步骤观察的起点
+		updateTime, err := time.ParseInLocation(utils.DefaultTimeFormatLayout, task.UpdateTime, time.Local)
+		if err != nil {
+			return "", err
+		}
+		bake := time.Duration(task.Param.ProgressiveCanary.BakeSeconds) * time.Second
+		if time.Since(updateTime) < bake {
+			return "", nil
+		}
+
+		step := task.Param.ProgressiveCanary.GetCurrentStep(task.CanaryVerdicts)
+		verdict, err := s.EvaluateCanaryStep(ctx, task, step)
+		if err != nil {
+			return "", err
+		}
+
+		nextStatus := task.Param.ProgressiveCanary.GetBakeNextStatus(verdict)
+		switch nextStatus {
+		// 指标未通过, 清理金丝雀部署
+		case entity.TaskStatusCanaryAbortUnderway:
+			err = s.DeleteDeployment(ctx, task.ClusterName, task.EnvName, &req.DeleteDeploymentReq{
+				Namespace: task.Namespace,
+				Name:      task.Version,
+				Env:       string(task.EnvName),
+			})
+			if err != nil && !errcode.EqualError(_errcode.K8sResourceNotFoundError, err) {
+				return "", err
+			}
+		// 扩容至下一步实例数
+		case entity.TaskStatusCanaryStepScaleUnderway:
+			err = s.UpdateDeploymentScaleAndIgnoreResponse(ctx, task.ClusterName, task.EnvName,
+				task.Namespace, task.Version, task.Param.ProgressiveCanary.GetStepPodCount(step+1))
+			if err != nil {
+				return "", err
+			}
+		}
+
+		// k8s 操作完成后判定结果随状态一同记录, 操作失败重试时重新判定当前步骤, 不会跳过步骤或重复记录
+		task.CanaryVerdicts = append(task.CanaryVerdicts, verdict)
+		return nextStatus, nil
+	// 渐进式金丝雀全部通过, 自动全量
+	case entity.TaskStatusCanaryAnalysisFinish:
+		err := s.PromoteCanaryTask(ctx, project, app, task)
+		if err != nil {
+			return "", err
+		}
 		return entity.TaskStatusSuccess, nil
+	// 渐进式金丝雀未通过, 清理中
+	case entity.TaskStatusCanaryAbortUnderway:
+		err := s.CheckDeploymentExistance(ctx, task.ClusterName, task.EnvName, &req.GetDeploymentDetailReq{
+			Namespace: task.Namespace,
+			Name:      task.Version,
+			Env:       string(task.EnvName),
+		})
+		if err == nil {
+			return "", nil
+		}
+		if !errcode.EqualError(_errcode.K8sResourceNotFoundError, err) {
+			return "", err
+		}
+
+		// 移除 redis 中金丝雀部署记录
+		err = s.dao.RemoveAppClusterRunningTasks(ctx, task.AppID, task.EnvName, task.ClusterName, task.Version)
+		if err != nil {
+			return "", err
+		}
+		return entity.TaskStatusFail, nil
 	// istio virtualservice 创建中
 	case entity.TaskStatusCreateVirtualServiceUnderway:
 		// 检查 virtual service 状态
@@ -1378,6 +1599,10 @@ func (s *Service) transformFullCanaryDeployTaskStatus(ctx context.Context, proje
 			return entity.TaskStatusUpdateDeploymentScaleFinish, nil
 		}
 	case entity.TaskStatusUpdateDeploymentScaleFinish:
+		return s.applyTaskPDB(ctx, project, app, task)
+	case entity.TaskStatusCreatePDBUnderway:
+		return s.checkTaskPDBCreated(ctx, task)
+	case entity.TaskStatusCreatePDBFinish:
 		// todo:: 暂时不移除 K8sAnnotationHPASkipped 注解
 		//err := s.EnableDeploymentHPAAndIgnoreResponse(ctx, task.ClusterName, task.EnvName, &req.EnableDeploymentHPAReq{
 		//	Env:       string(task.EnvName),
@@ -1564,6 +1789,36 @@ func (s *Service) transformFullCanaryDeployTaskStatus(ctx context.Context, proje
 			return entity.TaskStatusCleanHPAFinish, nil
 		}
 	case entity.TaskStatusCleanHPAFinish:
+		// 未开启 PodDisruptionBudget 时跳过清理阶段
+		if !isPDBEnabled(task.EnvName) {
+			return entity.TaskStatusCleanPDBFinish, nil
+		}
+		// 删除除当前版本以外的所有 PodDisruptionBudget
+		err := s.DeletePDBs(ctx, task.ClusterName, &req.DeletePDBsReq{
+			Namespace:      task.Namespace,
+			ProjectName:    project.Name,
+			AppName:        app.Name,
+			InverseVersion: task.Version,
+			Env:            string(task.EnvName),
+		})
+		if err != nil {
+			return "", err
+		}
+		return entity.TaskStatusCleanPDBUnderway, nil
+	case entity.TaskStatusCleanPDBUnderway:
+		isDeleted, err := s.checkPDBsDeleted(ctx, task.ClusterName, &req.GetPDBsReq{
+			Namespace:   task.Namespace,
+			ProjectName: project.Name,
+			AppName:     app.Name,
+			Env:         string(task.EnvName),
+		}, task.Version)
+		if err != nil {
+			return "", err
+		}
+		if isDeleted {
+			return entity.TaskStatusCleanPDBFinish, nil
+		}
+	case entity.TaskStatusCleanPDBFinish:
 		// 删除除当前版本以外的所有deployment
 		err := s.DeleteDeployments(ctx, task.ClusterName, task.EnvName, &req.DeleteDeploymentsReq{
 			Namespace:      task.Namespace,
@@ -1989,6 +2244,31 @@ func (s *Service) transformDeleteTaskStatus(ctx context.Context, project *resp.P
 		}
 		return entity.TaskStatusCleanHPAFinish, nil
 	case entity.TaskStatusCleanHPAFinish:
+		if !isPDBEnabled(task.EnvName) {
+			return entity.TaskStatusCleanPDBFinish, nil
+		}
+		err := s.DeletePDB(ctx, task.ClusterName, &req.GetPDBDetailReq{
+			Namespace: task.Namespace,
+			Name:      task.Version,
+			Env:       string(task.EnvName),
+		})
+		if err != nil {
+			return "", err
+		}
+		return entity.TaskStatusCleanPDBUnderway, nil
+	case entity.TaskStatusCleanPDBUnderway:
+		_, err := s.GetPDBDetail(ctx, task.ClusterName, &req.GetPDBDetailReq{
+			Namespace: task.Namespace,
+			Name:      task.Version,
+			Env:       string(task.EnvName),
+		})
+		if err != nil {
+			if errcode.EqualError(_errcode.K8sResourceNotFoundError, err) {
+				return entity.TaskStatusCleanPDBFinish, nil
+			}
+			return "", err
+		}
+	case entity.TaskStatusCleanPDBFinish:
 		err := s.DeleteDeployment(ctx, task.ClusterName, task.EnvName, &req.DeleteDeploymentReq{
 			Namespace: task.Namespace,
 			Name:      task.Version,
@@ -2090,6 +2370,19 @@ func (s *Service) transformDeleteTaskStatus(ctx context.Context, project *resp.P
 			}
 		}
 
+		// 最后一个部署删除后, 仅在指定删除时清理持久化存储卷声明
+		if task.Param.DeleteVolumes {
+			err := s.DeletePVCs(ctx, task.ClusterName, &req.ListPVCsReq{
+				Namespace:   task.Namespace,
+				ProjectName: project.Name,
+				AppName:     app.Name,
+				Env:         string(task.EnvName),
+			})
+			if err != nil {
+				return "", err
+			}
+		}
+
 		err := s.DeleteLogConfig(ctx, task.ClusterName, task.EnvName, app)
 		if err != nil {
 			if !errcode.EqualError(_errcode.K8sResourceNotFoundError, err) {
@@ -2129,6 +2422,19 @@ func (s *Service) transformDeleteTaskStatus(ctx context.Context, project *resp.P
 // transformCleanTaskStatus 清理应用信息
 func (s *Service) transformCleanTaskStatus(ctx context.Context, task *resp.TaskDetailResp) (
 	entity.TaskStatus, error) {
+	// 只清理指定版本
+	if task.Param.CleanedVersion != "" {
+		return s.transformCleanVersionTaskStatus(ctx, task)
+	}
+
+	// 可插拔工作负载先清理工作负载本身, 其余资源沿用通用清理流程
+	if w, wt, ok := s.getWorkload(task.Param.CleanedAppType); ok {
+		switch task.Status {
+		case entity.TaskStatusInit, wt.Phases.CleanUnderway, wt.Phases.CleanFinish:
+			return s.transformWorkloadCleanTaskStatus(ctx, task, w, wt)
+		}
+	}
+
 	switch task.Status {
 	case entity.TaskStatusInit:
 		if task.Param.CleanedAppType == entity.AppTypeCronJob {
@@ -2222,6 +2528,33 @@ func (s *Service) transformCleanTaskStatus(ctx context.Context, task *resp.TaskD
 			return entity.TaskStatusCleanHPAFinish, nil
 		}
 	case entity.TaskStatusCleanHPAFinish:
+		if !isPDBEnabled(task.EnvName) {
+			return entity.TaskStatusCleanPDBFinish, nil
+		}
+		err := s.DeletePDBs(ctx, task.ClusterName, &req.DeletePDBsReq{
+			Namespace:   task.Namespace,
+			ProjectName: task.Param.CleanedProjectName,
+			AppName:     task.Param.CleanedAppName,
+			Env:         string(task.EnvName),
+		})
+		if err != nil {
+			return "", err
+		}
+		return entity.TaskStatusCleanPDBUnderway, nil
+	case entity.TaskStatusCleanPDBUnderway:
+		isDeleted, err := s.checkPDBsDeleted(ctx, task.ClusterName, &req.GetPDBsReq{
+			Namespace:   task.Namespace,
+			ProjectName: task.Param.CleanedProjectName,
+			AppName:     task.Param.CleanedAppName,
+			Env:         string(task.EnvName),
+		}, "")
+		if err != nil {
+			return "", err
+		}
+		if isDeleted {
+			return entity.TaskStatusCleanPDBFinish, nil
+		}
+	case entity.TaskStatusCleanPDBFinish:
 		err := s.DeleteDeployments(ctx, task.ClusterName, task.EnvName, &req.DeleteDeploymentsReq{
 			Namespace:   task.Namespace,
 			ProjectName: task.Param.CleanedProjectName,
@@ -2250,6 +2583,12 @@ func (s *Service) transformCleanTaskStatus(ctx context.Context, task *resp.TaskD
 			return entity.TaskStatusCleanK8sServiceFinish, nil
 		}
 
+		// 蓝绿部署按版本创建的服务
+		if _, e := s.deleteBlueGreenVersionServices(ctx, task,
+			task.Param.CleanedProjectName, task.Param.CleanedAppName, ""); e != nil {
+			return "", e
+		}
+
 		return entity.TaskStatusCleanAliServiceFinish, nil
 
 		err := s.deletePrivateZoneRecordEntry(ctx, &resp.AppDetailResp{
@@ -2495,6 +2834,30 @@ func (s *Service) transformCleanTaskStatus(ctx context.Context, task *resp.TaskD
 		if err != nil {
 			return "", err
 		}
+
+		// 密钥变量 Secret 与 ConfigMap 一同清理
+		err = s.DeleteSecrets(ctx, task.ClusterName, &req.ListSecretsReq{
+			Namespace:   task.Namespace,
+			ProjectName: task.Param.CleanedProjectName,
+			AppName:     task.Param.CleanedAppName,
+			Env:         string(task.EnvName),
+		})
+		if err != nil {
+			return "", err
+		}
+
+		// 持久化存储卷声明仅在指定删除时清理
+		if task.Param.DeleteVolumes {
+			err = s.DeletePVCs(ctx, task.ClusterName, &req.ListPVCsReq{
+				Namespace:   task.Namespace,
+				ProjectName: task.Param.CleanedProjectName,
+				AppName:     task.Param.CleanedAppName,
+				Env:         string(task.EnvName),
+			})
+			if err != nil {
+				return "", err
+			}
+		}
 		return entity.TaskStatusCleanConfigMapUnderway, nil
 	case entity.TaskStatusCleanConfigMapUnderway:
 		cms, err := s.ListConfigMaps(ctx, task.ClusterName, &req.ListConfigMapsReq{
@@ -2506,7 +2869,30 @@ func (s *Service) transformCleanTaskStatus(ctx context.Context, task *resp.TaskD
 		if err != nil {
 			return "", err
 		}
-		if len(cms) == 0 {
+
+		secrets, err := s.ListSecrets(ctx, task.ClusterName, &req.ListSecretsReq{
+			Namespace:   task.Namespace,
+			ProjectName: task.Param.CleanedProjectName,
+			AppName:     task.Param.CleanedAppName,
+			Env:         string(task.EnvName),
+		})
+		if err != nil {
+			return "", err
+		}
+		pvcCount := 0
+		if task.Param.DeleteVolumes {
+			pvcs, e := s.ListPVCs(ctx, task.ClusterName, &req.ListPVCsReq{
+				Namespace:   task.Namespace,
+				ProjectName: task.Param.CleanedProjectName,
+				AppName:     task.Param.CleanedAppName,
+				Env:         string(task.EnvName),
+			})
+			if e != nil {
+				return "", e
+			}
+			pvcCount = len(pvcs)
+		}
+		if len(cms) == 0 && len(secrets) == 0 && pvcCount == 0 {
 			return entity.TaskStatusCleanConfigMapFinish, nil
 		}
 	case entity.TaskStatusCleanConfigMapFinish:
@@ -2522,35 +2908,163 @@ func (s *Service) transformCleanTaskStatus(ctx context.Context, task *resp.TaskD
 	return "", nil
 }
 
-// transformReloadConfigStatus transform reload config task status.
-func (s *Service) transformReloadConfigStatus(ctx context.Context, project *resp.ProjectDetailResp,
-	app *resp.AppDetailResp, task *resp.TaskDetailResp, team *resp.TeamDetailResp) (entity.TaskStatus, error) {
+// transformCleanVersionTaskStatus 清理指定版本的 HPA, Deployment 及按版本创建的服务
+func (s *Service) transformCleanVersionTaskStatus(ctx context.Context, task *resp.TaskDetailResp) (
+	entity.TaskStatus, error) {
+	version := task.Param.CleanedVersion
+
 	switch task.Status {
 	case entity.TaskStatusInit:
-		getReq := &req.GetConfigManagerFileReq{
-			ProjectID:   project.ID,
-			ProjectName: project.Name,
-			EnvName:     task.EnvName,
-			CommitID:    task.Param.ConfigCommitID,
-			IsDecrypt:   true,
-			FormatType:  req.ConfigManagerFormatTypeJSON,
-		}
-
-		if task.Param.ConfigRenamePrefix != "" {
-			getReq.ConfigRenamePrefix = task.Param.ConfigRenamePrefix
-			getReq.ConfigRenameMode = task.Param.ConfigRenameMode
-		}
-
-		configData, err := s.GetAppConfig(ctx, getReq)
-		if err != nil {
-			return "", err
-		}
-		// Render template, update configMap's commit id and config data hash.
-		data, err := s.RenderConfigMapTemplate(ctx, project, app, task, team, configData.Config)
+		hpaList, err := s.GetHPAs(ctx, task.ClusterName,
+			&req.GetHPAsReq{
+				Namespace:   task.Namespace,
+				ProjectName: task.Param.CleanedProjectName,
+				AppName:     task.Param.CleanedAppName,
+				Version:     version,
+				Env:         string(task.EnvName),
+			})
 		if err != nil {
 			return "", err
 		}
-		// Apply ConfigMap.
+
+		for i := range hpaList {
+			err = s.DeleteHPA(ctx, task.ClusterName, &req.DeleteHPAReq{
+				Namespace: hpaList[i].GetNamespace(),
+				Name:      hpaList[i].GetName(),
+				Env:       string(task.EnvName),
+			})
+			if err != nil {
+				return "", err
+			}
+		}
+		return entity.TaskStatusCleanHPAUnderway, nil
+	case entity.TaskStatusCleanHPAUnderway:
+		hpaList, err := s.GetHPAs(ctx, task.ClusterName,
+			&req.GetHPAsReq{
+				Namespace:   task.Namespace,
+				ProjectName: task.Param.CleanedProjectName,
+				AppName:     task.Param.CleanedAppName,
+				Version:     version,
+				Env:         string(task.EnvName),
+			})
+		if err != nil {
+			return "", err
+		}
+		if len(hpaList) == 0 {
+			return entity.TaskStatusCleanHPAFinish, nil
+		}
+	case entity.TaskStatusCleanHPAFinish:
+		if !isPDBEnabled(task.EnvName) {
+			return entity.TaskStatusCleanPDBFinish, nil
+		}
+		err := s.DeletePDB(ctx, task.ClusterName, &req.GetPDBDetailReq{
+			Namespace: task.Namespace,
+			Name:      version,
+			Env:       string(task.EnvName),
+		})
+		if err != nil {
+			return "", err
+		}
+		return entity.TaskStatusCleanPDBUnderway, nil
+	case entity.TaskStatusCleanPDBUnderway:
+		_, err := s.GetPDBDetail(ctx, task.ClusterName, &req.GetPDBDetailReq{
+			Namespace: task.Namespace,
+			Name:      version,
+			Env:       string(task.EnvName),
+		})
+		if errcode.EqualError(_errcode.K8sResourceNotFoundError, err) {
+			return entity.TaskStatusCleanPDBFinish, nil
+		}
+		if err != nil {
+			return "", err
+		}
+	case entity.TaskStatusCleanPDBFinish:
+		err := s.DeleteDeployment(ctx, task.ClusterName, task.EnvName, &req.DeleteDeploymentReq{
+			Namespace: task.Namespace,
+			Name:      version,
+			Env:       string(task.EnvName),
+		})
+		if err != nil && !errcode.EqualError(_errcode.K8sResourceNotFoundError, err) {
+			return "", err
+		}
+		return entity.TaskStatusCleanDeploymentUnderway, nil
+	case entity.TaskStatusCleanDeploymentUnderway:
+		err := s.CheckDeploymentExistance(ctx, task.ClusterName, task.EnvName, &req.GetDeploymentDetailReq{
+			Namespace: task.Namespace,
+			Name:      version,
+			Env:       string(task.EnvName),
+		})
+		if errcode.EqualError(_errcode.K8sResourceNotFoundError, err) {
+			return entity.TaskStatusCleanDeploymentFinish, nil
+		}
+		if err != nil {
+			return "", err
+		}
+	case entity.TaskStatusCleanDeploymentFinish:
+		deleted, err := s.deleteBlueGreenVersionServices(ctx, task,
+			task.Param.CleanedProjectName, task.Param.CleanedAppName, version)
+		if err != nil {
+			return "", err
+		}
+		if deleted {
+			return entity.TaskStatusCleanK8sServiceUnderway, nil
+		}
+		return entity.TaskStatusCleanK8sServiceFinish, nil
+	case entity.TaskStatusCleanK8sServiceUnderway:
+		_, err := s.GetServiceDetail(ctx, task.ClusterName,
+			&req.GetServiceDetailReq{
+				Namespace: task.Namespace,
+				Name:      version,
+				Env:       string(task.EnvName),
+			})
+		if errcode.EqualError(_errcode.K8sResourceNotFoundError, err) {
+			return entity.TaskStatusCleanK8sServiceFinish, nil
+		}
+		if err != nil {
+			return "", err
+		}
+	case entity.TaskStatusCleanK8sServiceFinish:
+		// 清理 redis 中该版本的部署记录
+		err := s.dao.RemoveAppClusterRunningTasks(ctx, task.AppID, task.EnvName, task.ClusterName, version)
+		if err != nil {
+			return "", err
+		}
+		return entity.TaskStatusSuccess, nil
+	default:
+		return "", _errcode.InvalidTaskStatusError
+	}
+	return "", nil
+}
+
+// transformReloadConfigStatus transform reload config task status.
+func (s *Service) transformReloadConfigStatus(ctx context.Context, project *resp.ProjectDetailResp,
+	app *resp.AppDetailResp, task *resp.TaskDetailResp, team *resp.TeamDetailResp) (entity.TaskStatus, error) {
+	switch task.Status {
+	case entity.TaskStatusInit:
+		getReq := &req.GetConfigManagerFileReq{
+			ProjectID:   project.ID,
+			ProjectName: project.Name,
+			EnvName:     task.EnvName,
+			CommitID:    task.Param.ConfigCommitID,
+			IsDecrypt:   true,
+			FormatType:  req.ConfigManagerFormatTypeJSON,
+		}
+
+		if task.Param.ConfigRenamePrefix != "" {
+			getReq.ConfigRenamePrefix = task.Param.ConfigRenamePrefix
+			getReq.ConfigRenameMode = task.Param.ConfigRenameMode
+		}
+
+		configData, err := s.GetAppConfig(ctx, getReq)
+		if err != nil {
+			return "", err
+		}
+		// Render template, update configMap's commit id and config data hash.
+		data, err := s.RenderConfigMapTemplate(ctx, project, app, task, team, configData.Config)
+		if err != nil {
+			return "", err
+		}
+		// Apply ConfigMap.
 		_, err = s.ApplyConfigMap(ctx, task.ClusterName, data, string(task.EnvName))
 		if err != nil {
 			return "", err
@@ -2620,3 +3134,570 @@ func (s *Service) transformReloadConfigStatus(ctx context.Context, project *resp
 	}
 	return "", nil
 }
+
+// transformRollbackTaskStatus 使用上一次部署成功的参数重建 ConfigMap/Deployment/HPA, 自动回滚时清理失败版本
+func (s *Service) transformRollbackTaskStatus(ctx context.Context, project *resp.ProjectDetailResp,
+	app *resp.AppDetailResp, task *resp.TaskDetailResp, team *resp.TeamDetailResp) (entity.TaskStatus, error) {
+	switch task.Status {
+	// 初始状态
+	case entity.TaskStatusInit:
+		// 未使用配置中心时跳过阶段
+		if task.Param.ConfigCommitID == "" {
+			return entity.TaskStatusCreateConfigMapFinish, nil
+		}
+
+		err := s.applyTaskConfigMap(ctx, project, app, task, team)
+		if err != nil {
+			return "", err
+		}
+		return entity.TaskStatusCreateConfigMapUnderway, nil
+	// K8s ConfigMap 创建中
+	case entity.TaskStatusCreateConfigMapUnderway:
+		created, err := s.isTaskConfigMapCreated(ctx, project, app, task)
+		if err != nil {
+			return "", err
+		}
+		if created {
+			return entity.TaskStatusCreateConfigMapFinish, nil
+		}
+	// K8s ConfigMap 创建完成
+	case entity.TaskStatusCreateConfigMapFinish:
+		// 渲染模版
+		data, err := s.RenderDeploymentTemplate(ctx, project, app, task, team)
+		if err != nil {
+			return "", err
+		}
+
+		// 生成Deployment
+		err = s.ApplyDeploymentAndIgnoreResponse(ctx, task.ClusterName, task.EnvName, data)
+		if err != nil {
+			return "", err
+		}
+		return entity.TaskStatusCreateFullDeploymentUnderway, nil
+	// K8s Deployment 创建中
+	case entity.TaskStatusCreateFullDeploymentUnderway:
+		status, err := s.GetDeploymentStatus(ctx, task.ClusterName, task.EnvName, &req.GetDeploymentDetailReq{
+			Namespace: task.Namespace,
+			Name:      task.Version,
+			Env:       string(task.EnvName),
+		})
+		if err == nil {
+			if int(status.UpdatedReplicas) >= task.Param.MinPodCount &&
+				status.AvailableReplicas == status.UpdatedReplicas &&
+				int(status.UnavailableReplicas) == 0 {
+				return entity.TaskStatusCreateFullDeploymentFinish, nil
+			}
+		} else if !errcode.EqualError(_errcode.K8sResourceNotFoundError, err) {
+			return "", err
+		}
+	// K8s Deployment 创建完成
+	case entity.TaskStatusCreateFullDeploymentFinish:
+		return s.applyTaskPDB(ctx, project, app, task)
+	// K8s PodDisruptionBudget 创建中
+	case entity.TaskStatusCreatePDBUnderway:
+		return s.checkTaskPDBCreated(ctx, task)
+	// K8s PodDisruptionBudget 创建完成
+	case entity.TaskStatusCreatePDBFinish:
+		// 不自动扩缩容
+		if !task.Param.IsAutoScale {
+			return entity.TaskStatusCreateHPAFinish, nil
+		}
+
+		// 渲染模版
+		data, err := s.RenderHPATemplate(ctx, project, app, task, team)
+		if err != nil {
+			return "", err
+		}
+		// 生成HPA
+		_, err = s.ApplyHPA(ctx, task.ClusterName, data, string(task.EnvName))
+		if err != nil {
+			return "", err
+		}
+		return entity.TaskStatusCreateHPAUnderway, nil
+	// K8s HPA 创建中
+	case entity.TaskStatusCreateHPAUnderway:
+		return entity.TaskStatusCreateHPAFinish, nil
+	// K8s HPA 创建完成
+	case entity.TaskStatusCreateHPAFinish:
+		inverseVersion := task.Version
+		if !task.Param.IsAutoScale {
+			inverseVersion = ""
+		}
+
+		// 删除失败部署遗留的hpa
+		err := s.DeleteHPAs(ctx, task.ClusterName,
+			&req.DeleteHPAsReq{
+				Namespace:      task.Namespace,
+				ProjectName:    project.Name,
+				AppName:        app.Name,
+				Env:            string(task.EnvName),
+				InverseVersion: inverseVersion,
+			})
+		if err != nil {
+			return "", err
+		}
+		return entity.TaskStatusCleanHPAUnderway, nil
+	// HPA 清理中
+	case entity.TaskStatusCleanHPAUnderway:
+		hpaList, err := s.GetHPAs(ctx, task.ClusterName,
+			&req.GetHPAsReq{
+				Namespace:   task.Namespace,
+				ProjectName: project.Name,
+				AppName:     app.Name,
+				Env:         string(task.EnvName),
+			})
+		if err != nil {
+			return "", err
+		}
+
+		// 检查是否除当前版本外都已删除
+		isDeleted := true
+		for i := range hpaList {
+			if !task.Param.IsAutoScale || hpaList[i].GetName() != task.Version {
+				isDeleted = false
+				break
+			}
+		}
+		if isDeleted {
+			return entity.TaskStatusCleanHPAFinish, nil
+		}
+	// HPA 清理完成
+	case entity.TaskStatusCleanHPAFinish:
+		failedVersion, err := s.getRollbackFailedVersion(ctx, task)
+		if err != nil {
+			return "", err
+		}
+		if failedVersion == "" {
+			return entity.TaskStatusSuccess, nil
+		}
+
+		// 删除失败部署遗留的deployment, 避免失败版本继续运行
+		detailReq := &req.GetDeploymentDetailReq{
+			Namespace: task.Namespace,
+			Name:      failedVersion,
+			Env:       string(task.EnvName),
+		}
+		err = s.CheckDeploymentExistance(ctx, task.ClusterName, task.EnvName, detailReq)
+		if err != nil {
+			if errcode.EqualError(_errcode.K8sResourceNotFoundError, err) {
+				return entity.TaskStatusCleanDeploymentFinish, nil
+			}
+			return "", err
+		}
+
+		err = s.DeleteDeployment(ctx, task.ClusterName, task.EnvName, &req.DeleteDeploymentReq{
+			Namespace: task.Namespace,
+			Name:      failedVersion,
+			Env:       string(task.EnvName),
+		})
+		if err != nil {
+			return "", err
+		}
+		return entity.TaskStatusCleanDeploymentUnderway, nil
+	// 失败版本 Deployment 清理中
+	case entity.TaskStatusCleanDeploymentUnderway:
+		failedVersion, err := s.getRollbackFailedVersion(ctx, task)
+		if err != nil {
+			return "", err
+		}
+
+		err = s.CheckDeploymentExistance(ctx, task.ClusterName, task.EnvName, &req.GetDeploymentDetailReq{
+			Namespace: task.Namespace,
+			Name:      failedVersion,
+			Env:       string(task.EnvName),
+		})
+		if err != nil {
+			if errcode.EqualError(_errcode.K8sResourceNotFoundError, err) {
+				return entity.TaskStatusCleanDeploymentFinish, nil
+			}
+			return "", err
+		}
+	// 失败版本 Deployment 清理完成
+	case entity.TaskStatusCleanDeploymentFinish:
+		return entity.TaskStatusSuccess, nil
+	default:
+		return "", _errcode.InvalidTaskStatusError
+	}
+	return "", nil
+}
+
+// getRollbackFailedVersion 获取回滚任务关联的失败任务版本, 非自动回滚或版本相同时返回空
+func (s *Service) getRollbackFailedVersion(ctx context.Context, task *resp.TaskDetailResp) (string, error) {
+	if task.Action != entity.TaskActionRollback || task.RelatedTaskID == "" {
+		return "", nil
+	}
+
+	failedTask, err := s.GetTaskDetail(ctx, task.RelatedTaskID)
+	if err != nil {
+		return "", err
+	}
+
+	if failedTask.Version == task.Version {
+		return "", nil
+	}
+
+	return failedTask.Version, nil
+}
+
+// transformBlueGreenTaskStatus 蓝绿部署及切回
+// 新版本以全量实例数部署在旧版本旁, 就绪后一次性切换流量, 旧版本保留至保留期结束后由清理任务清理
+func (s *Service) transformBlueGreenTaskStatus(ctx context.Context, project *resp.ProjectDetailResp,
+	app *resp.AppDetailResp, task *resp.TaskDetailResp, team *resp.TeamDetailResp) (entity.TaskStatus, error) {
+	switch task.Status {
+	// 初始状态
+	case entity.TaskStatusInit:
+		// 切回时旧版本仍在运行, 直接切换流量
+		if task.Action == entity.TaskActionBlueGreenSwitchBack {
+			err := s.SwitchBlueGreenTraffic(ctx, project, app, task, task.Version)
+			if err != nil {
+				return "", err
+			}
+			return entity.TaskStatusBlueGreenSwitchUnderway, nil
+		}
+
+		// 新版本与旧版本标签一致, 创建新版本前先将流量固定在旧版本, 避免新版本就绪后提前接收流量
+		err := s.SwitchBlueGreenTraffic(ctx, project, app, task, task.PreviousVersion)
+		if err != nil {
+			return "", err
+		}
+
+		return s.transformRollbackTaskStatus(ctx, project, app, task, team)
+	// 配置, Deployment 及 HPA 的创建与回滚一致, 但不清理其他版本
+	case entity.TaskStatusCreateConfigMapUnderway, entity.TaskStatusCreateConfigMapFinish,
+		entity.TaskStatusCreateFullDeploymentUnderway, entity.TaskStatusCreateFullDeploymentFinish,
+		entity.TaskStatusCreatePDBUnderway, entity.TaskStatusCreatePDBFinish, entity.TaskStatusCreateHPAUnderway:
+		return s.transformRollbackTaskStatus(ctx, project, app, task, team)
+	// K8s HPA 创建完成
+	case entity.TaskStatusCreateHPAFinish:
+		err := s.SwitchBlueGreenTraffic(ctx, project, app, task, task.Version)
+		if err != nil {
+			return "", err
+		}
+		return entity.TaskStatusBlueGreenSwitchUnderway, nil
+	// 流量切换中
+	case entity.TaskStatusBlueGreenSwitchUnderway:
+		switched, err := s.CheckBlueGreenTrafficSwitched(ctx, project, app, task, task.Version)
+		if err != nil {
+			return "", err
+		}
+		if switched {
+			return entity.TaskStatusBlueGreenSwitchFinish, nil
+		}
+	// 流量切换完成
+	case entity.TaskStatusBlueGreenSwitchFinish:
+		return entity.TaskStatusSuccess, nil
+	default:
+		return "", _errcode.InvalidTaskStatusError
+	}
+	return "", nil
+}
+
+// transformWorkloadTaskStatus 可插拔工作负载(StatefulSet/DaemonSet)的任务状态机
+// 各阶段的任务状态由 entity.WorkloadType 提供, k8s 操作由 workload 接口完成
+func (s *Service) transformWorkloadTaskStatus(ctx context.Context, project *resp.ProjectDetailResp,
+	app *resp.AppDetailResp, task *resp.TaskDetailResp, team *resp.TeamDetailResp,
+	w workload, wt *entity.WorkloadType) (entity.TaskStatus, error) {
+	if !wt.SupportsAction(task.Action) {
+		return "", errors.Wrapf(_errcode.UnknownTaskActionError, "%s is not supported by %s", task.Action, wt.AppType)
+	}
+
+	switch task.Action {
+	case entity.TaskActionFullDeploy:
+		return s.transformWorkloadFullDeployTaskStatus(ctx, project, app, task, team, w, wt)
+	case entity.TaskActionRestart:
+		return s.transformWorkloadRestartTaskStatus(ctx, task, w, wt)
+	case entity.TaskActionStop, entity.TaskActionResume:
+		return s.transformWorkloadScaleTaskStatus(ctx, task, w, wt)
+	case entity.TaskActionDelete:
+		return s.transformWorkloadDeleteTaskStatus(ctx, project, app, task, w, wt)
+	default:
+		return "", _errcode.UnknownTaskActionError
+	}
+}
+
+func (s *Service) transformWorkloadFullDeployTaskStatus(ctx context.Context, project *resp.ProjectDetailResp,
+	app *resp.AppDetailResp, task *resp.TaskDetailResp, team *resp.TeamDetailResp,
+	w workload, wt *entity.WorkloadType) (entity.TaskStatus, error) {
+	switch task.Status {
+	// 初始状态
+	case entity.TaskStatusInit:
+		err := s.ApplyLogConfig(ctx, project, app, task, team)
+		if err != nil {
+			if errcode.EqualError(_errcode.LogConfigDisabled, err) {
+				// 禁用日志接入时跳过阶段
+				return entity.TaskStatusCreateAliLogConfigFinish, nil
+			}
+
+			return "", err
+		}
+
+		return entity.TaskStatusCreateAliLogConfigUnderway, nil
+	// 云日志配置创建中
+	case entity.TaskStatusCreateAliLogConfigUnderway:
+		err := s.LogConfigExistanceCheck(ctx, app, task)
+		if err == nil {
+			return entity.TaskStatusCreateAliLogConfigFinish, nil
+		}
+
+		if errcode.EqualError(_errcode.K8sResourceNotFoundError, err) {
+			return "", nil
+		}
+
+		return "", err
+	// 云日志配置创建完成
+	case entity.TaskStatusCreateAliLogConfigFinish:
+		// 未使用配置中心时跳过阶段
+		if task.Param.ConfigCommitID == "" {
+			return entity.TaskStatusCreateConfigMapFinish, nil
+		}
+
+		err := s.applyTaskConfigMap(ctx, project, app, task, team)
+		if err != nil {
+			return "", err
+		}
+		return entity.TaskStatusCreateConfigMapUnderway, nil
+	// K8s ConfigMap 创建中
+	case entity.TaskStatusCreateConfigMapUnderway:
+		created, err := s.isTaskConfigMapCreated(ctx, project, app, task)
+		if err != nil {
+			return "", err
+		}
+		if created {
+			return entity.TaskStatusCreateConfigMapFinish, nil
+		}
+	// K8s ConfigMap 创建完成, 渲染工作负载时挂载配置
+	case entity.TaskStatusCreateConfigMapFinish:
+		data, err := w.Render(ctx, project, app, task, team)
+		if err != nil {
+			return "", err
+		}
+
+		err = w.Apply(ctx, task.ClusterName, data, string(task.EnvName))
+		if err != nil {
+			return "", err
+		}
+		return wt.Phases.CreateUnderway, nil
+	// 工作负载滚动更新中
+	case wt.Phases.CreateUnderway:
+		status, err := w.GetStatus(ctx, task.ClusterName, &req.GetWorkloadDetailReq{
+			Namespace: task.Namespace,
+			Name:      task.Version,
+			Env:       string(task.EnvName),
+		})
+		if err == nil {
+			if status.RolledOut {
+				return wt.Phases.CreateFinish, nil
+			}
+		} else if !errcode.EqualError(_errcode.K8sResourceNotFoundError, err) {
+			return "", err
+		}
+	// 工作负载创建完成
+	case wt.Phases.CreateFinish:
+		// 工作负载名称固定, 清理同集群其他版本的部署记录
+		err := s.dao.CleanAppRunningTasks(ctx, task.AppID, task.EnvName, task.ClusterName, task.Version)
+		if err != nil {
+			return "", err
+		}
+		return entity.TaskStatusSuccess, nil
+	default:
+		return "", _errcode.InvalidTaskStatusError
+	}
+	return "", nil
+}
+
+func (s *Service) transformWorkloadRestartTaskStatus(ctx context.Context, task *resp.TaskDetailResp,
+	w workload, wt *entity.WorkloadType) (entity.TaskStatus, error) {
+	detailReq := &req.GetWorkloadDetailReq{
+		Namespace: task.Namespace,
+		Name:      task.Version,
+		Env:       string(task.EnvName),
+	}
+
+	switch task.Status {
+	case entity.TaskStatusInit:
+		err := w.Restart(ctx, task.ClusterName, detailReq)
+		if err != nil {
+			return "", err
+		}
+		return wt.Phases.RestartUnderway, nil
+	case wt.Phases.RestartUnderway:
+		status, err := w.GetStatus(ctx, task.ClusterName, detailReq)
+		if err != nil {
+			return "", err
+		}
+		if status.RolledOut {
+			return wt.Phases.RestartFinish, nil
+		}
+	case wt.Phases.RestartFinish:
+		return entity.TaskStatusSuccess, nil
+	default:
+		return "", _errcode.InvalidTaskStatusError
+	}
+	return "", nil
+}
+
+// transformWorkloadScaleTaskStatus 停止及恢复均伸缩至任务参数中的最小实例数
+func (s *Service) transformWorkloadScaleTaskStatus(ctx context.Context, task *resp.TaskDetailResp,
+	w workload, wt *entity.WorkloadType) (entity.TaskStatus, error) {
+	if !wt.Scalable() {
+		return "", errors.Wrapf(_errcode.UnknownTaskActionError, "%s is not scalable", wt.AppType)
+	}
+
+	detailReq := &req.GetWorkloadDetailReq{
+		Namespace: task.Namespace,
+		Name:      task.Version,
+		Env:       string(task.EnvName),
+	}
+
+	switch task.Status {
+	case entity.TaskStatusInit:
+		err := w.Scale(ctx, task.ClusterName, detailReq, task.Param.MinPodCount)
+		if err != nil {
+			return "", err
+		}
+		return wt.Phases.ScaleUnderway, nil
+	case wt.Phases.ScaleUnderway:
+		status, err := w.GetStatus(ctx, task.ClusterName, detailReq)
+		if err != nil {
+			return "", err
+		}
+		// 实例数符合
+		if status.DesiredReplicas == task.Param.MinPodCount && status.ReadyReplicas == task.Param.MinPodCount {
+			return wt.Phases.ScaleFinish, nil
+		}
+	case wt.Phases.ScaleFinish:
+		return entity.TaskStatusSuccess, nil
+	default:
+		return "", _errcode.InvalidTaskStatusError
+	}
+	return "", nil
+}
+
+func (s *Service) transformWorkloadDeleteTaskStatus(ctx context.Context, project *resp.ProjectDetailResp,
+	app *resp.AppDetailResp, task *resp.TaskDetailResp, w workload, wt *entity.WorkloadType) (entity.TaskStatus, error) {
+	switch task.Status {
+	case entity.TaskStatusInit:
+		err := w.Delete(ctx, task.ClusterName, &req.DeleteWorkloadReq{
+			Namespace: task.Namespace,
+			Name:      task.Version,
+			Env:       string(task.EnvName),
+		})
+		if err != nil {
+			if errcode.EqualError(_errcode.K8sResourceNotFoundError, err) {
+				return wt.Phases.CleanFinish, nil
+			}
+			return "", err
+		}
+		return wt.Phases.CleanUnderway, nil
+	case wt.Phases.CleanUnderway:
+		_, err := w.GetStatus(ctx, task.ClusterName, &req.GetWorkloadDetailReq{
+			Namespace: task.Namespace,
+			Name:      task.Version,
+			Env:       string(task.EnvName),
+		})
+		if err != nil {
+			if errcode.EqualError(_errcode.K8sResourceNotFoundError, err) {
+				return wt.Phases.CleanFinish, nil
+			}
+			return "", err
+		}
+	case wt.Phases.CleanFinish:
+		// 需要确保是否还有其他部署
+		list, err := w.List(ctx, task.ClusterName, &req.GetWorkloadsReq{
+			Namespace:   task.Namespace,
+			ProjectName: project.Name,
+			AppName:     app.Name,
+			Env:         string(task.EnvName),
+		})
+		if err != nil {
+			return "", err
+		}
+		// 包含其他部署，不能删除日志配置
+		if len(list) > 0 {
+			return entity.TaskStatusCleanAliLogConfigFinish, nil
+		}
+
+		err = s.DeleteLogConfig(ctx, task.ClusterName, task.EnvName, app)
+		if err != nil {
+			if !errcode.EqualError(_errcode.K8sResourceNotFoundError, err) {
+				return "", err
+			}
+
+			return entity.TaskStatusCleanAliLogConfigFinish, nil
+		}
+
+		return entity.TaskStatusCleanAliLogConfigUnderway, nil
+	case entity.TaskStatusCleanAliLogConfigUnderway:
+		_, err := s.GetAliLogConfigDetail(ctx, task.ClusterName,
+			&req.GetAliLogConfigDetailReq{
+				Namespace: task.Namespace,
+				Name:      app.AliLogConfigName,
+				Env:       string(task.EnvName),
+			})
+		if err != nil {
+			if errcode.EqualError(_errcode.K8sResourceNotFoundError, err) {
+				return entity.TaskStatusCleanAliLogConfigFinish, nil
+			}
+			return "", err
+		}
+	case entity.TaskStatusCleanAliLogConfigFinish:
+		// 移除 redis 中的部署记录
+		err := s.dao.RemoveAppClusterRunningTasks(ctx, task.AppID, task.EnvName, task.ClusterName, task.Version)
+		if err != nil {
+			return "", err
+		}
+		return entity.TaskStatusSuccess, nil
+	default:
+		return "", _errcode.InvalidTaskStatusError
+	}
+	return "", nil
+}
+
+// transformWorkloadCleanTaskStatus 删除应用时清理可插拔工作负载, 完成后继续通用的清理流程
+func (s *Service) transformWorkloadCleanTaskStatus(ctx context.Context, task *resp.TaskDetailResp,
+	w workload, wt *entity.WorkloadType) (entity.TaskStatus, error) {
+	listReq := &req.GetWorkloadsReq{
+		Namespace:   task.Namespace,
+		ProjectName: task.Param.CleanedProjectName,
+		AppName:     task.Param.CleanedAppName,
+		Env:         string(task.EnvName),
+	}
+
+	switch task.Status {
+	case entity.TaskStatusInit:
+		if task.Param.CleanedProjectName == "" || task.Param.CleanedAppName == "" {
+			return wt.Phases.CleanFinish, nil
+		}
+
+		list, err := w.List(ctx, task.ClusterName, listReq)
+		if err != nil {
+			return "", err
+		}
+
+		for _, item := range list {
+			err = w.Delete(ctx, task.ClusterName, &req.DeleteWorkloadReq{
+				Namespace: item.Namespace,
+				Name:      item.Name,
+				Env:       string(task.EnvName),
+			})
+			if err != nil && !errcode.EqualError(_errcode.K8sResourceNotFoundError, err) {
+				return "", err
+			}
+		}
+		return wt.Phases.CleanUnderway, nil
+	case wt.Phases.CleanUnderway:
+		list, err := w.List(ctx, task.ClusterName, listReq)
+		if err != nil {
+			return "", err
+		}
+
+		if len(list) == 0 {
+			return wt.Phases.CleanFinish, nil
+		}
+	case wt.Phases.CleanFinish:
+		return entity.TaskStatusCleanDeploymentFinish, nil
+	default:
+		return "", _errcode.InvalidTaskStatusError
+	}
+	return "", nil
+}
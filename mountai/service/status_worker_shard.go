@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gitlab.shanhai.int/sre/library/log"
+	"gitlab.shanhai.int/sre/library/net/redlock"
+
+	"rulai/config"
+	"rulai/models/entity"
+	"rulai/models/resp"
+	"rulai/utils"
+)
+
+const (
+	defaultStatusWorkerShardCount  = 64
+	defaultStatusWorkerLeaseExpiry = 30 * time.Second
+)
+
+// 分片归属, 当前副本持有租约的分片为 1
+var StatusWorkerShardOwner = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "status_worker_shard_owner",
+	},
+	[]string{"shard", "replica"},
+)
+
+// 分片内待处理的任务数
+var StatusWorkerShardPendingTasks = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "status_worker_shard_pending_tasks",
+	},
+	[]string{"shard"},
+)
+
+// 分片延迟, 即分片内最久未变更的待处理任务距今的秒数
+var StatusWorkerShardLagSeconds = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "status_worker_shard_lag_seconds",
+	},
+	[]string{"shard"},
+)
+
+// 存活的状态处理副本数
+var StatusWorkerReplicas = prometheus.NewGauge(
+	prometheus.GaugeOpts{
+		Name: "status_worker_replicas",
+	},
+)
+
+// StatusWorkerShardCollector 状态处理分片收集器
+var StatusWorkerShardCollector = []prometheus.Collector{
+	StatusWorkerShardOwner, StatusWorkerShardPendingTasks, StatusWorkerShardLagSeconds, StatusWorkerReplicas,
+}
+
+// StatusWorkerSharder 状态处理任务的分片管理
+// 应用按 id 哈希到固定数量的分片, 分片再按一致性哈希分配给存活副本, 副本通过续约租约持有分片
+// 副本宕机后心跳及租约过期, 其分片由其余副本接管
+type StatusWorkerSharder struct {
+	s            *Service
+	replicaID    string
+	shardCount   int
+	expiry       time.Duration
+	virtualNodes int
+
+	mu     sync.RWMutex
+	leases map[int]*redlock.Mutex
+}
+
+// NewStatusWorkerSharder 新建状态处理任务的分片管理
+func (s *Service) NewStatusWorkerSharder() *StatusWorkerSharder {
+	w := &StatusWorkerSharder{
+		s:          s,
+		shardCount: defaultStatusWorkerShardCount,
+		expiry:     defaultStatusWorkerLeaseExpiry,
+		leases:     make(map[int]*redlock.Mutex),
+	}
+
+	if c := config.Conf.StatusWorker; c != nil {
+		if c.ShardCount > 0 {
+			w.shardCount = c.ShardCount
+		}
+		if c.LeaseExpiry > 0 {
+			w.expiry = time.Duration(c.LeaseExpiry)
+		}
+		w.virtualNodes = c.VirtualNodes
+	}
+
+	hostname, _ := os.Hostname()
+	w.replicaID = fmt.Sprintf("%s-%d", hostname, os.Getpid())
+
+	return w
+}
+
+// Run 定期上报心跳并重新分配分片, 退出时释放持有的分片
+// 续约与任务处理相互独立, 避免单轮任务处理过久导致租约过期
+func (w *StatusWorkerSharder) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.expiry / 3)
+	defer ticker.Stop()
+
+	for {
+		err := w.Rebalance(ctx)
+		if err != nil {
+			log.Errorc(ctx, "rebalance status worker shards error: %s", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			w.Release(context.Background())
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Rebalance 根据存活副本计算应持有的分片, 续约/获取应持有的分片, 释放不再属于当前副本的分片
+func (w *StatusWorkerSharder) Rebalance(ctx context.Context) error {
+	err := w.s.dao.HeartbeatTaskStatusWorkerReplica(ctx, w.replicaID, w.expiry)
+	if err != nil {
+		return err
+	}
+
+	replicas, err := w.s.dao.GetTaskStatusWorkerReplicas(ctx, w.expiry)
+	if err != nil {
+		return err
+	}
+	StatusWorkerReplicas.Set(float64(len(replicas)))
+
+	ring := utils.NewHashRing(replicas, w.virtualNodes)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for shard := 0; shard < w.shardCount; shard++ {
+		desired := ring.Get(strconv.Itoa(shard)) == w.replicaID
+		lease, held := w.leases[shard]
+
+		switch {
+		case held && desired:
+			if !lease.Extend(ctx) {
+				log.Warnc(ctx, "status worker shard(%d) lease lost by replica(%s)", shard, w.replicaID)
+				w.dropShard(shard)
+			}
+		case held && !desired:
+			lease.Unlock(ctx)
+			w.dropShard(shard)
+		case !held && desired:
+			// 原持有者可能尚未释放, 租约过期后下一轮再获取
+			lease = w.s.dao.GetTaskStatusWorkerShardLease(ctx, shard, w.expiry)
+			if lease.Lock(ctx) != nil {
+				continue
+			}
+			w.leases[shard] = lease
+			StatusWorkerShardOwner.WithLabelValues(strconv.Itoa(shard), w.replicaID).Set(1)
+		}
+	}
+
+	return nil
+}
+
+// Release 释放全部分片并移除副本, 其余副本可立即接管
+func (w *StatusWorkerSharder) Release(ctx context.Context) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for shard, lease := range w.leases {
+		lease.Unlock(ctx)
+		w.dropShard(shard)
+	}
+
+	err := w.s.dao.RemoveTaskStatusWorkerReplica(ctx, w.replicaID)
+	if err != nil {
+		log.Errorc(ctx, "remove status worker replica(%s) error: %s", w.replicaID, err)
+	}
+}
+
+func (w *StatusWorkerSharder) dropShard(shard int) {
+	delete(w.leases, shard)
+	StatusWorkerShardOwner.DeleteLabelValues(strconv.Itoa(shard), w.replicaID)
+	StatusWorkerShardPendingTasks.DeleteLabelValues(strconv.Itoa(shard))
+	StatusWorkerShardLagSeconds.DeleteLabelValues(strconv.Itoa(shard))
+}
+
+// Shard 获取应用所属分片
+func (w *StatusWorkerSharder) Shard(appID string) int {
+	return utils.HashShard(appID, w.shardCount)
+}
+
+// FilterOwnedTasks 过滤出当前副本持有分片内的任务, 并上报各分片的待处理任务数及延迟
+func (w *StatusWorkerSharder) FilterOwnedTasks(tasks []*resp.TaskDetailResp) []*resp.TaskDetailResp {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	now := time.Now()
+	pending := make(map[int]int, len(w.leases))
+	lag := make(map[int]time.Duration, len(w.leases))
+	for shard := range w.leases {
+		pending[shard] = 0
+		lag[shard] = 0
+	}
+
+	res := make([]*resp.TaskDetailResp, 0)
+	for _, task := range tasks {
+		shard := w.Shard(task.AppID)
+		if _, ok := w.leases[shard]; !ok {
+			continue
+		}
+
+		res = append(res, task)
+		pending[shard]++
+		if d := now.Sub(getTaskPendingSince(task)); d > lag[shard] {
+			lag[shard] = d
+		}
+	}
+
+	for shard := range w.leases {
+		StatusWorkerShardPendingTasks.WithLabelValues(strconv.Itoa(shard)).Set(float64(pending[shard]))
+		StatusWorkerShardLagSeconds.WithLabelValues(strconv.Itoa(shard)).Set(lag[shard].Seconds())
+	}
+
+	return res
+}
+
+// getTaskPendingSince 任务开始等待处理的时间, 定时任务从计划时间算起
+func getTaskPendingSince(task *resp.TaskDetailResp) time.Time {
+	since := task.UpdateTime
+	if task.Status == entity.TaskStatusInit && task.DeployType == entity.ScheduledTaskDeployType {
+		since = task.ScheduleTime
+	}
+
+	t, err := time.ParseInLocation(utils.DefaultTimeFormatLayout, since, time.Local)
+	if err != nil {
+		return time.Now()
+	}
+	return t
+}
@@ -82,6 +82,11 @@ func (s *Service) CreateTask(ctx context.Context, project *resp.ProjectDetailRes
 		return nil, err
 	}
 
+	err = validateTaskPDB(app, task)
+	if err != nil {
+		return nil, err
+	}
+
 	if task.Approval.Type == entity.DefaultTaskApprovalType {
 		approvalReq, e := s.generateDingApprovalReq(ctx, task, project, app)
 		if e != nil {
@@ -144,6 +149,14 @@ func (s *Service) CreateTask(ctx context.Context, project *resp.ProjectDetailRes
 		}
 	}
 
+	// 封网期间强制变更, 记录并通知项目负责人
+	if createReq.FreezeWindowID != "" {
+		e := s.recordFreezeOverride(ctx, project, app, task, createReq.FreezeWindowID)
+		if e != nil {
+			log.Errorc(ctx, "record freeze override of task(%s) err: %v", task.ID.Hex(), e)
+		}
+	}
+
 	// 创建 task 后, 如果是初始发布操作, 添加部署信息至 redis
 	for _, act := range entity.TaskActionInitDeployList {
 		if act != task.Action {
@@ -652,8 +665,8 @@ func (s *Service) GetSingleTask(ctx context.Context, getReq *req.GetTasksReq) (*
 
 // 获取上次部署成功的任务最后的运行参数(加上hpa)
 func (s *Service) GetLatestDeploySuccessTaskFinalVersion(ctx context.Context, getReq *req.GetLatestTaskReq) (*resp.TaskDetailResp, error) {
-	// 获取最后deploy成功的任务
-	getReq.ActionList = entity.TaskActionInitDeployList
+	// 获取最后deploy成功的任务(包括回滚)
+	getReq.ActionList = entity.TaskActionFinalVersionList
 	task, err := s.GetLatestSuccessTask(ctx, getReq)
 	if err != nil {
 		return nil, err
@@ -679,6 +692,8 @@ func (s *Service) GetLatestDeploySuccessTaskFinalVersion(ctx context.Context, ge
 			task.Param.IsAutoScale = true
 			task.Param.MinPodCount = hpaTask.Param.MinPodCount
 			task.Param.MaxPodCount = hpaTask.Param.MaxPodCount
+			task.Param.HPAMetrics = hpaTask.Param.HPAMetrics
+			task.Param.HPABehavior = hpaTask.Param.HPABehavior
 		}
 		return nil
 	})
@@ -1032,6 +1047,7 @@ func (s *Service) CreateLatestDeployTask(ctx context.Context, clusterName entity
 
 	createReq.Version = ""
 	createReq.Action = entity.TaskActionFullDeploy
+	createReq.FreezeOverrideReason, createReq.ErrorBudgetOverrideReason = "", ""
 	createReq.Approval, createReq.DeployType, createReq.ScheduleTime = new(req.ApprovalReq), "", 0
 
 	_, err = s.CreateTask(ctx, project, app, createReq, operatorID)
@@ -1042,6 +1058,88 @@ func (s *Service) CreateLatestDeployTask(ctx context.Context, clusterName entity
 	return nil
 }
 
+// FillRollbackTaskReq : 使用上一次部署成功的任务参数填充回滚任务
+func (s *Service) FillRollbackTaskReq(ctx context.Context, createReq *req.CreateTaskReq) error {
+	task, err := s.GetLatestDeploySuccessTaskFinalVersion(ctx, &req.GetLatestTaskReq{
+		AppID:       createReq.AppID,
+		EnvName:     createReq.EnvName,
+		ClusterName: createReq.ClusterName,
+		Version:     createReq.Version,
+	})
+	if err != nil {
+		return err
+	}
+
+	if task.Param == nil || task.Param.ImageVersion == "" {
+		return errors.Wrapf(_errcode.NoRequiredTaskError, "no image version in latest task(%s)", task.ID)
+	}
+
+	param := new(req.CreateTaskParamReq)
+	err = deepcopy.Copy(task.Param).To(param)
+	if err != nil {
+		return errors.Wrap(errcode.InternalError, err.Error())
+	}
+
+	createReq.Version = task.Version
+	createReq.Param = param
+
+	return nil
+}
+
+// AutoRollbackTask : 全量部署失败后, 若应用开启了自动回滚则创建回滚任务
+func (s *Service) AutoRollbackTask(ctx context.Context, failedTask *resp.TaskDetailResp) error {
+	if failedTask.Action != entity.TaskActionFullDeploy {
+		return nil
+	}
+
+	app, err := s.GetAppDetail(ctx, failedTask.AppID)
+	if err != nil {
+		return err
+	}
+
+	if app.Type != entity.AppTypeService && app.Type != entity.AppTypeWorker {
+		return nil
+	}
+
+	if env, ok := app.Env[failedTask.EnvName]; !ok || !env.EnableAutoRollback {
+		return nil
+	}
+
+	project, err := s.GetProjectDetail(ctx, app.ProjectID)
+	if err != nil {
+		return err
+	}
+
+	createReq := &req.CreateTaskReq{
+		AppID:         failedTask.AppID,
+		Version:       failedTask.Version,
+		Description:   fmt.Sprintf("全量部署任务(%s)失败, 自动回滚", failedTask.ID),
+		ClusterName:   failedTask.ClusterName,
+		EnvName:       failedTask.EnvName,
+		Action:        entity.TaskActionRollback,
+		Namespace:     failedTask.Namespace,
+		RelatedTaskID: failedTask.ID,
+	}
+	err = s.FillRollbackTaskReq(ctx, createReq)
+	if err != nil {
+		// 首次部署即失败时没有可回滚的版本
+		if errcode.EqualError(_errcode.NoRequiredTaskError, err) {
+			log.Warnc(ctx, "skip auto rollback of task(%s): %s", failedTask.ID, err)
+			return nil
+		}
+		return err
+	}
+
+	task, err := s.CreateTask(ctx, project, app, createReq, entity.K8sSystemUserID)
+	if err != nil {
+		return err
+	}
+
+	log.Infoc(ctx, "auto rollback task(%s) created for failed task(%s)", task.ID, failedTask.ID)
+
+	return nil
+}
+
 // DeleteAppTasks : 删除应用所有的任务
 func (s *Service) DeleteAppTasks(ctx context.Context, appID string) error {
 	err := s.dao.DeleteTasks(ctx, bson.M{
@@ -1167,6 +1265,10 @@ func (s *Service) generateUpdateTaskMap(_ context.Context, task *resp.TaskDetail
 		change["approval.status"] = updateReq.ApprovalStatus
 	}
 
+	if updateReq.CanaryVerdicts != nil {
+		change["canary_verdicts"] = updateReq.CanaryVerdicts
+	}
+
 	if updateReq.Status != "" {
 		change["status"] = updateReq.Status
 	}
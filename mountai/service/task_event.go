@@ -0,0 +1,165 @@
+package service
+
+import (
+	"rulai/dao"
+	"rulai/models/entity"
+	"rulai/models/req"
+	"rulai/models/resp"
+	"rulai/utils"
+
+	"context"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"gitlab.shanhai.int/sre/library/base/deepcopy.v2"
+	"gitlab.shanhai.int/sre/library/log"
+	"gitlab.shanhai.int/sre/library/net/errcode"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// taskEventWorker 当前 worker 实例名
+var taskEventWorker, _ = os.Hostname()
+
+type taskObjectVersionsKey struct{}
+
+// taskObjectVersions 状态流转过程中 apply 的 K8s 对象版本
+type taskObjectVersions struct {
+	sync.Mutex
+	versions map[string]string
+}
+
+// withTaskObjectVersions 在 context 中开启 K8s 对象版本收集
+func withTaskObjectVersions(ctx context.Context) context.Context {
+	return context.WithValue(ctx, taskObjectVersionsKey{}, &taskObjectVersions{versions: make(map[string]string)})
+}
+
+// recordTaskObjectVersion 记录 apply 后的 K8s 对象版本, context 未开启收集时忽略
+func recordTaskObjectVersion(ctx context.Context, kind string, obj metav1.Object) {
+	collector, ok := ctx.Value(taskObjectVersionsKey{}).(*taskObjectVersions)
+	if !ok {
+		return
+	}
+
+	collector.Lock()
+	defer collector.Unlock()
+	collector.versions[kind+"/"+obj.GetName()] = obj.GetResourceVersion()
+}
+
+func getTaskObjectVersions(ctx context.Context) map[string]string {
+	collector, ok := ctx.Value(taskObjectVersionsKey{}).(*taskObjectVersions)
+	if !ok {
+		return nil
+	}
+
+	collector.Lock()
+	defer collector.Unlock()
+	if len(collector.versions) == 0 {
+		return nil
+	}
+
+	res := make(map[string]string, len(collector.versions))
+	for k, v := range collector.versions {
+		res[k] = v
+	}
+	return res
+}
+
+// recordTaskEvent 记录任务状态变化或出错事件, 记录失败不影响任务流转
+func (s *Service) recordTaskEvent(ctx context.Context, projectID string, task *resp.TaskDetailResp,
+	nextStatus entity.TaskStatus, transformErr error) {
+	now := time.Now()
+	event := &entity.TaskEvent{
+		ID:             primitive.NewObjectID(),
+		TaskID:         task.ID,
+		AppID:          task.AppID,
+		ProjectID:      projectID,
+		EnvName:        task.EnvName,
+		ClusterName:    task.ClusterName,
+		Action:         task.Action,
+		FromStatus:     task.Status,
+		ToStatus:       task.Status,
+		Worker:         taskEventWorker,
+		RetryCount:     task.RetryCount,
+		ObjectVersions: getTaskObjectVersions(ctx),
+		CreateTime:     &now,
+	}
+
+	if transformErr != nil {
+		event.Error = transformErr.Error()
+	} else {
+		event.ToStatus = nextStatus
+		event.DurationMs = now.Sub(s.getTaskPhaseStartTime(ctx, task)).Milliseconds()
+	}
+
+	err := s.dao.CreateTaskEvent(ctx, event)
+	if err != nil {
+		log.Errorc(ctx, "create event of task(%s) err: %v", task.ID, err)
+	}
+}
+
+// getTaskPhaseStartTime 获取任务当前阶段的开始时间, 即上一次状态变化的时间
+func (s *Service) getTaskPhaseStartTime(ctx context.Context, task *resp.TaskDetailResp) time.Time {
+	event, err := s.dao.FindLatestTaskEvent(ctx, bson.M{
+		"task_id": task.ID,
+		"$expr":   bson.M{"$ne": bson.A{"$from_status", "$to_status"}},
+	})
+	if err == nil && event.CreateTime != nil {
+		return *event.CreateTime
+	}
+
+	createTime, err := time.ParseInLocation(utils.DefaultTimeFormatLayout, task.CreateTime, time.Local)
+	if err != nil {
+		return time.Now()
+	}
+	return createTime
+}
+
+// GetTaskEvents 获取任务的状态流转事件
+func (s *Service) GetTaskEvents(ctx context.Context, taskID string) ([]*resp.TaskEventDetail, error) {
+	events, err := s.dao.FindTaskEvents(ctx, bson.M{"task_id": taskID}, dao.MongoFindOptionWithSortByIDAsc)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*resp.TaskEventDetail, 0)
+	err = deepcopy.Copy(&events).To(&res)
+	if err != nil {
+		return nil, errors.Wrap(errcode.InternalError, err.Error())
+	}
+
+	return res, nil
+}
+
+// GetTaskPhaseStats 按项目, 环境, 集群及任务类型统计各阶段耗时
+func (s *Service) GetTaskPhaseStats(ctx context.Context, getReq *req.GetTaskPhaseStatsReq) ([]*entity.TaskPhaseStat, error) {
+	filter := bson.M{}
+	if getReq.ProjectID != "" {
+		filter["project_id"] = getReq.ProjectID
+	}
+	if getReq.EnvName != "" {
+		filter["env_name"] = getReq.EnvName
+	}
+	if getReq.ClusterName != "" {
+		filter["cluster_name"] = getReq.ClusterName
+	}
+	if getReq.Action != "" {
+		filter["action"] = getReq.Action
+	}
+
+	timeFilter := bson.M{}
+	if getReq.MinTimestamp > 0 {
+		timeFilter["$gte"] = time.Unix(getReq.MinTimestamp, 0)
+	}
+	if getReq.MaxTimestamp > 0 {
+		timeFilter["$lte"] = time.Unix(getReq.MaxTimestamp, 0)
+	}
+	if len(timeFilter) > 0 {
+		filter["create_time"] = timeFilter
+	}
+
+	return s.dao.AggregateTaskPhaseStats(ctx, filter)
+}
@@ -0,0 +1,30 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestService_TaskObjectVersions(t *testing.T) {
+	cm := &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cm", ResourceVersion: "1"}}
+
+	t.Run("disabled", func(t *testing.T) {
+		ctx := context.Background()
+		recordTaskObjectVersion(ctx, "ConfigMap", cm)
+		assert.Nil(t, getTaskObjectVersions(ctx))
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		ctx := withTaskObjectVersions(context.Background())
+		assert.Nil(t, getTaskObjectVersions(ctx))
+
+		recordTaskObjectVersion(ctx, "ConfigMap", cm)
+		cm.ResourceVersion = "2"
+		recordTaskObjectVersion(ctx, "ConfigMap", cm)
+		assert.Equal(t, map[string]string{"ConfigMap/cm": "2"}, getTaskObjectVersions(ctx))
+	})
+}
@@ -0,0 +1,371 @@
+package service
+
+import (
+	"rulai/models/entity"
+	"rulai/models/req"
+	"rulai/models/resp"
+	"rulai/utils"
+	_errcode "rulai/utils/errcode"
+
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gitlab.shanhai.int/sre/library/base/deepcopy.v2"
+	"gitlab.shanhai.int/sre/library/net/errcode"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+const (
+	// 预览中隐藏的配置内容
+	taskPreviewRedactedValue = "<redacted>"
+	// 钉钉审批表单中展示的最大差异条数
+	taskPreviewMaxFormDiffs = 30
+)
+
+// k8sObjectKindResources 预览时获取线上对象所需的资源名
+var k8sObjectKindResources = map[string]string{
+	entity.K8sObjectKindAliyunLogConfig: "aliyunlogconfigs",
+	entity.K8sObjectKindConfigMap:       "configmaps",
+	entity.K8sObjectKindCronHPA:         "cronhorizontalpodautoscalers",
+	entity.K8sObjectKindDeployment:      "deployments",
+	entity.K8sObjectKindHPA:             "horizontalpodautoscalers",
+	entity.K8sObjectKindIngress:         "ingresses",
+	entity.K8sObjectKindService:         "services",
+	entity.K8sObjectKindVirtualService:  "virtualservices",
+}
+
+// taskPreviewExactPaths 按全量比较的字段, 线上多出的键视为将被删除
+var taskPreviewExactPaths = []string{"metadata.labels", "data"}
+
+// PreviewTask 预览任务将要 apply 的 K8s 对象与线上对象的差异, 不会创建任务或修改集群
+func (s *Service) PreviewTask(ctx context.Context, project *resp.ProjectDetailResp, app *resp.AppDetailResp,
+	createReq *req.CreateTaskReq, operatorID string) (*resp.TaskPreviewResp, error) {
+	task, err := s.generateTaskEntity(ctx, project, app, createReq, operatorID)
+	if err != nil {
+		return nil, err
+	}
+
+	objects, err := s.previewTaskObjects(ctx, project, app, task)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp.TaskPreviewResp{Objects: objects}, nil
+}
+
+// previewTaskObjects 渲染任务涉及的所有模板并与线上对象比较
+func (s *Service) previewTaskObjects(ctx context.Context, project *resp.ProjectDetailResp, app *resp.AppDetailResp,
+	taskEntity *entity.Task) ([]*resp.K8sObjectPreview, error) {
+	if app.Type != entity.AppTypeService && app.Type != entity.AppTypeWorker {
+		return nil, errors.Wrapf(errcode.InvalidParams, "app type %s does not support preview", app.Type)
+	}
+
+	task := new(resp.TaskDetailResp)
+	err := deepcopy.Copy(taskEntity).To(task)
+	if err != nil {
+		return nil, errors.Wrap(errcode.InternalError, err.Error())
+	}
+
+	// 与状态机保持一致, 不修改调用方的应用信息
+	previewApp := *app
+	previewApp.EnableIstio = false
+	app = &previewApp
+	team := project.Team
+
+	rendered := make([][]byte, 0)
+	render := func(data []byte, e error) error {
+		if e != nil {
+			return e
+		}
+		rendered = append(rendered, data)
+		return nil
+	}
+
+	switch task.Action {
+	case entity.TaskActionFullDeploy, entity.TaskActionCanaryDeploy:
+		err = s.renderPreviewLogConfig(ctx, project, app, task, render)
+		if err != nil {
+			return nil, err
+		}
+
+		err = render(s.renderPreviewConfigMap(ctx, project, app, task))
+		if err != nil {
+			return nil, err
+		}
+
+		err = render(s.RenderDeploymentTemplate(ctx, project, app, task, team))
+		if err != nil {
+			return nil, err
+		}
+
+		// 金丝雀部署不创建 HPA
+		if task.Action == entity.TaskActionFullDeploy {
+			err = s.renderPreviewHPA(ctx, project, app, task, render)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		data, e := s.renderPreviewServiceObjects(ctx, project, app, task)
+		if e != nil {
+			return nil, e
+		}
+		rendered = append(rendered, data...)
+	case entity.TaskActionUpdateHPA:
+		err = s.renderPreviewHPA(ctx, project, app, task, render)
+		if err != nil {
+			return nil, err
+		}
+	case entity.TaskActionReloadConfig:
+		err = render(s.renderPreviewConfigMap(ctx, project, app, task))
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.Wrapf(errcode.InvalidParams, "action %s does not support preview", task.Action)
+	}
+
+	runningVersion, err := s.getPreviewRunningVersion(ctx, task)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*resp.K8sObjectPreview, 0, len(rendered))
+	for _, data := range rendered {
+		if len(data) == 0 {
+			continue
+		}
+
+		preview, e := s.diffRenderedK8sObject(ctx, task, runningVersion, data)
+		if e != nil {
+			return nil, e
+		}
+		res = append(res, preview)
+	}
+
+	return res, nil
+}
+
+// getPreviewRunningVersion 获取线上运行的版本, 首次部署时返回空
+func (s *Service) getPreviewRunningVersion(ctx context.Context, task *resp.TaskDetailResp) (string, error) {
+	runningTask, err := s.GetLatestDeploySuccessTaskFinalVersion(ctx, &req.GetLatestTaskReq{
+		AppID:       task.AppID,
+		EnvName:     task.EnvName,
+		ClusterName: task.ClusterName,
+	})
+	if errcode.EqualError(_errcode.NoRequiredTaskError, err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return runningTask.Version, nil
+}
+
+// renderPreviewLogConfig 渲染日志采集配置, 目前仅阿里云使用模板创建
+func (s *Service) renderPreviewLogConfig(ctx context.Context, project *resp.ProjectDetailResp, app *resp.AppDetailResp,
+	task *resp.TaskDetailResp, render func([]byte, error) error) error {
+	clusterInfo, err := s.getClusterInfo(task.ClusterName, string(task.EnvName))
+	if err != nil {
+		return err
+	}
+
+	if clusterInfo.disableLogConfig || clusterInfo.vendor != entity.VendorAli {
+		return nil
+	}
+
+	return render(s.RenderAliLogConfigTemplate(ctx, project, app, task, project.Team))
+}
+
+// renderPreviewConfigMap 渲染配置文件 ConfigMap, 未指定配置时跳过
+func (s *Service) renderPreviewConfigMap(ctx context.Context, project *resp.ProjectDetailResp, app *resp.AppDetailResp,
+	task *resp.TaskDetailResp) ([]byte, error) {
+	if task.Param.ConfigCommitID == "" {
+		return nil, nil
+	}
+
+	getReq := &req.GetConfigManagerFileReq{
+		ProjectID:   project.ID,
+		ProjectName: project.Name,
+		EnvName:     task.EnvName,
+		CommitID:    task.Param.ConfigCommitID,
+		IsDecrypt:   true,
+		FormatType:  req.ConfigManagerFormatTypeJSON,
+	}
+
+	if task.Param.ConfigRenamePrefix != "" {
+		getReq.ConfigRenamePrefix = task.Param.ConfigRenamePrefix
+		getReq.ConfigRenameMode = task.Param.ConfigRenameMode
+	}
+
+	configData, err := s.GetAppConfig(ctx, getReq)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.RenderConfigMapTemplate(ctx, project, app, task, project.Team, configData.Config)
+}
+
+// renderPreviewHPA 渲染 HPA 及 CronHPA
+func (s *Service) renderPreviewHPA(ctx context.Context, project *resp.ProjectDetailResp, app *resp.AppDetailResp,
+	task *resp.TaskDetailResp, render func([]byte, error) error) error {
+	if task.Param.IsAutoScale {
+		err := render(s.RenderHPATemplate(ctx, project, app, task, project.Team))
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(task.Param.CronScaleJobGroups) > 0 {
+		return render(s.RenderCronHPATemplate(ctx, project, app, task, project.Team))
+	}
+
+	return nil
+}
+
+// renderPreviewServiceObjects 渲染 Service 及 Ingress/VirtualService
+func (s *Service) renderPreviewServiceObjects(ctx context.Context, project *resp.ProjectDetailResp,
+	app *resp.AppDetailResp, task *resp.TaskDetailResp) ([][]byte, error) {
+	if app.Type != entity.AppTypeService {
+		return nil, nil
+	}
+
+	serviceName, err := s.GetCurrentServiceName(ctx, task.ClusterName, task.EnvName, app)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.RenderServiceTemplates(ctx, project, app, task, project.Team, serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	if app.ServiceExposeType != entity.AppServiceExposeTypeIngress {
+		return res, nil
+	}
+
+	if s.GetApplicationIstioState(ctx, task.EnvName, task.ClusterName, app) {
+		data, e := s.RenderVirtualServiceTemplate(ctx, project, app, task, serviceName)
+		if e != nil {
+			return nil, e
+		}
+
+		return append(res, []byte(data)), nil
+	}
+
+	data, err := s.RenderIngressTemplate(ctx, project, app, task, serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(res, data), nil
+}
+
+// diffRenderedK8sObject 获取渲染对象对应的线上对象并比较字段差异
+// 以版本命名的对象(Deployment/HPA 等)与线上运行版本的对象比较
+func (s *Service) diffRenderedK8sObject(ctx context.Context, task *resp.TaskDetailResp, runningVersion string,
+	data []byte) (*resp.K8sObjectPreview, error) {
+	desired := new(unstructured.Unstructured)
+	err := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), len(data)).Decode(&desired.Object)
+	if err != nil {
+		return nil, errors.Wrapf(_errcode.K8sInternalError, "decode rendered yaml error: %s", err)
+	}
+
+	kind := desired.GetKind()
+	preview := &resp.K8sObjectPreview{
+		Kind:      kind,
+		Namespace: desired.GetNamespace(),
+		Name:      desired.GetName(),
+		Diffs:     make([]*resp.K8sObjectFieldDiff, 0),
+	}
+
+	resource, ok := k8sObjectKindResources[kind]
+	if !ok {
+		return nil, errors.Wrapf(errcode.InternalError, "invalid k8s API kind(%s)", kind)
+	}
+
+	gv, err := schema.ParseGroupVersion(desired.GetAPIVersion())
+	if err != nil {
+		return nil, errors.Wrap(_errcode.K8sInternalError, err.Error())
+	}
+
+	c, err := s.GetK8sDynamicClient(task.ClusterName, string(task.EnvName))
+	if err != nil {
+		return nil, errors.Wrap(_errcode.K8sInternalError, err.Error())
+	}
+
+	liveName := preview.Name
+	if runningVersion != "" && preview.Name == task.Version {
+		liveName = runningVersion
+	}
+
+	live, err := c.Resource(gv.WithResource(resource)).Namespace(preview.Namespace).
+		Get(ctx, liveName, metav1.GetOptions{})
+	if k8sErrors.IsNotFound(err) {
+		preview.Action = resp.K8sObjectPreviewActionCreate
+		return preview, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(_errcode.K8sInternalError, err.Error())
+	}
+
+	// 名称统一为新版本, 仅展示内容差异
+	live.SetName(preview.Name)
+
+	preview.Diffs = utils.DiffObjectFields(live.Object, desired.Object, taskPreviewExactPaths...)
+	preview.Action = resp.K8sObjectPreviewActionUpdate
+	if len(preview.Diffs) == 0 {
+		preview.Action = resp.K8sObjectPreviewActionUnchanged
+	}
+
+	// 配置内容可能包含解密后的敏感信息, 仅展示变化的键
+	if kind == entity.K8sObjectKindConfigMap {
+		for _, diff := range preview.Diffs {
+			if !strings.HasPrefix(diff.Path, "data") {
+				continue
+			}
+			if diff.Old != nil {
+				diff.Old = taskPreviewRedactedValue
+			}
+			if diff.New != nil {
+				diff.New = taskPreviewRedactedValue
+			}
+		}
+	}
+
+	return preview, nil
+}
+
+// generateTaskPreviewSummary 生成任务变更预览摘要, 附加至钉钉审批表单
+func (s *Service) generateTaskPreviewSummary(ctx context.Context, task *entity.Task,
+	project *resp.ProjectDetailResp, app *resp.AppDetailResp) string {
+	objects, err := s.previewTaskObjects(ctx, project, app, task)
+	if err != nil {
+		return fmt.Sprintf("预览失败: %s", err)
+	}
+
+	builder := strings.Builder{}
+	count := 0
+	for _, object := range objects {
+		builder.WriteString(fmt.Sprintf("%s %s/%s: %s\n", object.Kind, object.Namespace, object.Name, object.Action))
+		for _, diff := range object.Diffs {
+			if count >= taskPreviewMaxFormDiffs {
+				builder.WriteString("...\n")
+				return builder.String()
+			}
+
+			builder.WriteString(fmt.Sprintf("  %s %s: %v -> %v\n", diff.Type, diff.Path, diff.Old, diff.New))
+			count++
+		}
+	}
+
+	return builder.String()
+}
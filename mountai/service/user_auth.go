@@ -1,6 +1,7 @@
 package service
 
 import (
+	"rulai/config"
 	"rulai/dao"
 	"rulai/models/entity"
 	"rulai/models/req"
@@ -82,6 +83,20 @@ func (s *Service) GetUserByID(ctx context.Context, id string) (*entity.UserAuth,
 	return s.dao.FindSingleUserAuth(ctx, bson.M{"_id": id})
 }
 
+// IsSREUser 是否为 SRE 用户, k8s系统用户视为 SRE
+func (s *Service) IsSREUser(operatorID string) bool {
+	if operatorID == entity.K8sSystemUserID {
+		return true
+	}
+
+	for _, id := range config.Conf.Other.SREUserIDs {
+		if id == operatorID {
+			return true
+		}
+	}
+	return false
+}
+
 // 是否有权限
 func (s *Service) ValidateHasPermission(ctx context.Context, validateReq *req.ValidateHasPermissionReq) error {
 	// k8s系统用户拥有权限
@@ -116,7 +131,8 @@ func (s *Service) ValidateHasPermission(ctx context.Context, validateReq *req.Va
 	switch validateReq.OperateType {
 	case entity.OperateTypeDeleteProject, entity.OperateTypeDeleteApp, entity.OperateTypeCorrectAppName,
 		entity.OperateTypeReadVariableValue, entity.OperateTypeUpdateVariableValue,
-		entity.OperateTypeCreateVariableValue, entity.OperateTypeDeleteVariableValue, entity.OperateTypeDeleteJob:
+		entity.OperateTypeCreateVariableValue, entity.OperateTypeDeleteVariableValue, entity.OperateTypeDeleteJob,
+		entity.OperateTypeManageNotification, entity.OperateTypeManageSLO:
 		if member.AccessLevel == entity.GitMemberAccessOwner || member.AccessLevel == entity.GitMemberAccessMaintainer {
 			return nil
 		}
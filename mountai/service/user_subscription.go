@@ -79,7 +79,7 @@ func (p *P0AppOperation) ShouldHandle(_ context.Context) (bool, error) {
 
 // Handle implements MessageOperator
 func (p *P0AppOperation) Handle(ctx context.Context) error {
-	msgReq, err := p.generateRobotMessageReq(&req.AppOpMessage{
+	msg := newNotificationMessage(p.message, p.project, p.task, &req.AppOpMessage{
 		Title:           fmt.Sprintf("%s 进行了%s操作", p.project.Name, entity.GetSubscribeActionDisplay(p.message.ActionType)),
 		ProjectName:     p.project.Name,
 		AppName:         p.app.Name,
@@ -92,11 +92,8 @@ func (p *P0AppOperation) Handle(ctx context.Context) error {
 		TeamName:        p.project.Team.Name,
 		Branch:          p.task.GetDeployBranch(),
 	})
-	if err != nil {
-		return errors.Wrapf(err, "send p0 operation msg fail, task id: %s", p.message.TaskID)
-	}
 
-	if err = p.SendRobotMsgToFeishu(ctx, msgReq); err != nil {
+	if err := p.deliverNotification(ctx, &feishuNotifier{s: p.Service}, msg, "", ""); err != nil {
 		return errors.Wrapf(err, "send p0 operation msg fail, task id: %s", p.message.TaskID)
 	}
 	return nil
@@ -180,7 +177,8 @@ func (u *UserSubAppOperation) ShouldHandle(ctx context.Context) (bool, error) {
 
 // Handle implements MessageOperator
 func (u *UserSubAppOperation) Handle(ctx context.Context) error {
-	res, err := u.SendDingCropMessage(ctx, &req.AppOpMessage{
+	notifier := &dingTalkNotifier{s: u.Service, emails: u.emails}
+	err := u.deliverNotification(ctx, notifier, newNotificationMessage(u.message, u.project, u.task, &req.AppOpMessage{
 		Title:       fmt.Sprintf("%s 进行了%s操作", u.project.Name, entity.GetTaskActionDisplay(u.task.Action)),
 		ProjectName: u.project.Name,
 		AppName:     u.app.Name,
@@ -189,7 +187,7 @@ func (u *UserSubAppOperation) Handle(ctx context.Context) error {
 		OpTime:      u.message.OpTime,
 		UserName:    u.user.Name,
 		DetailURL:   u.GetAmsFrontendProjectURL(u.project.ID, u.message.Env),
-	}, u.emails, defaultTmpName, defaultAppOpMsg)
+	}), "", "")
 	if err != nil {
 		return err
 	}
@@ -209,7 +207,7 @@ func (u *UserSubAppOperation) Handle(ctx context.Context) error {
 			ProjectID:  u.app.ProjectID,
 			Env:        u.message.Env,
 		},
-		TaskID:     res.TaskID,
+		TaskID:     notifier.result.TaskID,
 		CreateTime: &now,
 		UpdateTime: &now,
 	})
@@ -244,7 +242,8 @@ func (f *FailedTaskOperation) ShouldHandle(ctx context.Context) (bool, error) {
 
 // Handle implements MessageOperator
 func (f *FailedTaskOperation) Handle(ctx context.Context) error {
-	res, err := f.SendDingCropMessage(ctx, &req.AppOpMessage{
+	notifier := &dingTalkNotifier{s: f.Service, emails: []string{f.user.Email}}
+	err := f.deliverNotification(ctx, notifier, newNotificationMessage(f.message, f.project, f.task, &req.AppOpMessage{
 		Title: fmt.Sprintf("%s的应用%s%s失败", f.project.Name,
 			f.app.Name, entity.GetTaskActionDisplay(f.task.Action)),
 		ProjectName: f.project.Name,
@@ -254,7 +253,7 @@ func (f *FailedTaskOperation) Handle(ctx context.Context) error {
 		OpTime:      f.message.OpTime,
 		UserName:    f.user.Name,
 		DetailURL:   f.GetAmsFrontendProjectURL(f.project.ID, f.message.Env),
-	}, []string{f.user.Email}, defaultTmpName, defaultAppOpMsg)
+	}), "", "")
 	if err != nil {
 		return err
 	}
@@ -274,12 +273,93 @@ func (f *FailedTaskOperation) Handle(ctx context.Context) error {
 			ProjectID:  f.app.ProjectID,
 			Env:        f.message.Env,
 		},
-		TaskID:     res.TaskID,
+		TaskID:     notifier.result.TaskID,
 		CreateTime: &now,
 		UpdateTime: &now,
 	})
 }
 
+// ProjectNotificationOperation represents operations routed by project notification rules
+type ProjectNotificationOperation struct {
+	*Service
+	message *entity.SubscribeEventMsg
+	project *resp.ProjectDetailResp
+	app     *resp.AppDetailResp
+	task    *resp.TaskDetailResp
+	user    *resp.UserProfileResp
+
+	rules []*entity.NotificationRule
+}
+
+func newProjectNotificationOperation(service *Service, message *entity.SubscribeEventMsg, project *resp.ProjectDetailResp,
+	app *resp.AppDetailResp, task *resp.TaskDetailResp, user *resp.UserProfileResp) MessageOperator {
+	return &ProjectNotificationOperation{
+		Service: service,
+		message: message,
+		project: project,
+		app:     app,
+		task:    task,
+		user:    user,
+	}
+}
+
+// ShouldHandle implements MessageOperator
+func (o *ProjectNotificationOperation) ShouldHandle(ctx context.Context) (bool, error) {
+	event := entity.GetNotificationEvent(o.message.ActionType, o.task.Status)
+	if event == "" {
+		return false, nil
+	}
+
+	rules, err := o.GetMatchedNotificationRules(ctx, o.project.ID, o.message.Env, event)
+	if err != nil {
+		return false, err
+	}
+	o.rules = rules
+
+	return len(o.rules) > 0, nil
+}
+
+// Handle implements MessageOperator
+func (o *ProjectNotificationOperation) Handle(ctx context.Context) error {
+	title := fmt.Sprintf("%s 进行了%s操作", o.project.Name, entity.GetSubscribeActionDisplay(o.message.ActionType))
+	if o.task.Status == entity.TaskStatusFail {
+		title = fmt.Sprintf("%s的应用%s%s失败", o.project.Name, o.app.Name, entity.GetSubscribeActionDisplay(o.message.ActionType))
+	}
+
+	msg := newNotificationMessage(o.message, o.project, o.task, &req.AppOpMessage{
+		Title:           title,
+		ProjectName:     o.project.Name,
+		AppName:         o.app.Name,
+		UserName:        o.user.Name,
+		Env:             string(o.message.Env),
+		Action:          entity.GetSubscribeActionDisplay(o.message.ActionType),
+		OpTime:          o.message.OpTime,
+		DetailURL:       o.GetAmsFrontendProjectURL(o.project.ID, o.message.Env),
+		ProjectLanguage: o.project.Language,
+		Branch:          o.task.GetDeployBranch(),
+	})
+	if o.project.Team != nil {
+		msg.TeamName = o.project.Team.Name
+	}
+
+	if err := o.DispatchProjectNotifications(ctx, o.rules, msg); err != nil {
+		return errors.Wrapf(err, "dispatch project notification fail, task id: %s", o.message.TaskID)
+	}
+	return nil
+}
+
+// newNotificationMessage 根据订阅事件生成通知消息
+func newNotificationMessage(message *entity.SubscribeEventMsg, project *resp.ProjectDetailResp,
+	task *resp.TaskDetailResp, appOpMessage *req.AppOpMessage) *req.NotificationMessage {
+	return &req.NotificationMessage{
+		AppOpMessage: appOpMessage,
+		Event:        entity.GetNotificationEvent(message.ActionType, task.Status),
+		ProjectID:    project.ID,
+		AppID:        message.AppID,
+		TaskID:       message.TaskID,
+	}
+}
+
 // HandleAppOpMsgEvent handle message event
 func (s *Service) HandleAppOpMsgEvent(ctx context.Context, msg *sarama.ConsumerMessage) error {
 	message := new(entity.SubscribeEventMsg)
@@ -310,6 +390,7 @@ func (s *Service) HandleAppOpMsgEvent(ctx context.Context, msg *sarama.ConsumerM
 
 	operationMsgEvents := []MessageOperator{
 		newP0AppOperation(s, message, project, app, task, user),
+		newProjectNotificationOperation(s, message, project, app, task, user),
 		// newUserSubAppOperation(s, message, project, app, task, user),
 		// newFailedTaskOperation(s, message, project, app, task, user),
 	}
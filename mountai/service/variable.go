@@ -37,11 +37,13 @@ func (s *Service) getVariableFilter(_ context.Context, getReq *req.GetVariablesR
 	return filter
 }
 
+// GetProjectVariables 获取项目的普通变量, 密钥变量不参与镜像参数渲染
 func (s *Service) GetProjectVariables(ctx context.Context, projectID string) ([]*entity.Variable, error) {
 	filter := s.getVariableFilter(ctx, &req.GetVariablesReq{
 		ProjectID: projectID,
 		Type:      entity.ProjectVariableType,
 	})
+	filter["secret"] = bson.M{"$ne": true}
 
 	return s.dao.FindVariables(ctx, filter, dao.MongoFindOptionWithSortByIDAsc)
 }
@@ -78,7 +80,9 @@ func (s *Service) GetVariables(ctx context.Context, getReq *req.GetVariablesReq,
 	for idx, variable := range res {
 		userIDs = append(userIDs, variables[idx].OwnerID, variables[idx].EditorID)
 
-		if hiddenVal {
+		if variables[idx].Secret {
+			variable.Value = entity.SecretVariableMask
+		} else if hiddenVal {
 			variable.Value = ""
 		}
 	}
@@ -111,7 +115,7 @@ func (s *Service) CountVariable(ctx context.Context, filter bson.M) (int, error)
 	return s.dao.CountVariable(ctx, filter)
 }
 
-func (s *Service) UpdateSingleVariable(ctx context.Context, variableID string, updateReq *req.UpdateVariableReq) error {
+func (s *Service) UpdateSingleVariable(ctx context.Context, variable *entity.Variable, updateReq *req.UpdateVariableReq) error {
 	changeMap := make(map[string]interface{})
 	changeMap["update_time"] = time.Now()
 	changeMap["editor_id"] = updateReq.OperatorID
@@ -122,9 +126,18 @@ func (s *Service) UpdateSingleVariable(ctx context.Context, variableID string, u
 
 	if updateReq.Value != "" {
 		changeMap["value"] = updateReq.Value
+
+		if variable.Secret {
+			envelope, err := s.encryptSecretVariable(updateReq.Value)
+			if err != nil {
+				return err
+			}
+			changeMap["value"] = envelope.Ciphertext
+			changeMap["encrypted_data_key"] = envelope.EncryptedDataKey
+		}
 	}
 
-	return s.dao.UpdateSingleVariableByID(ctx, variableID, bson.M{
+	return s.dao.UpdateSingleVariableByID(ctx, variable.ID.Hex(), bson.M{
 		"$set": changeMap,
 	})
 }
@@ -137,6 +150,7 @@ func (s *Service) CreateSingleVariable(ctx context.Context, createReq *req.Creat
 		Key:        createReq.Key,
 		Value:      createReq.Value,
 		Type:       createReq.Type,
+		Secret:     createReq.Secret,
 		OwnerID:    createReq.OperatorID,
 		EditorID:   createReq.OperatorID,
 		CreateTime: &now,
@@ -147,6 +161,15 @@ func (s *Service) CreateSingleVariable(ctx context.Context, createReq *req.Creat
 		variable.ProjectID = createReq.ProjectID
 	}
 
+	if createReq.Secret {
+		envelope, err := s.encryptSecretVariable(createReq.Value)
+		if err != nil {
+			return nil, err
+		}
+		variable.Value = envelope.Ciphertext
+		variable.EncryptedDataKey = envelope.EncryptedDataKey
+	}
+
 	err := s.dao.CreateSingleVariable(ctx, variable)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"time"
+
+	"github.com/pkg/errors"
+	"gitlab.shanhai.int/sre/library/base/deepcopy.v2"
+	"gitlab.shanhai.int/sre/library/base/encrypt"
+	"gitlab.shanhai.int/sre/library/net/errcode"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"rulai/config"
+	"rulai/dao"
+	"rulai/models/entity"
+	"rulai/models/req"
+	"rulai/models/resp"
+)
+
+func (s *Service) getSecretMasterKey() ([]byte, error) {
+	if config.Conf.Secret == nil || config.Conf.Secret.MasterKey == "" {
+		return nil, errors.Wrap(errcode.InternalError, "secret master key is not configured")
+	}
+
+	key, err := base64.StdEncoding.DecodeString(config.Conf.Secret.MasterKey)
+	if err != nil {
+		return nil, errors.Wrapf(errcode.InternalError, "decode secret master key error: %s", err)
+	}
+
+	return key, nil
+}
+
+func (s *Service) encryptSecretVariable(value string) (*encrypt.Envelope, error) {
+	masterKey, err := s.getSecretMasterKey()
+	if err != nil {
+		return nil, err
+	}
+
+	envelope, err := encrypt.EnvelopeEncrypt(masterKey, []byte(value))
+	if err != nil {
+		return nil, errors.Wrap(errcode.InternalError, err.Error())
+	}
+
+	return envelope, nil
+}
+
+// GetProjectSecretVariables 获取项目中指定的密钥变量(密文), 不存在的变量名不会返回
+func (s *Service) GetProjectSecretVariables(ctx context.Context, projectID string, keys []string) ([]*entity.Variable, error) {
+	filter := s.getVariableFilter(ctx, &req.GetVariablesReq{
+		ProjectID: projectID,
+		Type:      entity.ProjectVariableType,
+	})
+	filter["secret"] = true
+	filter["key"] = bson.M{"$in": keys}
+
+	return s.dao.FindVariables(ctx, filter, dao.MongoFindOptionWithSortByIDAsc)
+}
+
+// ReadSecretVariables 解密项目的密钥变量, 每次读取都会记录审计
+func (s *Service) ReadSecretVariables(ctx context.Context, projectID string, keys []string,
+	audit *entity.VariableAudit) (map[string]string, error) {
+	variables, err := s.GetProjectSecretVariables(ctx, projectID, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(variables) != len(keys) {
+		return nil, errors.Wrapf(errcode.InvalidParams, "secret variables of project(%s) not found", projectID)
+	}
+
+	masterKey, err := s.getSecretMasterKey()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	res := make(map[string]string, len(variables))
+	audits := make([]*entity.VariableAudit, len(variables))
+	for i, variable := range variables {
+		value, e := encrypt.EnvelopeDecrypt(masterKey, &encrypt.Envelope{
+			EncryptedDataKey: variable.EncryptedDataKey,
+			Ciphertext:       variable.Value,
+		})
+		if e != nil {
+			return nil, errors.Wrapf(errcode.InternalError, "decrypt secret variable(%s) error: %s", variable.Key, e)
+		}
+		res[variable.Key] = string(value)
+
+		audits[i] = &entity.VariableAudit{
+			ID:         primitive.NewObjectID(),
+			VariableID: variable.ID.Hex(),
+			ProjectID:  projectID,
+			Key:        variable.Key,
+			Action:     audit.Action,
+			OperatorID: audit.OperatorID,
+			TaskID:     audit.TaskID,
+			CreateTime: &now,
+		}
+	}
+
+	// 审计记录写入失败时不返回明文
+	err = s.dao.CreateVariableAudits(ctx, audits)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// GetVariableAudits 获取密钥变量的读取审计记录
+func (s *Service) GetVariableAudits(ctx context.Context, getReq *req.GetVariableAuditsReq) ([]*resp.VariableAuditResp, int, error) {
+	limit := int64(getReq.Limit)
+	skip := int64(getReq.Page-1) * limit
+	filter := bson.M{"variable_id": getReq.VariableID}
+
+	audits, err := s.dao.FindVariableAudits(ctx, filter, &options.FindOptions{
+		Limit: &limit,
+		Skip:  &skip,
+		Sort:  dao.MongoSortByCreateTimeDesc,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	count, err := s.dao.CountVariableAudits(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	res := make([]*resp.VariableAuditResp, 0)
+	err = deepcopy.Copy(&audits).To(&res)
+	if err != nil {
+		return nil, 0, errors.Wrap(errcode.InternalError, err.Error())
+	}
+
+	userIDs := make([]string, len(audits))
+	for i := range audits {
+		userIDs[i] = audits[i].OperatorID
+	}
+
+	usersInfo, err := s.GetUsersInfo(ctx, userIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for i := range res {
+		res[i].Operator = usersInfo[audits[i].OperatorID]
+	}
+
+	return res, count, nil
+}
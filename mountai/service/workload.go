@@ -0,0 +1,256 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gitlab.shanhai.int/sre/library/net/errcode"
+	v1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"rulai/models/entity"
+	"rulai/models/req"
+	"rulai/models/resp"
+)
+
+// workload 可插拔工作负载的 k8s 操作接口
+// 新增工作负载类型时实现该接口并在 workloadFactories 中注册, 状态机及运行状态查询均通过该接口完成
+type workload interface {
+	// Render 渲染工作负载模板
+	Render(ctx context.Context, project *resp.ProjectDetailResp, app *resp.AppDetailResp,
+		task *resp.TaskDetailResp, team *resp.TeamDetailResp) ([]byte, error)
+	// Apply 声明式创建/更新工作负载
+	Apply(ctx context.Context, clusterName entity.ClusterName, yamlData []byte, env string) error
+	// GetStatus 获取工作负载状态, 不存在时返回 K8sResourceNotFoundError
+	GetStatus(ctx context.Context, clusterName entity.ClusterName, getReq *req.GetWorkloadDetailReq) (*workloadStatus, error)
+	// List 按标签获取工作负载状态列表
+	List(ctx context.Context, clusterName entity.ClusterName, getReq *req.GetWorkloadsReq) ([]*workloadStatus, error)
+	// Restart 滚动重启工作负载
+	Restart(ctx context.Context, clusterName entity.ClusterName, restartReq *req.GetWorkloadDetailReq) error
+	// Scale 伸缩实例数, 仅 WorkloadType.Scalable 为真时调用
+	Scale(ctx context.Context, clusterName entity.ClusterName, scaleReq *req.GetWorkloadDetailReq, replicas int) error
+	// Delete 删除工作负载
+	Delete(ctx context.Context, clusterName entity.ClusterName, deleteReq *req.DeleteWorkloadReq) error
+}
+
+// workloadStatus 各类工作负载统一的运行状态
+type workloadStatus struct {
+	Name       string
+	Namespace  string
+	CreateTime metav1.Time
+	Image      string
+	// 期望实例数
+	DesiredReplicas int
+	// 已就绪实例数
+	ReadyReplicas int
+	// 最新的 spec 已全部滚动完成
+	RolledOut bool
+}
+
+// workloadFactories 各 k8s 资源类型对应的 workload 实现
+var workloadFactories = map[string]func(s *Service) workload{
+	entity.K8sObjectKindStatefulSet: func(s *Service) workload { return &statefulSetWorkload{s: s} },
+	entity.K8sObjectKindDaemonSet:   func(s *Service) workload { return &daemonSetWorkload{s: s} },
+}
+
+// getWorkload 获取应用类型对应的可插拔工作负载, 非可插拔类型返回 false
+func (s *Service) getWorkload(appType entity.AppType) (workload, *entity.WorkloadType, bool) {
+	wt, ok := entity.GetWorkloadType(appType)
+	if !ok {
+		return nil, nil, false
+	}
+
+	factory, ok := workloadFactories[wt.Kind]
+	if !ok {
+		return nil, nil, false
+	}
+
+	return factory(s), wt, true
+}
+
+func (s *Service) getWorkloadsLabelSelector(getReq *req.GetWorkloadsReq) string {
+	labels := make([]string, 0)
+	if getReq.ProjectName != "" {
+		labels = append(labels, fmt.Sprintf("project=%s", getReq.ProjectName))
+	}
+
+	if getReq.AppName != "" {
+		labels = append(labels, fmt.Sprintf("app=%s", getReq.AppName))
+	}
+
+	if getReq.Version != "" {
+		labels = append(labels, fmt.Sprintf("version=%s", getReq.Version))
+	}
+	return strings.Join(labels, ",")
+}
+
+// statefulSetWorkload StatefulSet 工作负载
+type statefulSetWorkload struct {
+	s *Service
+}
+
+func (w *statefulSetWorkload) Render(ctx context.Context, project *resp.ProjectDetailResp, app *resp.AppDetailResp,
+	task *resp.TaskDetailResp, team *resp.TeamDetailResp) ([]byte, error) {
+	return w.s.RenderStatefulSetTemplate(ctx, project, app, task, team)
+}
+
+func (w *statefulSetWorkload) Apply(ctx context.Context, clusterName entity.ClusterName, yamlData []byte, env string) error {
+	_, err := w.s.ApplyStatefulSet(ctx, clusterName, yamlData, env)
+	return err
+}
+
+func (w *statefulSetWorkload) GetStatus(ctx context.Context, clusterName entity.ClusterName,
+	getReq *req.GetWorkloadDetailReq) (*workloadStatus, error) {
+	statefulSet, err := w.s.GetStatefulSetDetail(ctx, clusterName, getReq)
+	if err != nil {
+		return nil, err
+	}
+
+	return w.toStatus(statefulSet), nil
+}
+
+func (w *statefulSetWorkload) List(ctx context.Context, clusterName entity.ClusterName,
+	getReq *req.GetWorkloadsReq) ([]*workloadStatus, error) {
+	list, err := w.s.GetStatefulSets(ctx, clusterName, getReq)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*workloadStatus, len(list))
+	for i := range list {
+		res[i] = w.toStatus(&list[i])
+	}
+	return res, nil
+}
+
+func (w *statefulSetWorkload) Restart(ctx context.Context, clusterName entity.ClusterName,
+	restartReq *req.GetWorkloadDetailReq) error {
+	_, err := w.s.RestartStatefulSet(ctx, clusterName, restartReq)
+	return err
+}
+
+func (w *statefulSetWorkload) Scale(ctx context.Context, clusterName entity.ClusterName,
+	scaleReq *req.GetWorkloadDetailReq, replicas int) error {
+	_, err := w.s.UpdateStatefulSetScale(ctx, clusterName, scaleReq, replicas)
+	return err
+}
+
+func (w *statefulSetWorkload) Delete(ctx context.Context, clusterName entity.ClusterName,
+	deleteReq *req.DeleteWorkloadReq) error {
+	return w.s.DeleteStatefulSet(ctx, clusterName, deleteReq)
+}
+
+// toStatus 滚动完成的判断与 kubectl rollout status 一致
+func (w *statefulSetWorkload) toStatus(statefulSet *v1.StatefulSet) *workloadStatus {
+	desired := 1
+	if statefulSet.Spec.Replicas != nil {
+		desired = int(*statefulSet.Spec.Replicas)
+	}
+
+	res := &workloadStatus{
+		Name:            statefulSet.GetName(),
+		Namespace:       statefulSet.GetNamespace(),
+		CreateTime:      statefulSet.GetCreationTimestamp(),
+		DesiredReplicas: desired,
+		ReadyReplicas:   int(statefulSet.Status.ReadyReplicas),
+		RolledOut: statefulSet.Status.ObservedGeneration >= statefulSet.GetGeneration() &&
+			int(statefulSet.Status.ReadyReplicas) == desired &&
+			int(statefulSet.Status.UpdatedReplicas) == desired &&
+			statefulSet.Status.CurrentRevision == statefulSet.Status.UpdateRevision,
+	}
+	if len(statefulSet.Spec.Template.Spec.Containers) > 0 {
+		res.Image = statefulSet.Spec.Template.Spec.Containers[0].Image
+	}
+	return res
+}
+
+// daemonSetWorkload DaemonSet 工作负载
+type daemonSetWorkload struct {
+	s *Service
+}
+
+func (w *daemonSetWorkload) Render(ctx context.Context, project *resp.ProjectDetailResp, app *resp.AppDetailResp,
+	task *resp.TaskDetailResp, team *resp.TeamDetailResp) ([]byte, error) {
+	return w.s.RenderDaemonSetTemplate(ctx, project, app, task, team)
+}
+
+func (w *daemonSetWorkload) Apply(ctx context.Context, clusterName entity.ClusterName, yamlData []byte, env string) error {
+	_, err := w.s.ApplyDaemonSet(ctx, clusterName, yamlData, env)
+	return err
+}
+
+func (w *daemonSetWorkload) GetStatus(ctx context.Context, clusterName entity.ClusterName,
+	getReq *req.GetWorkloadDetailReq) (*workloadStatus, error) {
+	daemonSet, err := w.s.GetDaemonSetDetail(ctx, clusterName, getReq)
+	if err != nil {
+		return nil, err
+	}
+
+	return w.toStatus(daemonSet), nil
+}
+
+func (w *daemonSetWorkload) List(ctx context.Context, clusterName entity.ClusterName,
+	getReq *req.GetWorkloadsReq) ([]*workloadStatus, error) {
+	list, err := w.s.GetDaemonSets(ctx, clusterName, getReq)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*workloadStatus, len(list))
+	for i := range list {
+		res[i] = w.toStatus(&list[i])
+	}
+	return res, nil
+}
+
+func (w *daemonSetWorkload) Restart(ctx context.Context, clusterName entity.ClusterName,
+	restartReq *req.GetWorkloadDetailReq) error {
+	_, err := w.s.RestartDaemonSet(ctx, clusterName, restartReq)
+	return err
+}
+
+func (w *daemonSetWorkload) Scale(_ context.Context, _ entity.ClusterName, _ *req.GetWorkloadDetailReq, _ int) error {
+	return errors.Wrap(errcode.InvalidParams, "DaemonSet does not support scale")
+}
+
+func (w *daemonSetWorkload) Delete(ctx context.Context, clusterName entity.ClusterName,
+	deleteReq *req.DeleteWorkloadReq) error {
+	return w.s.DeleteDaemonSet(ctx, clusterName, deleteReq)
+}
+
+// toStatus DaemonSet 的期望实例数为调度到的节点数
+func (w *daemonSetWorkload) toStatus(daemonSet *v1.DaemonSet) *workloadStatus {
+	desired := int(daemonSet.Status.DesiredNumberScheduled)
+	res := &workloadStatus{
+		Name:            daemonSet.GetName(),
+		Namespace:       daemonSet.GetNamespace(),
+		CreateTime:      daemonSet.GetCreationTimestamp(),
+		DesiredReplicas: desired,
+		ReadyReplicas:   int(daemonSet.Status.NumberReady),
+		RolledOut: daemonSet.Status.ObservedGeneration >= daemonSet.GetGeneration() &&
+			int(daemonSet.Status.UpdatedNumberScheduled) == desired &&
+			int(daemonSet.Status.NumberAvailable) == desired,
+	}
+	if len(daemonSet.Spec.Template.Spec.Containers) > 0 {
+		res.Image = daemonSet.Spec.Template.Spec.Containers[0].Image
+	}
+	return res
+}
+
+// CheckWorkloadsExistance 校验应用的可插拔工作负载是否存在
+func (s *Service) CheckWorkloadsExistance(ctx context.Context, clusterName entity.ClusterName,
+	appType entity.AppType, getReq *req.GetWorkloadsReq) (bool, error) {
+	w, _, ok := s.getWorkload(appType)
+	if !ok {
+		return false, errors.Wrapf(errcode.InvalidParams, "%s is not a pluggable workload type", appType)
+	}
+
+	list, err := w.List(ctx, clusterName, getReq)
+	if err != nil {
+		return false, err
+	}
+
+	return len(list) > 0, nil
+}
@@ -0,0 +1,18 @@
+sum (
+    rate (
+        web_response_total {
+            namespace="{{.Namespace}}",
+            pod=~"{{.DeploymentName}}-[a-z0-9]+-[a-z0-9]+",
+            status_code=~"5.."
+        } [{{.CountTime}}]
+    )
+)
+/
+sum (
+    rate (
+        web_response_total {
+            namespace="{{.Namespace}}",
+            pod=~"{{.DeploymentName}}-[a-z0-9]+-[a-z0-9]+"
+        } [{{.CountTime}}]
+    )
+)
\ No newline at end of file
@@ -0,0 +1,9 @@
+max (
+    max_over_time (
+        web_request_duration_millisecond_summary {
+            namespace="{{.Namespace}}",
+            pod=~"{{.DeploymentName}}-[a-z0-9]+-[a-z0-9]+",
+            quantile="0.99"
+        } [{{.CountTime}}]
+    )
+)
\ No newline at end of file
@@ -0,0 +1,13 @@
+quantile_over_time (
+    {{.Quantile}},
+    max (
+        rate (
+            container_cpu_usage_seconds_total {
+                {{.ContainerLabelName}}=~"{{.ContainerName}}",
+                image!="",
+                job="kubelet",
+                namespace=~"{{.Namespace}}"
+            } [5m]
+        )
+    ) [{{.CountTime}}:5m]
+)
\ No newline at end of file
@@ -0,0 +1,11 @@
+quantile_over_time (
+    {{.Quantile}},
+    max (
+        container_memory_working_set_bytes {
+            {{.ContainerLabelName}}=~"{{.ContainerName}}",
+            image!="",
+            job="kubelet",
+            namespace=~"{{.Namespace}}"
+        }
+    ) [{{.CountTime}}:5m]
+)
\ No newline at end of file
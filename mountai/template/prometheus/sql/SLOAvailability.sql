@@ -0,0 +1,18 @@
+sum (
+    increase (
+        web_response_total {
+            namespace=~"{{.Namespace}}",
+            pod=~"{{.PodRegex}}",
+            status_code=~"5.."
+        } [{{.Window}}]
+    )
+)
+/
+sum (
+    increase (
+        web_response_total {
+            namespace=~"{{.Namespace}}",
+            pod=~"{{.PodRegex}}"
+        } [{{.Window}}]
+    )
+)
\ No newline at end of file
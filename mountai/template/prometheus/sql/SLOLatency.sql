@@ -0,0 +1,11 @@
+avg_over_time (
+    (
+        max (
+            web_request_duration_millisecond_summary {
+                namespace=~"{{.Namespace}}",
+                pod=~"{{.PodRegex}}",
+                quantile="0.99"
+            }
+        ) > bool {{.LatencyThresholdMs}}
+    ) [{{.Window}}:1m]
+)
\ No newline at end of file
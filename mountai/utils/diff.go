@@ -0,0 +1,142 @@
+package utils
+
+import (
+	"rulai/models/resp"
+
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// DiffObjectFields 比较线上对象与期望对象的字段差异
+// 仅比较期望对象中声明的字段, 线上对象中由 K8s 填充的默认值及状态不计入差异;
+// exactPaths 中的 map 字段(如 metadata.labels)按全量比较, 线上多出的键记为删除
+func DiffObjectFields(live, desired map[string]interface{}, exactPaths ...string) []*resp.K8sObjectFieldDiff {
+	exact := make(map[string]bool, len(exactPaths))
+	for _, path := range exactPaths {
+		exact[path] = true
+	}
+
+	diffs := make([]*resp.K8sObjectFieldDiff, 0)
+	diffFieldValue("", live, desired, exact, &diffs)
+
+	return diffs
+}
+
+func diffFieldValue(path string, live, desired interface{}, exact map[string]bool, diffs *[]*resp.K8sObjectFieldDiff) {
+	switch desiredValue := desired.(type) {
+	case map[string]interface{}:
+		liveValue, ok := live.(map[string]interface{})
+		if !ok {
+			*diffs = append(*diffs, &resp.K8sObjectFieldDiff{
+				Path: path,
+				Type: resp.K8sObjectFieldDiffTypeChanged,
+				Old:  live,
+				New:  desired,
+			})
+			return
+		}
+
+		for _, key := range SortedMapKeys(desiredValue) {
+			value := desiredValue[key]
+			if value == nil {
+				continue
+			}
+
+			fieldPath := joinFieldPath(path, key)
+			old, ok := liveValue[key]
+			if !ok {
+				*diffs = append(*diffs, &resp.K8sObjectFieldDiff{
+					Path: fieldPath,
+					Type: resp.K8sObjectFieldDiffTypeAdded,
+					New:  value,
+				})
+				continue
+			}
+
+			diffFieldValue(fieldPath, old, value, exact, diffs)
+		}
+
+		if !exact[path] {
+			return
+		}
+
+		for _, key := range SortedMapKeys(liveValue) {
+			if _, ok := desiredValue[key]; ok {
+				continue
+			}
+
+			*diffs = append(*diffs, &resp.K8sObjectFieldDiff{
+				Path: joinFieldPath(path, key),
+				Type: resp.K8sObjectFieldDiffTypeRemoved,
+				Old:  liveValue[key],
+			})
+		}
+	case []interface{}:
+		liveValue, ok := live.([]interface{})
+		// 列表长度变化时整体记为变更, 避免按下标比较产生误导
+		if !ok || len(liveValue) != len(desiredValue) {
+			*diffs = append(*diffs, &resp.K8sObjectFieldDiff{
+				Path: path,
+				Type: resp.K8sObjectFieldDiffTypeChanged,
+				Old:  live,
+				New:  desired,
+			})
+			return
+		}
+
+		for i := range desiredValue {
+			diffFieldValue(fmt.Sprintf("%s[%d]", path, i), liveValue[i], desiredValue[i], exact, diffs)
+		}
+	default:
+		if !equalFieldScalar(live, desired) {
+			*diffs = append(*diffs, &resp.K8sObjectFieldDiff{
+				Path: path,
+				Type: resp.K8sObjectFieldDiffTypeChanged,
+				Old:  live,
+				New:  desired,
+			})
+		}
+	}
+}
+
+// equalFieldScalar 比较标量字段, 数值统一按 float64 比较(yaml 解析与 K8s 返回的数值类型不同)
+func equalFieldScalar(a, b interface{}) bool {
+	fa, okA := toFloat64(a)
+	fb, okB := toFloat64(b)
+	if okA && okB {
+		return fa == fb
+	}
+
+	return reflect.DeepEqual(a, b)
+}
+
+func toFloat64(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+
+	return 0, false
+}
+
+func joinFieldPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+
+	// 含有 "." 的键(如注解名)使用下标形式避免歧义
+	if strings.Contains(key, ".") || strings.Contains(key, "/") {
+		return fmt.Sprintf("%s[%q]", path, key)
+	}
+
+	return path + "." + key
+}
@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"rulai/models/resp"
+
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffObjectFields(t *testing.T) {
+	live := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name":            "demo",
+			"resourceVersion": "12",
+			"labels": map[string]interface{}{
+				"app":     "demo",
+				"version": "v1",
+			},
+			"annotations": map[string]interface{}{
+				"deployment.kubernetes.io/revision": "3",
+			},
+		},
+		"spec": map[string]interface{}{
+			"replicas": int64(2),
+			"containers": []interface{}{
+				map[string]interface{}{"name": "demo", "image": "demo:v1", "imagePullPolicy": "IfNotPresent"},
+			},
+		},
+	}
+
+	t.Run("unchanged", func(t *testing.T) {
+		desired := map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"name":              "demo",
+				"creationTimestamp": nil,
+				"labels":            map[string]interface{}{"app": "demo", "version": "v1"},
+			},
+			"spec": map[string]interface{}{
+				"replicas":   float64(2),
+				"containers": []interface{}{map[string]interface{}{"name": "demo", "image": "demo:v1"}},
+			},
+		}
+
+		assert.Empty(t, DiffObjectFields(live, desired, "metadata.labels"))
+	})
+
+	t.Run("changed", func(t *testing.T) {
+		desired := map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"labels": map[string]interface{}{"app": "demo", "env": "prd"},
+				"annotations": map[string]interface{}{
+					"deployment.kubernetes.io/revision": "4",
+				},
+			},
+			"spec": map[string]interface{}{
+				"containers": []interface{}{map[string]interface{}{"name": "demo", "image": "demo:v2"}},
+			},
+		}
+
+		diffs := DiffObjectFields(live, desired, "metadata.labels")
+		assert.Equal(t, []*resp.K8sObjectFieldDiff{
+			{Path: `metadata.annotations["deployment.kubernetes.io/revision"]`, Type: resp.K8sObjectFieldDiffTypeChanged, Old: "3", New: "4"},
+			{Path: "metadata.labels.env", Type: resp.K8sObjectFieldDiffTypeAdded, New: "prd"},
+			{Path: "metadata.labels.version", Type: resp.K8sObjectFieldDiffTypeRemoved, Old: "v1"},
+			{Path: "spec.containers[0].image", Type: resp.K8sObjectFieldDiffTypeChanged, Old: "demo:v1", New: "demo:v2"},
+		}, diffs)
+	})
+
+	t.Run("list length changed", func(t *testing.T) {
+		desired := map[string]interface{}{
+			"spec": map[string]interface{}{
+				"containers": []interface{}{},
+			},
+		}
+
+		diffs := DiffObjectFields(live, desired)
+		assert.Len(t, diffs, 1)
+		assert.Equal(t, "spec.containers", diffs[0].Path)
+	})
+}
@@ -94,4 +94,7 @@ var (
 	DuplicatedImageTagError       = errcode.New(9010081, "镜像标签已经存在,请重新提交代码并重新构建镜像")
 	QDNSTagNotFound               = errcode.New(9010082, "QDNS Tag 无法找到资源")
 	FeishuInternalError           = errcode.New(9010083, "飞书内部错误")
+	DeployFrozenError             = errcode.New(9010084, "当前处于封网窗口内").WithStatusCode(http.StatusForbidden)
+	NotificationDeliveryError     = errcode.New(9010085, "通知发送失败")
+	ErrorBudgetExhaustedError     = errcode.New(9010086, "错误预算已耗尽").WithStatusCode(http.StatusForbidden)
 )
@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"hash/crc32"
+	"sort"
+	"strconv"
+)
+
+// DefaultHashRingVirtualNodes 一致性哈希环每个节点默认的虚拟节点数
+const DefaultHashRingVirtualNodes = 100
+
+// HashRing 一致性哈希环, 节点增减时只迁移相邻区间的 key
+type HashRing struct {
+	hashes []uint32
+	nodes  map[uint32]string
+}
+
+// NewHashRing 新建一致性哈希环, virtualNodes 不大于 0 时使用默认值
+func NewHashRing(nodes []string, virtualNodes int) *HashRing {
+	if virtualNodes <= 0 {
+		virtualNodes = DefaultHashRingVirtualNodes
+	}
+
+	r := &HashRing{
+		hashes: make([]uint32, 0, len(nodes)*virtualNodes),
+		nodes:  make(map[uint32]string, len(nodes)*virtualNodes),
+	}
+	for _, node := range nodes {
+		for i := 0; i < virtualNodes; i++ {
+			h := crc32.ChecksumIEEE([]byte(node + "#" + strconv.Itoa(i)))
+			// 哈希冲突时保留字典序较小的节点, 保证各副本计算结果一致
+			if exist, ok := r.nodes[h]; ok {
+				if exist > node {
+					r.nodes[h] = node
+				}
+				continue
+			}
+			r.nodes[h] = node
+			r.hashes = append(r.hashes, h)
+		}
+	}
+	sort.Slice(r.hashes, func(i, j int) bool { return r.hashes[i] < r.hashes[j] })
+
+	return r
+}
+
+// Get 获取 key 所属节点, 环为空时返回空字符串
+func (r *HashRing) Get(key string) string {
+	if len(r.hashes) == 0 {
+		return ""
+	}
+
+	h := crc32.ChecksumIEEE([]byte(key))
+	i := sort.Search(len(r.hashes), func(i int) bool { return r.hashes[i] >= h })
+	if i == len(r.hashes) {
+		i = 0
+	}
+	return r.nodes[r.hashes[i]]
+}
+
+// HashShard 将 key 映射到 [0, shardCount) 的分片
+func HashShard(key string, shardCount int) int {
+	if shardCount <= 0 {
+		return 0
+	}
+	return int(crc32.ChecksumIEEE([]byte(key)) % uint32(shardCount))
+}
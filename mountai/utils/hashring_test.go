@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashRing_Get(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		assert.Equal(t, "", NewHashRing(nil, 0).Get("a"))
+	})
+
+	t.Run("stable", func(t *testing.T) {
+		r1 := NewHashRing([]string{"a", "b", "c"}, 0)
+		r2 := NewHashRing([]string{"c", "a", "b"}, 0)
+		for i := 0; i < 64; i++ {
+			assert.Equal(t, r1.Get(strconv.Itoa(i)), r2.Get(strconv.Itoa(i)))
+		}
+	})
+
+	t.Run("remove node", func(t *testing.T) {
+		before := NewHashRing([]string{"a", "b", "c"}, 0)
+		after := NewHashRing([]string{"a", "b"}, 0)
+		owners := map[string]int{}
+		for i := 0; i < 64; i++ {
+			key := strconv.Itoa(i)
+			owners[before.Get(key)]++
+			// 仅原属于被移除节点的 key 发生迁移
+			if before.Get(key) != "c" {
+				assert.Equal(t, before.Get(key), after.Get(key))
+			}
+			assert.NotEqual(t, "c", after.Get(key))
+		}
+		assert.Len(t, owners, 3)
+	})
+}
+
+func TestHashShard(t *testing.T) {
+	assert.Equal(t, 0, HashShard("app", 0))
+	for i := 0; i < 100; i++ {
+		shard := HashShard(strconv.Itoa(i), 16)
+		assert.True(t, shard >= 0 && shard < 16)
+		assert.Equal(t, shard, HashShard(strconv.Itoa(i), 16))
+	}
+}
@@ -0,0 +1,21 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignWebhookPayload(t *testing.T) {
+	body := []byte(`{"event":"deploy"}`)
+
+	signature := SignWebhookPayload("secret", 1700000000, body)
+	assert.Equal(t, "sha256=", signature[:len(WebhookSignaturePrefix)])
+	assert.Len(t, signature, len(WebhookSignaturePrefix)+64)
+	assert.Equal(t, signature, SignWebhookPayload("secret", 1700000000, body))
+
+	assert.True(t, VerifyWebhookSignature("secret", 1700000000, body, signature))
+	assert.False(t, VerifyWebhookSignature("other", 1700000000, body, signature))
+	assert.False(t, VerifyWebhookSignature("secret", 1700000001, body, signature))
+	assert.False(t, VerifyWebhookSignature("secret", 1700000000, []byte(`{}`), signature))
+}